@@ -15,6 +15,15 @@ type Cache interface {
 	Gets(ctx context.Context, keys []string) (map[string]string, error)
 	Delete(ctx context.Context, key string) error
 	Clear(ctx context.Context) error
+	// TTL returns the remaining lifetime of key, 0 if it does not expire, or
+	// ErrKeyNotFound
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// Expire sets the remaining lifetime of key without rewriting its value,
+	// e.g. to slide the expiry of a session. A non-positive ttl removes the
+	// expiry. It returns ErrKeyNotFound if key does not exist.
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	// GetWithTTL returns the value of key with its remaining lifetime, as TTL
+	GetWithTTL(ctx context.Context, key string) (string, time.Duration, error)
 }
 
 func SetTyped[T any](ctx context.Context, cache Cache, key string, value T, expiry time.Duration) error {
@@ -94,3 +94,45 @@ func (c *freeCache) Clear(ctx context.Context) error {
 	c.cache.Clear()
 	return nil
 }
+
+func (c *freeCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttlSeconds, err := c.cache.TTL([]byte(key))
+	if err != nil {
+		if err == freecache.ErrNotFound {
+			return 0, ErrKeyNotFound
+		}
+		return 0, fmt.Errorf("failed to get ttl of key %s: %w", key, err)
+	}
+	return time.Duration(ttlSeconds) * time.Second, nil
+}
+
+// Expire rounds ttl up to the second, the resolution of freecache, so that a
+// sub-second ttl does not remove the expiry
+func (c *freeCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	ttlSeconds := 0 // No expiry
+	if ttl > 0 {
+		ttlSeconds = int((ttl + time.Second - 1) / time.Second)
+	}
+
+	if err := c.cache.Touch([]byte(key), ttlSeconds); err != nil {
+		if err == freecache.ErrNotFound {
+			return ErrKeyNotFound
+		}
+		return fmt.Errorf("failed to expire key %s: %w", key, err)
+	}
+	return nil
+}
+
+func (c *freeCache) GetWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	data, expireAt, err := c.cache.GetWithExpiration([]byte(key))
+	if err != nil {
+		if err == freecache.ErrNotFound {
+			return "", 0, ErrKeyNotFound
+		}
+		return "", 0, fmt.Errorf("failed to get key %s: %w", key, err)
+	}
+	if expireAt == 0 {
+		return string(data), 0, nil
+	}
+	return string(data), max(time.Until(time.Unix(int64(expireAt), 0)), 0), nil
+}
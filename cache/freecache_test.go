@@ -397,6 +397,70 @@ func TestFreeCache_Expiry(t *testing.T) {
 	})
 }
 
+func TestFreeCache_TTL(t *testing.T) {
+	cache := createTestFreeCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "session", "data", time.Minute))
+	require.NoError(t, cache.Set(ctx, "forever", "data", 0))
+
+	t.Run("ttl", func(t *testing.T) {
+		ttl, err := cache.TTL(ctx, "session")
+		assert.NoError(t, err)
+		assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+		ttl, err = cache.TTL(ctx, "forever")
+		assert.NoError(t, err)
+		assert.Zero(t, ttl)
+
+		_, err = cache.TTL(ctx, "missing")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("get with ttl", func(t *testing.T) {
+		value, ttl, err := cache.GetWithTTL(ctx, "session")
+		assert.NoError(t, err)
+		assert.Equal(t, "data", value)
+		assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+		_, ttl, err = cache.GetWithTTL(ctx, "forever")
+		assert.NoError(t, err)
+		assert.Zero(t, ttl)
+
+		_, _, err = cache.GetWithTTL(ctx, "missing")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("expire extends the lifetime", func(t *testing.T) {
+		require.NoError(t, cache.Expire(ctx, "session", time.Hour))
+		ttl, err := cache.TTL(ctx, "session")
+		assert.NoError(t, err)
+		assert.InDelta(t, time.Hour, ttl, float64(time.Second))
+
+		value, err := cache.Get(ctx, "session")
+		assert.NoError(t, err)
+		assert.Equal(t, "data", value)
+	})
+
+	t.Run("expire rounds up sub-second ttl", func(t *testing.T) {
+		require.NoError(t, cache.Expire(ctx, "session", 100*time.Millisecond))
+		ttl, err := cache.TTL(ctx, "session")
+		assert.NoError(t, err)
+		assert.Positive(t, ttl)
+	})
+
+	t.Run("expire removes the expiry", func(t *testing.T) {
+		require.NoError(t, cache.Expire(ctx, "session", 0))
+		ttl, err := cache.TTL(ctx, "session")
+		assert.NoError(t, err)
+		assert.Zero(t, ttl)
+	})
+
+	t.Run("expire missing key", func(t *testing.T) {
+		assert.Equal(t, ErrKeyNotFound, cache.Expire(ctx, "missing", time.Minute))
+	})
+}
+
 func TestFreeCache_Concurrent(t *testing.T) {
 	cache := createTestFreeCache(t)
 	ctx := context.Background()
@@ -0,0 +1,256 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Operations reported to a StatsRecorder
+const (
+	OperationGet    = "get"
+	OperationGets   = "gets"
+	OperationSet    = "set"
+	OperationSetNX  = "setnx"
+	OperationSets   = "sets"
+	OperationSetsNX = "setsnx"
+	OperationDelete = "delete"
+	OperationClear  = "clear"
+	OperationTTL    = "ttl"
+	OperationExpire = "expire"
+)
+
+// OperationRecord describes one call to an InstrumentedCache
+type OperationRecord struct {
+	Cache     string // name set with WithCacheName
+	Operation string
+	Keys      int // number of keys read or written
+	Hits      int // keys found, for get and gets
+	Misses    int // keys not found, for get and gets
+	Duration  time.Duration
+	Err       error // nil for misses and missing keys, which are not errors
+}
+
+// StatsRecorder receives every operation of an InstrumentedCache, e.g. to
+// export metrics, see metrics.MetricExporter.CacheRecorder. It is called
+// synchronously and must be safe for concurrent use.
+type StatsRecorder func(ctx context.Context, record *OperationRecord)
+
+// Stats is a snapshot of the counters of an InstrumentedCache
+type Stats struct {
+	Hits    uint64
+	Misses  uint64
+	Sets    uint64 // keys written by Set, SetNX, Sets and SetsNX
+	Deletes uint64
+	Clears  uint64
+	Errors  uint64
+}
+
+// HitRate returns the share of reads that were hits, or 0 without reads
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// InstrumentedCache decorates a Cache to count hits, misses, writes and
+// errors, and to report every operation with its latency to a StatsRecorder,
+// e.g.
+//
+//	recorder, _ := exporter.CacheRecorder()
+//	c := NewInstrumentedCache(NewRedisCache(client), WithCacheName("sessions"), WithStatsRecorder(recorder))
+//
+// Wrap namespace views rather than the cache given to NewNamespacedCache, which
+// could otherwise no longer use the Redis prefix deletion.
+type InstrumentedCache struct {
+	cache    Cache
+	name     string
+	recorder StatsRecorder
+
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	sets    atomic.Uint64
+	deletes atomic.Uint64
+	clears  atomic.Uint64
+	errors  atomic.Uint64
+}
+
+type InstrumentedCacheOption func(*InstrumentedCache)
+
+// WithCacheName sets the name reported in OperationRecord.Cache
+func WithCacheName(name string) InstrumentedCacheOption {
+	return func(c *InstrumentedCache) {
+		c.name = name
+	}
+}
+
+// WithStatsRecorder reports every operation to recorder
+func WithStatsRecorder(recorder StatsRecorder) InstrumentedCacheOption {
+	return func(c *InstrumentedCache) {
+		c.recorder = recorder
+	}
+}
+
+// NewInstrumentedCache wraps cache to record its statistics
+func NewInstrumentedCache(cache Cache, opts ...InstrumentedCacheOption) *InstrumentedCache {
+	c := &InstrumentedCache{cache: cache}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Stats returns a snapshot of the counters since the cache was created
+func (c *InstrumentedCache) Stats() Stats {
+	return Stats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Sets:    c.sets.Load(),
+		Deletes: c.deletes.Load(),
+		Clears:  c.clears.Load(),
+		Errors:  c.errors.Load(),
+	}
+}
+
+func (c *InstrumentedCache) Set(ctx context.Context, key string, value string, expiry time.Duration) error {
+	start := time.Now()
+	err := c.cache.Set(ctx, key, value, expiry)
+	c.record(ctx, &OperationRecord{Operation: OperationSet, Keys: 1, Duration: time.Since(start), Err: err})
+	return err
+}
+
+func (c *InstrumentedCache) SetNX(ctx context.Context, key string, value string, expiry time.Duration) (bool, error) {
+	start := time.Now()
+	ok, err := c.cache.SetNX(ctx, key, value, expiry)
+	record := &OperationRecord{Operation: OperationSetNX, Keys: 1, Duration: time.Since(start), Err: err}
+	if !ok {
+		record.Keys = 0
+	}
+	c.record(ctx, record)
+	return ok, err
+}
+
+func (c *InstrumentedCache) Get(ctx context.Context, key string) (string, error) {
+	start := time.Now()
+	value, err := c.cache.Get(ctx, key)
+	record := &OperationRecord{Operation: OperationGet, Keys: 1, Duration: time.Since(start)}
+	switch {
+	case err == nil:
+		record.Hits = 1
+	case errors.Is(err, ErrKeyNotFound):
+		record.Misses = 1
+	default:
+		record.Err = err
+	}
+	c.record(ctx, record)
+	return value, err
+}
+
+func (c *InstrumentedCache) Sets(ctx context.Context, kvs map[string]string, expiry time.Duration) error {
+	start := time.Now()
+	err := c.cache.Sets(ctx, kvs, expiry)
+	c.record(ctx, &OperationRecord{Operation: OperationSets, Keys: len(kvs), Duration: time.Since(start), Err: err})
+	return err
+}
+
+func (c *InstrumentedCache) SetsNX(ctx context.Context, kvs map[string]string, expiry time.Duration) (map[string]bool, error) {
+	start := time.Now()
+	results, err := c.cache.SetsNX(ctx, kvs, expiry)
+	written := 0
+	for _, ok := range results {
+		if ok {
+			written++
+		}
+	}
+	c.record(ctx, &OperationRecord{Operation: OperationSetsNX, Keys: written, Duration: time.Since(start), Err: err})
+	return results, err
+}
+
+func (c *InstrumentedCache) Gets(ctx context.Context, keys []string) (map[string]string, error) {
+	start := time.Now()
+	results, err := c.cache.Gets(ctx, keys)
+	record := &OperationRecord{Operation: OperationGets, Keys: len(keys), Duration: time.Since(start), Err: err}
+	if err == nil {
+		record.Hits = len(results)
+		record.Misses = len(keys) - len(results)
+	}
+	c.record(ctx, record)
+	return results, err
+}
+
+func (c *InstrumentedCache) Delete(ctx context.Context, key string) error {
+	start := time.Now()
+	err := c.cache.Delete(ctx, key)
+	c.record(ctx, &OperationRecord{Operation: OperationDelete, Keys: 1, Duration: time.Since(start), Err: err})
+	return err
+}
+
+func (c *InstrumentedCache) Clear(ctx context.Context) error {
+	start := time.Now()
+	err := c.cache.Clear(ctx)
+	c.record(ctx, &OperationRecord{Operation: OperationClear, Duration: time.Since(start), Err: err})
+	return err
+}
+
+func (c *InstrumentedCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	start := time.Now()
+	ttl, err := c.cache.TTL(ctx, key)
+	c.record(ctx, &OperationRecord{Operation: OperationTTL, Keys: 1, Duration: time.Since(start), Err: ignoreKeyNotFound(err)})
+	return ttl, err
+}
+
+func (c *InstrumentedCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	start := time.Now()
+	err := c.cache.Expire(ctx, key, ttl)
+	c.record(ctx, &OperationRecord{Operation: OperationExpire, Keys: 1, Duration: time.Since(start), Err: ignoreKeyNotFound(err)})
+	return err
+}
+
+// GetWithTTL is recorded as a get
+func (c *InstrumentedCache) GetWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	start := time.Now()
+	value, ttl, err := c.cache.GetWithTTL(ctx, key)
+	record := &OperationRecord{Operation: OperationGet, Keys: 1, Duration: time.Since(start)}
+	switch {
+	case err == nil:
+		record.Hits = 1
+	case errors.Is(err, ErrKeyNotFound):
+		record.Misses = 1
+	default:
+		record.Err = err
+	}
+	c.record(ctx, record)
+	return value, ttl, err
+}
+
+func ignoreKeyNotFound(err error) error {
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil
+	}
+	return err
+}
+
+// record updates the counters and reports record to the recorder
+func (c *InstrumentedCache) record(ctx context.Context, record *OperationRecord) {
+	record.Cache = c.name
+	if record.Err != nil {
+		c.errors.Add(1)
+	} else {
+		c.hits.Add(uint64(record.Hits))
+		c.misses.Add(uint64(record.Misses))
+		switch record.Operation {
+		case OperationSet, OperationSetNX, OperationSets, OperationSetsNX:
+			c.sets.Add(uint64(record.Keys))
+		case OperationDelete:
+			c.deletes.Add(1)
+		case OperationClear:
+			c.clears.Add(1)
+		}
+	}
+	if c.recorder != nil {
+		c.recorder(ctx, record)
+	}
+}
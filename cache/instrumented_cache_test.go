@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingCache struct {
+	Cache
+}
+
+func (failingCache) Get(ctx context.Context, key string) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func TestInstrumentedCache_Stats(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+
+	var (
+		mu      sync.Mutex
+		records []OperationRecord
+	)
+	c := NewInstrumentedCache(NewRedisCache(client),
+		WithCacheName("test"),
+		WithStatsRecorder(func(ctx context.Context, record *OperationRecord) {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, *record)
+		}),
+	)
+
+	require.NoError(t, c.Set(ctx, "a", "1", time.Minute))
+	require.NoError(t, c.Sets(ctx, map[string]string{"b": "2", "c": "3"}, time.Minute))
+	ok, err := c.SetNX(ctx, "a", "9", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = c.Get(ctx, "a")
+	require.NoError(t, err)
+	_, err = c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	values, err := c.Gets(ctx, []string{"b", "c", "x"})
+	require.NoError(t, err)
+	assert.Len(t, values, 2)
+
+	require.NoError(t, c.Delete(ctx, "a"))
+	require.NoError(t, c.Clear(ctx))
+
+	stats := c.Stats()
+	assert.Equal(t, Stats{Hits: 3, Misses: 2, Sets: 3, Deletes: 1, Clears: 1}, stats)
+	assert.InDelta(t, 0.6, stats.HitRate(), 1e-9)
+
+	require.Len(t, records, 8)
+	assert.Equal(t, "test", records[0].Cache)
+	assert.Equal(t, OperationSet, records[0].Operation)
+	assert.Equal(t, OperationRecord{Cache: "test", Operation: OperationGets, Keys: 3, Hits: 2, Misses: 1, Duration: records[5].Duration}, records[5])
+	assert.NoError(t, records[4].Err, "a miss is not an error")
+}
+
+func TestInstrumentedCache_Errors(t *testing.T) {
+	c := NewInstrumentedCache(failingCache{})
+
+	_, err := c.Get(context.Background(), "a")
+	assert.Error(t, err)
+	assert.Equal(t, Stats{Errors: 1}, c.Stats())
+	assert.Zero(t, c.Stats().HitRate())
+}
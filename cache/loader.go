@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// LoadFunc loads the value of key from the backing store, returning
+// ErrKeyNotFound when it does not exist.
+type LoadFunc func(ctx context.Context, key string) (string, error)
+
+// loaderEntry is the value stored by Loader, with the time it goes stale
+type loaderEntry struct {
+	Value    string `json:"v,omitempty"`
+	NotFound bool   `json:"nf,omitempty"`
+	FreshMs  int64  `json:"f"` // Unix milliseconds
+}
+
+// Loader reads through a cache, loading missing keys with a LoadFunc. Loads of
+// the same key are deduplicated within the process, so bursts of misses reach
+// the backing store once.
+//
+// Values are stored in an envelope, the keys of a Loader must only be read
+// and written through it.
+type Loader struct {
+	cache       Cache
+	ttl         time.Duration
+	staleTTL    time.Duration
+	negativeTTL time.Duration
+	lg          *zap.Logger
+	now         func() time.Time
+	group       singleflight.Group
+}
+
+type LoaderOption func(*Loader)
+
+// WithStaleTTL keeps values up to staleTTL after they go stale. Stale values
+// are served right away while a background load refreshes them.
+func WithStaleTTL(staleTTL time.Duration) LoaderOption {
+	return func(l *Loader) {
+		l.staleTTL = staleTTL
+	}
+}
+
+// WithNegativeTTL caches for negativeTTL that a key was not found, so repeated
+// reads of a missing key do not reach the backing store. It should be short,
+// a key created in the meantime is reported missing until then.
+func WithNegativeTTL(negativeTTL time.Duration) LoaderOption {
+	return func(l *Loader) {
+		l.negativeTTL = negativeTTL
+	}
+}
+
+// WithLoaderLogger sets the logger used to report cache and background load
+// failures
+func WithLoaderLogger(lg *zap.Logger) LoaderOption {
+	return func(l *Loader) {
+		l.lg = lg
+	}
+}
+
+// NewLoader creates a loader caching loaded values in cache for ttl, e.g.
+//
+//	loader := NewLoader(c, time.Minute, WithStaleTTL(5*time.Minute), WithNegativeTTL(10*time.Second))
+//	value, err := loader.GetOrLoad(ctx, "provider:"+id, loadProvider)
+func NewLoader(cache Cache, ttl time.Duration, opts ...LoaderOption) *Loader {
+	l := &Loader{
+		cache: cache,
+		ttl:   ttl,
+		lg:    zap.L(),
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// GetOrLoad returns the cached value of key, loading it with load on a miss.
+// It returns ErrKeyNotFound for keys load reported missing, cached or not.
+// Cache failures fall back to load and load errors are not cached.
+func (l *Loader) GetOrLoad(ctx context.Context, key string, load LoadFunc) (string, error) {
+	if entry, ok := l.get(ctx, key); ok {
+		if l.now().UnixMilli() >= entry.FreshMs {
+			l.refresh(ctx, key, load)
+		}
+		return entry.result()
+	}
+
+	// The shared load must not fail for every waiter when the caller that
+	// started it goes away, each caller waits on its own context instead
+	loadCtx := context.WithoutCancel(ctx)
+	ch := l.group.DoChan(key, func() (any, error) {
+		return l.load(loadCtx, key, load)
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(*loaderEntry).result()
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Delete removes key, e.g. after the backing store changed
+func (l *Loader) Delete(ctx context.Context, key string) error {
+	return l.cache.Delete(ctx, key)
+}
+
+func (l *Loader) get(ctx context.Context, key string) (*loaderEntry, bool) {
+	data, err := l.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrKeyNotFound) {
+			l.lg.Warn("failed to get cached value", zap.String("key", key), zap.Error(err))
+		}
+		return nil, false
+	}
+	var entry loaderEntry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		l.lg.Warn("failed to unmarshal cached value", zap.String("key", key), zap.Error(err))
+		return nil, false
+	}
+	return &entry, true
+}
+
+// refresh reloads a stale key in the background, once at a time per key
+func (l *Loader) refresh(ctx context.Context, key string, load LoadFunc) {
+	ctx = context.WithoutCancel(ctx)
+	l.group.DoChan(key, func() (any, error) {
+		entry, err := l.load(ctx, key, load)
+		if err != nil {
+			l.lg.Warn("failed to refresh stale cached value", zap.String("key", key), zap.Error(err))
+		}
+		return entry, err
+	})
+}
+
+// load loads key and caches the result, not found results only with a
+// negative TTL
+func (l *Loader) load(ctx context.Context, key string, load LoadFunc) (*loaderEntry, error) {
+	value, err := load(ctx, key)
+	var entry loaderEntry
+	var expiry time.Duration
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		entry = loaderEntry{NotFound: true, FreshMs: l.now().Add(l.negativeTTL).UnixMilli()}
+		expiry = l.negativeTTL
+	case err != nil:
+		return nil, err
+	default:
+		entry = loaderEntry{Value: value, FreshMs: l.now().Add(l.ttl).UnixMilli()}
+		expiry = l.ttl + l.staleTTL
+	}
+
+	if expiry > 0 {
+		data, err := json.Marshal(entry)
+		if err == nil {
+			err = l.cache.Set(ctx, key, string(data), expiry)
+		}
+		if err != nil {
+			l.lg.Warn("failed to cache loaded value", zap.String("key", key), zap.Error(err))
+		}
+	}
+	return &entry, nil
+}
+
+func (e *loaderEntry) result() (string, error) {
+	if e.NotFound {
+		return "", ErrKeyNotFound
+	}
+	return e.Value, nil
+}
+
+// GetOrLoadTyped is GetOrLoad for JSON encoded values of type T
+func GetOrLoadTyped[T any](ctx context.Context, l *Loader, key string, load func(ctx context.Context, key string) (T, error)) (T, error) {
+	var result T
+	value, err := l.GetOrLoad(ctx, key, func(ctx context.Context, key string) (string, error) {
+		loaded, err := load(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		data, err := json.Marshal(loaded)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrJsonMarshal, err)
+		}
+		return string(data), nil
+	})
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal([]byte(value), &result); err != nil {
+		return result, ErrJsonUnmarshal
+	}
+	return result, nil
+}
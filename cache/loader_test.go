@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_DeduplicatesLoads(t *testing.T) {
+	loader := NewLoader(createTestFreeCache(t), time.Minute)
+	ctx := context.Background()
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context, string) (string, error) {
+		loads.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := loader.GetOrLoad(ctx, "key", load)
+			assert.NoError(t, err)
+			assert.Equal(t, "value", value)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	value, err := loader.GetOrLoad(ctx, "key", load)
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestLoader_FirstCallerCanceled(t *testing.T) {
+	loader := NewLoader(createTestFreeCache(t), time.Minute)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func(ctx context.Context, _ string) (string, error) {
+		close(started)
+		select {
+		case <-release:
+			return "value", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	// The first caller starts the load and goes away
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := loader.GetOrLoad(firstCtx, "key", load)
+		firstErr <- err
+	}()
+	<-started
+
+	waiter := make(chan string, 1)
+	go func() {
+		value, err := loader.GetOrLoad(context.Background(), "key", load)
+		assert.NoError(t, err)
+		waiter <- value
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+
+	// The waiter still gets the loaded value
+	close(release)
+	assert.Equal(t, "value", <-waiter)
+}
+
+func TestLoader_NegativeCaching(t *testing.T) {
+	ctx := context.Background()
+	var loads atomic.Int32
+	missing := func(context.Context, string) (string, error) {
+		loads.Add(1)
+		return "", ErrKeyNotFound
+	}
+
+	t.Run("not found results are cached with a negative TTL", func(t *testing.T) {
+		loads.Store(0)
+		loader := NewLoader(createTestFreeCache(t), time.Minute, WithNegativeTTL(10*time.Second))
+		for i := 0; i < 3; i++ {
+			_, err := loader.GetOrLoad(ctx, "missing", missing)
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+		}
+		assert.Equal(t, int32(1), loads.Load())
+	})
+
+	t.Run("not found results are not cached by default", func(t *testing.T) {
+		loads.Store(0)
+		loader := NewLoader(createTestFreeCache(t), time.Minute)
+		for i := 0; i < 3; i++ {
+			_, err := loader.GetOrLoad(ctx, "missing", missing)
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+		}
+		assert.Equal(t, int32(3), loads.Load())
+	})
+
+	t.Run("load errors are not cached", func(t *testing.T) {
+		loader := NewLoader(createTestFreeCache(t), time.Minute, WithNegativeTTL(10*time.Second))
+		loadErr := errors.New("database down")
+		_, err := loader.GetOrLoad(ctx, "key", func(context.Context, string) (string, error) { return "", loadErr })
+		assert.ErrorIs(t, err, loadErr)
+		value, err := loader.GetOrLoad(ctx, "key", func(context.Context, string) (string, error) { return "value", nil })
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+}
+
+func TestLoader_StaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	loader := NewLoader(createTestFreeCache(t), time.Minute, WithStaleTTL(5*time.Minute))
+	var mu sync.Mutex
+	now := time.Now()
+	loader.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	var version atomic.Int32
+	load := func(context.Context, string) (string, error) {
+		if version.Add(1) == 1 {
+			return "v1", nil
+		}
+		return "v2", nil
+	}
+
+	value, err := loader.GetOrLoad(ctx, "key", load)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	// stale: served right away while refreshed in the background
+	mu.Lock()
+	now = now.Add(2 * time.Minute)
+	mu.Unlock()
+	value, err = loader.GetOrLoad(ctx, "key", load)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	assert.Eventually(t, func() bool {
+		value, err := loader.GetOrLoad(ctx, "key", load)
+		return err == nil && value == "v2"
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), version.Load())
+}
+
+func TestGetOrLoadTyped(t *testing.T) {
+	type provider struct {
+		Name string `json:"name"`
+	}
+	loader := NewLoader(createTestFreeCache(t), time.Minute)
+	var loads int
+	load := func(_ context.Context, key string) (provider, error) {
+		loads++
+		return provider{Name: key}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		result, err := GetOrLoadTyped(context.Background(), loader, "pragmatic", load)
+		require.NoError(t, err)
+		assert.Equal(t, provider{Name: "pragmatic"}, result)
+	}
+	assert.Equal(t, 1, loads)
+}
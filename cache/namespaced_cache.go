@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// prefixDeleter is implemented by caches that can delete every key under a
+// prefix, such as the Redis cache
+type prefixDeleter interface {
+	deletePrefix(ctx context.Context, prefix string) error
+}
+
+// NamespacedCache scopes a shared cache per namespace, typically per tenant,
+// so the entries of one namespace can be invalidated without clearing the
+// others, e.g.
+//
+//	nc := NewNamespacedCache(NewRedisCache(client))
+//	tenant := nc.Namespace("operator:42")
+//	_ = tenant.Set(ctx, "config", value, time.Hour)
+//	_ = nc.InvalidateNamespace(ctx, "operator:42")
+//
+// Keys are stored as <namespace>:<key>. When the underlying cache supports it
+// (Redis), InvalidateNamespace deletes the keys of the namespace with SCAN and
+// UNLINK. Other caches (FreeCache, TieredCache) use key versioning instead:
+// keys are stored as <namespace>:<version>:<key>, and invalidating bumps the
+// version stored under <namespace>:_version, leaving the old entries to expire
+// or be evicted. Versioning costs an extra read of the version per operation.
+//
+// With Redis, a namespace followed by ':' must not be the start of another
+// namespace: invalidating "operator" would also drop "operator:42".
+type NamespacedCache struct {
+	cache Cache
+}
+
+// NewNamespacedCache wraps cache to provide namespaced views
+func NewNamespacedCache(cache Cache) *NamespacedCache {
+	return &NamespacedCache{cache: cache}
+}
+
+// Namespace returns a view of the cache whose keys are scoped to namespace.
+// Clear on the view invalidates the namespace only.
+func (c *NamespacedCache) Namespace(namespace string) Cache {
+	return &namespaceCache{parent: c, namespace: namespace}
+}
+
+// InvalidateNamespace drops every entry of namespace
+func (c *NamespacedCache) InvalidateNamespace(ctx context.Context, namespace string) error {
+	if deleter, ok := c.cache.(prefixDeleter); ok {
+		return deleter.deletePrefix(ctx, namespace+":")
+	}
+	return c.cache.Set(ctx, versionKey(namespace), newNamespaceVersion(), 0)
+}
+
+// prefix returns the prefix of the keys of namespace
+func (c *NamespacedCache) prefix(ctx context.Context, namespace string) (string, error) {
+	if _, ok := c.cache.(prefixDeleter); ok {
+		return namespace + ":", nil
+	}
+
+	version, err := c.cache.Get(ctx, versionKey(namespace))
+	if errors.Is(err, ErrKeyNotFound) {
+		// A fresh version also covers a version key evicted from a local
+		// cache, which must not bring older entries back
+		version = newNamespaceVersion()
+		err = c.cache.Set(ctx, versionKey(namespace), version, 0)
+	}
+	if err != nil {
+		return "", err
+	}
+	return namespace + ":" + version + ":", nil
+}
+
+func versionKey(namespace string) string {
+	return namespace + ":_version"
+}
+
+func newNamespaceVersion() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
+type namespaceCache struct {
+	parent    *NamespacedCache
+	namespace string
+}
+
+func (c *namespaceCache) Set(ctx context.Context, key string, value string, expiry time.Duration) error {
+	prefix, err := c.parent.prefix(ctx, c.namespace)
+	if err != nil {
+		return err
+	}
+	return c.parent.cache.Set(ctx, prefix+key, value, expiry)
+}
+
+func (c *namespaceCache) SetNX(ctx context.Context, key string, value string, expiry time.Duration) (bool, error) {
+	prefix, err := c.parent.prefix(ctx, c.namespace)
+	if err != nil {
+		return false, err
+	}
+	return c.parent.cache.SetNX(ctx, prefix+key, value, expiry)
+}
+
+func (c *namespaceCache) Get(ctx context.Context, key string) (string, error) {
+	prefix, err := c.parent.prefix(ctx, c.namespace)
+	if err != nil {
+		return "", err
+	}
+	return c.parent.cache.Get(ctx, prefix+key)
+}
+
+func (c *namespaceCache) Sets(ctx context.Context, kvs map[string]string, expiry time.Duration) error {
+	prefix, err := c.parent.prefix(ctx, c.namespace)
+	if err != nil {
+		return err
+	}
+	return c.parent.cache.Sets(ctx, prefixKeys(prefix, kvs), expiry)
+}
+
+func (c *namespaceCache) SetsNX(ctx context.Context, kvs map[string]string, expiry time.Duration) (map[string]bool, error) {
+	prefix, err := c.parent.prefix(ctx, c.namespace)
+	if err != nil {
+		return nil, err
+	}
+	results, err := c.parent.cache.SetsNX(ctx, prefixKeys(prefix, kvs), expiry)
+	return trimKeys(prefix, results), err
+}
+
+func (c *namespaceCache) Gets(ctx context.Context, keys []string) (map[string]string, error) {
+	prefix, err := c.parent.prefix(ctx, c.namespace)
+	if err != nil {
+		return nil, err
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = prefix + key
+	}
+	results, err := c.parent.cache.Gets(ctx, prefixed)
+	return trimKeys(prefix, results), err
+}
+
+func (c *namespaceCache) Delete(ctx context.Context, key string) error {
+	prefix, err := c.parent.prefix(ctx, c.namespace)
+	if err != nil {
+		return err
+	}
+	return c.parent.cache.Delete(ctx, prefix+key)
+}
+
+func (c *namespaceCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	prefix, err := c.parent.prefix(ctx, c.namespace)
+	if err != nil {
+		return 0, err
+	}
+	return c.parent.cache.TTL(ctx, prefix+key)
+}
+
+func (c *namespaceCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	prefix, err := c.parent.prefix(ctx, c.namespace)
+	if err != nil {
+		return err
+	}
+	return c.parent.cache.Expire(ctx, prefix+key, ttl)
+}
+
+func (c *namespaceCache) GetWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	prefix, err := c.parent.prefix(ctx, c.namespace)
+	if err != nil {
+		return "", 0, err
+	}
+	return c.parent.cache.GetWithTTL(ctx, prefix+key)
+}
+
+func (c *namespaceCache) Clear(ctx context.Context) error {
+	return c.parent.InvalidateNamespace(ctx, c.namespace)
+}
+
+func prefixKeys(prefix string, kvs map[string]string) map[string]string {
+	prefixed := make(map[string]string, len(kvs))
+	for key, value := range kvs {
+		prefixed[prefix+key] = value
+	}
+	return prefixed
+}
+
+func trimKeys[T any](prefix string, values map[string]T) map[string]T {
+	if values == nil {
+		return nil
+	}
+	trimmed := make(map[string]T, len(values))
+	for key, value := range values {
+		trimmed[key[len(prefix):]] = value
+	}
+	return trimmed
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coocood/freecache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNamespacedCache(t *testing.T, nc *NamespacedCache) {
+	ctx := context.Background()
+	tenant1 := nc.Namespace("tenant1")
+	tenant2 := nc.Namespace("tenant2")
+
+	require.NoError(t, tenant1.Set(ctx, "a", "1", time.Minute))
+	require.NoError(t, tenant1.Sets(ctx, map[string]string{"b": "2", "c": "3"}, time.Minute))
+	require.NoError(t, tenant2.Set(ctx, "a", "other", time.Minute))
+
+	value, err := tenant1.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+	value, err = tenant2.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "other", value)
+
+	values, err := tenant1.Gets(ctx, []string{"a", "b", "missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, values)
+
+	assert.NoError(t, tenant1.Delete(ctx, "c"))
+	_, err = tenant1.Get(ctx, "c")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, nc.InvalidateNamespace(ctx, "tenant1"))
+
+	_, err = tenant1.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	values, err = tenant1.Gets(ctx, []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+
+	value, err = tenant2.Get(ctx, "a")
+	assert.NoError(t, err, "invalidating a namespace should not touch the others")
+	assert.Equal(t, "other", value)
+
+	// The namespace is usable again after invalidation
+	require.NoError(t, tenant1.Set(ctx, "a", "new", time.Minute))
+	value, err = tenant1.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "new", value)
+
+	require.NoError(t, tenant2.Clear(ctx))
+	_, err = tenant2.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestNamespacedCache_FreeCache(t *testing.T) {
+	testNamespacedCache(t, NewNamespacedCache(NewFreeCache(freecache.NewCache(1024*1024))))
+}
+
+func TestNamespacedCache_FreeCacheVersionEvicted(t *testing.T) {
+	ctx := context.Background()
+	fc := NewFreeCache(freecache.NewCache(1024 * 1024))
+	tenant := NewNamespacedCache(fc).Namespace("tenant")
+
+	require.NoError(t, tenant.Set(ctx, "a", "1", time.Minute))
+	require.NoError(t, fc.Delete(ctx, versionKey("tenant")))
+
+	_, err := tenant.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrKeyNotFound, "a lost version must not resurrect older entries")
+}
+
+func TestNamespacedCache_Redis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	testNamespacedCache(t, NewNamespacedCache(NewRedisCache(client, WithKeyPrefix("svc:"))))
+
+	ctx := context.Background()
+	tenant := NewNamespacedCache(NewRedisCache(client, WithKeyPrefix("svc:"))).Namespace("tenant*")
+	require.NoError(t, tenant.Set(ctx, "a", "1", time.Minute))
+	assert.True(t, mr.Exists("svc:tenant*:a"), "redis keys should not be versioned")
+
+	require.NoError(t, client.Set(ctx, "svc:tenantX:a", "x", 0).Err())
+	require.NoError(t, tenant.Clear(ctx))
+	assert.False(t, mr.Exists("svc:tenant*:a"))
+	assert.True(t, mr.Exists("svc:tenantX:a"), "glob characters in the namespace should be escaped")
+}
@@ -3,29 +3,52 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// clearScanCount is the SCAN batch size used when clearing a prefixed cache
+const clearScanCount = 1000
+
 type redisCache struct {
 	client *redis.Client
+	prefix string
+}
+
+type RedisCacheOption func(*redisCache)
+
+// WithKeyPrefix scopes every key to the given prefix, e.g. "svc:cache:".
+// Clear then only deletes keys under the prefix instead of flushing the whole DB.
+func WithKeyPrefix(prefix string) RedisCacheOption {
+	return func(c *redisCache) {
+		c.prefix = prefix
+	}
 }
 
-func NewRedisCache(client *redis.Client) Cache {
-	return &redisCache{client: client}
+func NewRedisCache(client *redis.Client, opts ...RedisCacheOption) Cache {
+	c := &redisCache{client: client}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *redisCache) key(key string) string {
+	return c.prefix + key
 }
 
 func (c *redisCache) Set(ctx context.Context, key string, value string, expiry time.Duration) error {
-	return c.client.Set(ctx, key, value, expiry).Err()
+	return c.client.Set(ctx, c.key(key), value, expiry).Err()
 }
 
 func (c *redisCache) SetNX(ctx context.Context, key string, value string, expiry time.Duration) (bool, error) {
-	return c.client.SetNX(ctx, key, value, expiry).Result()
+	return c.client.SetNX(ctx, c.key(key), value, expiry).Result()
 }
 
 func (c *redisCache) Get(ctx context.Context, key string) (string, error) {
-	data, err := c.client.Get(ctx, key).Result()
+	data, err := c.client.Get(ctx, c.key(key)).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return "", ErrKeyNotFound
@@ -40,7 +63,7 @@ func (c *redisCache) Sets(ctx context.Context, kvs map[string]string, expiry tim
 	pipe := c.client.Pipeline()
 
 	for key, value := range kvs {
-		pipe.Set(ctx, key, value, expiry)
+		pipe.Set(ctx, c.key(key), value, expiry)
 	}
 
 	cmds, err := pipe.Exec(ctx)
@@ -63,7 +86,7 @@ func (c *redisCache) SetsNX(ctx context.Context, kvs map[string]string, expiry t
 
 	cmds := make(map[string]*redis.BoolCmd, len(kvs))
 	for key, value := range kvs {
-		cmds[key] = pipe.SetNX(ctx, key, value, expiry)
+		cmds[key] = pipe.SetNX(ctx, c.key(key), value, expiry)
 	}
 
 	_, err := pipe.Exec(ctx)
@@ -88,7 +111,7 @@ func (c *redisCache) Gets(ctx context.Context, keys []string) (map[string]string
 	pipe := c.client.Pipeline()
 
 	for _, key := range keys {
-		pipe.Get(ctx, key)
+		pipe.Get(ctx, c.key(key))
 	}
 
 	cmds, err := pipe.Exec(ctx)
@@ -114,10 +137,149 @@ func (c *redisCache) Gets(ctx context.Context, keys []string) (map[string]string
 	return results, nil
 }
 
+// getsWithTTL is Gets also returning the remaining lifetime of every key
+// found, as TTL, reading the lifetimes in the same pipeline
+func (c *redisCache) getsWithTTL(ctx context.Context, keys []string) (map[string]string, map[string]time.Duration, error) {
+	results := make(map[string]string)
+	ttls := make(map[string]time.Duration)
+
+	pipe := c.client.Pipeline()
+	gets := make([]*redis.StringCmd, len(keys))
+	pttls := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		gets[i] = pipe.Get(ctx, c.key(key))
+		pttls[i] = pipe.PTTL(ctx, c.key(key))
+	}
+
+	_, err := pipe.Exec(ctx)
+	if err != nil && err != redis.Nil {
+		return results, ttls, fmt.Errorf("failed to execute pipeline: %w", err)
+	}
+
+	for i, key := range keys {
+		value, err := gets[i].Result()
+		if err != nil {
+			continue
+		}
+		// A key expiring between GET and PTTL is reported missing
+		ttl, err := ttlResult(pttls[i].Result())
+		if err != nil {
+			continue
+		}
+		results[key] = value
+		ttls[key] = ttl
+	}
+
+	return results, ttls, nil
+}
+
 func (c *redisCache) Delete(ctx context.Context, key string) error {
-	return c.client.Del(ctx, key).Err()
+	return c.client.Del(ctx, c.key(key)).Err()
+}
+
+func (c *redisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return ttlResult(c.client.PTTL(ctx, c.key(key)).Result())
 }
 
+func (c *redisCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if ttl > 0 {
+		ok, err := c.client.PExpire(ctx, c.key(key), ttl).Result()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrKeyNotFound
+		}
+		return nil
+	}
+
+	// PERSIST also reports false for an existing key without expiry
+	ok, err := c.client.Persist(ctx, c.key(key)).Result()
+	if err != nil || ok {
+		return err
+	}
+	n, err := c.client.Exists(ctx, c.key(key)).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+func (c *redisCache) GetWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	pipe := c.client.Pipeline()
+	get := pipe.Get(ctx, c.key(key))
+	pttl := pipe.PTTL(ctx, c.key(key))
+
+	_, err := pipe.Exec(ctx)
+	if err != nil && err != redis.Nil {
+		return "", 0, fmt.Errorf("failed to execute pipeline: %w", err)
+	}
+
+	data, err := get.Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", 0, ErrKeyNotFound
+		}
+		return "", 0, err
+	}
+	ttl, err := ttlResult(pttl.Result())
+	if err != nil {
+		return "", 0, err
+	}
+	return data, ttl, nil
+}
+
+// ttlResult maps the -2 (missing key) and -1 (no expiry) replies of PTTL
+func ttlResult(ttl time.Duration, err error) (time.Duration, error) {
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case ttl == -2:
+		return 0, ErrKeyNotFound
+	case ttl < 0:
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// Clear flushes the whole DB when no prefix is set, otherwise it only deletes
+// keys under the prefix
 func (c *redisCache) Clear(ctx context.Context) error {
-	return c.client.FlushDB(ctx).Err()
+	if c.prefix == "" {
+		return c.client.FlushDB(ctx).Err()
+	}
+	return c.deletePrefix(ctx, "")
+}
+
+// deletePrefix deletes every key starting with prefix, within the cache prefix
+func (c *redisCache) deletePrefix(ctx context.Context, prefix string) error {
+	match := escapeGlob(c.key(prefix)) + "*"
+
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, match, clearScanCount).Result()
+		if err != nil {
+			return fmt.Errorf("failed to scan keys: %w", err)
+		}
+		if len(keys) > 0 {
+			if err := c.client.Unlink(ctx, keys...).Err(); err != nil {
+				return fmt.Errorf("failed to delete keys: %w", err)
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// escapeGlob escapes the characters SCAN MATCH treats as a pattern
+func escapeGlob(s string) string {
+	return globEscaper.Replace(s)
 }
+
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
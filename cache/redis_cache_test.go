@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
@@ -125,3 +126,73 @@ func TestRedisCacheCurrencySetsAndGets(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, currency2.Value(), values[currency2.Key()])
 }
+
+func TestRedisCacheKeyPrefix(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	cache := NewRedisCache(client, WithKeyPrefix("svc:"))
+
+	assert.NoError(t, cache.Set(ctx, "a", "1", time.Minute))
+	assert.NoError(t, cache.Sets(ctx, map[string]string{"b": "2", "c": "3"}, time.Minute))
+	assert.NoError(t, client.Set(ctx, "other", "x", 0).Err())
+
+	ok, err := cache.SetNX(ctx, "a", "9", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	value, err := client.Get(ctx, "svc:a").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+
+	values, err := cache.Gets(ctx, []string{"a", "b", "c", "missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2", "c": "3"}, values)
+
+	assert.NoError(t, cache.Clear(ctx))
+
+	_, err = cache.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	value, err = client.Get(ctx, "other").Result()
+	assert.NoError(t, err, "Clear should not touch keys outside the prefix")
+	assert.Equal(t, "x", value)
+}
+
+func TestRedisCacheTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	cache := NewRedisCache(client, WithKeyPrefix("svc:"))
+
+	assert.NoError(t, cache.Set(ctx, "session", "data", time.Minute))
+	assert.NoError(t, cache.Set(ctx, "forever", "data", 0))
+
+	ttl, err := cache.TTL(ctx, "session")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+	ttl, err = cache.TTL(ctx, "forever")
+	assert.NoError(t, err)
+	assert.Zero(t, ttl)
+	_, err = cache.TTL(ctx, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	assert.NoError(t, cache.Expire(ctx, "session", time.Hour))
+	assert.Equal(t, time.Hour, mr.TTL("svc:session"))
+
+	value, ttl, err := cache.GetWithTTL(ctx, "session")
+	assert.NoError(t, err)
+	assert.Equal(t, "data", value)
+	assert.Equal(t, time.Hour, ttl)
+	_, _, err = cache.GetWithTTL(ctx, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	assert.NoError(t, cache.Expire(ctx, "session", 0))
+	assert.Zero(t, mr.TTL("svc:session"))
+	assert.NoError(t, cache.Expire(ctx, "forever", 0))
+	assert.ErrorIs(t, cache.Expire(ctx, "missing", time.Hour), ErrKeyNotFound)
+	assert.ErrorIs(t, cache.Expire(ctx, "missing", 0), ErrKeyNotFound)
+}
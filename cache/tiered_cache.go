@@ -0,0 +1,338 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// invalidationMessage is published on the invalidation channel whenever a key
+// is written or deleted so other instances drop their stale L1 copy
+type invalidationMessage struct {
+	Source string   `json:"source"`
+	Keys   []string `json:"keys,omitempty"`
+	Clear  bool     `json:"clear,omitempty"`
+}
+
+// TieredCache combines a local L1 cache (typically FreeCache) with a remote L2
+// cache (typically Redis). Reads go through L1 and fall back to L2, filling L1
+// on a hit; writes go to L2 first and then to L1.
+//
+// L1 entries live at most l1TTL. With WithInvalidation, writes are also
+// broadcast over Redis pub/sub so every other instance evicts the key from its
+// L1 instead of serving it until it expires.
+type TieredCache struct {
+	l1    Cache
+	l2    Cache
+	l1TTL time.Duration
+	lg    *zap.Logger
+
+	redisClient *redis.Client
+	channel     string
+	instanceID  string
+	pubsub      *redis.PubSub
+
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+type TieredCacheOption func(*TieredCache)
+
+// WithL1TTL sets the maximum lifetime of L1 entries. Defaults to 1 minute.
+func WithL1TTL(ttl time.Duration) TieredCacheOption {
+	return func(c *TieredCache) {
+		c.l1TTL = ttl
+	}
+}
+
+// WithInvalidation enables L1 invalidation across instances through the given
+// Redis pub/sub channel
+func WithInvalidation(client *redis.Client, channel string) TieredCacheOption {
+	return func(c *TieredCache) {
+		c.redisClient = client
+		c.channel = channel
+	}
+}
+
+// WithTieredCacheLogger sets the logger used to report invalidation failures
+func WithTieredCacheLogger(lg *zap.Logger) TieredCacheOption {
+	return func(c *TieredCache) {
+		c.lg = lg
+	}
+}
+
+// NewTieredCache creates a two-tier cache, e.g.
+//
+//	c, err := NewTieredCache(
+//		NewFreeCache(freecache.NewCache(100*1024*1024)),
+//		NewRedisCache(client),
+//		WithInvalidation(client, "cache:invalidate"),
+//	)
+//
+// Close must be called to stop the invalidation subscriber.
+func NewTieredCache(l1 Cache, l2 Cache, opts ...TieredCacheOption) (*TieredCache, error) {
+	c := &TieredCache{
+		l1:         l1,
+		l2:         l2,
+		l1TTL:      time.Minute,
+		lg:         zap.L(),
+		instanceID: uuid.New().String(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.redisClient != nil {
+		if c.channel == "" {
+			return nil, fmt.Errorf("invalidation channel cannot be empty")
+		}
+		c.pubsub = c.redisClient.Subscribe(context.Background(), c.channel)
+		// Wait for the subscription to be confirmed so no invalidation is missed
+		if _, err := c.pubsub.Receive(context.Background()); err != nil {
+			_ = c.pubsub.Close()
+			return nil, fmt.Errorf("failed to subscribe to invalidation channel: %w", err)
+		}
+		c.wg.Add(1)
+		go c.invalidationLoop()
+	}
+
+	return c, nil
+}
+
+func (c *TieredCache) Set(ctx context.Context, key string, value string, expiry time.Duration) error {
+	if err := c.l2.Set(ctx, key, value, expiry); err != nil {
+		return err
+	}
+	_ = c.l1.Set(ctx, key, value, c.localExpiry(expiry))
+	c.publish(ctx, invalidationMessage{Keys: []string{key}})
+	return nil
+}
+
+func (c *TieredCache) SetNX(ctx context.Context, key string, value string, expiry time.Duration) (bool, error) {
+	ok, err := c.l2.SetNX(ctx, key, value, expiry)
+	if err != nil || !ok {
+		return ok, err
+	}
+	_ = c.l1.Set(ctx, key, value, c.localExpiry(expiry))
+	c.publish(ctx, invalidationMessage{Keys: []string{key}})
+	return true, nil
+}
+
+func (c *TieredCache) Get(ctx context.Context, key string) (string, error) {
+	if value, err := c.l1.Get(ctx, key); err == nil {
+		return value, nil
+	}
+
+	value, ttl, err := c.l2.GetWithTTL(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	c.fillL1(ctx, key, value, ttl)
+	return value, nil
+}
+
+func (c *TieredCache) Sets(ctx context.Context, kvs map[string]string, expiry time.Duration) error {
+	if err := c.l2.Sets(ctx, kvs, expiry); err != nil {
+		return err
+	}
+	_ = c.l1.Sets(ctx, kvs, c.localExpiry(expiry))
+	c.publish(ctx, invalidationMessage{Keys: mapKeys(kvs)})
+	return nil
+}
+
+func (c *TieredCache) SetsNX(ctx context.Context, kvs map[string]string, expiry time.Duration) (map[string]bool, error) {
+	results, err := c.l2.SetsNX(ctx, kvs, expiry)
+	if err != nil {
+		return results, err
+	}
+
+	written := make(map[string]string, len(results))
+	for key, ok := range results {
+		if ok {
+			written[key] = kvs[key]
+		}
+	}
+	if len(written) > 0 {
+		_ = c.l1.Sets(ctx, written, c.localExpiry(expiry))
+		c.publish(ctx, invalidationMessage{Keys: mapKeys(written)})
+	}
+	return results, nil
+}
+
+// batchTTLGetter is implemented by L2 caches reading the lifetimes of a batch
+// of keys along with their values
+type batchTTLGetter interface {
+	getsWithTTL(ctx context.Context, keys []string) (map[string]string, map[string]time.Duration, error)
+}
+
+// Gets reads the keys missing from L1 from L2 in one batch. They are cached in
+// L1 for their remaining lifetime in L2, like Get, when L2 reads lifetimes in
+// the same batch, as the Redis cache does, and not cached in L1 otherwise.
+func (c *TieredCache) Gets(ctx context.Context, keys []string) (map[string]string, error) {
+	results, err := c.l1.Gets(ctx, keys)
+	if err != nil || results == nil {
+		results = make(map[string]string, len(keys))
+	}
+
+	var missing []string
+	for _, key := range keys {
+		if _, ok := results[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	var remote map[string]string
+	if getter, ok := c.l2.(batchTTLGetter); ok {
+		var ttls map[string]time.Duration
+		remote, ttls, err = getter.getsWithTTL(ctx, missing)
+		if err != nil {
+			return results, err
+		}
+		for key, value := range remote {
+			c.fillL1(ctx, key, value, ttls[key])
+		}
+	} else {
+		remote, err = c.l2.Gets(ctx, missing)
+		if err != nil {
+			return results, err
+		}
+	}
+	for key, value := range remote {
+		results[key] = value
+	}
+	return results, nil
+}
+
+func (c *TieredCache) Delete(ctx context.Context, key string) error {
+	if err := c.l2.Delete(ctx, key); err != nil {
+		return err
+	}
+	_ = c.l1.Delete(ctx, key)
+	c.publish(ctx, invalidationMessage{Keys: []string{key}})
+	return nil
+}
+
+func (c *TieredCache) Clear(ctx context.Context) error {
+	if err := c.l2.Clear(ctx); err != nil {
+		return err
+	}
+	_ = c.l1.Clear(ctx)
+	c.publish(ctx, invalidationMessage{Clear: true})
+	return nil
+}
+
+// TTL returns the lifetime of key in L2, L1 entries only living up to l1TTL
+func (c *TieredCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.l2.TTL(ctx, key)
+}
+
+// Expire sets the lifetime of key in L2 and caps its L1 entry accordingly.
+// Other instances evict their L1 copy, which could outlive a shortened ttl.
+func (c *TieredCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if err := c.l2.Expire(ctx, key, ttl); err != nil {
+		return err
+	}
+	_ = c.l1.Expire(ctx, key, c.localExpiry(ttl))
+	c.publish(ctx, invalidationMessage{Keys: []string{key}})
+	return nil
+}
+
+// GetWithTTL reads key from L2 for its lifetime, filling L1 like Get
+func (c *TieredCache) GetWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	value, ttl, err := c.l2.GetWithTTL(ctx, key)
+	if err != nil {
+		return "", 0, err
+	}
+	c.fillL1(ctx, key, value, ttl)
+	return value, ttl, nil
+}
+
+// Close stops the invalidation subscriber. It does not close the underlying
+// caches or the Redis client.
+func (c *TieredCache) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		if c.pubsub != nil {
+			err = c.pubsub.Close()
+		}
+		c.wg.Wait()
+	})
+	return err
+}
+
+// localExpiry caps the L1 expiry to l1TTL so a missed invalidation is only
+// stale for a bounded time
+func (c *TieredCache) localExpiry(expiry time.Duration) time.Duration {
+	if expiry <= 0 || expiry > c.l1TTL {
+		return c.l1TTL
+	}
+	return expiry
+}
+
+// fillL1 caches a value read from L2 in L1 for no longer than its remaining
+// lifetime ttl in L2, 0 meaning no expiry, so L1 never outlives L2. Values
+// with less than a second left, the resolution of FreeCache, are not cached.
+func (c *TieredCache) fillL1(ctx context.Context, key string, value string, ttl time.Duration) {
+	if ttl > 0 && ttl < time.Second {
+		return
+	}
+	_ = c.l1.Set(ctx, key, value, c.localExpiry(ttl))
+}
+
+func (c *TieredCache) publish(ctx context.Context, msg invalidationMessage) {
+	if c.redisClient == nil {
+		return
+	}
+	msg.Source = c.instanceID
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	if err := c.redisClient.Publish(ctx, c.channel, data).Err(); err != nil {
+		c.lg.Warn("failed to publish cache invalidation",
+			zap.String("channel", c.channel),
+			zap.Strings("keys", msg.Keys),
+			zap.Error(err),
+		)
+	}
+}
+
+func (c *TieredCache) invalidationLoop() {
+	defer c.wg.Done()
+
+	for redisMsg := range c.pubsub.Channel() {
+		var msg invalidationMessage
+		if err := json.Unmarshal([]byte(redisMsg.Payload), &msg); err != nil {
+			continue
+		}
+		if msg.Source == c.instanceID {
+			continue
+		}
+
+		ctx := context.Background()
+		if msg.Clear {
+			_ = c.l1.Clear(ctx)
+			continue
+		}
+		for _, key := range msg.Keys {
+			_ = c.l1.Delete(ctx, key)
+		}
+	}
+}
+
+func mapKeys(kvs map[string]string) []string {
+	keys := make([]string, 0, len(kvs))
+	for key := range kvs {
+		keys = append(keys, key)
+	}
+	return keys
+}
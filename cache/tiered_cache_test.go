@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coocood/freecache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTieredCache(t *testing.T, client *redis.Client, opts ...TieredCacheOption) *TieredCache {
+	t.Helper()
+	c, err := NewTieredCache(NewFreeCache(freecache.NewCache(1024*1024)), NewRedisCache(client), opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestTieredCache_ReadThrough(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	c := newTestTieredCache(t, client)
+
+	require.NoError(t, client.Set(ctx, "key1", "value1", 0).Err())
+
+	value, err := c.Get(ctx, "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, "value1", value)
+
+	// Served from L1 after the first read
+	mr.Del("key1")
+	value, err = c.Get(ctx, "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, "value1", value)
+
+	_, err = c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestTieredCache_WriteThrough(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	c := newTestTieredCache(t, client)
+
+	require.NoError(t, c.Sets(ctx, map[string]string{"a": "1", "b": "2"}, time.Minute))
+	value, err := client.Get(ctx, "a").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+
+	ok, err := c.SetNX(ctx, "a", "9", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, client.Set(ctx, "c", "3", 0).Err())
+	values, err := c.Gets(ctx, []string{"a", "b", "c", "missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2", "c": "3"}, values)
+
+	require.NoError(t, c.Delete(ctx, "a"))
+	_, err = c.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestTieredCache_Invalidation(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	first := newTestTieredCache(t, client, WithInvalidation(client, "cache:invalidate"))
+	second := newTestTieredCache(t, client, WithInvalidation(client, "cache:invalidate"))
+
+	require.NoError(t, first.Set(ctx, "key1", "v1", time.Minute))
+	value, err := second.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	require.NoError(t, first.Set(ctx, "key1", "v2", time.Minute))
+	assert.Eventually(t, func() bool {
+		value, err := second.Get(ctx, "key1")
+		return err == nil && value == "v2"
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, first.Delete(ctx, "key1"))
+	assert.Eventually(t, func() bool {
+		_, err := second.Get(ctx, "key1")
+		return err == ErrKeyNotFound
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTieredCache_L1TTL(t *testing.T) {
+	c := newTestTieredCache(t, nil, WithL1TTL(30*time.Second))
+	assert.Equal(t, 30*time.Second, c.localExpiry(0))
+	assert.Equal(t, 30*time.Second, c.localExpiry(time.Hour))
+	assert.Equal(t, 10*time.Second, c.localExpiry(10*time.Second))
+}
+
+func TestTieredCache_ReadThroughRemainingTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	c := newTestTieredCache(t, client, WithL1TTL(time.Minute))
+
+	// The L1 copy expires with the L2 entry rather than after the L1 TTL
+	require.NoError(t, client.Set(ctx, "short", "value", 5*time.Second).Err())
+	value, err := c.Get(ctx, "short")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	ttl, err := c.l1.TTL(ctx, "short")
+	assert.NoError(t, err)
+	assert.InDelta(t, 5*time.Second, ttl, float64(time.Second))
+
+	// Entries about to expire are not cached in L1
+	require.NoError(t, client.Set(ctx, "expiring", "value", 500*time.Millisecond).Err())
+	value, err = c.Get(ctx, "expiring")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	_, err = c.l1.Get(ctx, "expiring")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// Entries without expiry live the L1 TTL
+	require.NoError(t, client.Set(ctx, "persistent", "value", 0).Err())
+	_, err = c.Get(ctx, "persistent")
+	assert.NoError(t, err)
+	ttl, err = c.l1.TTL(ctx, "persistent")
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+}
+
+func TestTieredCache_GetsRemainingTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	c := newTestTieredCache(t, client, WithL1TTL(time.Minute))
+
+	require.NoError(t, client.Set(ctx, "short", "a", 5*time.Second).Err())
+	require.NoError(t, client.Set(ctx, "expiring", "b", 500*time.Millisecond).Err())
+	require.NoError(t, client.Set(ctx, "persistent", "c", 0).Err())
+
+	values, err := c.Gets(ctx, []string{"short", "expiring", "persistent", "missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"short": "a", "expiring": "b", "persistent": "c"}, values)
+
+	ttl, err := c.l1.TTL(ctx, "short")
+	assert.NoError(t, err)
+	assert.InDelta(t, 5*time.Second, ttl, float64(time.Second))
+	_, err = c.l1.Get(ctx, "expiring")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	ttl, err = c.l1.TTL(ctx, "persistent")
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+	// L2 caches without batch lifetimes do not fill L1
+	c, err = NewTieredCache(NewFreeCache(freecache.NewCache(1024*1024)), NewFreeCache(freecache.NewCache(1024*1024)))
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.l2.Set(ctx, "key", "value", time.Hour))
+	values, err = c.Gets(ctx, []string{"key"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"key": "value"}, values)
+	_, err = c.l1.Get(ctx, "key")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestTieredCache_Expire(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	c := newTestTieredCache(t, client, WithL1TTL(30*time.Second))
+
+	require.NoError(t, c.Set(ctx, "session", "data", time.Minute))
+	require.NoError(t, c.Expire(ctx, "session", time.Hour))
+	assert.Equal(t, time.Hour, mr.TTL("session"))
+
+	ttl, err := c.TTL(ctx, "session")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, ttl)
+
+	// The L1 copy stays capped to the L1 TTL
+	ttl, err = c.l1.TTL(ctx, "session")
+	assert.NoError(t, err)
+	assert.InDelta(t, 30*time.Second, ttl, float64(time.Second))
+
+	value, ttl, err := c.GetWithTTL(ctx, "session")
+	assert.NoError(t, err)
+	assert.Equal(t, "data", value)
+	assert.Equal(t, time.Hour, ttl)
+
+	assert.ErrorIs(t, c.Expire(ctx, "missing", time.Hour), ErrKeyNotFound)
+}
@@ -0,0 +1,212 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ConfigData holds the entries of a ConfigMap or Secret, with accessors
+// returning fallback for missing keys and an error for malformed values
+type ConfigData map[string]string
+
+type ConfigMapInfo struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	Data            ConfigData        `json:"data"` // Data and BinaryData entries
+	Labels          map[string]string `json:"labels"`
+	ResourceVersion string            `json:"resource_version"`
+}
+
+type SecretInfo struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	Type            string            `json:"type"`
+	Data            ConfigData        `json:"-"` // Decoded values, never serialized
+	Labels          map[string]string `json:"labels"`
+	ResourceVersion string            `json:"resource_version"`
+}
+
+// ConfigMapHandler receives the events of WatchConfigMap. Calls are serialized.
+type ConfigMapHandler func(eventType EventType, configMap ConfigMapInfo)
+
+// String returns the value of key, or fallback if it is missing
+func (d ConfigData) String(key, fallback string) string {
+	if value, ok := d[key]; ok {
+		return value
+	}
+	return fallback
+}
+
+// Int returns the value of key as an int, or fallback if it is missing
+func (d ConfigData) Int(key string, fallback int) (int, error) {
+	value, ok := d[key]
+	if !ok {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback, fmt.Errorf("invalid int for key %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// Bool returns the value of key as a bool, or fallback if it is missing
+func (d ConfigData) Bool(key string, fallback bool) (bool, error) {
+	value, ok := d[key]
+	if !ok {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback, fmt.Errorf("invalid bool for key %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// Duration returns the value of key as a duration, e.g. "30s", or fallback if
+// it is missing
+func (d ConfigData) Duration(key string, fallback time.Duration) (time.Duration, error) {
+	value, ok := d[key]
+	if !ok {
+		return fallback, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback, fmt.Errorf("invalid duration for key %s: %w", key, err)
+	}
+	return duration, nil
+}
+
+// GetConfigMap returns the ConfigMap name in namespace
+func (k *K8sClient) GetConfigMap(ctx context.Context, namespace, name string) (*ConfigMapInfo, error) {
+	configMap, err := k.client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configmap %s/%s: %w", namespace, name, err)
+	}
+	info := toConfigMapInfo(*configMap)
+	return &info, nil
+}
+
+// ListConfigMaps returns the ConfigMaps matching the WithNamespaces and
+// WithLabels options
+func (k *K8sClient) ListConfigMaps(ctx context.Context, options ...GetDeploymentOption) ([]ConfigMapInfo, error) {
+	configMaps, err := listInNamespaces(options, func(namespace, labelSelector string) ([]corev1.ConfigMap, error) {
+		list, err := k.client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list configmaps: %w", err)
+		}
+		return list.Items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	configMapInfos := make([]ConfigMapInfo, 0, len(configMaps))
+	for _, configMap := range configMaps {
+		configMapInfos = append(configMapInfos, toConfigMapInfo(configMap))
+	}
+	return configMapInfos, nil
+}
+
+// GetSecret returns the Secret name in namespace with its values decoded
+func (k *K8sClient) GetSecret(ctx context.Context, namespace, name string) (*SecretInfo, error) {
+	secret, err := k.client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+
+	info := SecretInfo{
+		Name:            secret.Name,
+		Namespace:       secret.Namespace,
+		Type:            string(secret.Type),
+		Data:            make(ConfigData, len(secret.Data)+len(secret.StringData)),
+		Labels:          secret.Labels,
+		ResourceVersion: secret.ResourceVersion,
+	}
+	// The API base64-decodes Data; StringData is only set on objects that
+	// have not been through the API server, e.g. in tests
+	for key, value := range secret.Data {
+		info.Data[key] = string(value)
+	}
+	for key, value := range secret.StringData {
+		info.Data[key] = value
+	}
+	return &info, nil
+}
+
+// WatchConfigMap calls handler with the ConfigMap name in namespace and again
+// every time it changes, until ctx is done. It returns once the initial state
+// has been listed, after replaying it as an EventAdded event if the ConfigMap
+// exists. Like WatchDeployments it is backed by an informer, so broken
+// watches are re-established automatically.
+func (k *K8sClient) WatchConfigMap(ctx context.Context, namespace, name string, handler ConfigMapHandler) error {
+	if handler == nil {
+		return fmt.Errorf("configmap handler is required")
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(k.client, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	factory.Start(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("failed to sync configmap cache: %w", context.Cause(ctx))
+	}
+
+	mu := &sync.Mutex{}
+	emit := func(eventType EventType, obj any) {
+		configMap, ok := unwrapDeleted(obj).(*corev1.ConfigMap)
+		if !ok || configMap.Name != name {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		handler(eventType, toConfigMapInfo(*configMap))
+	}
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) { emit(EventAdded, obj) },
+		UpdateFunc: func(oldObj, obj any) {
+			// Skip relists that carry no change
+			oldConfigMap, oldOK := oldObj.(*corev1.ConfigMap)
+			configMap, ok := obj.(*corev1.ConfigMap)
+			if oldOK && ok && configMap.ResourceVersion != "" && oldConfigMap.ResourceVersion == configMap.ResourceVersion {
+				return
+			}
+			emit(EventUpdated, obj)
+		},
+		DeleteFunc: func(obj any) { emit(EventDeleted, obj) },
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add configmap event handler: %w", err)
+	}
+	return nil
+}
+
+func toConfigMapInfo(configMap corev1.ConfigMap) ConfigMapInfo {
+	data := make(ConfigData, len(configMap.Data)+len(configMap.BinaryData))
+	for key, value := range configMap.Data {
+		data[key] = value
+	}
+	for key, value := range configMap.BinaryData {
+		data[key] = string(value)
+	}
+	return ConfigMapInfo{
+		Name:            configMap.Name,
+		Namespace:       configMap.Namespace,
+		Data:            data,
+		Labels:          configMap.Labels,
+		ResourceVersion: configMap.ResourceVersion,
+	}
+}
@@ -0,0 +1,167 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// maxWarningEvents is the number of warning events attached per deployment or
+// pod by WithWarningEvents
+const maxWarningEvents = 10
+
+type EventInfo struct {
+	Type      string    `json:"type"`   // Normal or Warning
+	Reason    string    `json:"reason"` // e.g. BackOff, FailedScheduling
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Kind      string    `json:"kind"` // Kind of the involved object
+	Name      string    `json:"name"` // Name of the involved object
+	Namespace string    `json:"namespace"`
+	Source    string    `json:"source,omitempty"` // Reporting component, e.g. kubelet
+}
+
+// ObjectRef identifies the object events are about. Empty fields match any
+// object, e.g. ObjectRef{Kind: "Pod"} matches the events of every pod.
+type ObjectRef struct {
+	Kind string
+	Name string
+}
+
+// GetEvents returns the events of involvedObject in namespace, most recent
+// first. Kubernetes keeps events for an hour by default.
+func (k *K8sClient) GetEvents(ctx context.Context, namespace string, involvedObject ObjectRef) ([]EventInfo, error) {
+	var selectors []fields.Selector
+	if involvedObject.Kind != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.kind", involvedObject.Kind))
+	}
+	if involvedObject.Name != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.name", involvedObject.Name))
+	}
+	events, err := k.listEvents(ctx, namespace, fields.AndSelectors(selectors...))
+	if err != nil {
+		return nil, err
+	}
+
+	eventInfos := make([]EventInfo, 0, len(events))
+	for _, event := range events {
+		// Also filter here for clients ignoring field selectors
+		if involvedObject.Kind != "" && event.InvolvedObject.Kind != involvedObject.Kind {
+			continue
+		}
+		if involvedObject.Name != "" && event.InvolvedObject.Name != involvedObject.Name {
+			continue
+		}
+		eventInfos = append(eventInfos, toEventInfo(event))
+	}
+	sortEventsByLastSeen(eventInfos)
+	return eventInfos, nil
+}
+
+// WithWarningEvents makes GetDeploymentAndPods fill in DeploymentInfo.Warnings
+// and PodInfo.Warnings with the most recent warning events of each object
+func WithWarningEvents() GetDeploymentOption {
+	return func(opts *GetDeploymentOptions) {
+		opts.WarningEvents = true
+	}
+}
+
+// fillWarningEvents sets the warnings of deployments and their pods, listing
+// the warning events once per namespace
+func (k *K8sClient) fillWarningEvents(ctx context.Context, deployments []DeploymentInfo, namespaces []string) error {
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+
+	warnings := make(map[string][]EventInfo)
+	for _, namespace := range namespaces {
+		events, err := k.listEvents(ctx, namespace, fields.OneTermEqualSelector("type", corev1.EventTypeWarning))
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if event.Type != corev1.EventTypeWarning {
+				continue
+			}
+			key := event.InvolvedObject.Kind + "/" + event.Namespace + "/" + event.InvolvedObject.Name
+			warnings[key] = append(warnings[key], toEventInfo(event))
+		}
+	}
+
+	recent := func(kind, namespace, name string) []EventInfo {
+		events := warnings[kind+"/"+namespace+"/"+name]
+		sortEventsByLastSeen(events)
+		return events[:min(len(events), maxWarningEvents)]
+	}
+	for i := range deployments {
+		deployment := &deployments[i]
+		deployment.Warnings = recent("Deployment", deployment.Namespace, deployment.Name)
+		for j := range deployment.Pods {
+			pod := &deployment.Pods[j]
+			pod.Warnings = recent("Pod", pod.Namespace, pod.Name)
+		}
+	}
+	return nil
+}
+
+func (k *K8sClient) listEvents(ctx context.Context, namespace string, selector fields.Selector) ([]corev1.Event, error) {
+	list, err := k.client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	return list.Items, nil
+}
+
+func sortEventsByLastSeen(events []EventInfo) {
+	slices.SortStableFunc(events, func(a, b EventInfo) int {
+		return b.LastSeen.Compare(a.LastSeen)
+	})
+}
+
+func toEventInfo(event corev1.Event) EventInfo {
+	count := event.Count
+	lastSeen := event.LastTimestamp.Time
+	// Events reported with the events.k8s.io API use EventTime and Series
+	if event.Series != nil {
+		count = event.Series.Count
+		lastSeen = event.Series.LastObservedTime.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = event.EventTime.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = event.CreationTimestamp.Time
+	}
+	firstSeen := event.FirstTimestamp.Time
+	if firstSeen.IsZero() {
+		firstSeen = lastSeen
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	source := event.Source.Component
+	if source == "" {
+		source = event.ReportingController
+	}
+
+	return EventInfo{
+		Type:      event.Type,
+		Reason:    event.Reason,
+		Message:   event.Message,
+		Count:     count,
+		FirstSeen: firstSeen,
+		LastSeen:  lastSeen,
+		Kind:      event.InvolvedObject.Kind,
+		Name:      event.InvolvedObject.Name,
+		Namespace: event.Namespace,
+		Source:    source,
+	}
+}
@@ -0,0 +1,312 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Job statuses reported in JobInfo.Status
+const (
+	JobStatusPending   = "Pending" // No pod is running yet
+	JobStatusRunning   = "Running"
+	JobStatusSucceeded = "Succeeded"
+	JobStatusFailed    = "Failed"
+)
+
+// jobNameLabel is set by the job controller on the pods of a job
+const jobNameLabel = "batch.kubernetes.io/job-name"
+
+// maxJobLogLines is the number of log lines of the failed pod attached to
+// JobInfo.Logs by WaitForJobCompletion
+const maxJobLogLines int64 = 100
+
+// ErrJobFailed is returned by WaitForJobCompletion when the job reaches its
+// backoff limit or active deadline.
+var ErrJobFailed = errors.New("job failed")
+
+// jobPollInterval is how often WaitForJobCompletion checks the job status
+var jobPollInterval = 2 * time.Second
+
+// JobSpec describes a single container job created by CreateJob. Its pods are
+// never restarted in place, failures are retried with new pods up to
+// BackoffLimit, so the logs of failed attempts stay available.
+type JobSpec struct {
+	Name               string
+	Image              string
+	Command            []string
+	Args               []string
+	Env                map[string]string
+	Labels             map[string]string // Set on the job and its pods
+	ServiceAccountName string
+	// BackoffLimit is the number of retries before the job is marked failed,
+	// the Kubernetes default of 6 when nil
+	BackoffLimit *int32
+	// ActiveDeadline fails the job once it has been running this long. Zero
+	// disables the deadline.
+	ActiveDeadline time.Duration
+	// TTLAfterFinished deletes the job and its pods this long after it
+	// finished. Zero keeps them until deleted.
+	TTLAfterFinished time.Duration
+}
+
+type JobInfo struct {
+	Name           string            `json:"name"`
+	Namespace      string            `json:"namespace"`
+	Status         string            `json:"status"`
+	Active         int32             `json:"active"`
+	Succeeded      int32             `json:"succeeded"`
+	Failed         int32             `json:"failed"`
+	StartTime      *time.Time        `json:"start_time,omitempty"`
+	CompletionTime *time.Time        `json:"completion_time,omitempty"`
+	Reason         string            `json:"reason,omitempty"` // Failure reason, e.g. BackoffLimitExceeded
+	Message        string            `json:"message,omitempty"`
+	Labels         map[string]string `json:"labels"`
+	// Logs is the tail of the log of the last failed pod, set by
+	// WaitForJobCompletion when the job failed
+	Logs string `json:"logs,omitempty"`
+}
+
+type CronJobInfo struct {
+	Name               string            `json:"name"`
+	Namespace          string            `json:"namespace"`
+	Schedule           string            `json:"schedule"`
+	TimeZone           string            `json:"time_zone,omitempty"`
+	Suspended          bool              `json:"suspended"`
+	ActiveJobs         []string          `json:"active_jobs,omitempty"`
+	LastScheduleTime   *time.Time        `json:"last_schedule_time,omitempty"`
+	LastSuccessfulTime *time.Time        `json:"last_successful_time,omitempty"`
+	Labels             map[string]string `json:"labels"`
+}
+
+// CreateJob creates a job in namespace and returns its initial status
+func (k *K8sClient) CreateJob(ctx context.Context, namespace string, spec JobSpec) (*JobInfo, error) {
+	if spec.Name == "" {
+		return nil, fmt.Errorf("job name is required")
+	}
+	if spec.Image == "" {
+		return nil, fmt.Errorf("job image is required")
+	}
+
+	job, err := k.client.BatchV1().Jobs(namespace).Create(ctx, buildJob(namespace, spec), metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job %s/%s: %w", namespace, spec.Name, err)
+	}
+	info := toJobInfo(*job)
+	return &info, nil
+}
+
+// GetJobStatus returns the current status of a job
+func (k *K8sClient) GetJobStatus(ctx context.Context, namespace, name string) (*JobInfo, error) {
+	job, err := k.client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job %s/%s: %w", namespace, name, err)
+	}
+	info := toJobInfo(*job)
+	return &info, nil
+}
+
+// WaitForJobCompletion polls a job until it succeeds or fails. When the job
+// fails it returns its status with the tail of the log of its last failed pod
+// and an error wrapping ErrJobFailed. It returns an error wrapping
+// context.DeadlineExceeded if the job is not finished within timeout.
+func (k *K8sClient) WaitForJobCompletion(ctx context.Context, namespace, name string, timeout time.Duration) (*JobInfo, error) {
+	var job *batchv1.Job
+	err := wait.PollUntilContextTimeout(ctx, jobPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		job, err = k.client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to get job %s/%s: %w", namespace, name, err)
+		}
+		status := jobStatus(job)
+		return status == JobStatusSucceeded || status == JobStatusFailed, nil
+	})
+	if err != nil {
+		if wait.Interrupted(err) {
+			return nil, fmt.Errorf("timed out waiting for job %s/%s: %w", namespace, name, context.DeadlineExceeded)
+		}
+		return nil, err
+	}
+
+	info := toJobInfo(*job)
+	if info.Status != JobStatusFailed {
+		return &info, nil
+	}
+	// The logs are best effort, the pods may already be gone
+	info.Logs, _ = k.failedJobLogs(ctx, job)
+	return &info, fmt.Errorf("%s/%s: %s: %w", namespace, name, info.Reason, ErrJobFailed)
+}
+
+// ListCronJobs returns the cron jobs matching the WithNamespaces and
+// WithLabels options
+func (k *K8sClient) ListCronJobs(ctx context.Context, options ...GetDeploymentOption) ([]CronJobInfo, error) {
+	cronJobs, err := listInNamespaces(options, func(namespace, labelSelector string) ([]batchv1.CronJob, error) {
+		list, err := k.client.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list cron jobs: %w", err)
+		}
+		return list.Items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]CronJobInfo, 0, len(cronJobs))
+	for _, cronJob := range cronJobs {
+		infos = append(infos, toCronJobInfo(cronJob))
+	}
+	return infos, nil
+}
+
+// failedJobLogs returns the log tail of the most recent failed pod of job
+func (k *K8sClient) failedJobLogs(ctx context.Context, job *batchv1.Job) (string, error) {
+	labelSelector := jobNameLabel + "=" + job.Name
+	if job.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+		if err != nil {
+			return "", fmt.Errorf("invalid selector of job %s/%s: %w", job.Namespace, job.Name, err)
+		}
+		labelSelector = selector.String()
+	}
+	pods, err := k.client.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods of job %s/%s: %w", job.Namespace, job.Name, err)
+	}
+
+	var failed []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodFailed {
+			failed = append(failed, pod)
+		}
+	}
+	if len(failed) == 0 {
+		return "", nil
+	}
+	last := slices.MaxFunc(failed, func(a, b corev1.Pod) int {
+		return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+	})
+
+	var logs strings.Builder
+	if err := k.GetPodLogs(ctx, last.Namespace, last.Name, "", &logs, WithTailLines(maxJobLogLines)); err != nil {
+		return "", err
+	}
+	return logs.String(), nil
+}
+
+func buildJob(namespace string, spec JobSpec) *batchv1.Job {
+	env := make([]corev1.EnvVar, 0, len(spec.Env))
+	for name, value := range spec.Env {
+		env = append(env, corev1.EnvVar{Name: name, Value: value})
+	}
+	slices.SortFunc(env, func(a, b corev1.EnvVar) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      spec.Name,
+			Namespace: namespace,
+			Labels:    spec.Labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: spec.BackoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: spec.Labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: spec.ServiceAccountName,
+					Containers: []corev1.Container{{
+						Name:    spec.Name,
+						Image:   spec.Image,
+						Command: spec.Command,
+						Args:    spec.Args,
+						Env:     env,
+					}},
+				},
+			},
+		},
+	}
+	if spec.ActiveDeadline > 0 {
+		seconds := int64(spec.ActiveDeadline / time.Second)
+		job.Spec.ActiveDeadlineSeconds = &seconds
+	}
+	if spec.TTLAfterFinished > 0 {
+		seconds := int32(spec.TTLAfterFinished / time.Second)
+		job.Spec.TTLSecondsAfterFinished = &seconds
+	}
+	return job
+}
+
+// jobStatus derives the status of a job from its conditions and pod counts
+func jobStatus(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return JobStatusSucceeded
+		case batchv1.JobFailed:
+			return JobStatusFailed
+		}
+	}
+	if job.Status.Active > 0 {
+		return JobStatusRunning
+	}
+	return JobStatusPending
+}
+
+func toJobInfo(job batchv1.Job) JobInfo {
+	info := JobInfo{
+		Name:           job.Name,
+		Namespace:      job.Namespace,
+		Status:         jobStatus(&job),
+		Active:         job.Status.Active,
+		Succeeded:      job.Status.Succeeded,
+		Failed:         job.Status.Failed,
+		StartTime:      toTimePtr(job.Status.StartTime),
+		CompletionTime: toTimePtr(job.Status.CompletionTime),
+		Labels:         job.Labels,
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			info.Reason = condition.Reason
+			info.Message = condition.Message
+		}
+	}
+	return info
+}
+
+func toCronJobInfo(cronJob batchv1.CronJob) CronJobInfo {
+	info := CronJobInfo{
+		Name:               cronJob.Name,
+		Namespace:          cronJob.Namespace,
+		Schedule:           cronJob.Spec.Schedule,
+		Suspended:          cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+		LastScheduleTime:   toTimePtr(cronJob.Status.LastScheduleTime),
+		LastSuccessfulTime: toTimePtr(cronJob.Status.LastSuccessfulTime),
+		Labels:             cronJob.Labels,
+	}
+	if cronJob.Spec.TimeZone != nil {
+		info.TimeZone = *cronJob.Spec.TimeZone
+	}
+	for _, ref := range cronJob.Status.Active {
+		info.ActiveJobs = append(info.ActiveJobs, ref.Name)
+	}
+	return info
+}
+
+func toTimePtr(t *metav1.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return &t.Time
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/samber/lo"
 	appsv1 "k8s.io/api/apps/v1"
@@ -14,6 +15,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 type DeploymentInfo struct {
@@ -24,6 +26,12 @@ type DeploymentInfo struct {
 	App       string            `json:"app,omitempty"`
 	Labels    map[string]string `json:"labels"`
 	Pods      []PodInfo         `json:"pods"`
+	// Requests, Limits and Usage are the sums over Pods
+	Requests ResourceList  `json:"requests"`
+	Limits   ResourceList  `json:"limits"`
+	Usage    *ResourceList `json:"usage,omitempty"`
+	// Warnings are the most recent warning events, set with WithWarningEvents
+	Warnings []EventInfo `json:"warnings,omitempty"`
 }
 
 type PodInfo struct {
@@ -34,10 +42,16 @@ type PodInfo struct {
 	Labels    map[string]string `json:"labels"`
 	NodeName  string            `json:"node_name"`
 	IP        string            `json:"ip"`
+	Requests  ResourceList      `json:"requests"`           // Sum of the container requests
+	Limits    ResourceList      `json:"limits"`             // Sum of the container limits
+	Usage     *ResourceList     `json:"usage,omitempty"`    // Current usage, set with WithResourceUsage
+	Warnings  []EventInfo       `json:"warnings,omitempty"` // Recent warning events, set with WithWarningEvents
 }
 
 type K8sClient struct {
-	client *kubernetes.Clientset
+	client  kubernetes.Interface
+	metrics metricsclientset.Interface
+	config  *rest.Config
 }
 
 func NewK8sClient() (*K8sClient, error) {
@@ -61,15 +75,51 @@ func NewK8sClient() (*K8sClient, error) {
 		return nil, fmt.Errorf("failed to create k8s client: %w", err)
 	}
 
+	metricsClientset, err := metricsclientset.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s metrics client: %w", err)
+	}
+
 	return &K8sClient{
-		client: clientset,
+		client:  clientset,
+		metrics: metricsClientset,
+		config:  config,
 	}, nil
 }
 
-// GetDeploymentOptions defines options for GetDeploymentAndPods
+// Ping checks that the API server is reachable, e.g. for a readiness check.
+// The discovery client does not take a context, so a stuck call is only
+// abandoned by the caller when ctx is done.
+func (k *K8sClient) Ping(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := k.client.Discovery().ServerVersion()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to reach k8s api server: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetDeploymentOptions defines options for GetDeploymentAndPods and WatchDeployments
 type GetDeploymentOptions struct {
 	Namespaces []string
 	Labels     map[string]string
+	// ResyncPeriod makes WatchDeployments replay every cached object as an
+	// update on this interval. Zero disables resync.
+	ResyncPeriod time.Duration
+	// ResourceUsage makes GetDeploymentAndPods fill in the current usage of
+	// the pods from metrics-server
+	ResourceUsage bool
+	// WarningEvents makes GetDeploymentAndPods attach the recent warning
+	// events of the deployments and pods
+	WarningEvents bool
 }
 
 // GetDeploymentOption defines a function that configures GetDeploymentOptions
@@ -89,6 +139,22 @@ func WithLabels(labels map[string]string) GetDeploymentOption {
 	}
 }
 
+// WithResyncPeriod sets the resync interval of WatchDeployments
+func WithResyncPeriod(period time.Duration) GetDeploymentOption {
+	return func(opts *GetDeploymentOptions) {
+		opts.ResyncPeriod = period
+	}
+}
+
+// WithResourceUsage makes GetDeploymentAndPods fill in PodInfo.Usage and
+// DeploymentInfo.Usage from the metrics.k8s.io API, which requires
+// metrics-server in the cluster
+func WithResourceUsage() GetDeploymentOption {
+	return func(opts *GetDeploymentOptions) {
+		opts.ResourceUsage = true
+	}
+}
+
 func (k *K8sClient) GetDeploymentAndPods(ctx context.Context, options ...GetDeploymentOption) ([]DeploymentInfo, error) {
 	// Apply default options
 	opts := &GetDeploymentOptions{}
@@ -98,15 +164,7 @@ func (k *K8sClient) GetDeploymentAndPods(ctx context.Context, options ...GetDepl
 
 	var allDeployments []appsv1.Deployment
 
-	// Build label selector
-	var labelSelector string
-	if len(opts.Labels) > 0 {
-		var selectors []string
-		for key, value := range opts.Labels {
-			selectors = append(selectors, fmt.Sprintf("%s=%s", key, value))
-		}
-		labelSelector = strings.Join(selectors, ",")
-	}
+	labelSelector := buildLabelSelector(opts.Labels)
 
 	// If no namespaces specified, get all deployments
 	if len(opts.Namespaces) == 0 {
@@ -136,34 +194,55 @@ func (k *K8sClient) GetDeploymentAndPods(ctx context.Context, options ...GetDepl
 			return DeploymentInfo{}
 		}
 
-		// Extract app label if it exists, otherwise use deployment name prefix
-		appLabel := ""
-		if deployment.Labels != nil {
-			if app, exists := deployment.Labels["app"]; exists {
-				appLabel = app
-			}
+		return toDeploymentInfo(deployment, pods)
+	})
+
+	if opts.ResourceUsage {
+		if err := k.fillResourceUsage(ctx, deploymentInfos, opts.Namespaces); err != nil {
+			return nil, err
 		}
-		// If no app label, extract from deployment name (e.g., "wallet-deploy" -> "wallet")
-		if appLabel == "" {
-			if idx := strings.Index(deployment.Name, "-"); idx > 0 {
-				appLabel = deployment.Name[:idx]
-			} else {
-				appLabel = deployment.Name
-			}
+	}
+	if opts.WarningEvents {
+		if err := k.fillWarningEvents(ctx, deploymentInfos, opts.Namespaces); err != nil {
+			return nil, err
 		}
+	}
+
+	return deploymentInfos, nil
+}
+
+// fillResourceUsage sets the usage of the pods of deployments and their sums,
+// listing the pod metrics once per namespace
+func (k *K8sClient) fillResourceUsage(ctx context.Context, deployments []DeploymentInfo, namespaces []string) error {
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
 
-		return DeploymentInfo{
-			Name:      deployment.Name,
-			Namespace: deployment.Namespace,
-			Replicas:  *deployment.Spec.Replicas,
-			Ready:     deployment.Status.ReadyReplicas,
-			App:       appLabel,
-			Labels:    deployment.Labels,
-			Pods:      pods,
+	usage := make(map[string]ResourceList)
+	for _, namespace := range namespaces {
+		list, err := k.listPodMetrics(ctx, namespace, "")
+		if err != nil {
+			return err
 		}
-	})
+		for _, item := range list {
+			usage[item.Namespace+"/"+item.Name] = toPodMetrics(item).Usage
+		}
+	}
 
-	return deploymentInfos, nil
+	for i := range deployments {
+		var total ResourceList
+		for j := range deployments[i].Pods {
+			pod := &deployments[i].Pods[j]
+			podUsage, ok := usage[pod.Namespace+"/"+pod.Name]
+			if !ok {
+				continue
+			}
+			pod.Usage = &podUsage
+			total = total.Add(podUsage)
+		}
+		deployments[i].Usage = &total
+	}
+	return nil
 }
 
 func (k *K8sClient) getPodsForDeployment(ctx context.Context, deployment appsv1.Deployment) ([]PodInfo, error) {
@@ -180,20 +259,79 @@ func (k *K8sClient) getPodsForDeployment(ctx context.Context, deployment appsv1.
 	}
 
 	podInfos := lo.Map(pods.Items, func(pod corev1.Pod, _ int) PodInfo {
-		return PodInfo{
-			Name:      pod.Name,
-			Namespace: pod.Namespace,
-			Status:    string(pod.Status.Phase),
-			Ready:     isPodReady(pod),
-			Labels:    pod.Labels,
-			NodeName:  pod.Spec.NodeName,
-			IP:        pod.Status.PodIP,
-		}
+		return toPodInfo(pod)
 	})
 
 	return podInfos, nil
 }
 
+func buildLabelSelector(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var selectors []string
+	for key, value := range labels {
+		selectors = append(selectors, fmt.Sprintf("%s=%s", key, value))
+	}
+	return strings.Join(selectors, ",")
+}
+
+func toDeploymentInfo(deployment appsv1.Deployment, pods []PodInfo) DeploymentInfo {
+	// Extract app label if it exists, otherwise use deployment name prefix
+	appLabel := ""
+	if deployment.Labels != nil {
+		if app, exists := deployment.Labels["app"]; exists {
+			appLabel = app
+		}
+	}
+	// If no app label, extract from deployment name (e.g., "wallet-deploy" -> "wallet")
+	if appLabel == "" {
+		if idx := strings.Index(deployment.Name, "-"); idx > 0 {
+			appLabel = deployment.Name[:idx]
+		} else {
+			appLabel = deployment.Name
+		}
+	}
+
+	var replicas int32
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	var requests, limits ResourceList
+	for _, pod := range pods {
+		requests = requests.Add(pod.Requests)
+		limits = limits.Add(pod.Limits)
+	}
+
+	return DeploymentInfo{
+		Name:      deployment.Name,
+		Namespace: deployment.Namespace,
+		Replicas:  replicas,
+		Ready:     deployment.Status.ReadyReplicas,
+		App:       appLabel,
+		Labels:    deployment.Labels,
+		Pods:      pods,
+		Requests:  requests,
+		Limits:    limits,
+	}
+}
+
+func toPodInfo(pod corev1.Pod) PodInfo {
+	requests, limits := podResources(pod)
+	return PodInfo{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Status:    string(pod.Status.Phase),
+		Ready:     isPodReady(pod),
+		Labels:    pod.Labels,
+		NodeName:  pod.Spec.NodeName,
+		IP:        pod.Status.PodIP,
+		Requests:  requests,
+		Limits:    limits,
+	}
+}
+
 func isPodReady(pod corev1.Pod) bool {
 	for _, condition := range pod.Status.Conditions {
 		if condition.Type == corev1.PodReady {
@@ -2,12 +2,27 @@ package k8s
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+
+	"github.com/infigaming-com/go-common/snowflake"
 )
 
 // TestK8sClientRealCluster tests real GKE cluster connection
@@ -311,3 +326,835 @@ func TestAppLabelFallback(t *testing.T) {
 
 	t.Logf("App label fallback test passed for %d deployments", len(deployments))
 }
+
+func TestWatchDeployments(t *testing.T) {
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "wallet-deploy", Namespace: "default", Labels: map[string]string{"team": "payments"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "wallet"}},
+		},
+	}
+	other := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "game-deploy", Namespace: "default", Labels: map[string]string{"team": "games"}},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "game"}},
+		},
+	}
+	existingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "wallet-1", Namespace: "default", Labels: map[string]string{"app": "wallet"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+
+	clientset := fake.NewClientset(deployment, other, existingPod)
+	// The fake clientset drops changes made before a watch is opened, so wait
+	// for both informers to be watching before mutating.
+	watching := make(chan struct{}, 2)
+	clientset.PrependWatchReactor("*", func(k8stesting.Action) (bool, watch.Interface, error) {
+		select {
+		case watching <- struct{}{}:
+		default:
+		}
+		return false, nil, nil
+	})
+	client := &K8sClient{client: clientset}
+
+	events := make(chan WatchEvent, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := client.WatchDeployments(ctx, func(event WatchEvent) {
+		events <- event
+	}, WithNamespaces("default"), WithLabels(map[string]string{"team": "payments"}), WithResyncPeriod(0))
+	if err != nil {
+		t.Fatalf("WatchDeployments: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-watching:
+		case <-time.After(5 * time.Second):
+			t.Fatal("watch was not started")
+		}
+	}
+
+	next := func() WatchEvent {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return WatchEvent{}
+		}
+	}
+
+	// Initial state is replayed as added events, in no particular order.
+	var sawDeployment, sawPod bool
+	for i := 0; i < 2; i++ {
+		event := next()
+		if event.Type != EventAdded || event.Deployment.Name != "wallet-deploy" {
+			t.Fatalf("unexpected initial event: %+v", event)
+		}
+		if event.Pod == nil {
+			sawDeployment = true
+			if len(event.Deployment.Pods) != 1 || event.Deployment.Pods[0].Name != "wallet-1" {
+				t.Errorf("expected deployment pods, got %+v", event.Deployment.Pods)
+			}
+		} else {
+			sawPod = event.Pod.Name == "wallet-1"
+		}
+	}
+	if !sawDeployment || !sawPod {
+		t.Fatalf("missing initial events: deployment=%v pod=%v", sawDeployment, sawPod)
+	}
+
+	newPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "wallet-2", Namespace: "default", Labels: map[string]string{"app": "wallet"}}}
+	if _, err := clientset.CoreV1().Pods("default").Create(ctx, newPod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create pod: %v", err)
+	}
+	if event := next(); event.Type != EventAdded || event.Pod == nil || event.Pod.Name != "wallet-2" || event.Deployment.App != "wallet" {
+		t.Errorf("unexpected pod event: %+v", event)
+	}
+
+	if err := clientset.AppsV1().Deployments("default").Delete(ctx, "wallet-deploy", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete deployment: %v", err)
+	}
+	if event := next(); event.Type != EventDeleted || event.Pod != nil || event.Deployment.Name != "wallet-deploy" {
+		t.Errorf("unexpected delete event: %+v", event)
+	}
+
+	select {
+	case event := <-events:
+		t.Errorf("unexpected extra event: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestScaleAndRestartDeployment(t *testing.T) {
+	replicas := int32(1)
+	clientset := fake.NewClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "wallet-deploy", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	})
+	client := &K8sClient{client: clientset}
+	ctx := context.Background()
+
+	if err := client.ScaleDeployment(ctx, "default", "wallet-deploy", 3); err != nil {
+		t.Fatalf("ScaleDeployment: %v", err)
+	}
+	if err := client.RestartDeployment(ctx, "default", "wallet-deploy"); err != nil {
+		t.Fatalf("RestartDeployment: %v", err)
+	}
+
+	deployment, err := clientset.AppsV1().Deployments("default").Get(ctx, "wallet-deploy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get deployment: %v", err)
+	}
+	if *deployment.Spec.Replicas != 3 {
+		t.Errorf("expected 3 replicas, got %d", *deployment.Spec.Replicas)
+	}
+	if deployment.Spec.Template.Annotations[RestartedAtAnnotation] == "" {
+		t.Error("expected restartedAt annotation to be set")
+	}
+
+	if err := client.ScaleDeployment(ctx, "default", "wallet-deploy", -1); err == nil {
+		t.Error("expected error for negative replicas")
+	}
+	if err := client.ScaleDeployment(ctx, "default", "missing", 1); err == nil {
+		t.Error("expected error for missing deployment")
+	}
+}
+
+func TestWaitForRollout(t *testing.T) {
+	rolloutPollInterval = 10 * time.Millisecond
+	defer func() { rolloutPollInterval = 2 * time.Second }()
+
+	replicas := int32(2)
+	newDeployment := func(name string, status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     status,
+		}
+	}
+	clientset := fake.NewClientset(
+		newDeployment("complete", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}),
+		newDeployment("progressing", appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 1}),
+		newDeployment("stalled", appsv1.DeploymentStatus{ObservedGeneration: 2, Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+		}}),
+	)
+	client := &K8sClient{client: clientset}
+	ctx := context.Background()
+
+	if err := client.WaitForRollout(ctx, "default", "complete", time.Second); err != nil {
+		t.Errorf("expected complete rollout, got %v", err)
+	}
+	if err := client.WaitForRollout(ctx, "default", "progressing", 50*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected timeout, got %v", err)
+	}
+	if err := client.WaitForRollout(ctx, "default", "stalled", time.Second); !errors.Is(err, ErrProgressDeadlineExceeded) {
+		t.Errorf("expected ErrProgressDeadlineExceeded, got %v", err)
+	}
+	if err := client.WaitForRollout(ctx, "default", "missing", time.Second); err == nil {
+		t.Error("expected error for missing deployment")
+	}
+}
+
+func TestGetPodLogs(t *testing.T) {
+	clientset := fake.NewClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "wallet-1", Namespace: "default"},
+	})
+	client := &K8sClient{client: clientset}
+
+	var buf strings.Builder
+	err := client.GetPodLogs(context.Background(), "default", "wallet-1", "wallet", &buf,
+		WithTailLines(10), WithSinceTime(time.Now().Add(-time.Hour)))
+	if err != nil {
+		t.Fatalf("GetPodLogs: %v", err)
+	}
+	// The fake clientset always serves this body.
+	if buf.String() != "fake logs" {
+		t.Errorf("unexpected logs: %q", buf.String())
+	}
+}
+
+func TestExecInPod_Validation(t *testing.T) {
+	client := &K8sClient{client: fake.NewClientset()}
+
+	if _, err := client.ExecInPod(context.Background(), "default", "wallet-1", "", nil); err == nil {
+		t.Error("expected error for empty command")
+	}
+	if _, err := client.ExecInPod(context.Background(), "default", "wallet-1", "", []string{"ls"}); err == nil {
+		t.Error("expected error without rest config")
+	}
+}
+
+func TestNodeLeaseBackend(t *testing.T) {
+	clientset := fake.NewClientset()
+	client := &K8sClient{client: clientset}
+	backend := client.NodeLeaseBackend("default", "snowflake-node-")
+	ctx := context.Background()
+
+	first, err := backend.Claim(ctx, "svc:pod-a:1", 30*time.Second)
+	if err != nil || first != 0 {
+		t.Fatalf("Claim = %d, %v", first, err)
+	}
+	second, err := backend.Claim(ctx, "svc:pod-b:1", 30*time.Second)
+	if err != nil || second != 1 {
+		t.Fatalf("Claim = %d, %v", second, err)
+	}
+
+	if renewal, err := backend.Renew(ctx, first, "svc:pod-a:1", 30*time.Second); err != nil || renewal != snowflake.LeaseRenewed {
+		t.Fatalf("Renew = %v, %v", renewal, err)
+	}
+	if _, err := backend.Renew(ctx, first, "svc:pod-b:1", 30*time.Second); !errors.Is(err, snowflake.ErrLeaseNotHeld) {
+		t.Fatalf("expected ErrLeaseNotHeld renewing another holder's lease, got %v", err)
+	}
+
+	// An expired lease is reclaimed by its holder and claimable by others
+	lease, err := clientset.CoordinationV1().Leases("default").Get(ctx, "snowflake-node-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get lease: %v", err)
+	}
+	stale := metav1.NewMicroTime(time.Now().Add(-time.Minute))
+	lease.Spec.RenewTime = &stale
+	if _, err := clientset.CoordinationV1().Leases("default").Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update lease: %v", err)
+	}
+	if renewal, err := backend.Renew(ctx, first, "svc:pod-a:1", 30*time.Second); err != nil || renewal != snowflake.LeaseReclaimed {
+		t.Fatalf("Renew = %v, %v", renewal, err)
+	}
+	if _, err := clientset.CoordinationV1().Leases("default").Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update lease: %v", err)
+	}
+	if taken, err := backend.Claim(ctx, "svc:pod-c:1", 30*time.Second); err != nil || taken != 0 {
+		t.Fatalf("expected the expired node to be claimed, got %d, %v", taken, err)
+	}
+
+	if err := backend.Release(ctx, first, "svc:pod-a:1"); !errors.Is(err, snowflake.ErrLeaseNotHeld) {
+		t.Fatalf("expected ErrLeaseNotHeld releasing a lost lease, got %v", err)
+	}
+	if err := backend.Release(ctx, second, "svc:pod-b:1"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if _, err := clientset.CoordinationV1().Leases("default").Get(ctx, "snowflake-node-1", metav1.GetOptions{}); err == nil {
+		t.Error("expected the released lease to be deleted")
+	}
+}
+
+func TestNodeLeaseBackend_AcquireNodeLease(t *testing.T) {
+	client := &K8sClient{client: fake.NewClientset()}
+	backend := client.NodeLeaseBackend("default", "snowflake-node-")
+
+	nl, err := snowflake.AcquireNodeLease(context.Background(), nil,
+		snowflake.WithLeaseBackend(backend),
+		snowflake.WithServiceName("wallet"),
+	)
+	if err != nil {
+		t.Fatalf("AcquireNodeLease: %v", err)
+	}
+	if nl.NodeID() != 0 || !nl.IsHealthy() {
+		t.Errorf("unexpected lease node %d healthy %v", nl.NodeID(), nl.IsHealthy())
+	}
+	if err := nl.Release(context.Background()); err != nil {
+		t.Errorf("Release: %v", err)
+	}
+}
+
+func TestResourceMetrics(t *testing.T) {
+	resources := func(cpu, memory string) corev1.ResourceList {
+		return corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}
+	}
+
+	replicas := int32(2)
+	selector := map[string]string{"app": "wallet"}
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: selector},
+			Spec: corev1.PodSpec{
+				NodeName: "node-a",
+				Containers: []corev1.Container{
+					{Name: "app", Resources: corev1.ResourceRequirements{Requests: resources("250m", "256Mi"), Limits: resources("500m", "512Mi")}},
+					{Name: "sidecar", Resources: corev1.ResourceRequirements{Requests: resources("50m", "64Mi")}},
+				},
+			},
+		}
+	}
+	// The fake metrics client lists "pods" and "nodes" while NewSimpleClientset
+	// would store the objects as "podmetricses" and "nodemetricses"
+	metricsClientset := metricsfake.NewSimpleClientset()
+	podMetricsResource := metricsv1beta1.SchemeGroupVersion.WithResource("pods")
+	nodeMetricsResource := metricsv1beta1.SchemeGroupVersion.WithResource("nodes")
+	if err := metricsClientset.Tracker().Create(podMetricsResource, &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "wallet-1", Namespace: "default", Labels: selector},
+		Window:     metav1.Duration{Duration: 30 * time.Second},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{Name: "app", Usage: resources("120m", "200Mi")},
+			{Name: "sidecar", Usage: resources("5m", "20Mi")},
+		},
+	}, "default"); err != nil {
+		t.Fatalf("create pod metrics: %v", err)
+	}
+	if err := metricsClientset.Tracker().Create(nodeMetricsResource, &metricsv1beta1.NodeMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Usage:      resources("1500m", "3Gi"),
+	}, ""); err != nil {
+		t.Fatalf("create node metrics: %v", err)
+	}
+
+	client := &K8sClient{
+		client: fake.NewClientset(
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "wallet-deploy", Namespace: "default", Labels: selector},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas, Selector: &metav1.LabelSelector{MatchLabels: selector}},
+			},
+			pod("wallet-1"),
+			pod("wallet-2"),
+			&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+				Status:     corev1.NodeStatus{Allocatable: resources("4", "8Gi")},
+			},
+		),
+		metrics: metricsClientset,
+	}
+	ctx := context.Background()
+
+	podMetrics, err := client.GetPodMetrics(ctx, "default", WithLabels(selector))
+	if err != nil {
+		t.Fatalf("GetPodMetrics: %v", err)
+	}
+	if len(podMetrics) != 1 {
+		t.Fatalf("expected 1 pod metrics, got %d", len(podMetrics))
+	}
+	if got := podMetrics[0]; got.Usage != (ResourceList{CPUMillis: 125, MemoryBytes: 220 << 20}) || len(got.Containers) != 2 || got.Window != 30*time.Second {
+		t.Errorf("unexpected pod metrics: %+v", got)
+	}
+
+	nodeMetrics, err := client.GetNodeMetrics(ctx)
+	if err != nil {
+		t.Fatalf("GetNodeMetrics: %v", err)
+	}
+	if len(nodeMetrics) != 1 {
+		t.Fatalf("expected 1 node metrics, got %d", len(nodeMetrics))
+	}
+	if got := nodeMetrics[0]; got.Usage != (ResourceList{CPUMillis: 1500, MemoryBytes: 3 << 30}) || got.Allocatable != (ResourceList{CPUMillis: 4000, MemoryBytes: 8 << 30}) {
+		t.Errorf("unexpected node metrics: %+v", got)
+	}
+
+	deployments, err := client.GetDeploymentAndPods(ctx, WithNamespaces("default"), WithResourceUsage())
+	if err != nil {
+		t.Fatalf("GetDeploymentAndPods: %v", err)
+	}
+	if len(deployments) != 1 || len(deployments[0].Pods) != 2 {
+		t.Fatalf("unexpected deployments: %+v", deployments)
+	}
+	deployment := deployments[0]
+	if deployment.Requests != (ResourceList{CPUMillis: 600, MemoryBytes: 640 << 20}) {
+		t.Errorf("unexpected deployment requests: %+v", deployment.Requests)
+	}
+	if deployment.Limits != (ResourceList{CPUMillis: 1000, MemoryBytes: 1 << 30}) {
+		t.Errorf("unexpected deployment limits: %+v", deployment.Limits)
+	}
+	if deployment.Usage == nil || *deployment.Usage != (ResourceList{CPUMillis: 125, MemoryBytes: 220 << 20}) {
+		t.Errorf("unexpected deployment usage: %+v", deployment.Usage)
+	}
+	for _, pod := range deployment.Pods {
+		if (pod.Name == "wallet-1") != (pod.Usage != nil) {
+			t.Errorf("unexpected usage of pod %s: %+v", pod.Name, pod.Usage)
+		}
+	}
+
+	if _, err := (&K8sClient{client: fake.NewClientset()}).GetNodeMetrics(ctx); err == nil {
+		t.Error("expected error without a metrics client")
+	}
+}
+
+func TestGetServicesIngressesAndHPAs(t *testing.T) {
+	labels := map[string]string{"app": "wallet"}
+	pathType := networkingv1.PathTypePrefix
+	className := "nginx"
+	minReplicas := int32(2)
+	targetUtilization := int32(70)
+	currentUtilization := int32(45)
+
+	client := &K8sClient{client: fake.NewClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "wallet", Namespace: "default", Labels: labels},
+			Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeLoadBalancer,
+				ClusterIP: "10.0.0.10",
+				Selector:  labels,
+				Ports: []corev1.ServicePort{
+					{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromString("http"), NodePort: 30080},
+				},
+			},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}},
+			}},
+		},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "game", Namespace: "default", Labels: map[string]string{"app": "game"}}},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "wallet", Namespace: "default", Labels: labels},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: &className,
+				TLS:              []networkingv1.IngressTLS{{Hosts: []string{"wallet.example.com"}}},
+				Rules: []networkingv1.IngressRule{{
+					Host: "wallet.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/api",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+								Name: "wallet",
+								Port: networkingv1.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+			Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+				Ingress: []networkingv1.IngressLoadBalancerIngress{{IP: "203.0.113.10"}},
+			}},
+		},
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "wallet", Namespace: "default", Labels: labels},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "wallet-deploy"},
+				MinReplicas:    &minReplicas,
+				MaxReplicas:    10,
+				Metrics: []autoscalingv2.MetricSpec{{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name:   corev1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &targetUtilization},
+					},
+				}},
+			},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+				CurrentReplicas: 3,
+				DesiredReplicas: 3,
+				CurrentMetrics: []autoscalingv2.MetricStatus{{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricStatus{
+						Name:    corev1.ResourceCPU,
+						Current: autoscalingv2.MetricValueStatus{AverageUtilization: &currentUtilization},
+					},
+				}},
+			},
+		},
+	)}
+	ctx := context.Background()
+
+	services, err := client.GetServices(ctx, WithNamespaces("default"), WithLabels(labels))
+	if err != nil {
+		t.Fatalf("GetServices: %v", err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("expected 1 service, got %d", len(services))
+	}
+	service := services[0]
+	if service.Type != "LoadBalancer" || service.ClusterIP != "10.0.0.10" || len(service.ExternalIPs) != 1 || service.ExternalIPs[0] != "lb.example.com" {
+		t.Errorf("unexpected service: %+v", service)
+	}
+	if len(service.Ports) != 1 || service.Ports[0] != (ServicePort{Name: "http", Protocol: "TCP", Port: 80, TargetPort: "http", NodePort: 30080}) {
+		t.Errorf("unexpected service ports: %+v", service.Ports)
+	}
+
+	ingresses, err := client.GetIngresses(ctx)
+	if err != nil {
+		t.Fatalf("GetIngresses: %v", err)
+	}
+	if len(ingresses) != 1 {
+		t.Fatalf("expected 1 ingress, got %d", len(ingresses))
+	}
+	ingress := ingresses[0]
+	if ingress.ClassName != "nginx" || len(ingress.Hosts) != 1 || ingress.Hosts[0] != "wallet.example.com" || len(ingress.TLSHosts) != 1 {
+		t.Errorf("unexpected ingress: %+v", ingress)
+	}
+	wantRule := IngressRule{Host: "wallet.example.com", Path: "/api", PathType: "Prefix", ServiceName: "wallet", ServicePort: "80"}
+	if len(ingress.Rules) != 1 || ingress.Rules[0] != wantRule {
+		t.Errorf("unexpected ingress rules: %+v", ingress.Rules)
+	}
+	if len(ingress.Addresses) != 1 || ingress.Addresses[0] != "203.0.113.10" {
+		t.Errorf("unexpected ingress addresses: %v", ingress.Addresses)
+	}
+
+	hpas, err := client.GetHorizontalPodAutoscalers(ctx, WithNamespaces("default"))
+	if err != nil {
+		t.Fatalf("GetHorizontalPodAutoscalers: %v", err)
+	}
+	if len(hpas) != 1 {
+		t.Fatalf("expected 1 HPA, got %d", len(hpas))
+	}
+	hpa := hpas[0]
+	if hpa.TargetKind != "Deployment" || hpa.TargetName != "wallet-deploy" || hpa.MinReplicas != 2 || hpa.MaxReplicas != 10 || hpa.CurrentReplicas != 3 {
+		t.Errorf("unexpected HPA: %+v", hpa)
+	}
+	if len(hpa.Metrics) != 1 {
+		t.Fatalf("expected 1 HPA metric, got %d", len(hpa.Metrics))
+	}
+	metric := hpa.Metrics[0]
+	if metric.Type != "Resource" || metric.Name != "cpu" || metric.TargetUtilization == nil || *metric.TargetUtilization != 70 ||
+		metric.CurrentUtilization == nil || *metric.CurrentUtilization != 45 {
+		t.Errorf("unexpected HPA metric: %+v", metric)
+	}
+}
+
+func TestConfigMapsAndSecrets(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "wallet-config", Namespace: "default", Labels: map[string]string{"app": "wallet"}},
+			Data:       map[string]string{"timeout": "30s", "workers": "8", "debug": "true", "name": "wallet"},
+			BinaryData: map[string][]byte{"cert": []byte("binary")},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "game-config", Namespace: "games", Labels: map[string]string{"app": "game"}},
+			Data:       map[string]string{"workers": "many"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "wallet-secret", Namespace: "default"},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"password": []byte("s3cret")},
+		},
+	)
+	client := &K8sClient{client: clientset}
+	ctx := context.Background()
+
+	configMap, err := client.GetConfigMap(ctx, "default", "wallet-config")
+	if err != nil {
+		t.Fatalf("GetConfigMap: %v", err)
+	}
+	if configMap.Data.String("name", "") != "wallet" || configMap.Data.String("cert", "") != "binary" || configMap.Data.String("missing", "fallback") != "fallback" {
+		t.Errorf("unexpected configmap data: %+v", configMap.Data)
+	}
+	if timeout, err := configMap.Data.Duration("timeout", 0); err != nil || timeout != 30*time.Second {
+		t.Errorf("expected 30s timeout, got %v, %v", timeout, err)
+	}
+	if workers, err := configMap.Data.Int("workers", 1); err != nil || workers != 8 {
+		t.Errorf("expected 8 workers, got %d, %v", workers, err)
+	}
+	if debug, err := configMap.Data.Bool("debug", false); err != nil || !debug {
+		t.Errorf("expected debug, got %v, %v", debug, err)
+	}
+	if retries, err := configMap.Data.Int("retries", 3); err != nil || retries != 3 {
+		t.Errorf("expected fallback retries, got %d, %v", retries, err)
+	}
+
+	if _, err := client.GetConfigMap(ctx, "default", "missing"); err == nil {
+		t.Error("expected an error for a missing configmap")
+	}
+
+	configMaps, err := client.ListConfigMaps(ctx, WithNamespaces("games"))
+	if err != nil {
+		t.Fatalf("ListConfigMaps: %v", err)
+	}
+	if len(configMaps) != 1 || configMaps[0].Name != "game-config" {
+		t.Fatalf("expected game-config, got %+v", configMaps)
+	}
+	if _, err := configMaps[0].Data.Int("workers", 1); err == nil {
+		t.Error("expected an error for a malformed int")
+	}
+	configMaps, err = client.ListConfigMaps(ctx, WithLabels(map[string]string{"app": "wallet"}))
+	if err != nil || len(configMaps) != 1 || configMaps[0].Name != "wallet-config" {
+		t.Fatalf("expected wallet-config, got %+v, %v", configMaps, err)
+	}
+
+	secret, err := client.GetSecret(ctx, "default", "wallet-secret")
+	if err != nil {
+		t.Fatalf("GetSecret: %v", err)
+	}
+	if secret.Type != "Opaque" || secret.Data.String("password", "") != "s3cret" {
+		t.Errorf("unexpected secret: %+v", secret)
+	}
+}
+
+func TestWatchConfigMap(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "wallet-config", Namespace: "default"},
+		Data:       map[string]string{"workers": "8"},
+	}
+	clientset := fake.NewClientset(configMap)
+	watching := make(chan struct{}, 1)
+	clientset.PrependWatchReactor("configmaps", func(k8stesting.Action) (bool, watch.Interface, error) {
+		select {
+		case watching <- struct{}{}:
+		default:
+		}
+		return false, nil, nil
+	})
+	client := &K8sClient{client: clientset}
+
+	type configMapEvent struct {
+		eventType EventType
+		configMap ConfigMapInfo
+	}
+	events := make(chan configMapEvent, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := client.WatchConfigMap(ctx, "default", "wallet-config", func(eventType EventType, configMap ConfigMapInfo) {
+		events <- configMapEvent{eventType, configMap}
+	})
+	if err != nil {
+		t.Fatalf("WatchConfigMap: %v", err)
+	}
+	select {
+	case <-watching:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch was not started")
+	}
+
+	next := func() configMapEvent {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return configMapEvent{}
+		}
+	}
+
+	if event := next(); event.eventType != EventAdded || event.configMap.Data["workers"] != "8" {
+		t.Fatalf("unexpected initial event: %+v", event)
+	}
+
+	// Other configmaps are ignored
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "game-config", Namespace: "default"}}
+	if _, err := clientset.CoreV1().ConfigMaps("default").Create(ctx, other, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create configmap: %v", err)
+	}
+
+	updated := configMap.DeepCopy()
+	updated.Data["workers"] = "16"
+	if _, err := clientset.CoreV1().ConfigMaps("default").Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update configmap: %v", err)
+	}
+	if event := next(); event.eventType != EventUpdated || event.configMap.Data["workers"] != "16" {
+		t.Errorf("unexpected update event: %+v", event)
+	}
+
+	if err := clientset.CoreV1().ConfigMaps("default").Delete(ctx, "wallet-config", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete configmap: %v", err)
+	}
+	if event := next(); event.eventType != EventDeleted {
+		t.Errorf("unexpected delete event: %+v", event)
+	}
+
+	select {
+	case event := <-events:
+		t.Errorf("unexpected extra event: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestGetEventsAndWarnings(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	event := func(name, eventType, kind, object, reason string, lastSeen time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Name: object, Namespace: "default"},
+			Type:           eventType,
+			Reason:         reason,
+			Message:        reason + " on " + object,
+			Count:          3,
+			FirstTimestamp: metav1.NewTime(lastSeen.Add(-time.Minute)),
+			LastTimestamp:  metav1.NewTime(lastSeen),
+			Source:         corev1.EventSource{Component: "kubelet"},
+		}
+	}
+
+	selector := map[string]string{"app": "wallet"}
+	client := &K8sClient{client: fake.NewClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "wallet-deploy", Namespace: "default", Labels: selector},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: selector}},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "wallet-1", Namespace: "default", Labels: selector}},
+		event("e1", corev1.EventTypeWarning, "Pod", "wallet-1", "BackOff", now.Add(-2*time.Minute)),
+		event("e2", corev1.EventTypeWarning, "Pod", "wallet-1", "Unhealthy", now),
+		event("e3", corev1.EventTypeNormal, "Pod", "wallet-1", "Pulled", now),
+		event("e4", corev1.EventTypeWarning, "Deployment", "wallet-deploy", "ProgressDeadlineExceeded", now),
+		event("e5", corev1.EventTypeWarning, "Pod", "other", "BackOff", now),
+	)}
+	ctx := context.Background()
+
+	events, err := client.GetEvents(ctx, "default", ObjectRef{Kind: "Pod", Name: "wallet-1"})
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %+v", events)
+	}
+	if events[2].Reason != "BackOff" || events[2].Count != 3 || events[2].Source != "kubelet" || !events[2].LastSeen.Equal(now.Add(-2*time.Minute)) {
+		t.Errorf("unexpected oldest event: %+v", events[2])
+	}
+
+	all, err := client.GetEvents(ctx, "default", ObjectRef{})
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	if len(all) != 5 {
+		t.Errorf("expected 5 events, got %d", len(all))
+	}
+
+	deployments, err := client.GetDeploymentAndPods(ctx, WithNamespaces("default"), WithWarningEvents())
+	if err != nil {
+		t.Fatalf("GetDeploymentAndPods: %v", err)
+	}
+	if len(deployments) != 1 || len(deployments[0].Pods) != 1 {
+		t.Fatalf("unexpected deployments: %+v", deployments)
+	}
+	if warnings := deployments[0].Warnings; len(warnings) != 1 || warnings[0].Reason != "ProgressDeadlineExceeded" {
+		t.Errorf("unexpected deployment warnings: %+v", warnings)
+	}
+	warnings := deployments[0].Pods[0].Warnings
+	if len(warnings) != 2 || warnings[0].Reason != "Unhealthy" || warnings[1].Reason != "BackOff" {
+		t.Errorf("unexpected pod warnings: %+v", warnings)
+	}
+}
+
+func TestJobs(t *testing.T) {
+	jobPollInterval = 10 * time.Millisecond
+	defer func() { jobPollInterval = 2 * time.Second }()
+
+	newJob := func(name string, active int32, conditions ...batchv1.JobCondition) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "batch"},
+			Status:     batchv1.JobStatus{Active: active, Conditions: conditions},
+		}
+	}
+	newPod := func(name, job string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "batch", Labels: map[string]string{jobNameLabel: job}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	suspend := true
+	clientset := fake.NewClientset(
+		newJob("done", 0, batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}),
+		newJob("running", 1),
+		newJob("broken", 0, batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"}),
+		newPod("broken-abc", "broken", corev1.PodFailed),
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "cleanup", Namespace: "batch", Labels: map[string]string{"team": "wallet"}},
+			Spec:       batchv1.CronJobSpec{Schedule: "0 3 * * *", Suspend: &suspend},
+			Status:     batchv1.CronJobStatus{Active: []corev1.ObjectReference{{Name: "cleanup-1"}}},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "other"},
+			Spec:       batchv1.CronJobSpec{Schedule: "@hourly"},
+		},
+	)
+	client := &K8sClient{client: clientset}
+	ctx := context.Background()
+
+	backoffLimit := int32(0)
+	info, err := client.CreateJob(ctx, "batch", JobSpec{
+		Name:             "migrate",
+		Image:            "wallet:1.2.3",
+		Command:          []string{"/migrate"},
+		Env:              map[string]string{"B": "2", "A": "1"},
+		Labels:           map[string]string{"app": "wallet"},
+		BackoffLimit:     &backoffLimit,
+		ActiveDeadline:   time.Hour,
+		TTLAfterFinished: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	if info.Status != JobStatusPending {
+		t.Errorf("expected pending job, got %s", info.Status)
+	}
+	job, err := clientset.BatchV1().Jobs("batch").Get(ctx, "migrate", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get created job: %v", err)
+	}
+	podSpec := job.Spec.Template.Spec
+	if podSpec.RestartPolicy != corev1.RestartPolicyNever || podSpec.Containers[0].Env[0].Name != "A" {
+		t.Errorf("unexpected pod spec: %+v", podSpec)
+	}
+	if *job.Spec.BackoffLimit != 0 || *job.Spec.ActiveDeadlineSeconds != 3600 || *job.Spec.TTLSecondsAfterFinished != 60 {
+		t.Errorf("unexpected job spec: %+v", job.Spec)
+	}
+	if _, err := client.CreateJob(ctx, "batch", JobSpec{Name: "no-image"}); err == nil {
+		t.Error("expected error without image")
+	}
+
+	if info, err := client.GetJobStatus(ctx, "batch", "running"); err != nil || info.Status != JobStatusRunning {
+		t.Errorf("expected running job, got %+v, %v", info, err)
+	}
+	if _, err := client.GetJobStatus(ctx, "batch", "missing"); err == nil {
+		t.Error("expected error for missing job")
+	}
+
+	if info, err := client.WaitForJobCompletion(ctx, "batch", "done", time.Second); err != nil || info.Status != JobStatusSucceeded {
+		t.Errorf("expected succeeded job, got %+v, %v", info, err)
+	}
+	if _, err := client.WaitForJobCompletion(ctx, "batch", "running", 50*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected timeout, got %v", err)
+	}
+	info, err = client.WaitForJobCompletion(ctx, "batch", "broken", time.Second)
+	if !errors.Is(err, ErrJobFailed) {
+		t.Fatalf("expected ErrJobFailed, got %v", err)
+	}
+	// The fake clientset always serves this body.
+	if info.Reason != "BackoffLimitExceeded" || info.Logs != "fake logs" {
+		t.Errorf("unexpected failed job: %+v", info)
+	}
+
+	cronJobs, err := client.ListCronJobs(ctx, WithNamespaces("batch"), WithLabels(map[string]string{"team": "wallet"}))
+	if err != nil {
+		t.Fatalf("ListCronJobs: %v", err)
+	}
+	if len(cronJobs) != 1 || cronJobs[0].Name != "cleanup" || !cronJobs[0].Suspended ||
+		cronJobs[0].Schedule != "0 3 * * *" || len(cronJobs[0].ActiveJobs) != 1 {
+		t.Errorf("unexpected cron jobs: %+v", cronJobs)
+	}
+	if cronJobs, err := client.ListCronJobs(ctx); err != nil || len(cronJobs) != 2 {
+		t.Errorf("expected all cron jobs, got %+v, %v", cronJobs, err)
+	}
+}
@@ -0,0 +1,156 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// ResourceList holds CPU and memory amounts
+type ResourceList struct {
+	CPUMillis   int64 `json:"cpu_millis"`
+	MemoryBytes int64 `json:"memory_bytes"`
+}
+
+// Add returns the sum of r and other
+func (r ResourceList) Add(other ResourceList) ResourceList {
+	return ResourceList{
+		CPUMillis:   r.CPUMillis + other.CPUMillis,
+		MemoryBytes: r.MemoryBytes + other.MemoryBytes,
+	}
+}
+
+// ContainerMetrics is the current usage of one container
+type ContainerMetrics struct {
+	Name  string       `json:"name"`
+	Usage ResourceList `json:"usage"`
+}
+
+// PodMetrics is the current usage of a pod as reported by metrics-server
+type PodMetrics struct {
+	Name       string             `json:"name"`
+	Namespace  string             `json:"namespace"`
+	Timestamp  time.Time          `json:"timestamp"`
+	Window     time.Duration      `json:"window"`
+	Usage      ResourceList       `json:"usage"` // Sum of the containers
+	Containers []ContainerMetrics `json:"containers"`
+}
+
+// NodeMetrics is the current usage of a node as reported by metrics-server,
+// along with the capacity left for pods
+type NodeMetrics struct {
+	Name        string        `json:"name"`
+	Timestamp   time.Time     `json:"timestamp"`
+	Window      time.Duration `json:"window"`
+	Usage       ResourceList  `json:"usage"`
+	Allocatable ResourceList  `json:"allocatable"`
+}
+
+// GetPodMetrics returns the current usage of the pods in namespace, all
+// namespaces when empty, from the metrics.k8s.io API. It requires
+// metrics-server in the cluster. Only the WithLabels option applies.
+func (k *K8sClient) GetPodMetrics(ctx context.Context, namespace string, options ...GetDeploymentOption) ([]PodMetrics, error) {
+	opts := &GetDeploymentOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	list, err := k.listPodMetrics(ctx, namespace, buildLabelSelector(opts.Labels))
+	if err != nil {
+		return nil, err
+	}
+
+	podMetrics := make([]PodMetrics, 0, len(list))
+	for _, item := range list {
+		podMetrics = append(podMetrics, toPodMetrics(item))
+	}
+	return podMetrics, nil
+}
+
+// GetNodeMetrics returns the current usage and allocatable resources of every
+// node from the metrics.k8s.io API. It requires metrics-server in the cluster.
+func (k *K8sClient) GetNodeMetrics(ctx context.Context) ([]NodeMetrics, error) {
+	if k.metrics == nil {
+		return nil, fmt.Errorf("metrics client is not configured")
+	}
+
+	list, err := k.metrics.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node metrics: %w", err)
+	}
+	nodes, err := k.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	allocatable := make(map[string]ResourceList, len(nodes.Items))
+	for _, node := range nodes.Items {
+		allocatable[node.Name] = toResourceList(node.Status.Allocatable)
+	}
+
+	nodeMetrics := make([]NodeMetrics, 0, len(list.Items))
+	for _, item := range list.Items {
+		nodeMetrics = append(nodeMetrics, NodeMetrics{
+			Name:        item.Name,
+			Timestamp:   item.Timestamp.Time,
+			Window:      item.Window.Duration,
+			Usage:       toResourceList(item.Usage),
+			Allocatable: allocatable[item.Name],
+		})
+	}
+	return nodeMetrics, nil
+}
+
+func (k *K8sClient) listPodMetrics(ctx context.Context, namespace, labelSelector string) ([]metricsv1beta1.PodMetrics, error) {
+	if k.metrics == nil {
+		return nil, fmt.Errorf("metrics client is not configured")
+	}
+
+	list, err := k.metrics.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pod metrics: %w", err)
+	}
+	return list.Items, nil
+}
+
+func toPodMetrics(item metricsv1beta1.PodMetrics) PodMetrics {
+	podMetrics := PodMetrics{
+		Name:      item.Name,
+		Namespace: item.Namespace,
+		Timestamp: item.Timestamp.Time,
+		Window:    item.Window.Duration,
+	}
+	for _, container := range item.Containers {
+		usage := toResourceList(container.Usage)
+		podMetrics.Containers = append(podMetrics.Containers, ContainerMetrics{Name: container.Name, Usage: usage})
+		podMetrics.Usage = podMetrics.Usage.Add(usage)
+	}
+	return podMetrics
+}
+
+func toResourceList(resources corev1.ResourceList) ResourceList {
+	var list ResourceList
+	if cpu, ok := resources[corev1.ResourceCPU]; ok {
+		list.CPUMillis = cpu.MilliValue()
+	}
+	if memory, ok := resources[corev1.ResourceMemory]; ok {
+		list.MemoryBytes = memory.Value()
+	}
+	return list
+}
+
+// podResources returns the summed requests and limits of the containers of pod
+func podResources(pod corev1.Pod) (ResourceList, ResourceList) {
+	var requests, limits ResourceList
+	for _, container := range pod.Spec.Containers {
+		requests = requests.Add(toResourceList(container.Resources.Requests))
+		limits = limits.Add(toResourceList(container.Resources.Limits))
+	}
+	return requests, limits
+}
@@ -0,0 +1,189 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/infigaming-com/go-common/snowflake"
+)
+
+// maxSnowflakeNodeID is the highest node ID a snowflake lease can claim
+const maxSnowflakeNodeID = 1023
+
+// nodeLeaseBackend keeps snowflake node leases in coordination.k8s.io Lease
+// objects named "{prefix}{nodeID}", holding the holder identity, the renew
+// time and the lease duration.
+type nodeLeaseBackend struct {
+	client    kubernetes.Interface
+	namespace string
+	prefix    string
+	now       func() time.Time
+}
+
+// NodeLeaseBackend returns a snowflake.LeaseBackend keeping node leases in
+// Lease objects named "{prefix}{nodeID}" in namespace, for clusters where
+// Redis is not available at startup. Select it with snowflake.WithLeaseBackend.
+// The service account needs get, list, create, update and delete on leases.
+func (k *K8sClient) NodeLeaseBackend(namespace, prefix string) snowflake.LeaseBackend {
+	return &nodeLeaseBackend{client: k.client, namespace: namespace, prefix: prefix, now: time.Now}
+}
+
+func (b *nodeLeaseBackend) Claim(ctx context.Context, holder string, ttl time.Duration) (int64, error) {
+	list, err := b.client.CoordinationV1().Leases(b.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list leases in namespace %s: %w", b.namespace, err)
+	}
+
+	existing := make(map[int64]*coordinationv1.Lease)
+	for i := range list.Items {
+		lease := &list.Items[i]
+		if !strings.HasPrefix(lease.Name, b.prefix) {
+			continue
+		}
+		nodeID, err := strconv.ParseInt(strings.TrimPrefix(lease.Name, b.prefix), 10, 64)
+		if err != nil || nodeID < 0 || nodeID > maxSnowflakeNodeID {
+			continue
+		}
+		existing[nodeID] = lease
+	}
+
+	// Take the first free or expired node, moving on when another holder
+	// wins the race for it
+	for nodeID := int64(0); nodeID <= maxSnowflakeNodeID; nodeID++ {
+		lease, ok := existing[nodeID]
+		if !ok {
+			err = b.create(ctx, nodeID, holder, ttl)
+		} else if b.expired(lease) {
+			err = b.update(ctx, lease, holder, ttl)
+		} else {
+			continue
+		}
+
+		switch {
+		case err == nil:
+			return nodeID, nil
+		case apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err):
+			continue
+		default:
+			return 0, err
+		}
+	}
+	return 0, snowflake.ErrNoAvailableNode
+}
+
+func (b *nodeLeaseBackend) Renew(ctx context.Context, nodeID int64, holder string, ttl time.Duration) (snowflake.LeaseRenewal, error) {
+	lease, err := b.client.CoordinationV1().Leases(b.namespace).Get(ctx, b.leaseName(nodeID), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if err := b.create(ctx, nodeID, holder, ttl); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return 0, snowflake.ErrLeaseNotHeld
+			}
+			return 0, err
+		}
+		return snowflake.LeaseReclaimed, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get lease %s/%s: %w", b.namespace, b.leaseName(nodeID), err)
+	}
+
+	expired := b.expired(lease)
+	if holderOf(lease) != holder && !expired {
+		return 0, snowflake.ErrLeaseNotHeld
+	}
+	if err := b.update(ctx, lease, holder, ttl); err != nil {
+		if apierrors.IsConflict(err) {
+			return 0, snowflake.ErrLeaseNotHeld
+		}
+		return 0, err
+	}
+	if expired {
+		return snowflake.LeaseReclaimed, nil
+	}
+	return snowflake.LeaseRenewed, nil
+}
+
+func (b *nodeLeaseBackend) Release(ctx context.Context, nodeID int64, holder string) error {
+	lease, err := b.client.CoordinationV1().Leases(b.namespace).Get(ctx, b.leaseName(nodeID), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return snowflake.ErrLeaseNotHeld
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get lease %s/%s: %w", b.namespace, b.leaseName(nodeID), err)
+	}
+	if holderOf(lease) != holder {
+		return snowflake.ErrLeaseNotHeld
+	}
+
+	// Only delete the version we read, in case the lease changed hands since
+	err = b.client.CoordinationV1().Leases(b.namespace).Delete(ctx, lease.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion},
+	})
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return snowflake.ErrLeaseNotHeld
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete lease %s/%s: %w", b.namespace, lease.Name, err)
+	}
+	return nil
+}
+
+func (b *nodeLeaseBackend) create(ctx context.Context, nodeID int64, holder string, ttl time.Duration) error {
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: b.leaseName(nodeID), Namespace: b.namespace},
+	}
+	b.stamp(lease, holder, ttl)
+	_, err := b.client.CoordinationV1().Leases(b.namespace).Create(ctx, lease, metav1.CreateOptions{})
+	return err
+}
+
+// update takes over or renews lease; the API server rejects it with a
+// conflict if the lease changed since it was read
+func (b *nodeLeaseBackend) update(ctx context.Context, lease *coordinationv1.Lease, holder string, ttl time.Duration) error {
+	lease = lease.DeepCopy()
+	if holderOf(lease) != holder {
+		now := metav1.NewMicroTime(b.now())
+		lease.Spec.AcquireTime = &now
+	}
+	b.stamp(lease, holder, ttl)
+	_, err := b.client.CoordinationV1().Leases(b.namespace).Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+func (b *nodeLeaseBackend) stamp(lease *coordinationv1.Lease, holder string, ttl time.Duration) {
+	now := metav1.NewMicroTime(b.now())
+	seconds := int32(max(ttl/time.Second, 1))
+	lease.Spec.HolderIdentity = &holder
+	lease.Spec.LeaseDurationSeconds = &seconds
+	lease.Spec.RenewTime = &now
+	if lease.Spec.AcquireTime == nil {
+		lease.Spec.AcquireTime = &now
+	}
+}
+
+// expired reports whether the lease was not renewed within its duration
+func (b *nodeLeaseBackend) expired(lease *coordinationv1.Lease) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	duration := time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	return lease.Spec.RenewTime.Add(duration).Before(b.now())
+}
+
+func (b *nodeLeaseBackend) leaseName(nodeID int64) string {
+	return b.prefix + strconv.FormatInt(nodeID, 10)
+}
+
+func holderOf(lease *coordinationv1.Lease) string {
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
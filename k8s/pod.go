@@ -0,0 +1,151 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// PodLogOptions defines options for GetPodLogs
+type PodLogOptions struct {
+	// TailLines limits the output to the last lines of the log. Zero returns
+	// the whole log.
+	TailLines int64
+	// SinceTime only returns lines logged at or after this time
+	SinceTime time.Time
+	// Follow keeps streaming new lines until ctx is done or the container exits
+	Follow bool
+	// Previous returns the log of the previous terminated container instance
+	Previous bool
+}
+
+// PodLogOption defines a function that configures PodLogOptions
+type PodLogOption func(*PodLogOptions)
+
+// WithTailLines returns only the last n lines of the log
+func WithTailLines(n int64) PodLogOption {
+	return func(opts *PodLogOptions) {
+		opts.TailLines = n
+	}
+}
+
+// WithSinceTime returns only the lines logged at or after t
+func WithSinceTime(t time.Time) PodLogOption {
+	return func(opts *PodLogOptions) {
+		opts.SinceTime = t
+	}
+}
+
+// WithFollow streams new log lines as they are written
+func WithFollow() PodLogOption {
+	return func(opts *PodLogOptions) {
+		opts.Follow = true
+	}
+}
+
+// WithPrevious returns the log of the previous container instance
+func WithPrevious() PodLogOption {
+	return func(opts *PodLogOptions) {
+		opts.Previous = true
+	}
+}
+
+// GetPodLogs copies the log of a pod container to w. An empty container is
+// only valid for single container pods. With WithFollow it blocks until ctx is
+// done or the container exits.
+func (k *K8sClient) GetPodLogs(ctx context.Context, namespace, pod, container string, w io.Writer, options ...PodLogOption) error {
+	opts := &PodLogOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	logOptions := &corev1.PodLogOptions{
+		Container: container,
+		Follow:    opts.Follow,
+		Previous:  opts.Previous,
+	}
+	if opts.TailLines > 0 {
+		logOptions.TailLines = &opts.TailLines
+	}
+	if !opts.SinceTime.IsZero() {
+		sinceTime := metav1.NewTime(opts.SinceTime)
+		logOptions.SinceTime = &sinceTime
+	}
+
+	stream, err := k.client.CoreV1().Pods(namespace).GetLogs(pod, logOptions).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open logs of pod %s/%s: %w", namespace, pod, err)
+	}
+	defer stream.Close()
+
+	if _, err := io.Copy(w, stream); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read logs of pod %s/%s: %w", namespace, pod, err)
+	}
+	return nil
+}
+
+// ExecResult holds the output of a command run by ExecInPod
+type ExecResult struct {
+	Stdout string
+	Stderr string
+}
+
+// ExecInPod runs command in a pod container and returns its output. A command
+// exiting with a non-zero status is reported as an error, with the output
+// still returned.
+func (k *K8sClient) ExecInPod(ctx context.Context, namespace, pod, container string, command []string) (*ExecResult, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("command is required")
+	}
+	if k.config == nil {
+		return nil, fmt.Errorf("exec requires a client created by NewK8sClient")
+	}
+
+	req := k.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	// Prefer the WebSocket protocol and fall back to SPDY for older API
+	// servers, like kubectl does.
+	spdyExecutor, err := remotecommand.NewSPDYExecutor(k.config, "POST", req.URL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec executor: %w", err)
+	}
+	websocketExecutor, err := remotecommand.NewWebSocketExecutor(k.config, "GET", req.URL().String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec executor: %w", err)
+	}
+	executor, err := remotecommand.NewFallbackExecutor(websocketExecutor, spdyExecutor, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec executor: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	result := &ExecResult{Stdout: stdout.String(), Stderr: stderr.String()}
+	if err != nil {
+		return result, fmt.Errorf("failed to exec in pod %s/%s: %w", namespace, pod, err)
+	}
+	return result, nil
+}
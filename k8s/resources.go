@@ -0,0 +1,362 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ServiceInfo struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Type        string            `json:"type"`
+	ClusterIP   string            `json:"cluster_ip"`
+	ExternalIPs []string          `json:"external_ips,omitempty"` // External IPs and load balancer IPs or hostnames
+	Ports       []ServicePort     `json:"ports"`
+	Selector    map[string]string `json:"selector,omitempty"`
+	Labels      map[string]string `json:"labels"`
+}
+
+type ServicePort struct {
+	Name       string `json:"name,omitempty"`
+	Protocol   string `json:"protocol"`
+	Port       int32  `json:"port"`
+	TargetPort string `json:"target_port"`
+	NodePort   int32  `json:"node_port,omitempty"`
+}
+
+type IngressInfo struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	ClassName string            `json:"class_name,omitempty"`
+	Hosts     []string          `json:"hosts"`
+	TLSHosts  []string          `json:"tls_hosts,omitempty"`
+	Rules     []IngressRule     `json:"rules"`
+	Addresses []string          `json:"addresses,omitempty"` // Load balancer IPs or hostnames
+	Labels    map[string]string `json:"labels"`
+}
+
+type IngressRule struct {
+	Host        string `json:"host,omitempty"`
+	Path        string `json:"path,omitempty"`
+	PathType    string `json:"path_type,omitempty"`
+	ServiceName string `json:"service_name,omitempty"`
+	ServicePort string `json:"service_port,omitempty"` // Port number or name
+}
+
+type HPAInfo struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	TargetKind      string            `json:"target_kind"`
+	TargetName      string            `json:"target_name"`
+	MinReplicas     int32             `json:"min_replicas"`
+	MaxReplicas     int32             `json:"max_replicas"`
+	CurrentReplicas int32             `json:"current_replicas"`
+	DesiredReplicas int32             `json:"desired_replicas"`
+	Metrics         []HPAMetric       `json:"metrics"`
+	Labels          map[string]string `json:"labels"`
+}
+
+// HPAMetric is a scaling metric of an HPA. Resource metrics targeting an
+// average utilization set the utilization fields, other metrics set the
+// value fields.
+type HPAMetric struct {
+	Type               string `json:"type"`
+	Name               string `json:"name"` // Resource or metric name, e.g. "cpu"
+	TargetUtilization  *int32 `json:"target_utilization,omitempty"`
+	CurrentUtilization *int32 `json:"current_utilization,omitempty"`
+	TargetValue        string `json:"target_value,omitempty"`
+	CurrentValue       string `json:"current_value,omitempty"`
+}
+
+// GetServices returns the services matching the WithNamespaces and WithLabels
+// options
+func (k *K8sClient) GetServices(ctx context.Context, options ...GetDeploymentOption) ([]ServiceInfo, error) {
+	services, err := listInNamespaces(options, func(namespace, labelSelector string) ([]corev1.Service, error) {
+		list, err := k.client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		return list.Items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	serviceInfos := make([]ServiceInfo, 0, len(services))
+	for _, service := range services {
+		serviceInfos = append(serviceInfos, toServiceInfo(service))
+	}
+	return serviceInfos, nil
+}
+
+// GetIngresses returns the ingresses matching the WithNamespaces and
+// WithLabels options
+func (k *K8sClient) GetIngresses(ctx context.Context, options ...GetDeploymentOption) ([]IngressInfo, error) {
+	ingresses, err := listInNamespaces(options, func(namespace, labelSelector string) ([]networkingv1.Ingress, error) {
+		list, err := k.client.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ingresses: %w", err)
+		}
+		return list.Items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ingressInfos := make([]IngressInfo, 0, len(ingresses))
+	for _, ingress := range ingresses {
+		ingressInfos = append(ingressInfos, toIngressInfo(ingress))
+	}
+	return ingressInfos, nil
+}
+
+// GetHorizontalPodAutoscalers returns the autoscaling/v2 HPAs matching the
+// WithNamespaces and WithLabels options
+func (k *K8sClient) GetHorizontalPodAutoscalers(ctx context.Context, options ...GetDeploymentOption) ([]HPAInfo, error) {
+	hpas, err := listInNamespaces(options, func(namespace, labelSelector string) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+		list, err := k.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list horizontal pod autoscalers: %w", err)
+		}
+		return list.Items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hpaInfos := make([]HPAInfo, 0, len(hpas))
+	for _, hpa := range hpas {
+		hpaInfos = append(hpaInfos, toHPAInfo(hpa))
+	}
+	return hpaInfos, nil
+}
+
+// listInNamespaces lists objects like GetDeploymentAndPods: from all
+// namespaces when none are given, otherwise from each of them, skipping the
+// namespaces that fail
+func listInNamespaces[T any](options []GetDeploymentOption, list func(namespace, labelSelector string) ([]T, error)) ([]T, error) {
+	opts := &GetDeploymentOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+	labelSelector := buildLabelSelector(opts.Labels)
+
+	if len(opts.Namespaces) == 0 {
+		return list("", labelSelector)
+	}
+
+	var all []T
+	for _, namespace := range opts.Namespaces {
+		items, err := list(namespace, labelSelector)
+		if err != nil {
+			continue
+		}
+		all = append(all, items...)
+	}
+	return all, nil
+}
+
+func toServiceInfo(service corev1.Service) ServiceInfo {
+	info := ServiceInfo{
+		Name:        service.Name,
+		Namespace:   service.Namespace,
+		Type:        string(service.Spec.Type),
+		ClusterIP:   service.Spec.ClusterIP,
+		ExternalIPs: append([]string(nil), service.Spec.ExternalIPs...),
+		Selector:    service.Spec.Selector,
+		Labels:      service.Labels,
+	}
+	info.ExternalIPs = append(info.ExternalIPs, loadBalancerAddresses(service.Status.LoadBalancer.Ingress)...)
+
+	for _, port := range service.Spec.Ports {
+		info.Ports = append(info.Ports, ServicePort{
+			Name:       port.Name,
+			Protocol:   string(port.Protocol),
+			Port:       port.Port,
+			TargetPort: port.TargetPort.String(),
+			NodePort:   port.NodePort,
+		})
+	}
+	return info
+}
+
+func loadBalancerAddresses(ingresses []corev1.LoadBalancerIngress) []string {
+	var addresses []string
+	for _, ingress := range ingresses {
+		if ingress.IP != "" {
+			addresses = append(addresses, ingress.IP)
+		}
+		if ingress.Hostname != "" {
+			addresses = append(addresses, ingress.Hostname)
+		}
+	}
+	return addresses
+}
+
+func toIngressInfo(ingress networkingv1.Ingress) IngressInfo {
+	info := IngressInfo{
+		Name:      ingress.Name,
+		Namespace: ingress.Namespace,
+		Labels:    ingress.Labels,
+	}
+	if ingress.Spec.IngressClassName != nil {
+		info.ClassName = *ingress.Spec.IngressClassName
+	}
+
+	seen := make(map[string]bool)
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host != "" && !seen[rule.Host] {
+			seen[rule.Host] = true
+			info.Hosts = append(info.Hosts, rule.Host)
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			ingressRule := IngressRule{Host: rule.Host, Path: path.Path}
+			if path.PathType != nil {
+				ingressRule.PathType = string(*path.PathType)
+			}
+			if path.Backend.Service != nil {
+				ingressRule.ServiceName = path.Backend.Service.Name
+				ingressRule.ServicePort = serviceBackendPort(path.Backend.Service.Port)
+			}
+			info.Rules = append(info.Rules, ingressRule)
+		}
+	}
+	if backend := ingress.Spec.DefaultBackend; backend != nil && backend.Service != nil {
+		info.Rules = append(info.Rules, IngressRule{
+			ServiceName: backend.Service.Name,
+			ServicePort: serviceBackendPort(backend.Service.Port),
+		})
+	}
+
+	for _, tls := range ingress.Spec.TLS {
+		info.TLSHosts = append(info.TLSHosts, tls.Hosts...)
+	}
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			info.Addresses = append(info.Addresses, lb.IP)
+		}
+		if lb.Hostname != "" {
+			info.Addresses = append(info.Addresses, lb.Hostname)
+		}
+	}
+	return info
+}
+
+func serviceBackendPort(port networkingv1.ServiceBackendPort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return fmt.Sprintf("%d", port.Number)
+}
+
+func toHPAInfo(hpa autoscalingv2.HorizontalPodAutoscaler) HPAInfo {
+	info := HPAInfo{
+		Name:            hpa.Name,
+		Namespace:       hpa.Namespace,
+		TargetKind:      hpa.Spec.ScaleTargetRef.Kind,
+		TargetName:      hpa.Spec.ScaleTargetRef.Name,
+		MinReplicas:     1,
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+		Labels:          hpa.Labels,
+	}
+	if hpa.Spec.MinReplicas != nil {
+		info.MinReplicas = *hpa.Spec.MinReplicas
+	}
+
+	for i, spec := range hpa.Spec.Metrics {
+		var status *autoscalingv2.MetricStatus
+		if i < len(hpa.Status.CurrentMetrics) && hpa.Status.CurrentMetrics[i].Type == spec.Type {
+			status = &hpa.Status.CurrentMetrics[i]
+		}
+		info.Metrics = append(info.Metrics, toHPAMetric(spec, status))
+	}
+	return info
+}
+
+// toHPAMetric normalizes a metric spec and its current status, which may be
+// nil before the HPA first reads its metrics
+func toHPAMetric(spec autoscalingv2.MetricSpec, status *autoscalingv2.MetricStatus) HPAMetric {
+	metric := HPAMetric{Type: string(spec.Type)}
+
+	var (
+		target  autoscalingv2.MetricTarget
+		current *autoscalingv2.MetricValueStatus
+	)
+	switch spec.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		if spec.Resource == nil {
+			return metric
+		}
+		metric.Name = string(spec.Resource.Name)
+		target = spec.Resource.Target
+		if status != nil && status.Resource != nil {
+			current = &status.Resource.Current
+		}
+	case autoscalingv2.ContainerResourceMetricSourceType:
+		if spec.ContainerResource == nil {
+			return metric
+		}
+		metric.Name = string(spec.ContainerResource.Name)
+		target = spec.ContainerResource.Target
+		if status != nil && status.ContainerResource != nil {
+			current = &status.ContainerResource.Current
+		}
+	case autoscalingv2.PodsMetricSourceType:
+		if spec.Pods == nil {
+			return metric
+		}
+		metric.Name = spec.Pods.Metric.Name
+		target = spec.Pods.Target
+		if status != nil && status.Pods != nil {
+			current = &status.Pods.Current
+		}
+	case autoscalingv2.ObjectMetricSourceType:
+		if spec.Object == nil {
+			return metric
+		}
+		metric.Name = spec.Object.Metric.Name
+		target = spec.Object.Target
+		if status != nil && status.Object != nil {
+			current = &status.Object.Current
+		}
+	case autoscalingv2.ExternalMetricSourceType:
+		if spec.External == nil {
+			return metric
+		}
+		metric.Name = spec.External.Metric.Name
+		target = spec.External.Target
+		if status != nil && status.External != nil {
+			current = &status.External.Current
+		}
+	}
+
+	switch {
+	case target.AverageUtilization != nil:
+		metric.TargetUtilization = target.AverageUtilization
+	case target.AverageValue != nil:
+		metric.TargetValue = target.AverageValue.String()
+	case target.Value != nil:
+		metric.TargetValue = target.Value.String()
+	}
+	if current != nil {
+		switch {
+		case current.AverageUtilization != nil:
+			metric.CurrentUtilization = current.AverageUtilization
+		case current.AverageValue != nil:
+			metric.CurrentValue = current.AverageValue.String()
+		case current.Value != nil:
+			metric.CurrentValue = current.Value.String()
+		}
+	}
+	return metric
+}
@@ -0,0 +1,119 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// RestartedAtAnnotation is the pod template annotation patched by
+// RestartDeployment, the same one used by kubectl rollout restart.
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// ErrProgressDeadlineExceeded is returned by WaitForRollout when the
+// deployment reports that its rollout stopped progressing.
+var ErrProgressDeadlineExceeded = errors.New("deployment exceeded its progress deadline")
+
+// rolloutPollInterval is how often WaitForRollout checks the deployment status
+var rolloutPollInterval = 2 * time.Second
+
+// ScaleDeployment sets the desired number of replicas of a deployment
+func (k *K8sClient) ScaleDeployment(ctx context.Context, namespace, name string, replicas int32) error {
+	if replicas < 0 {
+		return fmt.Errorf("invalid replica count %d", replicas)
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{"replicas": replicas},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build scale patch: %w", err)
+	}
+
+	_, err = k.client.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to scale deployment %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// RestartDeployment triggers a rolling restart of a deployment by stamping its
+// pod template with the current time, like kubectl rollout restart.
+func (k *K8sClient) RestartDeployment(ctx context.Context, namespace, name string) error {
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"annotations": map[string]string{
+						RestartedAtAnnotation: time.Now().Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build restart patch: %w", err)
+	}
+
+	_, err = k.client.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to restart deployment %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// WaitForRollout polls a deployment until its latest rollout is complete: the
+// spec has been observed and all replicas are updated and available. It
+// returns ErrProgressDeadlineExceeded if the rollout stalls, and an error
+// wrapping context.DeadlineExceeded if it is not complete within timeout.
+func (k *K8sClient) WaitForRollout(ctx context.Context, namespace, name string, timeout time.Duration) error {
+	err := wait.PollUntilContextTimeout(ctx, rolloutPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		deployment, err := k.client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to get deployment %s/%s: %w", namespace, name, err)
+		}
+		return rolloutComplete(deployment)
+	})
+	if err != nil {
+		if wait.Interrupted(err) {
+			return fmt.Errorf("timed out waiting for rollout of %s/%s: %w", namespace, name, context.DeadlineExceeded)
+		}
+		return err
+	}
+	return nil
+}
+
+// rolloutComplete mirrors the checks of kubectl rollout status
+func rolloutComplete(deployment *appsv1.Deployment) (bool, error) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return false, nil
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing &&
+			condition.Status == corev1.ConditionFalse &&
+			condition.Reason == "ProgressDeadlineExceeded" {
+			return false, fmt.Errorf("%s/%s: %w", deployment.Namespace, deployment.Name, ErrProgressDeadlineExceeded)
+		}
+	}
+
+	status := deployment.Status
+	if deployment.Spec.Replicas != nil && status.UpdatedReplicas < *deployment.Spec.Replicas {
+		return false, nil
+	}
+	if status.Replicas > status.UpdatedReplicas {
+		return false, nil
+	}
+	if status.AvailableReplicas < status.UpdatedReplicas {
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,189 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// EventType is the kind of change reported by WatchDeployments
+type EventType string
+
+const (
+	EventAdded   EventType = "ADDED"
+	EventUpdated EventType = "UPDATED"
+	EventDeleted EventType = "DELETED"
+)
+
+// WatchEvent describes a change to a deployment or to one of its pods.
+//
+// For deployment events Pod is nil and Deployment.Pods holds the pods
+// currently selected by the deployment. For pod events Pod is the changed pod
+// and Deployment is the deployment owning it, without Pods.
+type WatchEvent struct {
+	Type       EventType
+	Deployment DeploymentInfo
+	Pod        *PodInfo
+}
+
+// WatchHandler receives the events of WatchDeployments. Calls are serialized.
+type WatchHandler func(event WatchEvent)
+
+// WatchDeployments streams add, update and delete events for the deployments
+// matching options and for their pods until ctx is done. It returns once the
+// initial state has been listed, after replaying it as EventAdded events.
+//
+// Watches are backed by informers: expired or broken watches are
+// re-established automatically, relisting when the resource version is too
+// old, so handler may see the same state more than once.
+func (k *K8sClient) WatchDeployments(ctx context.Context, handler WatchHandler, options ...GetDeploymentOption) error {
+	if handler == nil {
+		return fmt.Errorf("watch handler is required")
+	}
+
+	opts := &GetDeploymentOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	namespaces := opts.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	labelSelector := buildLabelSelector(opts.Labels)
+
+	mu := &sync.Mutex{}
+	var watchers []*namespaceWatcher
+	var synced []cache.InformerSynced
+	for _, namespace := range namespaces {
+		// Deployments and pods use separate factories since the label filter
+		// only applies to deployments.
+		deploymentFactory := informers.NewSharedInformerFactoryWithOptions(k.client, opts.ResyncPeriod,
+			informers.WithNamespace(namespace),
+			informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+				o.LabelSelector = labelSelector
+			}))
+		podFactory := informers.NewSharedInformerFactoryWithOptions(k.client, opts.ResyncPeriod,
+			informers.WithNamespace(namespace))
+
+		w := &namespaceWatcher{
+			mu:                 mu,
+			handler:            handler,
+			deploymentInformer: deploymentFactory.Apps().V1().Deployments().Informer(),
+			deployments:        deploymentFactory.Apps().V1().Deployments().Lister(),
+			podInformer:        podFactory.Core().V1().Pods().Informer(),
+			pods:               podFactory.Core().V1().Pods().Lister(),
+		}
+		watchers = append(watchers, w)
+		synced = append(synced, w.deploymentInformer.HasSynced, w.podInformer.HasSynced)
+
+		deploymentFactory.Start(ctx.Done())
+		podFactory.Start(ctx.Done())
+	}
+
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return fmt.Errorf("failed to sync deployment and pod caches: %w", context.Cause(ctx))
+	}
+
+	// Handlers are registered once both caches are complete so that every
+	// event, including the replay of existing objects, sees a full view.
+	for _, w := range watchers {
+		if err := w.register(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type namespaceWatcher struct {
+	mu      *sync.Mutex
+	handler WatchHandler
+
+	deploymentInformer cache.SharedIndexInformer
+	deployments        appslisters.DeploymentLister
+	podInformer        cache.SharedIndexInformer
+	pods               corelisters.PodLister
+}
+
+func (w *namespaceWatcher) register() error {
+	_, err := w.deploymentInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { w.onDeployment(EventAdded, obj) },
+		UpdateFunc: func(_, obj any) { w.onDeployment(EventUpdated, obj) },
+		DeleteFunc: func(obj any) { w.onDeployment(EventDeleted, obj) },
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add deployment event handler: %w", err)
+	}
+	_, err = w.podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { w.onPod(EventAdded, obj) },
+		UpdateFunc: func(_, obj any) { w.onPod(EventUpdated, obj) },
+		DeleteFunc: func(obj any) { w.onPod(EventDeleted, obj) },
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add pod event handler: %w", err)
+	}
+	return nil
+}
+
+func (w *namespaceWatcher) onDeployment(eventType EventType, obj any) {
+	deployment, ok := unwrapDeleted(obj).(*appsv1.Deployment)
+	if !ok {
+		return
+	}
+
+	var pods []PodInfo
+	if eventType != EventDeleted {
+		if selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector); err == nil {
+			matched, _ := w.pods.Pods(deployment.Namespace).List(selector)
+			for _, pod := range matched {
+				pods = append(pods, toPodInfo(*pod))
+			}
+		}
+	}
+
+	w.emit(WatchEvent{Type: eventType, Deployment: toDeploymentInfo(*deployment, pods)})
+}
+
+func (w *namespaceWatcher) onPod(eventType EventType, obj any) {
+	pod, ok := unwrapDeleted(obj).(*corev1.Pod)
+	if !ok {
+		return
+	}
+
+	deployments, err := w.deployments.Deployments(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		return
+	}
+	podInfo := toPodInfo(*pod)
+	for _, deployment := range deployments {
+		selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		w.emit(WatchEvent{Type: eventType, Deployment: toDeploymentInfo(*deployment, nil), Pod: &podInfo})
+	}
+}
+
+func (w *namespaceWatcher) emit(event WatchEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handler(event)
+}
+
+// unwrapDeleted returns the last known state of objects deleted while the
+// watch was disconnected.
+func unwrapDeleted(obj any) any {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
@@ -0,0 +1,141 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redsync/redsync/v4"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Suffixes appended to the lock key to name its fair queue: a sorted set of
+// the waiters by arrival, and one of their heartbeat deadlines so the waiters
+// of a crashed process are evicted instead of blocking the queue.
+const (
+	waitersKeySuffix    = ":waiters"
+	heartbeatsKeySuffix = ":waiters:heartbeats"
+)
+
+// WithFairQueue grants the lock in arrival order instead of to whichever
+// locker retries first, so that no locker starves under heavy contention.
+// Lockers queue in Redis and only the head of the queue competes for the
+// lock; the retries and retry delay bound the time spent in the queue.
+// Fairness only holds among lockers using this option, and TryLock fails
+// while lockers are queued. With Redlock the queue is kept on the first
+// instance, whose loss only loses the ordering, not the mutual exclusion.
+func WithFairQueue() LockOption {
+	return func(o *LockOptions) {
+		o.fairQueue = true
+	}
+}
+
+// evictExpiredWaiters is the prelude of the fair queue scripts, removing the
+// waiters whose heartbeat deadline passed
+const evictExpiredWaiters = `
+local queue = KEYS[1]
+local heartbeats = KEYS[2]
+local now = tonumber(ARGV[1])
+
+local expired = redis.call('ZRANGEBYSCORE', heartbeats, '-inf', now)
+for _, waiter in ipairs(expired) do
+	redis.call('ZREM', queue, waiter)
+end
+redis.call('ZREMRANGEBYSCORE', heartbeats, '-inf', now)
+`
+
+// enqueueWaiterScript appends ARGV[3] to the queue unless already queued,
+// extends its heartbeat deadline by ARGV[2] milliseconds and returns 1 if it
+// is at the head of the queue.
+var enqueueWaiterScript = redis.NewScript(evictExpiredWaiters + `
+local ttl = tonumber(ARGV[2])
+local waiter = ARGV[3]
+
+if not redis.call('ZSCORE', queue, waiter) then
+	local seq = 1
+	local last = redis.call('ZRANGE', queue, -1, -1, 'WITHSCORES')
+	if #last > 0 then
+		seq = tonumber(last[2]) + 1
+	end
+	redis.call('ZADD', queue, seq, waiter)
+end
+redis.call('ZADD', heartbeats, now + ttl, waiter)
+redis.call('PEXPIRE', queue, ttl)
+redis.call('PEXPIRE', heartbeats, ttl)
+
+local head = redis.call('ZRANGE', queue, 0, 0)
+if head[1] == waiter then
+	return 1
+end
+return 0
+`)
+
+// countWaitersScript returns the number of live waiters.
+var countWaitersScript = redis.NewScript(evictExpiredWaiters + `
+return redis.call('ZCARD', queue)
+`)
+
+// WaitersCount returns the number of lockers of key waiting in its fair
+// queue, see WithFairQueue.
+func (l *redisLock) WaitersCount(ctx context.Context, key string) (int64, error) {
+	if key == "" {
+		return 0, ErrInvalidLockKey
+	}
+	return countWaitersScript.Run(ctx, l.clients[0], waiterKeys(key), time.Now().UnixMilli()).Int64()
+}
+
+// lockFair queues for mutex, created with a single try, and acquires it once
+// at the head of the queue
+func (l *redisLock) lockFair(ctx context.Context, key string, mutex *redsync.Mutex, options *LockOptions) error {
+	client := l.clients[0]
+	keys := waiterKeys(key)
+	waiter := uuid.NewString()
+	defer func() {
+		ctx := context.WithoutCancel(ctx)
+		pipe := client.Pipeline()
+		pipe.ZRem(ctx, keys[0], waiter)
+		pipe.ZRem(ctx, keys[1], waiter)
+		_, _ = pipe.Exec(ctx)
+	}()
+
+	// Waiters heartbeat every retry delay, the deadline leaves room for a
+	// few missed ones
+	ttl := max(options.expiry, 3*options.retryDelay).Milliseconds()
+	var timer *time.Timer
+	for i := 0; ; i++ {
+		head, err := enqueueWaiterScript.Run(ctx, client, keys, time.Now().UnixMilli(), ttl, waiter).Bool()
+		if err != nil {
+			return err
+		}
+		if head {
+			err := mutex.LockContext(ctx)
+			if err == nil {
+				return nil
+			}
+			var errTaken *redsync.ErrTaken
+			if !errors.As(err, &errTaken) && !errors.Is(err, redsync.ErrFailed) {
+				return err
+			}
+		}
+		if i+1 >= options.retries {
+			return ErrLockNotAcquired
+		}
+
+		if timer == nil {
+			timer = time.NewTimer(options.retryDelay)
+			defer timer.Stop()
+		} else {
+			timer.Reset(options.retryDelay)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func waiterKeys(key string) []string {
+	return []string{key + waitersKeySuffix, key + heartbeatsKeySuffix}
+}
@@ -0,0 +1,130 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFairQueue(t *testing.T) {
+	_, clients := setupRedlockNodes(t, 1)
+	lock := NewRedisLock(clients[0])
+	ctx := context.Background()
+	key := "test-fair"
+	opts := []LockOption{WithFairQueue(), WithRetryDelay(5 * time.Millisecond), WithRetries(1000)}
+
+	unlock, err := lock.Lock(ctx, key, opts...)
+	require.NoError(t, err)
+
+	// Waiters arrive one after the other and must be granted in that order
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		order []int
+	)
+	for i := range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := lock.Lock(ctx, key, opts...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			assert.NoError(t, unlock(ctx))
+		}()
+		require.Eventually(t, func() bool {
+			waiters, err := lock.WaitersCount(ctx, key)
+			return err == nil && waiters == int64(i+1)
+		}, time.Second, time.Millisecond)
+	}
+
+	_, err = lock.TryLock(ctx, key, WithFairQueue())
+	assert.ErrorIs(t, err, ErrLockNotAcquired, "TryLock should not jump the queue")
+
+	require.NoError(t, unlock(ctx))
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+
+	waiters, err := lock.WaitersCount(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, waiters)
+}
+
+func TestFairQueue_ExpiredWaiter(t *testing.T) {
+	_, clients := setupRedlockNodes(t, 1)
+	lock := NewRedisLock(clients[0])
+	ctx := context.Background()
+	key := "test-fair-expired"
+
+	// A waiter of a crashed process, whose heartbeat deadline passed
+	require.NoError(t, clients[0].ZAdd(ctx, key+waitersKeySuffix, redis.Z{Score: 1, Member: "crashed"}).Err())
+	require.NoError(t, clients[0].ZAdd(ctx, key+heartbeatsKeySuffix, redis.Z{Score: 1, Member: "crashed"}).Err())
+
+	unlock, err := lock.Lock(ctx, key, WithFairQueue(), WithRetries(1))
+	require.NoError(t, err, "an expired waiter should not block the queue")
+	require.NoError(t, unlock(ctx))
+
+	waiters, err := lock.WaitersCount(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, waiters)
+}
+
+func TestFairQueue_Timeout(t *testing.T) {
+	_, clients := setupRedlockNodes(t, 1)
+	lock := NewRedisLock(clients[0])
+	ctx := context.Background()
+	key := "test-fair-timeout"
+
+	unlock, err := lock.Lock(ctx, key)
+	require.NoError(t, err)
+	defer unlock(ctx)
+
+	_, err = lock.Lock(ctx, key, WithFairQueue(), WithRetryDelay(time.Millisecond), WithRetries(3))
+	assert.ErrorIs(t, err, ErrLockNotAcquired)
+
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = lock.Lock(cancelled, key, WithFairQueue(), WithRetryDelay(time.Millisecond), WithRetries(1000))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Waiters leave the queue when they give up
+	waiters, err := lock.WaitersCount(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, waiters)
+}
+
+func TestHybridLock_WaitersCount(t *testing.T) {
+	_, clients := setupRedlockNodes(t, 1)
+	lock := NewHybridLock(NewRedisLock(clients[0]))
+	ctx := context.Background()
+	key := "test-hybrid-waiters"
+
+	unlock, err := lock.Lock(ctx, key)
+	require.NoError(t, err)
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = lock.Lock(waitCtx, key)
+	}()
+	require.Eventually(t, func() bool {
+		waiters, err := lock.WaitersCount(ctx, key)
+		return err == nil && waiters == 1
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+	require.NoError(t, unlock(ctx))
+	waiters, err := lock.WaitersCount(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, waiters)
+}
@@ -0,0 +1,74 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+)
+
+// fencingKeySuffix is appended to the lock key to name its fencing counter.
+// The counter never expires, so tokens keep increasing across lock expiries.
+const fencingKeySuffix = ":fencing"
+
+type fencingTokenKey struct{}
+
+// WithFencingToken stores a fencing token in token when the lock is acquired.
+// Tokens of a key increase with every acquisition, so a store receiving the
+// token along with each write can reject writes carrying a lower token than
+// one it has seen, i.e. from a holder whose lock expired mid-work.
+func WithFencingToken(token *int64) LockOption {
+	return func(o *LockOptions) {
+		o.fencingToken = token
+	}
+}
+
+// ContextWithFencingToken returns a copy of ctx carrying token, for passing
+// it down to the stores guarded by the lock
+func ContextWithFencingToken(ctx context.Context, token int64) context.Context {
+	return context.WithValue(ctx, fencingTokenKey{}, token)
+}
+
+// FencingTokenFromContext returns the fencing token set by
+// ContextWithFencingToken
+func FencingTokenFromContext(ctx context.Context) (int64, bool) {
+	token, ok := ctx.Value(fencingTokenKey{}).(int64)
+	return token, ok
+}
+
+// nextFencingToken increments the fencing counter of key on every instance.
+// With several instances the highest counter is used, which a majority must
+// have returned, so the token increases as long as a majority keeps its data.
+func (l *redisLock) nextFencingToken(ctx context.Context, key string) (int64, error) {
+	var (
+		token     int64
+		succeeded int
+		lastErr   error
+	)
+	for _, client := range l.clients {
+		n, err := client.Incr(ctx, key+fencingKeySuffix).Result()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		succeeded++
+		token = max(token, n)
+	}
+	if succeeded < len(l.clients)/2+1 {
+		return 0, fmt.Errorf("failed to increment fencing token: %w", lastErr)
+	}
+	return token, nil
+}
+
+// setFencingToken stores the next fencing token of key when requested,
+// releasing the lock if it cannot be obtained
+func (l *redisLock) setFencingToken(ctx context.Context, key string, options *LockOptions, unlock func(context.Context) error) error {
+	if options.fencingToken == nil {
+		return nil
+	}
+	token, err := l.nextFencingToken(ctx, key)
+	if err != nil {
+		_ = unlock(context.WithoutCancel(ctx))
+		return err
+	}
+	*options.fencingToken = token
+	return nil
+}
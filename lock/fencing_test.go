@@ -0,0 +1,73 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFencingToken(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	lock := NewRedisLock(client)
+	ctx := context.Background()
+	key := "test-fencing"
+
+	var first, second int64
+	unlock, err := lock.Lock(ctx, key, WithFencingToken(&first))
+	require.NoError(t, err)
+	require.NoError(t, unlock(ctx))
+
+	unlock, err = lock.TryLock(ctx, key, WithFencingToken(&second))
+	require.NoError(t, err)
+	assert.Greater(t, second, first)
+
+	// The counter outlives the lock, so a holder acquiring after an expiry
+	// still gets a higher token
+	server.FastForward(time.Minute)
+	assert.False(t, server.Exists(key))
+	var third int64
+	_, err = lock.TryLock(ctx, key, WithFencingToken(&third))
+	require.NoError(t, err)
+	assert.Greater(t, third, second)
+	assert.Error(t, unlock(ctx), "the expired holder no longer owns the lock")
+
+	tokenCtx := ContextWithFencingToken(ctx, third)
+	token, ok := FencingTokenFromContext(tokenCtx)
+	assert.True(t, ok)
+	assert.Equal(t, third, token)
+	_, ok = FencingTokenFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestFencingToken_Redlock(t *testing.T) {
+	servers, clients := setupRedlockNodes(t, 3)
+	lock, err := NewRedlock(clients...)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	key := "test-redlock-fencing"
+
+	// A lagging instance does not lower the token
+	_, err = clients[0].Set(ctx, key+fencingKeySuffix, 41, 0).Result()
+	require.NoError(t, err)
+
+	var token int64
+	unlock, err := lock.TryLock(ctx, key, WithFencingToken(&token))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), token)
+	require.NoError(t, unlock(ctx))
+
+	// Without a majority neither the lock nor a token is obtained
+	servers[1].Close()
+	servers[2].Close()
+	_, err = lock.TryLock(ctx, key, WithFencingToken(&token))
+	assert.Error(t, err)
+}
@@ -0,0 +1,181 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// hybridLock serializes lockers of a key within the process before they
+// reach the distributed lock, so that goroutines of the same instance queue
+// locally instead of polling Redis.
+type hybridLock struct {
+	local       *keyedMutex
+	distributed Lock
+}
+
+// NewHybridLock wraps a distributed Lock, e.g. from NewRedisLock or
+// NewRedlock, with a per-key in-process mutex. Only one goroutine per
+// instance and key competes for the distributed lock at a time, the others
+// wait on the local mutex without any Redis round trip. Options are passed to
+// the distributed lock; the retries and retry delay only apply to it.
+func NewHybridLock(distributed Lock) Lock {
+	return &hybridLock{local: newKeyedMutex(), distributed: distributed}
+}
+
+func (l *hybridLock) Lock(ctx context.Context, key string, opts ...LockOption) (func(context.Context) error, error) {
+	if key == "" {
+		return nil, ErrInvalidLockKey
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	release, err := l.local.lock(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := l.distributed.Lock(ctx, key, opts...)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return localUnlock(unlock, release), nil
+}
+
+func (l *hybridLock) TryLock(ctx context.Context, key string, opts ...LockOption) (func(context.Context) error, error) {
+	if key == "" {
+		return nil, ErrInvalidLockKey
+	}
+
+	release, ok := l.local.tryLock(key)
+	if !ok {
+		return nil, ErrLockNotAcquired
+	}
+	unlock, err := l.distributed.TryLock(ctx, key, opts...)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return localUnlock(unlock, release), nil
+}
+
+// WaitersCount returns the waiters of the distributed lock and the goroutines
+// of this instance waiting for the local mutex of key
+func (l *hybridLock) WaitersCount(ctx context.Context, key string) (int64, error) {
+	waiters, err := l.distributed.WaitersCount(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return waiters + l.local.waiters(key), nil
+}
+
+// localUnlock releases the distributed lock, then the local mutex, once
+func localUnlock(unlock func(context.Context) error, release func()) func(context.Context) error {
+	var once sync.Once
+	return func(ctx context.Context) error {
+		err := errors.New("failed to unlock")
+		once.Do(func() {
+			defer release()
+			err = unlock(ctx)
+		})
+		return err
+	}
+}
+
+// keyedMutex is a set of mutexes created on demand per key and removed once
+// no goroutine holds or waits for them.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	// sem holds a token while the key is locked, a channel so that waiting
+	// can be cancelled
+	sem  chan struct{}
+	refs int // holders and waiters, protected by keyedMutex.mu
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*keyedLock)}
+}
+
+// acquire returns the mutex of key, counting the caller as a reference
+func (m *keyedMutex) acquire(key string) *keyedLock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kl, ok := m.locks[key]
+	if !ok {
+		kl = &keyedLock{sem: make(chan struct{}, 1)}
+		m.locks[key] = kl
+	}
+	kl.refs++
+	return kl
+}
+
+// dereference drops a reference to the mutex of key, removing it once unused
+func (m *keyedMutex) dereference(key string, kl *keyedLock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kl.refs--
+	if kl.refs == 0 {
+		delete(m.locks, key)
+	}
+}
+
+// lock waits for the mutex of key until ctx is done and returns the function
+// releasing it
+func (m *keyedMutex) lock(ctx context.Context, key string) (func(), error) {
+	kl := m.acquire(key)
+	select {
+	case kl.sem <- struct{}{}:
+		return m.releaser(key, kl), nil
+	case <-ctx.Done():
+		m.dereference(key, kl)
+		return nil, ctx.Err()
+	}
+}
+
+// tryLock locks the mutex of key if it is free
+func (m *keyedMutex) tryLock(key string) (func(), bool) {
+	kl := m.acquire(key)
+	select {
+	case kl.sem <- struct{}{}:
+		return m.releaser(key, kl), true
+	default:
+		m.dereference(key, kl)
+		return nil, false
+	}
+}
+
+func (m *keyedMutex) releaser(key string, kl *keyedLock) func() {
+	return func() {
+		<-kl.sem
+		m.dereference(key, kl)
+	}
+}
+
+// size returns the number of keys held or waited for
+func (m *keyedMutex) size() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.locks)
+}
+
+// waiters returns the number of goroutines waiting for the mutex of key
+func (m *keyedMutex) waiters(key string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kl, ok := m.locks[key]
+	if !ok {
+		return 0
+	}
+	// One reference is the holder, or the goroutine about to hold the mutex
+	return int64(kl.refs - 1)
+}
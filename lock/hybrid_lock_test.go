@@ -0,0 +1,105 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contend has goroutines increment a counter under lock and returns the
+// number of Redis commands it took
+func contend(t *testing.T, lock Lock, server *miniredis.Miniredis, goroutines int) int {
+	ctx := context.Background()
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		holders int
+		counter int
+	)
+	before := server.CommandCount()
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := lock.Lock(ctx, "hybrid-key", WithRetryDelay(time.Millisecond), WithRetries(1000))
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			holders++
+			assert.Equal(t, 1, holders, "lock held by several goroutines")
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+			counter++
+
+			mu.Lock()
+			holders--
+			mu.Unlock()
+			assert.NoError(t, unlock(ctx))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, goroutines, counter)
+	return server.CommandCount() - before
+}
+
+func TestHybridLock_Contention(t *testing.T) {
+	servers, clients := setupRedlockNodes(t, 1)
+	lock := NewHybridLock(NewRedisLock(clients[0]))
+
+	hybridCommands := contend(t, lock, servers[0], 20)
+	redisCommands := contend(t, NewRedisLock(clients[0]), servers[0], 20)
+	t.Logf("redis commands: hybrid %d, redis %d", hybridCommands, redisCommands)
+	assert.Less(t, hybridCommands, redisCommands)
+	assert.Zero(t, lock.(*hybridLock).local.size(), "local mutexes should be removed once unused")
+}
+
+func TestHybridLock_TryLock(t *testing.T) {
+	_, clients := setupRedlockNodes(t, 1)
+	ctx := context.Background()
+	lock := NewHybridLock(NewRedisLock(clients[0]))
+
+	unlock, err := lock.TryLock(ctx, "hybrid-key")
+	require.NoError(t, err)
+
+	// held within the process
+	_, err = lock.TryLock(ctx, "hybrid-key")
+	assert.ErrorIs(t, err, ErrLockNotAcquired)
+
+	// held by another instance
+	other := NewHybridLock(NewRedisLock(clients[0]))
+	_, err = other.TryLock(ctx, "hybrid-key")
+	assert.ErrorIs(t, err, ErrLockNotAcquired)
+	assert.Zero(t, other.(*hybridLock).local.size())
+
+	require.NoError(t, unlock(ctx))
+	assert.Error(t, unlock(ctx), "second unlock should fail")
+
+	unlock, err = other.TryLock(ctx, "hybrid-key")
+	require.NoError(t, err)
+	require.NoError(t, unlock(ctx))
+
+	_, err = lock.TryLock(ctx, "")
+	assert.ErrorIs(t, err, ErrInvalidLockKey)
+}
+
+func TestHybridLock_ContextCancellation(t *testing.T) {
+	_, clients := setupRedlockNodes(t, 1)
+	lock := NewHybridLock(NewRedisLock(clients[0]))
+
+	unlock, err := lock.Lock(context.Background(), "hybrid-key")
+	require.NoError(t, err)
+	defer unlock(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = lock.Lock(ctx, "hybrid-key")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, lock.(*hybridLock).local.size(), "only the holder should reference the key")
+}
@@ -9,17 +9,27 @@ import (
 var (
 	ErrInvalidLockKey  = errors.New("invalid lock key")
 	ErrLockNotAcquired = errors.New("lock not acquired")
+	ErrLockLost        = errors.New("lock lost")
+	ErrNoRedisClients  = errors.New("no redis clients")
 )
 
 type Lock interface {
 	Lock(ctx context.Context, key string, opts ...LockOption) (func(context.Context) error, error)
 	TryLock(ctx context.Context, key string, opts ...LockOption) (func(context.Context) error, error)
+	// WaitersCount returns the number of lockers of key waiting in its fair
+	// queue, see WithFairQueue
+	WaitersCount(ctx context.Context, key string) (int64, error)
 }
 
 type LockOptions struct {
-	expiry     time.Duration
-	retryDelay time.Duration
-	retries    int
+	expiry        time.Duration
+	retryDelay    time.Duration
+	retries       int
+	autoRenew     bool
+	renewInterval time.Duration
+	onLost        func(key string, err error)
+	fencingToken  *int64
+	fairQueue     bool
 }
 
 type LockOption func(*LockOptions)
@@ -41,3 +51,23 @@ func WithRetries(retries int) LockOption {
 		o.retries = retries
 	}
 }
+
+// WithAutoRenew keeps extending the lock while it is held, so long running
+// work does not outlive the expiry. The lock is extended every interval
+// (expiry/3 if interval is not positive) until unlock is called or the
+// context passed to Lock is cancelled.
+func WithAutoRenew(interval time.Duration) LockOption {
+	return func(o *LockOptions) {
+		o.autoRenew = true
+		o.renewInterval = interval
+	}
+}
+
+// WithOnLost sets a callback invoked when auto renewal fails, meaning the
+// lock may have been acquired by someone else. err is ErrLockLost if the lock
+// was found to be no longer held. Renewal stops after the callback.
+func WithOnLost(onLost func(key string, err error)) LockOption {
+	return func(o *LockOptions) {
+		o.onLost = onLost
+	}
+}
@@ -3,6 +3,7 @@ package lock
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/go-redsync/redsync/v4"
@@ -11,7 +12,8 @@ import (
 )
 
 type redisLock struct {
-	rs *redsync.Redsync
+	rs      *redsync.Redsync
+	clients []*redis.Client
 }
 
 func defaultLockOptions() *LockOptions {
@@ -25,15 +27,17 @@ func defaultLockOptions() *LockOptions {
 func NewRedisLock(client *redis.Client) Lock {
 	pool := goredis.NewPool(client)
 	rs := redsync.New(pool)
-	return &redisLock{rs: rs}
+	return &redisLock{rs: rs, clients: []*redis.Client{client}}
 }
 
-func createUnlock(mutex *redsync.Mutex) func(context.Context) error {
+func createUnlock(mutex *redsync.Mutex, stopRenew func()) func(context.Context) error {
 	return func(ctx context.Context) error {
 		defer func() {
 			_ = recover()
 		}()
 
+		stopRenew()
+
 		ok, err := mutex.UnlockContext(ctx)
 		if err != nil {
 			return err
@@ -61,12 +65,22 @@ func (l *redisLock) Lock(ctx context.Context, key string, opts ...LockOption) (f
 		opt(options)
 	}
 
-	mutex := l.rs.NewMutex(key,
-		redsync.WithExpiry(options.expiry),
-		redsync.WithRetryDelay(options.retryDelay),
-		redsync.WithTries(options.retries),
-	)
-	err := mutex.LockContext(ctx)
+	var err error
+	var mutex *redsync.Mutex
+	if options.fairQueue {
+		mutex = l.rs.NewMutex(key,
+			redsync.WithExpiry(options.expiry),
+			redsync.WithTries(1),
+		)
+		err = l.lockFair(ctx, key, mutex, options)
+	} else {
+		mutex = l.rs.NewMutex(key,
+			redsync.WithExpiry(options.expiry),
+			redsync.WithRetryDelay(options.retryDelay),
+			redsync.WithTries(options.retries),
+		)
+		err = mutex.LockContext(ctx)
+	}
 	if err != nil {
 		var errTaken *redsync.ErrTaken
 		if errors.As(err, &errTaken) {
@@ -75,7 +89,11 @@ func (l *redisLock) Lock(ctx context.Context, key string, opts ...LockOption) (f
 		return nil, err
 	}
 
-	return createUnlock(mutex), nil
+	unlock := createUnlock(mutex, startRenew(ctx, mutex, options))
+	if err := l.setFencingToken(ctx, key, options, unlock); err != nil {
+		return nil, err
+	}
+	return unlock, nil
 }
 
 func (l *redisLock) TryLock(ctx context.Context, key string, opts ...LockOption) (func(context.Context) error, error) {
@@ -94,6 +112,17 @@ func (l *redisLock) TryLock(ctx context.Context, key string, opts ...LockOption)
 		opt(options)
 	}
 
+	// Queued lockers go first
+	if options.fairQueue {
+		waiters, err := l.WaitersCount(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if waiters > 0 {
+			return nil, ErrLockNotAcquired
+		}
+	}
+
 	mutex := l.rs.NewMutex(key,
 		redsync.WithExpiry(options.expiry),
 		redsync.WithTries(1),
@@ -108,5 +137,65 @@ func (l *redisLock) TryLock(ctx context.Context, key string, opts ...LockOption)
 		return nil, err
 	}
 
-	return createUnlock(mutex), nil
+	unlock := createUnlock(mutex, startRenew(ctx, mutex, options))
+	if err := l.setFencingToken(ctx, key, options, unlock); err != nil {
+		return nil, err
+	}
+	return unlock, nil
+}
+
+// startRenew starts the watchdog extending mutex when auto renewal is enabled.
+// The returned function stops it and waits for an in-flight extension, so
+// the lock is never extended after it has been released.
+func startRenew(ctx context.Context, mutex *redsync.Mutex, options *LockOptions) func() {
+	if !options.autoRenew {
+		return func() {}
+	}
+
+	interval := options.renewInterval
+	if interval <= 0 {
+		interval = options.expiry / 3
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			ok, err := mutex.ExtendContext(ctx)
+			if ok && err == nil {
+				continue
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				err = ErrLockLost
+			}
+			if options.onLost != nil {
+				options.onLost(mutex.Name(), err)
+			}
+			return
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+		})
+	}
 }
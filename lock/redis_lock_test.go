@@ -161,3 +161,57 @@ func TestRedisLock_ContextCancellation(t *testing.T) {
 	_, err = lock.TryLock(ctx, key)
 	assert.ErrorIs(t, err, context.Canceled)
 }
+
+func TestRedisLock_AutoRenew(t *testing.T) {
+	client := setupTestRedis(t)
+	lock := NewRedisLock(client)
+
+	ctx := context.Background()
+	key := "test-auto-renew"
+
+	unlock, err := lock.Lock(ctx, key, WithExpiry(300*time.Millisecond), WithAutoRenew(100*time.Millisecond))
+	assert.NoError(t, err)
+
+	// Lock should still be held well past its expiry
+	time.Sleep(700 * time.Millisecond)
+	_, err = lock.TryLock(ctx, key)
+	assert.ErrorIs(t, err, ErrLockNotAcquired)
+
+	err = unlock(ctx)
+	assert.NoError(t, err)
+
+	// Renewal stops on unlock
+	unlock, err = lock.TryLock(ctx, key)
+	assert.NoError(t, err)
+	err = unlock(ctx)
+	assert.NoError(t, err)
+}
+
+func TestRedisLock_AutoRenewOnLost(t *testing.T) {
+	client := setupTestRedis(t)
+	lock := NewRedisLock(client)
+
+	ctx := context.Background()
+	key := "test-auto-renew-lost"
+
+	lost := make(chan error, 1)
+	_, err := lock.Lock(ctx, key,
+		WithExpiry(time.Second),
+		WithAutoRenew(50*time.Millisecond),
+		WithOnLost(func(lostKey string, err error) {
+			assert.Equal(t, key, lostKey)
+			lost <- err
+		}),
+	)
+	assert.NoError(t, err)
+
+	// Simulate the lock being taken over by someone else
+	client.Set(ctx, key, "other-owner", time.Second)
+
+	select {
+	case err := <-lost:
+		assert.Error(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnLost was not called")
+	}
+}
@@ -0,0 +1,25 @@
+package lock
+
+import (
+	"github.com/go-redsync/redsync/v4"
+	redsyncredis "github.com/go-redsync/redsync/v4/redis"
+	"github.com/go-redsync/redsync/v4/redis/goredis/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedlock creates a Lock following the Redlock algorithm across independent
+// Redis instances. A lock is acquired only when a majority (N/2+1) of the
+// instances granted it within its validity time, which is the expiry minus the
+// acquisition time and a clock drift allowance. Instances must not be replicas
+// of each other.
+func NewRedlock(clients ...*redis.Client) (Lock, error) {
+	if len(clients) == 0 {
+		return nil, ErrNoRedisClients
+	}
+
+	pools := make([]redsyncredis.Pool, 0, len(clients))
+	for _, client := range clients {
+		pools = append(pools, goredis.NewPool(client))
+	}
+	return &redisLock{rs: redsync.New(pools...), clients: clients}, nil
+}
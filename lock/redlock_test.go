@@ -0,0 +1,52 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRedlockNodes(t *testing.T, n int) ([]*miniredis.Miniredis, []*redis.Client) {
+	servers := make([]*miniredis.Miniredis, n)
+	clients := make([]*redis.Client, n)
+	for i := range servers {
+		servers[i] = miniredis.RunT(t)
+		clients[i] = redis.NewClient(&redis.Options{Addr: servers[i].Addr()})
+		t.Cleanup(func() { _ = clients[i].Close() })
+	}
+	return servers, clients
+}
+
+func TestRedlock_Quorum(t *testing.T) {
+	servers, clients := setupRedlockNodes(t, 3)
+	lock, err := NewRedlock(clients...)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	key := "test-redlock"
+
+	// A single node holding the key does not prevent a majority
+	require.NoError(t, servers[0].Set(key, "other-owner"))
+	unlock, err := lock.TryLock(ctx, key, WithExpiry(time.Second))
+	require.NoError(t, err)
+	assert.True(t, servers[1].Exists(key))
+	assert.True(t, servers[2].Exists(key))
+	assert.NoError(t, unlock(ctx))
+	assert.False(t, servers[1].Exists(key))
+
+	// Two of three nodes holding the key do
+	require.NoError(t, servers[1].Set(key, "other-owner"))
+	_, err = lock.TryLock(ctx, key, WithExpiry(time.Second))
+	assert.ErrorIs(t, err, ErrLockNotAcquired)
+	assert.False(t, servers[2].Exists(key), "partially acquired lock must be released")
+}
+
+func TestRedlock_NoClients(t *testing.T) {
+	_, err := NewRedlock()
+	assert.ErrorIs(t, err, ErrNoRedisClients)
+}
@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/infigaming-com/go-common/request"
+)
+
+// RequestRecorder returns a recorder for request.WithRequestRecorder reporting
+// the requests that failed, then passing every record to next when not nil.
+func RequestRecorder(reporter Reporter, next request.RequestRecorder) request.RequestRecorder {
+	return func(record *request.RequestRecordData) {
+		if record.Error != "" {
+			reporter.Report(context.Background(), Event{
+				Err:    stderrors.New(record.Error),
+				Source: "request",
+				Tags: map[string]string{
+					"http.method":      record.Method,
+					"http.url":         record.Url,
+					"http.status_code": strconv.Itoa(record.HttpStatusCode),
+				},
+			})
+		}
+		if next != nil {
+			next(record)
+		}
+	}
+}
+
+// PubSubHooks returns hooks reporting failed message handling and publishing
+// on top of hooks, whose callbacks are still called.
+func PubSubHooks(reporter Reporter, hooks pubsub.Hooks) pubsub.Hooks {
+	onFailure := hooks.OnFailure
+	hooks.OnFailure = func(ctx context.Context, topic string, meta pubsub.MessageMetadata, err error) {
+		reporter.Report(ctx, Event{
+			Err:    err,
+			Source: "pubsub",
+			Tags: map[string]string{
+				"pubsub.topic":      topic,
+				"pubsub.message_id": meta.ID,
+				"pubsub.attempt":    strconv.Itoa(meta.Attempt),
+			},
+		})
+		if onFailure != nil {
+			onFailure(ctx, topic, meta, err)
+		}
+	}
+	onPublishFail := hooks.OnPublishFail
+	hooks.OnPublishFail = func(ctx context.Context, topic string, meta map[string]string, err error) {
+		reporter.Report(ctx, Event{
+			Err:    err,
+			Source: "pubsub",
+			Tags:   map[string]string{"pubsub.topic": topic, "pubsub.operation": "publish"},
+		})
+		if onPublishFail != nil {
+			onPublishFail(ctx, topic, meta, err)
+		}
+	}
+	return hooks
+}
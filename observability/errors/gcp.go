@@ -0,0 +1,143 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/api/clouderrorreporting/v1beta1"
+	"google.golang.org/api/option"
+
+	"github.com/infigaming-com/go-common/observability/logging"
+)
+
+const (
+	// gcpMaxPendingReports bounds the reports being sent at once, the ones
+	// beyond are dropped so an error storm cannot pile up goroutines
+	gcpMaxPendingReports = 64
+	gcpReportTimeout     = 10 * time.Second
+)
+
+// ServiceContext identifies the reporting service in Error Reporting, errors
+// being grouped per service and version.
+type ServiceContext struct {
+	Service string
+	Version string
+}
+
+// GCPReporter reports events to Google Cloud Error Reporting, each in its own
+// API call sent in the background.
+type GCPReporter struct {
+	events         *clouderrorreporting.ProjectsEventsService
+	projectName    string
+	serviceContext *clouderrorreporting.ServiceContext
+	pending        chan struct{}
+	wg             sync.WaitGroup
+	dropped        atomic.Uint64
+}
+
+// NewGCPReporter creates a reporter for the project projectID, authenticated
+// with the application default credentials unless opts say otherwise.
+func NewGCPReporter(ctx context.Context, projectID string, serviceContext ServiceContext, opts ...option.ClientOption) (*GCPReporter, error) {
+	if projectID == "" || serviceContext.Service == "" {
+		return nil, fmt.Errorf("project id and service are required")
+	}
+	service, err := clouderrorreporting.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create error reporting service: %w", err)
+	}
+	return &GCPReporter{
+		events:      service.Projects.Events,
+		projectName: "projects/" + projectID,
+		serviceContext: &clouderrorreporting.ServiceContext{
+			Service: serviceContext.Service,
+			Version: serviceContext.Version,
+		},
+		pending: make(chan struct{}, gcpMaxPendingReports),
+	}, nil
+}
+
+// Report sends event in the background. Error Reporting groups errors by
+// stack trace, the stack of the caller is used when event.Stack is empty.
+// Events are dropped while too many reports are being sent.
+func (r *GCPReporter) Report(ctx context.Context, event Event) {
+	stack := event.Stack
+	if len(stack) == 0 {
+		stack = debug.Stack()
+	}
+	reported := &clouderrorreporting.ReportedErrorEvent{
+		EventTime:      time.Now().UTC().Format(time.RFC3339Nano),
+		Message:        gcpMessage(event) + "\n\n" + string(stack),
+		ServiceContext: r.serviceContext,
+	}
+	if event.User != "" {
+		reported.Context = &clouderrorreporting.ErrorContext{User: event.User}
+	}
+
+	select {
+	case r.pending <- struct{}{}:
+	default:
+		r.dropped.Add(1)
+		logging.FromContext(ctx).Warn("[ERROR-REPORTING: too many pending reports, event dropped]", zap.Error(event.Err))
+		return
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() { <-r.pending }()
+
+		reportCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), gcpReportTimeout)
+		defer cancel()
+		if _, err := r.events.Report(r.projectName, reported).Context(reportCtx).Do(); err != nil {
+			logging.FromContext(ctx).Warn("[ERROR-REPORTING: failed to report error]", zap.Error(err), zap.NamedError("reported", event.Err))
+		}
+	}()
+}
+
+// Dropped returns the number of events dropped because too many reports were
+// being sent.
+func (r *GCPReporter) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// Flush waits until the reported events are sent or ctx is done.
+func (r *GCPReporter) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to flush error reports: %w", ctx.Err())
+	}
+}
+
+// gcpMessage returns the error message followed by the source and tags, which
+// Error Reporting has no fields for, e.g. "timeout [source=pubsub topic=orders]"
+func gcpMessage(event Event) string {
+	parts := make([]string, 0, len(event.Tags)+1)
+	if event.Source != "" {
+		parts = append(parts, "source="+event.Source)
+	}
+	keys := make([]string, 0, len(event.Tags))
+	for key := range event.Tags {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		parts = append(parts, key+"="+event.Tags[key])
+	}
+	if len(parts) == 0 {
+		return event.Err.Error()
+	}
+	return event.Err.Error() + " [" + strings.Join(parts, " ") + "]"
+}
@@ -0,0 +1,94 @@
+// Package errors sends failures to an error reporting service such as Sentry
+// or Google Cloud Error Reporting, so panics and failed requests or messages
+// of every component land in one place.
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrPanic is wrapped by the errors reported for recovered panics.
+var ErrPanic = stderrors.New("panic")
+
+// Event is a failure to report.
+type Event struct {
+	Err    error
+	Source string            // reporting component, e.g. "request" or "pubsub"
+	Tags   map[string]string // searchable context, e.g. the topic or URL
+	User   string            // ID of the affected user, if any
+	Stack  []byte            // as returned by debug.Stack(), captured by reporters needing it when empty
+}
+
+// Reporter sends events to an error reporting service. Report must not block
+// on the network; Flush waits for the events reported so far to be sent, e.g.
+// before the process exits.
+type Reporter interface {
+	Report(ctx context.Context, event Event)
+	Flush(ctx context.Context) error
+}
+
+// Multi returns a Reporter sending events to all reporters.
+func Multi(reporters ...Reporter) Reporter {
+	return multiReporter(reporters)
+}
+
+type multiReporter []Reporter
+
+func (m multiReporter) Report(ctx context.Context, event Event) {
+	for _, reporter := range m {
+		reporter.Report(ctx, event)
+	}
+}
+
+func (m multiReporter) Flush(ctx context.Context) error {
+	var errs []error
+	for _, reporter := range m {
+		if err := reporter.Flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// Recover reports a panic of the calling goroutine without re-panicking. It
+// must be deferred directly:
+//
+//	defer errors.Recover(ctx, reporter, "worker")
+func Recover(ctx context.Context, reporter Reporter, source string) {
+	if r := recover(); r != nil {
+		reportPanic(ctx, reporter, source, r)
+	}
+}
+
+// Go runs fn in a new goroutine, reporting its panic instead of crashing the
+// process.
+func Go(ctx context.Context, reporter Reporter, source string, fn func(ctx context.Context)) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				reportPanic(ctx, reporter, source, r)
+			}
+		}()
+		fn(ctx)
+	}()
+}
+
+// PanicError returns an error wrapping ErrPanic for the recovered value r,
+// wrapping r too when it is an error.
+func PanicError(r any) error {
+	if err, ok := r.(error); ok {
+		return fmt.Errorf("%w: %w", ErrPanic, err)
+	}
+	return fmt.Errorf("%w: %v", ErrPanic, r)
+}
+
+func reportPanic(ctx context.Context, reporter Reporter, source string, r any) {
+	reporter.Report(ctx, Event{
+		Err:    PanicError(r),
+		Source: source,
+		Stack:  debug.Stack(),
+	})
+}
@@ -0,0 +1,163 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/infigaming-com/go-common/request"
+)
+
+type recordingReporter struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recordingReporter) Report(_ context.Context, event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingReporter) Flush(context.Context) error { return nil }
+
+func (r *recordingReporter) reported() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+func TestRecoverAndGo(t *testing.T) {
+	reporter := &recordingReporter{}
+
+	func() {
+		defer Recover(context.Background(), reporter, "worker")
+		panic("boom")
+	}()
+
+	done := make(chan struct{})
+	Go(context.Background(), reporter, "job", func(context.Context) {
+		defer close(done)
+		panic(stderrors.New("failed"))
+	})
+	<-done
+	require.Eventually(t, func() bool { return len(reporter.reported()) == 2 }, time.Second, time.Millisecond)
+
+	events := reporter.reported()
+	assert.Equal(t, "worker", events[0].Source)
+	assert.ErrorIs(t, events[0].Err, ErrPanic)
+	assert.EqualError(t, events[0].Err, "panic: boom")
+	assert.Contains(t, string(events[0].Stack), "goroutine")
+	assert.Equal(t, "job", events[1].Source)
+	assert.EqualError(t, events[1].Err, "panic: failed")
+}
+
+func TestAdapters(t *testing.T) {
+	reporter := &recordingReporter{}
+
+	var recorded int
+	recorder := RequestRecorder(reporter, func(*request.RequestRecordData) { recorded++ })
+	recorder(&request.RequestRecordData{Method: http.MethodGet, Url: "http://example.com"})
+	recorder(&request.RequestRecordData{Method: http.MethodGet, Url: "http://example.com", HttpStatusCode: 502, Error: "bad gateway"})
+	assert.Equal(t, 2, recorded)
+
+	var failures int
+	hooks := PubSubHooks(reporter, pubsub.Hooks{
+		OnFailure: func(context.Context, string, pubsub.MessageMetadata, error) { failures++ },
+	})
+	hooks.OnFailure(context.Background(), "orders", pubsub.MessageMetadata{ID: "1", Attempt: 2}, stderrors.New("handler failed"))
+	hooks.OnPublishFail(context.Background(), "orders", nil, stderrors.New("publish failed"))
+	assert.Equal(t, 1, failures)
+
+	events := reporter.reported()
+	require.Len(t, events, 3)
+	assert.Equal(t, "request", events[0].Source)
+	assert.Equal(t, "502", events[0].Tags["http.status_code"])
+	assert.Equal(t, "pubsub", events[1].Source)
+	assert.Equal(t, "orders", events[1].Tags["pubsub.topic"])
+	assert.Equal(t, "2", events[1].Tags["pubsub.attempt"])
+	assert.EqualError(t, events[2].Err, "publish failed")
+}
+
+func TestGCPReporter(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		path     string
+		reported map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&reported)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	reporter, err := NewGCPReporter(context.Background(), "my-project", ServiceContext{Service: "wallet", Version: "1.2.0"},
+		option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	require.NoError(t, err)
+
+	reporter.Report(context.Background(), Event{
+		Err:    stderrors.New("boom"),
+		Source: "worker",
+		Tags:   map[string]string{"topic": "orders"},
+		User:   "42",
+	})
+	require.NoError(t, reporter.Flush(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "/v1beta1/projects/my-project/events:report", path)
+	assert.Contains(t, reported["message"], "boom [source=worker topic=orders]\n\ngoroutine ")
+	assert.Equal(t, map[string]any{"service": "wallet", "version": "1.2.0"}, reported["serviceContext"])
+	assert.Equal(t, map[string]any{"user": "42"}, reported["context"])
+
+	_, err = NewGCPReporter(context.Background(), "", ServiceContext{Service: "wallet"})
+	assert.Error(t, err)
+}
+
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *recordingTransport) Configure(sentry.ClientOptions)        {}
+func (t *recordingTransport) Flush(time.Duration) bool              { return true }
+func (t *recordingTransport) FlushWithContext(context.Context) bool { return true }
+func (t *recordingTransport) Close()                                {}
+func (t *recordingTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func TestSentryReporter(t *testing.T) {
+	transport := &recordingTransport{}
+	reporter, err := NewSentryReporter(sentry.ClientOptions{Dsn: "https://key@sentry.example.com/1", Transport: transport})
+	require.NoError(t, err)
+
+	reporter.Report(context.Background(), Event{Err: stderrors.New("failed"), Source: "request", Tags: map[string]string{"http.method": "GET"}})
+	reporter.Report(context.Background(), Event{Err: PanicError("boom"), Source: "worker", User: "42"})
+	require.NoError(t, reporter.Flush(context.Background()))
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	require.Len(t, transport.events, 2)
+	assert.Equal(t, map[string]string{"source": "request", "http.method": "GET"}, transport.events[0].Tags)
+	assert.Equal(t, sentry.LevelError, transport.events[0].Level)
+	assert.Equal(t, map[string]string{"source": "worker"}, transport.events[1].Tags, "scopes must not leak between reports")
+	assert.Equal(t, sentry.LevelFatal, transport.events[1].Level)
+	assert.Equal(t, "42", transport.events[1].User.ID)
+}
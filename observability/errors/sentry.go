@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// SentryReporter reports events to Sentry. Sentry sends events in the
+// background.
+type SentryReporter struct {
+	hub *sentry.Hub
+}
+
+// NewSentryReporter creates a reporter with its own Sentry client, leaving the
+// global hub of the sentry package untouched.
+func NewSentryReporter(options sentry.ClientOptions) (*SentryReporter, error) {
+	client, err := sentry.NewClient(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sentry client: %w", err)
+	}
+	return &SentryReporter{hub: sentry.NewHub(client, sentry.NewScope())}, nil
+}
+
+// Report captures event.Err with the source and tags as Sentry tags. Panics
+// are reported at the fatal level.
+func (r *SentryReporter) Report(ctx context.Context, event Event) {
+	// a hub is not safe for concurrent scope changes, each report gets a copy
+	hub := r.hub.Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTags(event.Tags)
+		if event.Source != "" {
+			scope.SetTag("source", event.Source)
+		}
+		if event.User != "" {
+			scope.SetUser(sentry.User{ID: event.User})
+		}
+		if stderrors.Is(event.Err, ErrPanic) {
+			scope.SetLevel(sentry.LevelFatal)
+		}
+	})
+	hub.CaptureException(event.Err)
+}
+
+// Flush waits until the reported events are sent or ctx is done.
+func (r *SentryReporter) Flush(ctx context.Context) error {
+	if !r.hub.FlushWithContext(ctx) {
+		return fmt.Errorf("failed to flush sentry events: %w", ctx.Err())
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/redis/go-redis/v9"
+)
+
+// Pinger is implemented by clients with a connectivity check, such as
+// k8s.K8sClient.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingCheck reports p as down when Ping fails.
+func PingCheck(p Pinger) CheckFunc {
+	return p.Ping
+}
+
+// RedisCheck reports client as down when it does not answer a PING, e.g. the
+// client backing a lock or cache.
+func RedisCheck(client redis.UniversalClient) CheckFunc {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// SubscriptionCheck reports sub as down while its circuit breaker is open, or
+// when it has received no message for longer than idle. Idle is ignored when 0,
+// and for subscriptions that have not received a message yet or are paused.
+func SubscriptionCheck(sub pubsub.Subscription, idle time.Duration) CheckFunc {
+	return func(ctx context.Context) error {
+		h := sub.Health()
+		if h.CircuitOpen {
+			return fmt.Errorf("subscription to %s has its circuit open after %d failures: %s", h.Topic, h.Failures, h.LastError)
+		}
+		if idle > 0 && !h.Paused && !h.LastActivity.IsZero() && time.Since(h.LastActivity) > idle {
+			return fmt.Errorf("subscription to %s idle since %s", h.Topic, h.LastActivity.Format(time.RFC3339))
+		}
+		return nil
+	}
+}
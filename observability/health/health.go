@@ -0,0 +1,270 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status of a check or of a whole report.
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Kind selects the probes a check belongs to. Liveness checks should only fail
+// when restarting the process helps, e.g. a deadlocked worker; dependencies
+// such as Redis belong to readiness.
+type Kind int
+
+const (
+	Liveness Kind = 1 << iota
+	Readiness
+)
+
+// CheckFunc reports a component as down by returning an error. It must return
+// once ctx is done.
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Status    Status    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  int64     `json:"duration_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the outcome of all the checks of a kind, as served by the handlers.
+// Status is down when any check is down.
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Registry holds the health checks of a service. It is safe for concurrent use.
+type Registry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu     sync.RWMutex
+	checks map[string]*check
+}
+
+type check struct {
+	name     string
+	kind     Kind
+	fn       CheckFunc
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu     sync.Mutex
+	result *CheckResult
+}
+
+// Option configures a Registry.
+type Option func(*Registry)
+
+// WithTimeout sets the default time a check may take before it is reported as
+// down. Defaults to 5s.
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *Registry) {
+		r.timeout = timeout
+	}
+}
+
+// WithCacheTTL sets the default time a check result is reused for, so frequent
+// probes do not hammer the dependencies. Defaults to 1s, 0 disables caching.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(r *Registry) {
+		r.cacheTTL = ttl
+	}
+}
+
+// CheckOption configures a single check.
+type CheckOption func(*check)
+
+// WithCheckTimeout overrides the registry timeout for a check.
+func WithCheckTimeout(timeout time.Duration) CheckOption {
+	return func(c *check) {
+		c.timeout = timeout
+	}
+}
+
+// WithCheckCacheTTL overrides the registry cache TTL for a check.
+func WithCheckCacheTTL(ttl time.Duration) CheckOption {
+	return func(c *check) {
+		c.cacheTTL = ttl
+	}
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry(opts ...Option) *Registry {
+	r := &Registry{
+		timeout:  5 * time.Second,
+		cacheTTL: time.Second,
+		now:      time.Now,
+		checks:   make(map[string]*check),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register adds a check under name for the probes in kind, e.g.
+// Liveness|Readiness. A check registered under the same name is replaced.
+func (r *Registry) Register(name string, kind Kind, fn CheckFunc, opts ...CheckOption) {
+	c := &check{
+		name:     name,
+		kind:     kind,
+		fn:       fn,
+		timeout:  r.timeout,
+		cacheTTL: r.cacheTTL,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = c
+}
+
+// RegisterLiveness adds a liveness check.
+func (r *Registry) RegisterLiveness(name string, fn CheckFunc, opts ...CheckOption) {
+	r.Register(name, Liveness, fn, opts...)
+}
+
+// RegisterReadiness adds a readiness check.
+func (r *Registry) RegisterReadiness(name string, fn CheckFunc, opts ...CheckOption) {
+	r.Register(name, Readiness, fn, opts...)
+}
+
+// Unregister removes the check registered under name.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks, name)
+}
+
+// Check runs the checks of kind concurrently and reports their results.
+func (r *Registry) Check(ctx context.Context, kind Kind) Report {
+	r.mu.RLock()
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		if c.kind&kind != 0 {
+			checks = append(checks, c)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status == StatusDown {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// run returns the cached result of c or runs it. Concurrent probes share a
+// single run of the check.
+func (r *Registry) run(ctx context.Context, c *check) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.result != nil && c.cacheTTL > 0 && r.now().Sub(c.result.CheckedAt) < c.cacheTTL {
+		return *c.result
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	start := r.now()
+	err := runCheck(ctx, c.fn)
+	result := CheckResult{
+		Status:    StatusUp,
+		Duration:  r.now().Sub(start).Milliseconds(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	c.result = &result
+	return result
+}
+
+// runCheck runs fn, returning when ctx is done even if fn ignores it
+func runCheck(ctx context.Context, fn CheckFunc) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return errors.New("check timed out")
+		}
+		return ctx.Err()
+	}
+}
+
+// Handler serves the report of kind as JSON, with status 200 when up and 503
+// when down.
+func (r *Registry) Handler(kind Kind) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context(), kind)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status == StatusDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+// LivenessHandler serves the liveness report, see Handler.
+func (r *Registry) LivenessHandler() http.Handler {
+	return r.Handler(Liveness)
+}
+
+// ReadinessHandler serves the readiness report, see Handler.
+func (r *Registry) ReadinessHandler() http.Handler {
+	return r.Handler(Readiness)
+}
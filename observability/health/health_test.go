@@ -0,0 +1,143 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryCheck(t *testing.T) {
+	r := NewRegistry(WithCacheTTL(0))
+	r.RegisterLiveness("worker", func(ctx context.Context) error { return nil })
+	r.RegisterReadiness("database", func(ctx context.Context) error { return errors.New("connection refused") })
+	r.Register("cache", Liveness|Readiness, func(ctx context.Context) error { return nil })
+
+	live := r.Check(context.Background(), Liveness)
+	assert.Equal(t, StatusUp, live.Status)
+	assert.Len(t, live.Checks, 2)
+	assert.Contains(t, live.Checks, "worker")
+	assert.Contains(t, live.Checks, "cache")
+
+	ready := r.Check(context.Background(), Readiness)
+	assert.Equal(t, StatusDown, ready.Status)
+	assert.Len(t, ready.Checks, 2)
+	assert.Equal(t, StatusDown, ready.Checks["database"].Status)
+	assert.Equal(t, "connection refused", ready.Checks["database"].Error)
+	assert.Equal(t, StatusUp, ready.Checks["cache"].Status)
+
+	r.Unregister("database")
+	assert.Equal(t, StatusUp, r.Check(context.Background(), Readiness).Status)
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	r := NewRegistry(WithTimeout(20 * time.Millisecond))
+	// ignores its context, the registry must not wait for it
+	r.RegisterReadiness("stuck", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	r.RegisterReadiness("slow", func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+			return nil
+		}
+	}, WithCheckTimeout(time.Second))
+
+	start := time.Now()
+	report := r.Check(context.Background(), Readiness)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, StatusDown, report.Checks["stuck"].Status)
+	assert.Equal(t, "check timed out", report.Checks["stuck"].Error)
+	assert.Equal(t, StatusUp, report.Checks["slow"].Status)
+}
+
+func TestRegistryCache(t *testing.T) {
+	var calls atomic.Int32
+	r := NewRegistry(WithCacheTTL(time.Hour))
+	r.RegisterReadiness("cached", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+	r.RegisterReadiness("uncached", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}, WithCheckCacheTTL(0))
+
+	for i := 0; i < 3; i++ {
+		r.Check(context.Background(), Readiness)
+	}
+	// one run of the cached check and three of the uncached one
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestRegistryPanic(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterLiveness("panics", func(ctx context.Context) error { panic("boom") })
+
+	report := r.Check(context.Background(), Liveness)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "check panicked: boom", report.Checks["panics"].Error)
+}
+
+func TestHandler(t *testing.T) {
+	var healthy atomic.Bool
+	r := NewRegistry(WithCacheTTL(0))
+	r.RegisterReadiness("dependency", func(ctx context.Context) error {
+		if !healthy.Load() {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+
+	server := httptest.NewServer(r.ReadinessHandler())
+	defer server.Close()
+
+	get := func() (int, Report) {
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		var report Report
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		return resp.StatusCode, report
+	}
+
+	status, report := get()
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "unavailable", report.Checks["dependency"].Error)
+
+	healthy.Store(true)
+	status, report = get()
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, StatusUp, report.Status)
+
+	// liveness has no checks and is always up
+	rec := httptest.NewRecorder()
+	r.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRedisCheck(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	check := RedisCheck(client)
+	assert.NoError(t, check(context.Background()))
+
+	mr.Close()
+	assert.Error(t, check(context.Background()))
+}
@@ -0,0 +1,159 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/infigaming-com/go-common/util"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Environment names accepted by NewLogger. Any other value is treated as
+// production.
+const (
+	EnvProduction  = "production"
+	EnvDevelopment = "development"
+	EnvLocal       = "local"
+)
+
+// Field keys added to every log line.
+const (
+	ServiceKey       = "service"
+	CorrelationIdKey = "correlation_id"
+)
+
+// NewLogger builds a logger with the shared defaults for env.
+//
+// Production writes JSON to stdout with ISO8601 timestamps, GCP severity names
+// under "severity", sampling (the first 100 entries per message and second,
+// then every 100th) and stack traces from error level. Development and local
+// write human readable console output without sampling and with stack traces
+// from warn level.
+//
+// level is a zap level name ("debug", "info", "warn", "error", ...); an empty
+// level means info.
+func NewLogger(env, level, serviceName string, opts ...zap.Option) (*zap.Logger, error) {
+	lvl := zapcore.InfoLevel
+	if level != "" {
+		parsed, err := zapcore.ParseLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+		}
+		lvl = parsed
+	}
+
+	cfg := newConfig(env)
+	cfg.Level = zap.NewAtomicLevelAt(lvl)
+
+	logger, err := cfg.Build(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build logger: %w", err)
+	}
+	if serviceName != "" {
+		logger = logger.With(zap.String(ServiceKey, serviceName))
+	}
+	return logger, nil
+}
+
+func newConfig(env string) zap.Config {
+	switch strings.ToLower(env) {
+	case EnvDevelopment, "dev", EnvLocal:
+		cfg := zap.NewDevelopmentConfig()
+		cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		cfg.OutputPaths = []string{"stdout"}
+		return cfg
+	default:
+		cfg := zap.NewProductionConfig()
+		cfg.Sampling = &zap.SamplingConfig{Initial: 100, Thereafter: 100}
+		cfg.EncoderConfig = productionEncoderConfig()
+		cfg.OutputPaths = []string{"stdout"}
+		return cfg
+	}
+}
+
+func productionEncoderConfig() zapcore.EncoderConfig {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.TimeKey = "time"
+	cfg.MessageKey = "message"
+	cfg.CallerKey = "ln"
+	cfg.FunctionKey = ""
+	cfg.LevelKey = "severity"
+	cfg.EncodeLevel = SeverityEncoder
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	return cfg
+}
+
+// SeverityEncoder encodes zap levels as severity names understood by both
+// Google Cloud Logging and the OTLP log data model (SeverityText).
+func SeverityEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(Severity(l))
+}
+
+// Severity maps a zap level to its Google Cloud Logging severity name.
+func Severity(l zapcore.Level) string {
+	switch l {
+	case zapcore.DebugLevel:
+		return "DEBUG"
+	case zapcore.InfoLevel:
+		return "INFO"
+	case zapcore.WarnLevel:
+		return "WARNING"
+	case zapcore.ErrorLevel:
+		return "ERROR"
+	case zapcore.DPanicLevel:
+		return "CRITICAL"
+	case zapcore.PanicLevel:
+		return "ALERT"
+	case zapcore.FatalLevel:
+		return "EMERGENCY"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// SeverityNumber maps a zap level to an OTLP SeverityNumber, e.g. 9 (INFO)
+// for info and 17 (ERROR) for error. Levels above error use the FATAL range.
+func SeverityNumber(l zapcore.Level) int {
+	switch l {
+	case zapcore.DebugLevel:
+		return 5
+	case zapcore.InfoLevel:
+		return 9
+	case zapcore.WarnLevel:
+		return 13
+	case zapcore.ErrorLevel:
+		return 17
+	case zapcore.DPanicLevel:
+		return 21
+	case zapcore.PanicLevel:
+		return 22
+	case zapcore.FatalLevel:
+		return 23
+	default:
+		return 0
+	}
+}
+
+type loggerKey struct{}
+
+// WithContext returns a copy of ctx carrying logger.
+func WithContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger stored by WithContext, or the global logger,
+// with the correlation ID of ctx (see util.CorrelationIdToCtx) attached so it
+// appears on every line.
+func FromContext(ctx context.Context) *zap.Logger {
+	logger, ok := ctx.Value(loggerKey{}).(*zap.Logger)
+	if !ok || logger == nil {
+		logger = zap.L()
+	}
+	if correlationId, err := util.CorrelationIdFromCtx(ctx); err == nil && correlationId != "" {
+		logger = logger.With(zap.String(CorrelationIdKey, correlationId))
+	}
+	return logger
+}
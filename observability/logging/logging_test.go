@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/infigaming-com/go-common/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewLogger(t *testing.T) {
+	for _, env := range []string{EnvProduction, EnvDevelopment, EnvLocal, ""} {
+		logger, err := NewLogger(env, "debug", "test-service")
+		require.NoError(t, err, env)
+		assert.True(t, logger.Core().Enabled(zapcore.DebugLevel), env)
+	}
+
+	logger, err := NewLogger(EnvProduction, "", "test-service")
+	require.NoError(t, err)
+	assert.False(t, logger.Core().Enabled(zapcore.DebugLevel))
+	assert.True(t, logger.Core().Enabled(zapcore.InfoLevel))
+
+	_, err = NewLogger(EnvProduction, "verbose", "test-service")
+	assert.Error(t, err)
+}
+
+func TestNewLogger_ServiceField(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger, err := NewLogger(EnvProduction, "info", "wallet", zap.WrapCore(func(zapcore.Core) zapcore.Core { return core }))
+	require.NoError(t, err)
+
+	logger.Info("hello")
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "wallet", logs.All()[0].ContextMap()[ServiceKey])
+}
+
+func TestProductionEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(productionEncoderConfig()), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	zap.New(core).Warn("careful")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "WARNING", line["severity"])
+	assert.Equal(t, "careful", line["message"])
+	assert.Contains(t, line, "time")
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		level    zapcore.Level
+		severity string
+		number   int
+	}{
+		{zapcore.DebugLevel, "DEBUG", 5},
+		{zapcore.InfoLevel, "INFO", 9},
+		{zapcore.WarnLevel, "WARNING", 13},
+		{zapcore.ErrorLevel, "ERROR", 17},
+		{zapcore.DPanicLevel, "CRITICAL", 21},
+		{zapcore.PanicLevel, "ALERT", 22},
+		{zapcore.FatalLevel, "EMERGENCY", 23},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.severity, Severity(tt.level))
+		assert.Equal(t, tt.number, SeverityNumber(tt.level))
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	ctx := WithContext(context.Background(), zap.New(core))
+
+	FromContext(ctx).Info("without correlation id")
+	FromContext(util.CorrelationIdToCtx(ctx, "abc-123")).Info("with correlation id")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.NotContains(t, entries[0].ContextMap(), CorrelationIdKey)
+	assert.Equal(t, "abc-123", entries[1].ContextMap()[CorrelationIdKey])
+
+	assert.NotNil(t, FromContext(context.Background()))
+}
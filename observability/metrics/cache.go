@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/infigaming-com/go-common/cache"
+)
+
+// Attributes recorded by the cache instrumentation
+const (
+	CacheNameKey      = "cache.name"
+	CacheOperationKey = "cache.operation"
+	CacheResultKey    = "cache.result"
+)
+
+// cacheDurationBuckets are the histogram boundaries, in seconds, of the cache
+// operation durations, from in-memory reads to slow Redis round trips
+var cacheDurationBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// CacheRecorder returns a recorder for cache.WithStatsRecorder that records,
+// for every operation of an instrumented cache:
+//
+//   - cache.operations, the operation count
+//   - cache.operation.duration, a duration histogram in seconds
+//   - cache.lookups, the keys read, with a cache.result of hit or miss
+//
+// with the cache name and operation as attributes, and the error type for
+// operations that failed. The hit rate of a cache is the share of its
+// cache.lookups with a hit result.
+func (mc *MetricExporter) CacheRecorder() (cache.StatsRecorder, error) {
+	operations, err := mc.meter.Int64Counter("cache.operations",
+		metric.WithDescription("Number of cache operations"),
+		metric.WithUnit("{operation}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache operation counter: %w", err)
+	}
+	duration, err := mc.meter.Float64Histogram("cache.operation.duration",
+		metric.WithDescription("Duration of cache operations"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(cacheDurationBuckets...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache operation duration histogram: %w", err)
+	}
+	lookups, err := mc.meter.Int64Counter("cache.lookups",
+		metric.WithDescription("Number of keys read from caches"),
+		metric.WithUnit("{key}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache lookup counter: %w", err)
+	}
+
+	return func(ctx context.Context, record *cache.OperationRecord) {
+		kvs := []attribute.KeyValue{
+			attribute.String(CacheNameKey, record.Cache),
+			attribute.String(CacheOperationKey, record.Operation),
+		}
+		if record.Err != nil {
+			kvs = append(kvs, attribute.String(ErrorTypeKey, "error"))
+		}
+		attrs := metric.WithAttributes(kvs...)
+		operations.Add(ctx, 1, attrs)
+		duration.Record(ctx, record.Duration.Seconds(), attrs)
+
+		if record.Hits > 0 {
+			lookups.Add(ctx, int64(record.Hits), metric.WithAttributes(append(kvs, attribute.String(CacheResultKey, "hit"))...))
+		}
+		if record.Misses > 0 {
+			lookups.Add(ctx, int64(record.Misses), metric.WithAttributes(append(kvs, attribute.String(CacheResultKey, "miss"))...))
+		}
+	}, nil
+}
@@ -0,0 +1,192 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/infigaming-com/go-common/request"
+)
+
+// Attributes recorded by the HTTP instrumentation
+const (
+	HTTPMethodKey     = "http.request.method"
+	HTTPRouteKey      = "http.route"
+	HTTPStatusCodeKey = "http.response.status_code"
+	ServerAddressKey  = "server.address"
+	ErrorTypeKey      = "error.type"
+)
+
+// durationBuckets are the histogram boundaries, in seconds, of the request
+// durations; the SDK defaults are sized for milliseconds
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+// httpServerMetrics holds the instruments of HTTPMiddleware
+type httpServerMetrics struct {
+	requests     metric.Int64Counter
+	duration     metric.Float64Histogram
+	active       metric.Int64UpDownCounter
+	responseSize metric.Int64Histogram
+}
+
+// HTTPMiddleware records, for every request served by next:
+//
+//   - http.server.requests, the request count
+//   - http.server.request.duration, a duration histogram in seconds
+//   - http.server.active_requests, the requests in flight
+//   - http.server.response.body.size, a response size histogram in bytes
+//
+// with the method, route and status code as attributes. The route is the
+// pattern matched by http.ServeMux, so the middleware should wrap the mux;
+// requests that matched no pattern are recorded with an empty route rather
+// than their path, to keep the cardinality bounded.
+func (mc *MetricExporter) HTTPMiddleware(next http.Handler) (http.Handler, error) {
+	m, err := mc.newHTTPServerMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		methodAttr := attribute.String(HTTPMethodKey, r.Method)
+
+		m.active.Add(r.Context(), 1, metric.WithAttributes(methodAttr))
+		defer m.active.Add(r.Context(), -1, metric.WithAttributes(methodAttr))
+
+		rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		attrs := metric.WithAttributes(
+			methodAttr,
+			attribute.String(HTTPRouteKey, r.Pattern),
+			attribute.Int(HTTPStatusCodeKey, rw.status),
+		)
+		m.requests.Add(r.Context(), 1, attrs)
+		m.duration.Record(r.Context(), time.Since(start).Seconds(), attrs)
+		m.responseSize.Record(r.Context(), rw.size, attrs)
+	}), nil
+}
+
+func (mc *MetricExporter) newHTTPServerMetrics() (*httpServerMetrics, error) {
+	requests, err := mc.meter.Int64Counter("http.server.requests",
+		metric.WithDescription("Number of HTTP requests served"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request counter: %w", err)
+	}
+	duration, err := mc.meter.Float64Histogram("http.server.request.duration",
+		metric.WithDescription("Duration of HTTP requests served"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request duration histogram: %w", err)
+	}
+	active, err := mc.meter.Int64UpDownCounter("http.server.active_requests",
+		metric.WithDescription("Number of HTTP requests in flight"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create active requests counter: %w", err)
+	}
+	responseSize, err := mc.meter.Int64Histogram("http.server.response.body.size",
+		metric.WithDescription("Size of HTTP response bodies"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create response size histogram: %w", err)
+	}
+	return &httpServerMetrics{requests: requests, duration: duration, active: active, responseSize: responseSize}, nil
+}
+
+// statusRecorder captures the status code and body size written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.size += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// RequestRecorder returns a recorder for request.WithRequestRecorder that
+// records, for every outgoing request:
+//
+//   - http.client.requests, the request count
+//   - http.client.request.duration, a duration histogram in seconds
+//   - http.client.response.body.size, a response size histogram in bytes
+//
+// with the method, server address and status code as attributes, and the
+// error type for requests that failed. A request can only have one recorder;
+// call the returned recorder from your own to record metrics alongside it.
+func (mc *MetricExporter) RequestRecorder() (request.RequestRecorder, error) {
+	requests, err := mc.meter.Int64Counter("http.client.requests",
+		metric.WithDescription("Number of HTTP requests sent"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request counter: %w", err)
+	}
+	duration, err := mc.meter.Float64Histogram("http.client.request.duration",
+		metric.WithDescription("Duration of HTTP requests sent"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request duration histogram: %w", err)
+	}
+	responseSize, err := mc.meter.Int64Histogram("http.client.response.body.size",
+		metric.WithDescription("Size of HTTP response bodies received"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create response size histogram: %w", err)
+	}
+
+	return func(data *request.RequestRecordData) {
+		// The recorder is called without a context once the request is done
+		ctx := context.Background()
+
+		host := ""
+		if parsedUrl, err := url.Parse(data.Url); err == nil {
+			host = parsedUrl.Host
+		}
+		kvs := []attribute.KeyValue{
+			attribute.String(HTTPMethodKey, data.Method),
+			attribute.String(ServerAddressKey, host),
+			attribute.Int(HTTPStatusCodeKey, data.HttpStatusCode),
+		}
+		if data.Error != "" {
+			kvs = append(kvs, attribute.String(ErrorTypeKey, "error"))
+		}
+		attrs := metric.WithAttributes(kvs...)
+
+		requests.Add(ctx, 1, attrs)
+		duration.Record(ctx, (time.Duration(data.Duration) * time.Millisecond).Seconds(), attrs)
+		responseSize.Record(ctx, int64(len(data.ResponseBody)), attrs)
+	}, nil
+}
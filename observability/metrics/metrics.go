@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -11,6 +12,7 @@ import (
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
@@ -26,6 +28,13 @@ type MetricExporter struct {
 	otlpEndpoint     string
 	otlpGRPCEndpoint string
 	environment      string
+	runtimeMetrics   bool
+	instrumentViews  []*instrumentView
+	views            []sdkmetric.View
+	exemplarFilter   exemplar.Filter
+
+	prometheus        bool
+	prometheusHandler http.Handler
 }
 
 // Option is a function that configures a MetricExporter
@@ -95,7 +104,9 @@ func NewMetricExporter(opts ...Option) (*MetricExporter, func(), error) {
 	}
 
 	// Validate required fields
-	if mc.otlpGRPCEndpoint != "" && mc.otlpEndpoint == "" {
+	if mc.prometheus {
+		// metrics are scraped, no endpoint is required
+	} else if mc.otlpGRPCEndpoint != "" && mc.otlpEndpoint == "" {
 		// gRPC endpoint is configured, HTTP endpoint is not required
 	} else if mc.otlpEndpoint == "" {
 		return nil, nil, fmt.Errorf("OTLP HTTP endpoint is required when gRPC endpoint is not configured")
@@ -114,9 +125,15 @@ func NewMetricExporter(opts ...Option) (*MetricExporter, func(), error) {
 		return nil, nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	// Create OTLP exporter (HTTP or gRPC)
+	// Create Prometheus reader or OTLP exporter (HTTP or gRPC)
+	var reader sdkmetric.Reader
 	var exporter sdkmetric.Exporter
-	if mc.otlpGRPCEndpoint != "" {
+	if mc.prometheus {
+		reader, err = mc.newPrometheusReader()
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if mc.otlpGRPCEndpoint != "" {
 		// Use gRPC if gRPC endpoint is configured
 		exporter, err = otlpmetricgrpc.New(context.Background(),
 			otlpmetricgrpc.WithEndpoint(mc.otlpGRPCEndpoint),
@@ -135,14 +152,22 @@ func NewMetricExporter(opts ...Option) (*MetricExporter, func(), error) {
 			return nil, nil, fmt.Errorf("failed to create OTLP HTTP exporter: %w", err)
 		}
 	}
+	if reader == nil {
+		reader = sdkmetric.NewPeriodicReader(exporter,
+			sdkmetric.WithInterval(10*time.Second),
+		)
+	}
 
 	// Create meter provider
-	meterProvider := sdkmetric.NewMeterProvider(
+	providerOpts := []sdkmetric.Option{
 		sdkmetric.WithResource(res),
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter,
-			sdkmetric.WithInterval(10*time.Second),
-		)),
-	)
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithView(mc.buildViews()...),
+	}
+	if mc.exemplarFilter != nil {
+		providerOpts = append(providerOpts, sdkmetric.WithExemplarFilter(mc.exemplarFilter))
+	}
+	meterProvider := sdkmetric.NewMeterProvider(providerOpts...)
 
 	// Set global meter provider
 	otel.SetMeterProvider(meterProvider)
@@ -154,6 +179,13 @@ func NewMetricExporter(opts ...Option) (*MetricExporter, func(), error) {
 	mc.meter = meter
 	mc.resource = res
 
+	if mc.runtimeMetrics {
+		if err := mc.registerRuntimeMetrics(); err != nil {
+			_ = meterProvider.Shutdown(context.Background())
+			return nil, nil, err
+		}
+	}
+
 	return mc, func() {
 		mc.meterProvider.Shutdown(context.Background())
 	}, nil
@@ -184,6 +216,27 @@ func (mc *MetricExporter) RecordCounter(ctx context.Context, name, description,
 	return nil
 }
 
+// RecordUpDownCounter adds value, which may be negative, to an up-down counter
+// metric, e.g. the number of items in flight
+func (mc *MetricExporter) RecordUpDownCounter(ctx context.Context, name, description, unit string, value int64, attributes map[string]string) error {
+	counter, err := mc.meter.Int64UpDownCounter(name,
+		metric.WithDescription(description),
+		metric.WithUnit(unit),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create up-down counter: %w", err)
+	}
+
+	// Convert attributes to key-value pairs
+	attrs := make([]attribute.KeyValue, 0, len(attributes))
+	for k, v := range attributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	counter.Add(ctx, value, metric.WithAttributes(attrs...))
+	return nil
+}
+
 // RecordGauge records a gauge metric
 func (mc *MetricExporter) RecordGauge(ctx context.Context, name, description, unit string, value float64, attributes map[string]string) error {
 	gauge, err := mc.meter.Float64ObservableGauge(name,
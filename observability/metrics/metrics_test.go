@@ -73,7 +73,7 @@ func TestNewMetricExporter(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			client, err := NewMetricExporter(tt.opts...)
+			client, _, err := NewMetricExporter(tt.opts...)
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -108,7 +108,7 @@ func TestNewMetricExporter(t *testing.T) {
 }
 
 func TestMetricClient_RecordCounter(t *testing.T) {
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("test-service"),
 		WithServiceNamespace("test"),
 		WithServiceVersion("1.0.0"),
@@ -186,7 +186,7 @@ func TestMetricClient_RecordCounter(t *testing.T) {
 }
 
 func TestMetricClient_RecordGauge(t *testing.T) {
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("test-service"),
 		WithServiceNamespace("test"),
 		WithServiceVersion("1.0.0"),
@@ -262,7 +262,7 @@ func TestMetricClient_RecordGauge(t *testing.T) {
 }
 
 func TestMetricClient_RecordHistogram(t *testing.T) {
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("test-service"),
 		WithServiceNamespace("test"),
 		WithServiceVersion("1.0.0"),
@@ -338,7 +338,7 @@ func TestMetricClient_RecordHistogram(t *testing.T) {
 }
 
 func TestMetricClient_Close(t *testing.T) {
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("test-service"),
 		WithServiceNamespace("test"),
 		WithServiceVersion("1.0.0"),
@@ -353,7 +353,7 @@ func TestMetricClient_Close(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Test close with timeout
-	client2, err := NewMetricExporter(
+	client2, _, err := NewMetricExporter(
 		WithServiceName("test-service-2"),
 		WithServiceNamespace("test"),
 		WithServiceVersion("1.0.0"),
@@ -370,7 +370,7 @@ func TestMetricClient_Close(t *testing.T) {
 
 func TestMetricClient_Integration(t *testing.T) {
 	// Test that we can create a client and record multiple metric types
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("integration-test"),
 		WithServiceNamespace("test"),
 		WithServiceVersion("1.0.0"),
@@ -405,7 +405,7 @@ func TestMetricClient_ContinuousMetrics(t *testing.T) {
 	t.Skip("Skipping continuous metrics test - run manually when needed for GCP verification")
 
 	// Test continuous metric sending to see active metrics in GCP
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("continuous-test"),
 		WithServiceNamespace("test"),
 		WithServiceVersion("1.0.0"),
@@ -480,7 +480,7 @@ func TestMetricClient_ContinuousMetrics(t *testing.T) {
 }
 
 func TestMetricClient_ConcurrentUsage(t *testing.T) {
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("concurrent-test"),
 		WithServiceNamespace("test"),
 		WithServiceVersion("1.0.0"),
@@ -510,7 +510,7 @@ func TestMetricClient_ConcurrentUsage(t *testing.T) {
 }
 
 func TestMetricClient_ResourceAttributes(t *testing.T) {
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("resource-test"),
 		WithServiceNamespace("test"),
 		WithServiceVersion("2.0.0"),
@@ -533,7 +533,7 @@ func TestMetricClient_ResourceAttributes(t *testing.T) {
 
 func ExampleNewMetricExporter_http() {
 	// Create metric exporter with HTTP endpoint (default behavior)
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("my-service"),
 		WithServiceNamespace("dev"),
 		WithServiceVersion("1.0.0"),
@@ -557,7 +557,7 @@ func ExampleNewMetricExporter_http() {
 
 func ExampleNewMetricExporter_grpc() {
 	// Create metric client with gRPC endpoint (automatically uses gRPC when configured)
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("my-service"),
 		WithServiceNamespace("dev"),
 		WithServiceVersion("1.0.0"),
@@ -581,7 +581,7 @@ func ExampleNewMetricExporter_grpc() {
 
 func ExampleNewMetricExporter_minimalHTTP() {
 	// Create metric client with just the required HTTP endpoint
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithOTLPEndpoint("localhost:4318"),
 	)
 	if err != nil {
@@ -598,7 +598,7 @@ func ExampleNewMetricExporter_minimalHTTP() {
 
 func ExampleNewMetricExporter_minimalGRPC() {
 	// Create metric client with just the required gRPC endpoint
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithOTLPGRPCEndpoint("localhost:4317"),
 	)
 	if err != nil {
@@ -615,7 +615,7 @@ func ExampleNewMetricExporter_minimalGRPC() {
 
 func ExampleNewMetricExporter_production() {
 	// Create metric client for production with gRPC
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("production-api"),
 		WithServiceNamespace("prod"),
 		WithServiceVersion("2.1.0"),
@@ -636,7 +636,7 @@ func ExampleNewMetricExporter_production() {
 
 func ExampleNewMetricExporter_grpcPrecedence() {
 	// Even if both endpoints are configured, gRPC takes precedence
-	client, err := NewMetricExporter(
+	client, _, err := NewMetricExporter(
 		WithServiceName("my-service"),
 		WithOTLPEndpoint("localhost:4318"),
 		WithOTLPGRPCEndpoint("localhost:4317"), // This will be used
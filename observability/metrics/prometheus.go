@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// PrometheusPath is the path RegisterPrometheusHandler serves metrics on
+const PrometheusPath = "/metrics"
+
+// WithPrometheusHandler exposes the metrics to be scraped by Prometheus
+// instead of pushing them to an OTLP collector, for environments without
+// one. The OTLP endpoints are ignored. Serve PrometheusHandler, e.g. with
+// RegisterPrometheusHandler.
+func WithPrometheusHandler() Option {
+	return func(mc *MetricExporter) {
+		mc.prometheus = true
+	}
+}
+
+// newPrometheusReader creates the reader collecting the metrics on every
+// scrape, registered to a registry of its own so that several exporters do
+// not collide in the default Prometheus registry
+func (mc *MetricExporter) newPrometheusReader() (sdkmetric.Reader, error) {
+	registry := prometheus.NewRegistry()
+	reader, err := otelprom.New(otelprom.WithRegisterer(registry))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Prometheus exporter: %w", err)
+	}
+	mc.prometheusHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	return reader, nil
+}
+
+// PrometheusHandler returns the handler serving the metrics in the Prometheus
+// exposition format, or nil without WithPrometheusHandler
+func (mc *MetricExporter) PrometheusHandler() http.Handler {
+	return mc.prometheusHandler
+}
+
+// RegisterPrometheusHandler serves the metrics on PrometheusPath of mux. It
+// does nothing without WithPrometheusHandler.
+func (mc *MetricExporter) RegisterPrometheusHandler(mux *http.ServeMux) {
+	if mc.prometheusHandler != nil {
+		mux.Handle(PrometheusPath, mc.prometheusHandler)
+	}
+}
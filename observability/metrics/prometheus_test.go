@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPrometheusHandler(t *testing.T) {
+	mc, shutdown, err := NewMetricExporter(
+		WithServiceName("test-service"),
+		WithOTLPEndpoint(""), // no collector required
+		WithPrometheusHandler(),
+	)
+	require.NoError(t, err)
+	defer shutdown()
+
+	ctx := context.Background()
+	require.NoError(t, mc.RecordCounter(ctx, "test_requests", "Test requests", "1", 3, map[string]string{"route": "/users"}))
+	require.NoError(t, mc.RecordHistogram(ctx, "test_latency", "Test latency", "s", 0.25, nil))
+
+	mux := http.NewServeMux()
+	mc.RegisterPrometheusHandler(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + PrometheusPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `test_requests_total{`)
+	assert.Contains(t, string(body), `route="/users"`)
+	assert.Contains(t, string(body), `test_latency_seconds_bucket{`)
+}
+
+func TestPrometheusHandler_Disabled(t *testing.T) {
+	mc, shutdown, err := NewMetricExporter(WithServiceName("test-service"))
+	require.NoError(t, err)
+	defer shutdown()
+
+	assert.Nil(t, mc.PrometheusHandler())
+}
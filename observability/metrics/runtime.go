@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	rtmetrics "runtime/metrics"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// WithRuntimeMetrics enables the Go runtime and process metrics collector.
+// The metrics are observed on every export of the MeterProvider:
+//
+//   - process.runtime.go.goroutines
+//   - process.runtime.go.mem.heap_alloc, heap_objects and sys
+//   - process.runtime.go.gc.count and gc.pause_total
+//   - process.cpu.time, the CPU time used by the process as estimated by the
+//     Go runtime, which refreshes the estimate on every GC cycle
+//   - process.uptime
+func WithRuntimeMetrics() Option {
+	return func(mc *MetricExporter) {
+		mc.runtimeMetrics = true
+	}
+}
+
+// cpu time samples read from runtime/metrics
+var cpuSamples = []rtmetrics.Sample{
+	{Name: "/cpu/classes/total:cpu-seconds"},
+	{Name: "/cpu/classes/idle:cpu-seconds"},
+}
+
+// registerRuntimeMetrics registers the observable instruments of the runtime
+// collector with a single callback reading the runtime stats once per export
+func (mc *MetricExporter) registerRuntimeMetrics() error {
+	start := time.Now()
+
+	goroutines, err := mc.meter.Int64ObservableGauge("process.runtime.go.goroutines",
+		metric.WithDescription("Number of goroutines that currently exist"),
+		metric.WithUnit("{goroutine}"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create goroutines gauge: %w", err)
+	}
+	heapAlloc, err := mc.meter.Int64ObservableGauge("process.runtime.go.mem.heap_alloc",
+		metric.WithDescription("Bytes of allocated heap objects"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create heap alloc gauge: %w", err)
+	}
+	heapObjects, err := mc.meter.Int64ObservableGauge("process.runtime.go.mem.heap_objects",
+		metric.WithDescription("Number of allocated heap objects"),
+		metric.WithUnit("{object}"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create heap objects gauge: %w", err)
+	}
+	memSys, err := mc.meter.Int64ObservableGauge("process.runtime.go.mem.sys",
+		metric.WithDescription("Bytes of memory obtained from the OS"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create sys memory gauge: %w", err)
+	}
+	gcCount, err := mc.meter.Int64ObservableCounter("process.runtime.go.gc.count",
+		metric.WithDescription("Number of completed garbage collection cycles"),
+		metric.WithUnit("{gc_cycle}"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create gc count counter: %w", err)
+	}
+	gcPause, err := mc.meter.Float64ObservableCounter("process.runtime.go.gc.pause_total",
+		metric.WithDescription("Cumulative stop-the-world pause time of garbage collection"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create gc pause counter: %w", err)
+	}
+	cpuTime, err := mc.meter.Float64ObservableCounter("process.cpu.time",
+		metric.WithDescription("CPU time used by the process"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create cpu time counter: %w", err)
+	}
+	uptime, err := mc.meter.Float64ObservableGauge("process.uptime",
+		metric.WithDescription("Time since the metrics were started"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create uptime gauge: %w", err)
+	}
+
+	_, err = mc.meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		var memStats runtime.MemStats
+		runtime.ReadMemStats(&memStats)
+
+		samples := make([]rtmetrics.Sample, len(cpuSamples))
+		copy(samples, cpuSamples)
+		rtmetrics.Read(samples)
+
+		observer.ObserveInt64(goroutines, int64(runtime.NumGoroutine()))
+		observer.ObserveInt64(heapAlloc, int64(memStats.HeapAlloc))
+		observer.ObserveInt64(heapObjects, int64(memStats.HeapObjects))
+		observer.ObserveInt64(memSys, int64(memStats.Sys))
+		observer.ObserveInt64(gcCount, int64(memStats.NumGC))
+		observer.ObserveFloat64(gcPause, time.Duration(memStats.PauseTotalNs).Seconds())
+		observer.ObserveFloat64(uptime, time.Since(start).Seconds())
+		if samples[0].Value.Kind() == rtmetrics.KindFloat64 && samples[1].Value.Kind() == rtmetrics.KindFloat64 {
+			observer.ObserveFloat64(cpuTime, samples[0].Value.Float64()-samples[1].Value.Float64())
+		}
+		return nil
+	}, goroutines, heapAlloc, heapObjects, memSys, gcCount, gcPause, cpuTime, uptime)
+	if err != nil {
+		return fmt.Errorf("failed to register runtime metrics callback: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/infigaming-com/go-common/observability/slow"
+)
+
+// SlowOperationKey is the operation name recorded by the slow operation
+// instrumentation
+const SlowOperationKey = "slow.operation"
+
+// slowDurationBuckets are the histogram boundaries, in seconds, of the slow
+// operation durations, from slow cache reads to report generations
+var slowDurationBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// SlowOperationRecorder returns a recorder for slow.WithRecorder that records,
+// for every operation exceeding its threshold:
+//
+//   - slow.operations, the slow operation count
+//   - slow.operation.duration, a duration histogram in seconds
+//
+// with the operation name as attribute.
+func (mc *MetricExporter) SlowOperationRecorder() (slow.Recorder, error) {
+	operations, err := mc.meter.Int64Counter("slow.operations",
+		metric.WithDescription("Number of operations exceeding their threshold"),
+		metric.WithUnit("{operation}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create slow operation counter: %w", err)
+	}
+	duration, err := mc.meter.Float64Histogram("slow.operation.duration",
+		metric.WithDescription("Duration of operations exceeding their threshold"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(slowDurationBuckets...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create slow operation duration histogram: %w", err)
+	}
+
+	return func(ctx context.Context, op slow.Operation) {
+		attrs := metric.WithAttributes(attribute.String(SlowOperationKey, op.Name))
+		operations.Add(ctx, 1, attrs)
+		duration.Record(ctx, op.Duration.Seconds(), attrs)
+	}, nil
+}
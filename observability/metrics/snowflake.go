@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/infigaming-com/go-common/snowflake"
+)
+
+// SnowflakeLeaseEventKey is the lease event recorded by the snowflake
+// instrumentation: acquired, renewed, renew_failed, expired, reclaimed or
+// released
+const SnowflakeLeaseEventKey = "snowflake.lease.event"
+
+// snowflakeWaitBuckets are the histogram boundaries, in seconds, of the waits
+// after a sequence overflow, which last at most a millisecond or so
+var snowflakeWaitBuckets = []float64{0.00001, 0.000025, 0.00005, 0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005}
+
+// SnowflakeMetrics returns a hook for snowflake.WithMetrics and
+// snowflake.WithLeaseMetrics that records:
+//
+//   - snowflake.ids, the IDs issued, whose rate is the IDs issued per second
+//   - snowflake.sequence.overflows, the times a node exhausted the 4096 IDs of a millisecond
+//   - snowflake.sequence.overflow.wait, a histogram of the resulting waits in seconds
+//   - snowflake.clock.rollbacks, the clock rollbacks detected
+//   - snowflake.lease.events, the lease events with their snowflake.lease.event
+//   - snowflake.lease.healthy, 1 while the lease is healthy and 0 otherwise
+func (mc *MetricExporter) SnowflakeMetrics() (snowflake.MetricsHook, error) {
+	ids, err := mc.meter.Int64Counter("snowflake.ids",
+		metric.WithDescription("Number of snowflake IDs issued"),
+		metric.WithUnit("{id}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snowflake ID counter: %w", err)
+	}
+	overflows, err := mc.meter.Int64Counter("snowflake.sequence.overflows",
+		metric.WithDescription("Number of snowflake sequence overflows"),
+		metric.WithUnit("{overflow}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snowflake sequence overflow counter: %w", err)
+	}
+	overflowWait, err := mc.meter.Float64Histogram("snowflake.sequence.overflow.wait",
+		metric.WithDescription("Time spent waiting for the next millisecond after a snowflake sequence overflow"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(snowflakeWaitBuckets...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snowflake overflow wait histogram: %w", err)
+	}
+	rollbacks, err := mc.meter.Int64Counter("snowflake.clock.rollbacks",
+		metric.WithDescription("Number of clock rollbacks detected by snowflake generators"),
+		metric.WithUnit("{rollback}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snowflake clock rollback counter: %w", err)
+	}
+	leaseEvents, err := mc.meter.Int64Counter("snowflake.lease.events",
+		metric.WithDescription("Number of snowflake node lease events"),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snowflake lease event counter: %w", err)
+	}
+	leaseHealthy, err := mc.meter.Int64Gauge("snowflake.lease.healthy",
+		metric.WithDescription("Whether the snowflake node lease is healthy"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snowflake lease health gauge: %w", err)
+	}
+
+	return &snowflakeMetrics{
+		ids:          ids,
+		overflows:    overflows,
+		overflowWait: overflowWait,
+		rollbacks:    rollbacks,
+		leaseEvents:  leaseEvents,
+		leaseHealthy: leaseHealthy,
+	}, nil
+}
+
+// snowflakeMetrics implements snowflake.MetricsHook and its optional
+// extensions with OpenTelemetry instruments
+type snowflakeMetrics struct {
+	ids          metric.Int64Counter
+	overflows    metric.Int64Counter
+	overflowWait metric.Float64Histogram
+	rollbacks    metric.Int64Counter
+	leaseEvents  metric.Int64Counter
+	leaseHealthy metric.Int64Gauge
+}
+
+func (m *snowflakeMetrics) OnIDGenerated(count int) {
+	m.ids.Add(context.Background(), int64(count))
+}
+
+func (m *snowflakeMetrics) OnClockRollback() {
+	m.rollbacks.Add(context.Background(), 1)
+}
+
+func (m *snowflakeMetrics) OnSequenceOverflow() {
+	m.overflows.Add(context.Background(), 1)
+}
+
+func (m *snowflakeMetrics) OnSequenceOverflowWait(wait time.Duration) {
+	m.overflowWait.Record(context.Background(), wait.Seconds())
+}
+
+func (m *snowflakeMetrics) OnLeaseAcquired(int64) {
+	m.leaseEvent("acquired")
+	m.leaseHealthy.Record(context.Background(), 1)
+}
+
+func (m *snowflakeMetrics) OnLeaseRenewed() {
+	m.leaseEvent("renewed")
+}
+
+func (m *snowflakeMetrics) OnLeaseRenewFail() {
+	m.leaseEvent("renew_failed")
+}
+
+func (m *snowflakeMetrics) OnLeaseExpired() {
+	m.leaseEvent("expired")
+}
+
+func (m *snowflakeMetrics) OnLeaseReclaimed(int64) {
+	m.leaseEvent("reclaimed")
+}
+
+func (m *snowflakeMetrics) OnLeaseReleased() {
+	m.leaseEvent("released")
+	m.leaseHealthy.Record(context.Background(), 0)
+}
+
+func (m *snowflakeMetrics) OnLeaseHealthChanged(healthy bool) {
+	var value int64
+	if healthy {
+		value = 1
+	}
+	m.leaseHealthy.Record(context.Background(), value)
+}
+
+func (m *snowflakeMetrics) leaseEvent(event string) {
+	m.leaseEvents.Add(context.Background(), 1, metric.WithAttributes(attribute.String(SnowflakeLeaseEventKey, event)))
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+)
+
+// instrumentView holds the overrides of the instruments matching name. They
+// are merged into a single view, since every view matching an instrument
+// exports its own stream: an instrument matching several names, e.g. through
+// wildcards, is exported once per name.
+type instrumentView struct {
+	name           string
+	rename         string
+	buckets        []float64
+	dropAttributes []attribute.Key
+}
+
+// instrumentView returns the overrides of name, creating them on first use
+func (mc *MetricExporter) instrumentView(name string) *instrumentView {
+	for _, v := range mc.instrumentViews {
+		if v.name == name {
+			return v
+		}
+	}
+	v := &instrumentView{name: name}
+	mc.instrumentViews = append(mc.instrumentViews, v)
+	return v
+}
+
+// WithHistogramBuckets sets the explicit bucket boundaries of the histograms
+// named name, overriding the boundaries set by the instrumentation, e.g.
+//
+//	WithHistogramBuckets("http.client.request.duration", 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5)
+//
+// name may use the * and ? wildcards to match several instruments.
+func WithHistogramBuckets(name string, bounds ...float64) Option {
+	return func(mc *MetricExporter) {
+		buckets := slices.Clone(bounds)
+		slices.Sort(buckets)
+		mc.instrumentView(name).buckets = slices.Compact(buckets)
+	}
+}
+
+// WithDroppedAttributes removes the attributes keys from the measurements of
+// the instruments named name, e.g. to drop a high-cardinality attribute
+// without changing the instrumentation. name may use the * and ? wildcards.
+func WithDroppedAttributes(name string, keys ...string) Option {
+	return func(mc *MetricExporter) {
+		v := mc.instrumentView(name)
+		for _, key := range keys {
+			v.dropAttributes = append(v.dropAttributes, attribute.Key(key))
+		}
+	}
+}
+
+// WithInstrumentName exports the instrument named name as newName. name must
+// not use wildcards.
+func WithInstrumentName(name, newName string) Option {
+	return func(mc *MetricExporter) {
+		mc.instrumentView(name).rename = newName
+	}
+}
+
+// WithView adds OpenTelemetry SDK views, for overrides not covered by the
+// options above. Views are applied after the ones built from those options.
+func WithView(views ...sdkmetric.View) Option {
+	return func(mc *MetricExporter) {
+		mc.views = append(mc.views, views...)
+	}
+}
+
+// WithExemplarFilter sets which measurements are offered as exemplars, by
+// default those recorded with a sampled span in their context
+// (exemplar.TraceBasedFilter). Use exemplar.AlwaysOffFilter to disable them.
+func WithExemplarFilter(filter exemplar.Filter) Option {
+	return func(mc *MetricExporter) {
+		mc.exemplarFilter = filter
+	}
+}
+
+// buildViews returns the views of the meter provider
+func (mc *MetricExporter) buildViews() []sdkmetric.View {
+	views := make([]sdkmetric.View, 0, len(mc.instrumentViews)+len(mc.views))
+	for _, v := range mc.instrumentViews {
+		mask := sdkmetric.Stream{Name: v.rename}
+		if v.buckets != nil {
+			mask.Aggregation = sdkmetric.AggregationExplicitBucketHistogram{Boundaries: v.buckets}
+		}
+		if len(v.dropAttributes) > 0 {
+			mask.AttributeFilter = attribute.NewDenyKeysFilter(v.dropAttributes...)
+		}
+		views = append(views, sdkmetric.NewView(sdkmetric.Instrument{Name: v.name}, mask))
+	}
+	return append(views, mc.views...)
+}
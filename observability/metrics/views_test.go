@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestViews(t *testing.T) {
+	mc := defaultConfig()
+	for _, opt := range []Option{
+		WithHistogramBuckets("latency", 1, 0.5, 0.1, 0.5),
+		WithDroppedAttributes("latency", "user.id"),
+		WithInstrumentName("requests", "provider.requests"),
+		WithDroppedAttributes("cache.*", "user.id"),
+	} {
+		opt(mc)
+	}
+	require.Len(t, mc.instrumentViews, 3, "options for the same name are merged")
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithView(mc.buildViews()...))
+	defer provider.Shutdown(context.Background())
+	meter := provider.Meter("test")
+
+	ctx := context.Background()
+	attrs := metric.WithAttributes(attribute.String("user.id", "42"), attribute.String("route", "/games"))
+	latency, err := meter.Float64Histogram("latency")
+	require.NoError(t, err)
+	latency.Record(ctx, 0.3, attrs)
+	requests, err := meter.Int64Counter("requests")
+	require.NoError(t, err)
+	requests.Add(ctx, 1, attrs)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	exported := map[string]metricdata.Metrics{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		exported[m.Name] = m
+	}
+
+	histogram := exported["latency"].Data.(metricdata.Histogram[float64])
+	require.Len(t, histogram.DataPoints, 1)
+	assert.Equal(t, []float64{0.1, 0.5, 1}, histogram.DataPoints[0].Bounds)
+	_, hasUser := histogram.DataPoints[0].Attributes.Value("user.id")
+	assert.False(t, hasUser)
+
+	require.Contains(t, exported, "provider.requests")
+	sum := exported["provider.requests"].Data.(metricdata.Sum[int64])
+	require.Len(t, sum.DataPoints, 1)
+	_, hasUser = sum.DataPoints[0].Attributes.Value("user.id")
+	assert.True(t, hasUser)
+	assert.NotContains(t, exported, "requests")
+}
@@ -0,0 +1,162 @@
+package slow
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Stat aggregates the slow occurrences of an operation over an interval.
+type Stat struct {
+	Name  string
+	Count int64
+	Max   time.Duration
+	Total time.Duration
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (s Stat) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("operation", s.Name)
+	enc.AddInt64("count", s.Count)
+	enc.AddDuration("max", s.Max)
+	enc.AddDuration("total", s.Total)
+	return nil
+}
+
+// ReportFunc receives the slowest operations of an interval, slowest first.
+type ReportFunc func(ctx context.Context, stats []Stat)
+
+// Reporter aggregates the slow operations of Trackers and reports the top N
+// slowest, by maximum duration, every interval. Intervals without slow
+// operations are not reported.
+type Reporter struct {
+	topN     int
+	interval time.Duration
+	report   ReportFunc
+
+	mu    sync.Mutex
+	stats map[string]*Stat
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// ReporterOption configures a Reporter.
+type ReporterOption func(*Reporter)
+
+// WithTopN sets the number of operations reported per interval. Default: 10.
+func WithTopN(n int) ReporterOption {
+	return func(r *Reporter) {
+		if n > 0 {
+			r.topN = n
+		}
+	}
+}
+
+// WithInterval sets the reporting interval. Default: 1 minute.
+func WithInterval(d time.Duration) ReporterOption {
+	return func(r *Reporter) {
+		if d > 0 {
+			r.interval = d
+		}
+	}
+}
+
+// WithReportFunc sets the destination of the reports. Default: a warning of
+// the global zap logger.
+func WithReportFunc(fn ReportFunc) ReporterOption {
+	return func(r *Reporter) {
+		if fn != nil {
+			r.report = fn
+		}
+	}
+}
+
+// NewReporter creates a Reporter and starts its reporting loop, stopped by
+// Stop.
+func NewReporter(opts ...ReporterOption) *Reporter {
+	r := &Reporter{
+		topN:     10,
+		interval: time.Minute,
+		report:   logReport,
+		stats:    map[string]*Stat{},
+		stopCh:   make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+
+	r.wg.Add(1)
+	go r.loop()
+	return r
+}
+
+// Flush reports the slow operations aggregated since the last report and
+// starts a new interval.
+func (r *Reporter) Flush(ctx context.Context) {
+	r.mu.Lock()
+	stats := make([]Stat, 0, len(r.stats))
+	for _, s := range r.stats {
+		stats = append(stats, *s)
+	}
+	clear(r.stats)
+	r.mu.Unlock()
+
+	if len(stats) == 0 {
+		return
+	}
+	slices.SortFunc(stats, func(a, b Stat) int {
+		return cmp.Or(cmp.Compare(b.Max, a.Max), strings.Compare(a.Name, b.Name))
+	})
+	if len(stats) > r.topN {
+		stats = stats[:r.topN]
+	}
+	r.report(ctx, stats)
+}
+
+// Stop stops the reporting loop and reports the current interval.
+func (r *Reporter) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+		r.wg.Wait()
+		r.Flush(context.Background())
+	})
+}
+
+func (r *Reporter) add(op Operation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.stats[op.Name]
+	if !ok {
+		s = &Stat{Name: op.Name}
+		r.stats[op.Name] = s
+	}
+	s.Count++
+	s.Total += op.Duration
+	s.Max = max(s.Max, op.Duration)
+}
+
+func (r *Reporter) loop() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.Flush(context.Background())
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+func logReport(ctx context.Context, stats []Stat) {
+	zap.L().Warn("[SLOW-OPERATIONS]", zap.Objects("operations", stats))
+}
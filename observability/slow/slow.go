@@ -0,0 +1,158 @@
+// Package slow reports the operations exceeding a duration threshold, e.g. a
+// cache read, lock acquisition, message handling or report generation:
+//
+//	done := slow.Track(ctx, "reports.generate", 10*time.Second)
+//	defer done()
+//
+// Slow operations are logged with the logger and correlation ID of the
+// context, recorded by the Recorder of the Tracker, e.g. the one of
+// metrics.MetricExporter.SlowOperationRecorder, and aggregated by its
+// Reporter, which periodically reports the slowest ones.
+package slow
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/infigaming-com/go-common/observability/logging"
+)
+
+// Operation is an operation that exceeded its threshold.
+type Operation struct {
+	Name      string // low cardinality name, e.g. "cache.get" or "pubsub.handle.orders"
+	Duration  time.Duration
+	Threshold time.Duration
+}
+
+// Recorder receives the slow operations, e.g. to record metrics.
+type Recorder func(ctx context.Context, op Operation)
+
+// Tracker logs, records and aggregates slow operations.
+type Tracker struct {
+	logger   *zap.Logger
+	recorder Recorder
+	reporter *Reporter
+}
+
+// Option configures a Tracker.
+type Option func(*Tracker)
+
+// WithLogger sets the logger of slow operations. Default: the logger of the
+// context, see logging.FromContext.
+func WithLogger(logger *zap.Logger) Option {
+	return func(t *Tracker) {
+		t.logger = logger
+	}
+}
+
+// WithRecorder sets the recorder of slow operations.
+func WithRecorder(recorder Recorder) Option {
+	return func(t *Tracker) {
+		t.recorder = recorder
+	}
+}
+
+// WithReporter aggregates slow operations in reporter.
+func WithReporter(reporter *Reporter) Option {
+	return func(t *Tracker) {
+		t.reporter = reporter
+	}
+}
+
+// New creates a Tracker.
+func New(opts ...Option) *Tracker {
+	t := &Tracker{}
+	for _, o := range opts {
+		o(t)
+	}
+	return t
+}
+
+// Track starts timing the operation name and returns the func to call once it
+// is done, which reports it if it took longer than threshold. It is safe to
+// call the returned func more than once, only the first call counts.
+func (t *Tracker) Track(ctx context.Context, name string, threshold time.Duration) func() {
+	start := time.Now()
+	var done atomic.Bool
+	return func() {
+		if done.Swap(true) {
+			return
+		}
+		if d := time.Since(start); d > threshold {
+			t.report(ctx, Operation{Name: name, Duration: d, Threshold: threshold})
+		}
+	}
+}
+
+// Record records and aggregates op without logging it, for callers that log
+// their slow operations with more details, like request.
+func (t *Tracker) Record(ctx context.Context, op Operation) {
+	if t.recorder != nil {
+		t.recorder(ctx, op)
+	}
+	if t.reporter != nil {
+		t.reporter.add(op)
+	}
+}
+
+func (t *Tracker) report(ctx context.Context, op Operation) {
+	logger := t.logger
+	if logger == nil {
+		logger = logging.FromContext(ctx)
+	}
+	fields := []zap.Field{
+		zap.String("operation", op.Name),
+		zap.Duration("duration", op.Duration),
+		zap.Duration("threshold", op.Threshold),
+	}
+	// A canceled or expired context tells apart the operations that were
+	// slow because their caller gave up
+	if err := ctx.Err(); err != nil {
+		fields = append(fields, zap.NamedError("contextError", err))
+	}
+	logger.Warn("[SLOW-OPERATION]", fields...)
+	t.Record(ctx, op)
+}
+
+var defaultTracker atomic.Pointer[Tracker]
+
+func init() {
+	defaultTracker.Store(New())
+}
+
+// Default returns the Tracker used when the context has none, which only logs
+// unless replaced by SetDefault.
+func Default() *Tracker {
+	return defaultTracker.Load()
+}
+
+// SetDefault replaces the default Tracker, e.g. at startup with one recording
+// metrics.
+func SetDefault(t *Tracker) {
+	if t != nil {
+		defaultTracker.Store(t)
+	}
+}
+
+type trackerKey struct{}
+
+// WithContext returns a copy of ctx carrying t.
+func WithContext(ctx context.Context, t *Tracker) context.Context {
+	return context.WithValue(ctx, trackerKey{}, t)
+}
+
+// FromContext returns the Tracker stored by WithContext, or the default one.
+func FromContext(ctx context.Context) *Tracker {
+	if t, ok := ctx.Value(trackerKey{}).(*Tracker); ok && t != nil {
+		return t
+	}
+	return Default()
+}
+
+// Track times the operation name with the Tracker of ctx, see Tracker.Track.
+func Track(ctx context.Context, name string, threshold time.Duration) func() {
+	return FromContext(ctx).Track(ctx, name, threshold)
+}
@@ -0,0 +1,104 @@
+package slow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTrack(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	var recorded []Operation
+	tracker := New(
+		WithLogger(zap.New(core)),
+		WithRecorder(func(ctx context.Context, op Operation) { recorded = append(recorded, op) }),
+	)
+	ctx := WithContext(context.Background(), tracker)
+
+	Track(ctx, "fast", time.Hour)()
+	assert.Empty(t, recorded)
+
+	done := Track(ctx, "slow", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	done()
+	done()
+	require.Len(t, recorded, 1, "an operation should be reported once")
+	assert.Equal(t, "slow", recorded[0].Name)
+	assert.Equal(t, time.Millisecond, recorded[0].Threshold)
+	assert.Greater(t, recorded[0].Duration, time.Millisecond)
+
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, "slow", fields["operation"])
+	assert.NotContains(t, fields, "contextError")
+
+	canceled, cancel := context.WithCancel(ctx)
+	done = Track(canceled, "canceled", 0)
+	cancel()
+	done()
+	require.Equal(t, 2, logs.Len())
+	assert.Contains(t, logs.All()[1].ContextMap(), "contextError")
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Same(t, Default(), FromContext(context.Background()))
+
+	tracker := New()
+	assert.Same(t, tracker, FromContext(WithContext(context.Background(), tracker)))
+
+	previous := Default()
+	defer SetDefault(previous)
+	SetDefault(tracker)
+	assert.Same(t, tracker, FromContext(context.Background()))
+}
+
+func TestReporter(t *testing.T) {
+	reports := make(chan []Stat, 1)
+	reporter := NewReporter(
+		WithTopN(2),
+		WithInterval(time.Hour),
+		WithReportFunc(func(ctx context.Context, stats []Stat) { reports <- stats }),
+	)
+	defer reporter.Stop()
+	tracker := New(WithLogger(zap.NewNop()), WithReporter(reporter))
+	ctx := context.Background()
+
+	tracker.Record(ctx, Operation{Name: "cache.get", Duration: 100 * time.Millisecond})
+	tracker.Record(ctx, Operation{Name: "cache.get", Duration: 300 * time.Millisecond})
+	tracker.Record(ctx, Operation{Name: "lock.acquire", Duration: 200 * time.Millisecond})
+	tracker.Record(ctx, Operation{Name: "pubsub.handle", Duration: 50 * time.Millisecond})
+
+	reporter.Flush(ctx)
+	assert.Equal(t, []Stat{
+		{Name: "cache.get", Count: 2, Max: 300 * time.Millisecond, Total: 400 * time.Millisecond},
+		{Name: "lock.acquire", Count: 1, Max: 200 * time.Millisecond, Total: 200 * time.Millisecond},
+	}, <-reports)
+
+	// Intervals without slow operations are not reported
+	reporter.Flush(ctx)
+	assert.Empty(t, reports)
+}
+
+func TestReporterInterval(t *testing.T) {
+	reports := make(chan []Stat, 1)
+	reporter := NewReporter(
+		WithInterval(10*time.Millisecond),
+		WithReportFunc(func(ctx context.Context, stats []Stat) { reports <- stats }),
+	)
+	defer reporter.Stop()
+	New(WithReporter(reporter)).Record(context.Background(), Operation{Name: "reports.generate", Duration: time.Minute})
+
+	select {
+	case stats := <-reports:
+		require.Len(t, stats, 1)
+		assert.Equal(t, "reports.generate", stats[0].Name)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for report")
+	}
+}
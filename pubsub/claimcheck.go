@@ -0,0 +1,90 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ClaimCheckAttribute holds the PayloadStore key of a payload offloaded by
+// WithPayloadOffload, the message data being empty
+const ClaimCheckAttribute = "pubsub_claim_check"
+
+// ErrPayloadTooLarge is returned by Publish when the data exceeds the limit of
+// WithMaxPayloadSize. It is not retried.
+var ErrPayloadTooLarge = errors.New("pubsub: payload too large")
+
+// PayloadStore keeps the payloads offloaded by WithPayloadOffload, e.g. in an
+// object storage bucket whose lifecycle rules delete them once every
+// subscriber is done with them.
+type PayloadStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// WithMaxPayloadSize makes Publish fail with ErrPayloadTooLarge when the
+// encoded data exceeds n bytes, after offloading, instead of the opaque error
+// of a broker rejecting it. Zero (default) disables the check.
+func WithMaxPayloadSize(n int) Option {
+	return func(o *options) {
+		if n >= 0 {
+			o.maxPayloadSize = n
+		}
+	}
+}
+
+// WithPayloadOffload applies the claim-check pattern: Publish uploads data
+// larger than threshold bytes to store and publishes an empty message with
+// ClaimCheckAttribute set to its key instead. Subscriptions download the
+// payload before validating and handling the message, so subscribers need a
+// client with the same store. Dead letters of offloaded messages keep the
+// reference rather than the data.
+func WithPayloadOffload(store PayloadStore, threshold int) Option {
+	return func(o *options) {
+		o.payloadStore = store
+		o.offloadThreshold = threshold
+	}
+}
+
+// offload replaces the data of env by a reference once uploaded, if it is
+// above the offload threshold
+func (c *Client) offload(ctx context.Context, topic string, env *Envelope) error {
+	store := c.opts.payloadStore
+	if store == nil || len(env.Data) <= c.opts.offloadThreshold {
+		return nil
+	}
+	key := topic + "/" + uuid.NewString()
+	if err := store.Put(ctx, key, env.Data); err != nil {
+		return fmt.Errorf("pubsub: offload payload of %s: %w", topic, err)
+	}
+	env.Data = nil
+	env.Attributes[ClaimCheckAttribute] = key
+	return nil
+}
+
+// checkPayloadSize enforces WithMaxPayloadSize
+func (c *Client) checkPayloadSize(topic string, env *Envelope) error {
+	if limit := c.opts.maxPayloadSize; limit > 0 && len(env.Data) > limit {
+		return fmt.Errorf("%w: %d bytes published to %s, limit %d", ErrPayloadTooLarge, len(env.Data), topic, limit)
+	}
+	return nil
+}
+
+// resolvePayload returns the data of a message, downloaded from the payload
+// store if it was offloaded
+func (c *Client) resolvePayload(ctx context.Context, attributes map[string]string, data []byte) ([]byte, error) {
+	key, ok := attributes[ClaimCheckAttribute]
+	if !ok {
+		return data, nil
+	}
+	if c.opts.payloadStore == nil {
+		return nil, ErrPermanent(fmt.Errorf("pubsub: payload %s offloaded without payload store", key))
+	}
+	data, err := c.opts.payloadStore.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("pubsub: resolve payload %s: %w", key, err)
+	}
+	return data, nil
+}
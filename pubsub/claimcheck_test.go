@@ -0,0 +1,154 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/infigaming-com/go-common/pubsub/driver/memory"
+)
+
+type memoryPayloadStore struct {
+	mu       sync.Mutex
+	payloads map[string][]byte
+}
+
+func (s *memoryPayloadStore) Put(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.payloads[key] = data
+	return nil
+}
+
+func (s *memoryPayloadStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.payloads[key]
+	if !ok {
+		return nil, errors.New("payload not found")
+	}
+	return data, nil
+}
+
+func TestMaxPayloadSize(t *testing.T) {
+	ctx := context.Background()
+	transport := memory.New()
+	client, err := pubsub.New(ctx, transport, pubsub.WithMaxPayloadSize(64))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	if _, err := client.Publish(ctx, "orders", orderCreated{ID: "1"}); err != nil {
+		t.Fatalf("publish small payload: %v", err)
+	}
+	_, err = client.Publish(ctx, "orders", orderCreated{ID: strings.Repeat("x", 100)})
+	if !errors.Is(err, pubsub.ErrPayloadTooLarge) {
+		t.Fatalf("expected ErrPayloadTooLarge, got %v", err)
+	}
+	if published := transport.Published("orders"); len(published) != 1 {
+		t.Fatalf("expected the large payload not to be published, got %d messages", len(published))
+	}
+}
+
+func TestPayloadOffload(t *testing.T) {
+	ctx := context.Background()
+	transport := memory.New()
+	store := &memoryPayloadStore{payloads: map[string][]byte{}}
+	client, err := pubsub.New(ctx, transport,
+		pubsub.WithPayloadOffload(store, 32),
+		pubsub.WithMaxPayloadSize(64),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	received := make(chan string, 2)
+	_, err = client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		var order orderCreated
+		if err := m.Decode(ctx, &order); err != nil {
+			return err
+		}
+		received <- order.ID
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	large := strings.Repeat("x", 100)
+	if _, err := client.Publish(ctx, "orders", orderCreated{ID: large}); err != nil {
+		t.Fatalf("publish large payload: %v", err)
+	}
+	if _, err := client.Publish(ctx, "orders", orderCreated{ID: "1"}); err != nil {
+		t.Fatalf("publish small payload: %v", err)
+	}
+
+	published := transport.Published("orders")
+	if len(published) != 2 {
+		t.Fatalf("expected 2 published messages, got %d", len(published))
+	}
+	key, ok := published[0].Attributes[pubsub.ClaimCheckAttribute]
+	if !ok || len(published[0].Data) != 0 {
+		t.Fatalf("expected the large payload to be offloaded, got %+v", published[0])
+	}
+	if _, ok := store.payloads[key]; !ok {
+		t.Fatalf("expected payload %s in the store", key)
+	}
+	if _, ok := published[1].Attributes[pubsub.ClaimCheckAttribute]; ok {
+		t.Fatal("expected the small payload to be published inline")
+	}
+
+	got := map[string]bool{}
+	for range 2 {
+		select {
+		case id := <-received:
+			got[id] = true
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for message")
+		}
+	}
+	if !got[large] || !got["1"] {
+		t.Fatalf("expected both orders to be handled, got %d", len(got))
+	}
+}
+
+func TestPayloadOffloadWithoutStore(t *testing.T) {
+	ctx := context.Background()
+	transport := memory.New()
+	failed := make(chan error, 1)
+	client, err := pubsub.New(ctx, transport, pubsub.WithHooks(pubsub.Hooks{
+		OnFailure: func(ctx context.Context, topic string, meta pubsub.MessageMetadata, err error) {
+			failed <- err
+		},
+	}))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	_, err = client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		t.Error("handler called for an unresolved payload")
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, err := transport.Publish(ctx, "orders", &pubsub.Envelope{Attributes: map[string]string{pubsub.ClaimCheckAttribute: "orders/missing"}}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	select {
+	case err := <-failed:
+		if !strings.Contains(err.Error(), "without payload store") {
+			t.Fatalf("unexpected failure %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for failure")
+	}
+}
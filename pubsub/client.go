@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -76,6 +77,30 @@ func (c *Client) Publish(ctx context.Context, topic string, payload any, opts ..
 	if po.orderingKey != "" && env.OrderingKey == "" {
 		env.OrderingKey = po.orderingKey
 	}
+	if po.deliveryDelay > 0 {
+		// rounded up so the message is never handled before the delay
+		due := time.Now().Add(po.deliveryDelay + time.Millisecond - 1)
+		env.Attributes[DeliverAtAttribute] = strconv.FormatInt(due.UnixMilli(), 10)
+	}
+	if err := validateSchema(ctx, c.opts.schemaRegistry, topic, env.Data); err != nil {
+		if errors.Is(err, ErrSchemaViolation) && c.opts.hooks.OnSchemaViolation != nil {
+			c.opts.hooks.OnSchemaViolation(ctx, topic, cloneMap(env.Attributes), err)
+		}
+		if c.opts.hooks.OnPublishFail != nil {
+			c.opts.hooks.OnPublishFail(ctx, topic, cloneMap(env.Attributes), err)
+		}
+		return "", err
+	}
+	err = c.offload(ctx, topic, env)
+	if err == nil {
+		err = c.checkPayloadSize(topic, env)
+	}
+	if err != nil {
+		if c.opts.hooks.OnPublishFail != nil {
+			c.opts.hooks.OnPublishFail(ctx, topic, cloneMap(env.Attributes), err)
+		}
+		return "", err
+	}
 	policy := po.retryPolicy
 	bo := backoff.New(backoff.Config{Initial: policy.InitialBackoff, Max: policy.MaxBackoff, Multiplier: policy.Multiplier, Jitter: policy.Jitter})
 	var attempt int
@@ -83,6 +108,7 @@ func (c *Client) Publish(ctx context.Context, topic string, payload any, opts ..
 		attempt++
 		id, err := c.transport.Publish(ctx, topic, env)
 		if err == nil {
+			c.archive(ctx, topic, env)
 			if c.opts.hooks.OnPublish != nil {
 				c.opts.hooks.OnPublish(ctx, topic, cloneMap(env.Attributes))
 			}
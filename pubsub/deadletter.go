@@ -0,0 +1,193 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// DeadLetterSourceAttribute holds the topic a dead letter failed on
+	DeadLetterSourceAttribute = "source"
+	// RequeuedAttribute is set to "true" on messages requeued by a
+	// DeadLetterManager
+	RequeuedAttribute = "pubsub_requeued"
+)
+
+// DeadLetter is the payload published to the dead-letter topic of
+// WithSubscriptionDeadLetter when a message fails permanently.
+type DeadLetter struct {
+	// ID is the ID of the dead letter itself, set by DeadLetterManager
+	ID string `json:"-"`
+
+	MessageID  string            `json:"message_id"`
+	Attributes map[string]string `json:"attributes"`
+	// Data is nil for payloads offloaded with WithPayloadOffload, the
+	// attributes holding their ClaimCheckAttribute
+	Data        []byte    `json:"data"`
+	SourceTopic string    `json:"source_topic,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	Attempt     int       `json:"attempt,omitempty"`
+	FailedAt    time.Time `json:"failed_at,omitzero"`
+}
+
+// DeadLetterManager inspects and requeues the messages of a dead-letter topic.
+type DeadLetterManager struct {
+	client       *Client
+	subscription string
+	idleTimeout  time.Duration
+}
+
+// DeadLetterManagerOption configures a DeadLetterManager.
+type DeadLetterManagerOption func(*DeadLetterManager)
+
+// WithDeadLetterIdleTimeout ends List and Requeue once the subscription has
+// delivered nothing for this long. Default: 5 seconds.
+func WithDeadLetterIdleTimeout(d time.Duration) DeadLetterManagerOption {
+	return func(m *DeadLetterManager) {
+		if d > 0 {
+			m.idleTimeout = d
+		}
+	}
+}
+
+// NewDeadLetterManager returns a manager reading the dead letters from
+// subscription, a subscription of the dead-letter topic dedicated to
+// operations, with the transport and decoder of client.
+func NewDeadLetterManager(client *Client, subscription string, opts ...DeadLetterManagerOption) *DeadLetterManager {
+	m := &DeadLetterManager{client: client, subscription: subscription, idleTimeout: 5 * time.Second}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// List returns up to limit dead letters, all of them if limit is not
+// positive. The messages are held unacknowledged until the listing ends, once
+// the subscription is idle, then nacked so they stay in the dead-letter topic.
+// Brokers bounding the outstanding messages of a subscription, e.g. to 1000 on
+// Google Pub/Sub, bound the listing too. Listing counts as a delivery for
+// brokers limiting delivery attempts.
+func (m *DeadLetterManager) List(ctx context.Context, limit int) ([]*DeadLetter, error) {
+	var letters []*DeadLetter
+	err := m.scan(ctx, func(letter *DeadLetter) (bool, bool, error) {
+		letters = append(letters, letter)
+		return false, limit > 0 && len(letters) >= limit, nil
+	})
+	return letters, err
+}
+
+// Requeue publishes the dead letters matched by match back to their source
+// topic with their original data and attributes, as new messages starting
+// over at attempt 0 with RequeuedAttribute set, and acks them. Other dead
+// letters are nacked. It returns the number of requeued messages.
+func (m *DeadLetterManager) Requeue(ctx context.Context, match func(*DeadLetter) bool) (int, error) {
+	var requeued int
+	err := m.scan(ctx, func(letter *DeadLetter) (bool, bool, error) {
+		if !match(letter) {
+			return false, false, nil
+		}
+		if letter.SourceTopic == "" {
+			m.client.logger().Warn(ctx, "dead letter without source topic", "subscription", m.subscription, "message", letter.ID)
+			return false, false, nil
+		}
+		attributes := cloneMap(letter.Attributes)
+		if attributes == nil {
+			attributes = map[string]string{}
+		}
+		attributes[RequeuedAttribute] = "true"
+		env := &Envelope{Data: letter.Data, Attributes: attributes}
+		if _, err := m.client.transport.Publish(ctx, letter.SourceTopic, env); err != nil {
+			return false, true, fmt.Errorf("pubsub: requeue %s: %w", letter.ID, err)
+		}
+		requeued++
+		return true, false, nil
+	})
+	if requeued > 0 {
+		m.client.logger().Info(ctx, "dead letters requeued", "subscription", m.subscription, "requeued", requeued)
+	}
+	return requeued, err
+}
+
+// MatchDeadLetterIDs matches the dead letters with the given IDs, as returned
+// by List
+func MatchDeadLetterIDs(ids ...string) func(*DeadLetter) bool {
+	return func(letter *DeadLetter) bool {
+		return slices.Contains(ids, letter.ID)
+	}
+}
+
+// scan passes each dead letter once to visit, which reports whether to ack it
+// and whether to stop. The other messages are held until the scan ends, so
+// the broker does not redeliver them meanwhile, then nacked.
+func (m *DeadLetterManager) scan(ctx context.Context, visit func(*DeadLetter) (bool, bool, error)) error {
+	scanCtx, activity, stop := watchIdle(ctx, m.idleTimeout)
+	defer stop()
+
+	var held []*TransportMessage
+	defer func() {
+		for _, raw := range held {
+			_ = raw.Nack()
+		}
+	}()
+
+	seen := map[string]struct{}{}
+	var visitErr error
+	err := m.client.transport.Subscribe(scanCtx, m.subscription, TransportSubscribeOptions{Parallelism: 1}, func(msgCtx context.Context, raw *TransportMessage) error {
+		held = append(held, raw)
+		if scanCtx.Err() != nil {
+			return scanCtx.Err()
+		}
+		activity()
+		if _, ok := seen[raw.ID]; ok {
+			// redelivered after its ack deadline, every dead letter has been seen
+			stop()
+			return scanCtx.Err()
+		}
+		seen[raw.ID] = struct{}{}
+
+		data, err := m.client.resolvePayload(msgCtx, raw.Attributes, raw.Data)
+		if err != nil {
+			m.client.logger().Warn(msgCtx, "dead letter payload resolution failed", "subscription", m.subscription, "message", raw.ID, "err", err)
+			return nil
+		}
+		letter := &DeadLetter{}
+		if err := m.client.decoder().Decode(msgCtx, data, letter); err != nil {
+			m.client.logger().Warn(msgCtx, "dead letter decode failed", "subscription", m.subscription, "message", raw.ID, "err", err)
+			return nil
+		}
+		letter.ID = raw.ID
+		if letter.SourceTopic == "" {
+			letter.SourceTopic = raw.Attributes[DeadLetterSourceAttribute]
+		}
+		ack, done, err := visit(letter)
+		if ack {
+			held = held[:len(held)-1]
+			if err := raw.Ack(); err != nil {
+				m.client.logger().Warn(msgCtx, "dead letter ack failed", "subscription", m.subscription, "message", raw.ID, "err", err)
+			}
+		}
+		if err != nil {
+			visitErr = err
+		}
+		if err != nil || done {
+			stop()
+			return scanCtx.Err()
+		}
+		return nil
+	})
+	stop()
+
+	if visitErr != nil {
+		return visitErr
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
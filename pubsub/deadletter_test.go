@@ -0,0 +1,99 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/infigaming-com/go-common/pubsub/driver/memory"
+)
+
+var errInvalidOrder = errors.New("invalid order")
+
+func TestDeadLetterManager(t *testing.T) {
+	ctx := context.Background()
+	transport := memory.New()
+	client, err := pubsub.New(ctx, transport)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	type received struct {
+		id       string
+		attempt  int
+		requeued bool
+	}
+	var fixed atomic.Bool
+	messages := make(chan received, 10)
+	_, err = client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		var order orderCreated
+		if err := m.Decode(ctx, &order); err != nil {
+			return err
+		}
+		if order.ID != "1" && !fixed.Load() {
+			return pubsub.ErrPermanent(errInvalidOrder)
+		}
+		messages <- received{id: order.ID, attempt: m.Attempt(), requeued: m.Attributes()[pubsub.RequeuedAttribute] == "true"}
+		return nil
+	}), pubsub.WithSubscriptionDeadLetter("orders-dlq"), pubsub.WithSubscriptionInactivityTimeout(-1))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	for _, id := range []string{"1", "2", "3"} {
+		if _, err := client.Publish(ctx, "orders", orderCreated{ID: id}, pubsub.WithAttributes(map[string]string{"tenant": "acme"})); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(transport.Published("orders-dlq")) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for dead letters")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	<-messages
+
+	manager := pubsub.NewDeadLetterManager(client, "orders-dlq", pubsub.WithDeadLetterIdleTimeout(100*time.Millisecond))
+	letters, err := manager.List(ctx, 0)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(letters) != 2 {
+		t.Fatalf("expected 2 dead letters, got %d", len(letters))
+	}
+	for _, letter := range letters {
+		if letter.SourceTopic != "orders" || !strings.Contains(letter.Reason, errInvalidOrder.Error()) ||
+			letter.Attributes["tenant"] != "acme" || letter.FailedAt.IsZero() {
+			t.Fatalf("unexpected dead letter %+v", letter)
+		}
+	}
+
+	// listing leaves the dead letters in place
+	if letters, err = manager.List(ctx, 1); err != nil || len(letters) != 1 {
+		t.Fatalf("expected 1 dead letter with limit, got %d, %v", len(letters), err)
+	}
+
+	fixed.Store(true)
+	requeued, err := manager.Requeue(ctx, pubsub.MatchDeadLetterIDs(letters[0].ID))
+	if err != nil || requeued != 1 {
+		t.Fatalf("expected 1 requeued message, got %d, %v", requeued, err)
+	}
+	select {
+	case m := <-messages:
+		if !m.requeued || m.attempt != 0 {
+			t.Fatalf("unexpected requeued message %+v", m)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for requeued message")
+	}
+
+	if letters, err = manager.List(ctx, 0); err != nil || len(letters) != 1 {
+		t.Fatalf("expected 1 dead letter left, got %d, %v", len(letters), err)
+	}
+}
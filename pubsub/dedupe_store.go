@@ -48,13 +48,19 @@ func newInMemoryDedupeStore(size int) *inMemoryDedupeStore {
 	if size <= 0 {
 		size = 1024
 	}
-	return &inMemoryDedupeStore{items: make(map[string]time.Time, size), size: size}
+	return &inMemoryDedupeStore{items: make(map[string]time.Time, size), strikes: map[string]strikeEntry{}, size: size}
 }
 
 type inMemoryDedupeStore struct {
-	mu    sync.Mutex
-	items map[string]time.Time
-	size  int
+	mu      sync.Mutex
+	items   map[string]time.Time
+	strikes map[string]strikeEntry
+	size    int
+}
+
+type strikeEntry struct {
+	count  int
+	expiry time.Time
 }
 
 func (d *inMemoryDedupeStore) Seen(_ context.Context, id string, ttl time.Duration) (bool, error) {
@@ -91,3 +97,36 @@ func (d *inMemoryDedupeStore) evictLocked(now time.Time) {
 		delete(d.items, k)
 	}
 }
+
+// Strike implements StrikeCounter, bounded to size ids like Seen.
+func (d *inMemoryDedupeStore) Strike(_ context.Context, id string, ttl time.Duration) (int, error) {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry, ok := d.strikes[id]
+	if !ok || !now.Before(entry.expiry) {
+		entry = strikeEntry{}
+		if len(d.strikes) >= d.size {
+			d.evictStrikesLocked(now)
+		}
+	}
+	entry.count++
+	entry.expiry = now.Add(ttl)
+	d.strikes[id] = entry
+	return entry.count, nil
+}
+
+// evictStrikesLocked frees a slot of d.strikes like evictLocked.
+func (d *inMemoryDedupeStore) evictStrikesLocked(now time.Time) {
+	for k, v := range d.strikes {
+		if now.After(v.expiry) {
+			delete(d.strikes, k)
+		}
+	}
+	for k := range d.strikes {
+		if len(d.strikes) < d.size {
+			return
+		}
+		delete(d.strikes, k)
+	}
+}
@@ -53,3 +53,16 @@ func (d *redisDedupeStore) Seen(ctx context.Context, id string, ttl time.Duratio
 	}
 	return !created, nil
 }
+
+// Strike implements StrikeCounter with a counter next to the dedupe key,
+// expiring ttl after the last strike.
+func (d *redisDedupeStore) Strike(ctx context.Context, id string, ttl time.Duration) (int, error) {
+	key := d.prefix + ":strikes:" + id
+	pipe := d.client.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(count.Val()), nil
+}
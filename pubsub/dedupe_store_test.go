@@ -214,3 +214,57 @@ func TestRedisDedupeStore_FailsOpenOnError(t *testing.T) {
 		// guard is what we really care about.
 	}
 }
+
+func TestInMemoryDedupeStore_Strike(t *testing.T) {
+	t.Parallel()
+	s := newInMemoryDedupeStore(8)
+	ctx := context.Background()
+
+	for want := 1; want <= 3; want++ {
+		strikes, err := s.Strike(ctx, "id-1", time.Minute)
+		if err != nil {
+			t.Fatalf("strike err: %v", err)
+		}
+		if strikes != want {
+			t.Fatalf("expected %d strikes, got %d", want, strikes)
+		}
+	}
+	if strikes, _ := s.Strike(ctx, "id-2", time.Minute); strikes != 1 {
+		t.Fatalf("strikes should be counted per id, got %d", strikes)
+	}
+
+	if _, err := s.Strike(ctx, "id-3", 10*time.Millisecond); err != nil {
+		t.Fatalf("strike err: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if strikes, _ := s.Strike(ctx, "id-3", time.Minute); strikes != 1 {
+		t.Fatalf("expired strikes should start over, got %d", strikes)
+	}
+}
+
+func TestRedisDedupeStore_Strike(t *testing.T) {
+	t.Parallel()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	s := NewRedisDedupeStore(client, "svc:dedupe:topic").(StrikeCounter)
+	ctx := context.Background()
+
+	for want := 1; want <= 2; want++ {
+		strikes, err := s.Strike(ctx, "id-1", time.Minute)
+		if err != nil {
+			t.Fatalf("strike err: %v", err)
+		}
+		if strikes != want {
+			t.Fatalf("expected %d strikes, got %d", want, strikes)
+		}
+	}
+	if ttl := mr.TTL("svc:dedupe:topic:strikes:id-1"); ttl != time.Minute {
+		t.Fatalf("expected strikes to expire in a minute, got %v", ttl)
+	}
+
+	mr.FastForward(2 * time.Minute)
+	if strikes, _ := s.Strike(ctx, "id-1", time.Minute); strikes != 1 {
+		t.Fatalf("expired strikes should start over, got %d", strikes)
+	}
+}
@@ -0,0 +1,109 @@
+package pubsub
+
+import (
+	"strconv"
+	"time"
+)
+
+// DeliverAtAttribute holds the Unix time in milliseconds before which a
+// message published with WithDeliveryDelay is not handed to the handler.
+const DeliverAtAttribute = "pubsub_deliver_at"
+
+// defaultMaxParked is the default of WithSubscriptionMaxParked
+const defaultMaxParked = 1000
+
+// WithDeliveryDelay delays handling of the message by d. Brokers without
+// native scheduling still deliver the message right away: the subscription
+// parks it, extending its ack deadline, until it is due. Messages due later
+// than the subscription max extension are parked for the max extension and
+// then nacked, so the broker redelivers them and each redelivery counts as an
+// attempt. Messages arriving while WithSubscriptionMaxParked messages are
+// parked are nacked right away, also using up an attempt.
+//
+// With a broker dead-letter policy, e.g. SubscriptionSpec.MaxDeliveryAttempts
+// on Google Pub/Sub, a message is dead-lettered before it is due once its
+// delay needs more redeliveries than the policy allows. Keep d below the max
+// delivery attempts times the max extension, and set a RedeliveryPolicy so
+// messages nacked at the parking limit are not redelivered right away.
+func WithDeliveryDelay(d time.Duration) PublishOption {
+	return func(o *publishOptions) {
+		if d > 0 {
+			o.deliveryDelay = d
+		}
+	}
+}
+
+// deliverAt returns the time a message is due, if it was delayed
+func deliverAt(attributes map[string]string) (time.Time, bool) {
+	value, ok := attributes[DeliverAtAttribute]
+	if !ok {
+		return time.Time{}, false
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
+
+// park holds a message that is not due yet and hands it back to
+// handleTransportMessage once it is. Messages due after the hold limit are
+// nacked at the limit instead, and messages beyond the parking limit right
+// away. Parked messages are nacked when the subscription stops.
+func (s *subscription) park(raw *TransportMessage, due time.Time) {
+	if s.parked.Add(1) > int64(s.options.maxParked) {
+		s.parked.Add(-1)
+		s.logger.Debug(s.ctx, "parking limit reached, message nacked", "topic", s.Topic(), "message", raw.ID, "due", due)
+		_ = raw.Nack()
+		return
+	}
+
+	hold := time.Until(due)
+	limit := s.options.maxExtension
+	if limit <= 0 {
+		limit = s.options.ackDeadline
+	}
+	redeliver := hold > limit
+	if redeliver {
+		hold = limit
+	}
+	s.logger.Debug(s.ctx, "message parked until due", "topic", s.Topic(), "message", raw.ID, "due", due, "hold", hold)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.parked.Add(-1)
+		timer := time.NewTimer(hold)
+		defer timer.Stop()
+
+		var extendC <-chan time.Time
+		if s.options.ackDeadline > 0 && raw.Extend != nil {
+			ticker := time.NewTicker(max(s.options.ackDeadline/2, time.Millisecond))
+			defer ticker.Stop()
+			extendC = ticker.C
+		}
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				_ = raw.Nack()
+				return
+			case <-raw.Done:
+				return
+			case <-extendC:
+				if err := raw.Extend(s.options.ackDeadline); err != nil {
+					s.logger.Warn(s.ctx, "extend parked message failed", "topic", s.Topic(), "message", raw.ID, "err", err)
+				}
+			case <-timer.C:
+				if redeliver {
+					_ = raw.Nack()
+					return
+				}
+				if err := s.handleTransportMessage(s.ctx, raw); err != nil {
+					_ = raw.Nack()
+				}
+				return
+			}
+		}
+	}()
+}
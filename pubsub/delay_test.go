@@ -0,0 +1,143 @@
+package pubsub_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/infigaming-com/go-common/pubsub/driver/memory"
+)
+
+func newDelayClient(t *testing.T, transport *memory.Transport) *pubsub.Client {
+	t.Helper()
+	client, err := pubsub.New(context.Background(), transport,
+		pubsub.WithDefaultAckDeadline(50*time.Millisecond),
+		pubsub.WithDefaultExtensionLimit(time.Second),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = client.Shutdown(ctx)
+	})
+	return client
+}
+
+func TestPublish_DeliveryDelay(t *testing.T) {
+	transport := memory.New()
+	client := newDelayClient(t, transport)
+
+	received := make(chan time.Time, 2)
+	_, err := client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		received <- time.Now()
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.Publish(context.Background(), "orders", orderCreated{ID: "1"}, pubsub.WithDeliveryDelay(200*time.Millisecond)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if _, err := client.Publish(context.Background(), "orders", orderCreated{ID: "2"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	first := <-received
+	if first.Sub(start) >= 200*time.Millisecond {
+		t.Fatal("undelayed message should not wait for the delayed one")
+	}
+	select {
+	case at := <-received:
+		if at.Sub(start) < 200*time.Millisecond {
+			t.Fatalf("delayed message handled after %v", at.Sub(start))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for delayed message")
+	}
+
+	// The parked message outlived several ack deadlines without expiring
+	stats := transport.Stats("orders")
+	if stats.Acked != 2 || stats.Expired != 0 || stats.Delivered != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	attrs := transport.Published("orders")[0].Attributes
+	if _, err := strconv.ParseInt(attrs[pubsub.DeliverAtAttribute], 10, 64); err != nil {
+		t.Fatalf("expected deliver at attribute, got %v", attrs)
+	}
+}
+
+func TestPublish_DeliveryDelayBeyondMaxExtension(t *testing.T) {
+	transport := memory.New()
+	client := newDelayClient(t, transport)
+
+	received := make(chan int, 1)
+	_, err := client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		received <- m.Attempt()
+		return nil
+	}), pubsub.WithSubscriptionMaxExtension(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.Publish(context.Background(), "orders", orderCreated{ID: "1"}, pubsub.WithDeliveryDelay(250*time.Millisecond)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	select {
+	case attempt := <-received:
+		if time.Since(start) < 250*time.Millisecond {
+			t.Fatal("message handled before it was due")
+		}
+		if attempt != 2 {
+			t.Fatalf("expected the message to be redelivered twice, got attempt %d", attempt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for delayed message")
+	}
+	if stats := transport.Stats("orders"); stats.Nacked != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestPublish_DeliveryDelayMaxParked(t *testing.T) {
+	transport := memory.New(memory.WithRedeliveryDelay(20 * time.Millisecond))
+	client := newDelayClient(t, transport)
+
+	received := make(chan time.Time, 3)
+	_, err := client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		received <- time.Now()
+		return nil
+	}), pubsub.WithSubscriptionMaxParked(1))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	start := time.Now()
+	for _, id := range []string{"1", "2", "3"} {
+		if _, err := client.Publish(context.Background(), "orders", orderCreated{ID: id}, pubsub.WithDeliveryDelay(100*time.Millisecond)); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case at := <-received:
+			if at.Sub(start) < 100*time.Millisecond {
+				t.Fatalf("delayed message handled after %v", at.Sub(start))
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for delayed messages")
+		}
+	}
+	// Only one message was parked at a time, the others were nacked
+	if stats := transport.Stats("orders"); stats.Acked != 3 || stats.Nacked == 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
@@ -4,15 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"sync"
 	"time"
 
 	gcppubsub "cloud.google.com/go/pubsub"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	"github.com/infigaming-com/go-common/pubsub"
 )
 
+// EmulatorHostEnv is the environment variable the emulator address is read
+// from when Config.EmulatorHost is empty, as set by
+// "gcloud beta emulators pubsub env-init".
+const EmulatorHostEnv = "PUBSUB_EMULATOR_HOST"
+
 type Config struct {
 	ProjectID       string
 	CredentialsJSON []byte
@@ -21,6 +32,17 @@ type Config struct {
 	Client          *gcppubsub.Client
 	Logger          pubsub.Logger
 	Receive         ReceiveSettings
+	// EmulatorHost connects to a Pub/Sub emulator at host:port without
+	// credentials, defaults to $PUBSUB_EMULATOR_HOST. Against an emulator
+	// AutoCreate is implied.
+	EmulatorHost string
+	// AutoCreate creates Topology when the transport is created, and topics
+	// on first publish and subscriptions of Topology on first subscribe when
+	// they are missing.
+	AutoCreate bool
+	// Topology lists the topics and subscriptions created by AutoCreate, as
+	// EnsureTopology creates them
+	Topology pubsub.TopologySpec
 }
 
 type ReceiveSettings struct {
@@ -35,6 +57,11 @@ type transport struct {
 	ownsClient bool
 	logger     pubsub.Logger
 	receive    ReceiveSettings
+	autoCreate bool
+	// subscriptions of the configured topology by name
+	subscriptions map[string]pubsub.SubscriptionSpec
+	// topics and subscriptions known to exist, only tracked with autoCreate
+	ensured sync.Map
 }
 
 func New(ctx context.Context, cfg Config) (pubsub.Transport, error) {
@@ -44,6 +71,11 @@ func New(ctx context.Context, cfg Config) (pubsub.Transport, error) {
 		owns   bool
 	)
 
+	emulatorHost := cfg.EmulatorHost
+	if emulatorHost == "" {
+		emulatorHost = os.Getenv(EmulatorHostEnv)
+	}
+
 	if cfg.Client != nil {
 		client = cfg.Client
 	} else {
@@ -51,11 +83,19 @@ func New(ctx context.Context, cfg Config) (pubsub.Transport, error) {
 			return nil, errors.New("googlepubsub: project id required when client is not provided")
 		}
 		opts := make([]option.ClientOption, 0, 3)
-		if len(cfg.CredentialsJSON) > 0 {
-			opts = append(opts, option.WithCredentialsJSON(cfg.CredentialsJSON))
-		}
-		if cfg.Endpoint != "" {
-			opts = append(opts, option.WithEndpoint(cfg.Endpoint))
+		if emulatorHost != "" {
+			opts = append(opts,
+				option.WithEndpoint(emulatorHost),
+				option.WithoutAuthentication(),
+				option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+			)
+		} else {
+			if len(cfg.CredentialsJSON) > 0 {
+				opts = append(opts, option.WithCredentialsJSON(cfg.CredentialsJSON))
+			}
+			if cfg.Endpoint != "" {
+				opts = append(opts, option.WithEndpoint(cfg.Endpoint))
+			}
 		}
 		if cfg.UserAgent != "" {
 			opts = append(opts, option.WithUserAgent(cfg.UserAgent))
@@ -72,13 +112,146 @@ func New(ctx context.Context, cfg Config) (pubsub.Transport, error) {
 		ownsClient: owns,
 		logger:     cfg.Logger,
 		receive:    cfg.Receive,
+		autoCreate: cfg.AutoCreate || emulatorHost != "",
 	}
 	if t.logger == nil {
 		t.logger = noopLogger{}
 	}
+	t.subscriptions = make(map[string]pubsub.SubscriptionSpec, len(cfg.Topology.Subscriptions))
+	for _, sub := range cfg.Topology.Subscriptions {
+		t.subscriptions[sub.Name] = sub
+	}
+	if t.autoCreate {
+		if err := t.EnsureTopology(ctx, cfg.Topology); err != nil {
+			if owns {
+				_ = client.Close()
+			}
+			return nil, err
+		}
+	}
 	return t, nil
 }
 
+// EnsureTopology implements pubsub.TopologyManager. Subscriptions are created
+// with the ack deadline, dead-letter policy and retry policy of their spec,
+// along with their topic and dead-letter topic. Dead-lettering also requires
+// the Pub/Sub service account to be allowed to publish to the dead-letter
+// topic and to subscribe to the subscription.
+func (t *transport) EnsureTopology(ctx context.Context, spec pubsub.TopologySpec) error {
+	topics := slices.Clone(spec.Topics)
+	for _, sub := range spec.Subscriptions {
+		topics = append(topics, sub.Topic)
+		if sub.DeadLetterTopic != "" {
+			topics = append(topics, sub.DeadLetterTopic)
+		}
+	}
+	slices.Sort(topics)
+	for _, topic := range slices.Compact(topics) {
+		if err := ensureTopic(ctx, t.client, topic); err != nil {
+			return err
+		}
+	}
+	for _, sub := range spec.Subscriptions {
+		if err := ensureSubscription(ctx, t.client, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateTopology implements pubsub.TopologyManager. It requires the
+// permissions to get the topics and subscriptions, e.g. roles/pubsub.viewer.
+func (t *transport) ValidateTopology(ctx context.Context, spec pubsub.TopologySpec) error {
+	var errs []error
+	for _, topic := range spec.Topics {
+		exists, err := t.client.Topic(topic).Exists(ctx)
+		if err != nil {
+			return fmt.Errorf("googlepubsub: check topic %s: %w", topic, err)
+		}
+		if !exists {
+			errs = append(errs, fmt.Errorf("%w: topic %s missing", pubsub.ErrTopologyMismatch, topic))
+		}
+	}
+	for _, sub := range spec.Subscriptions {
+		config, err := t.client.Subscription(sub.Name).Config(ctx)
+		if status.Code(err) == codes.NotFound {
+			errs = append(errs, fmt.Errorf("%w: subscription %s missing", pubsub.ErrTopologyMismatch, sub.Name))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("googlepubsub: check subscription %s: %w", sub.Name, err)
+		}
+		if config.Topic != nil && config.Topic.ID() != sub.Topic {
+			errs = append(errs, fmt.Errorf("%w: subscription %s bound to topic %s, want %s", pubsub.ErrTopologyMismatch, sub.Name, config.Topic.ID(), sub.Topic))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func ensureTopic(ctx context.Context, client *gcppubsub.Client, topic string) error {
+	exists, err := client.Topic(topic).Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("googlepubsub: check topic %s: %w", topic, err)
+	}
+	if exists {
+		return nil
+	}
+	if _, err := client.CreateTopic(ctx, topic); err != nil && status.Code(err) != codes.AlreadyExists {
+		return fmt.Errorf("googlepubsub: create topic %s: %w", topic, err)
+	}
+	return nil
+}
+
+func ensureSubscription(ctx context.Context, client *gcppubsub.Client, spec pubsub.SubscriptionSpec) error {
+	exists, err := client.Subscription(spec.Name).Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("googlepubsub: check subscription %s: %w", spec.Name, err)
+	}
+	if exists {
+		return nil
+	}
+	_, err = client.CreateSubscription(ctx, spec.Name, subscriptionConfig(client, spec))
+	if err != nil && status.Code(err) != codes.AlreadyExists {
+		return fmt.Errorf("googlepubsub: create subscription %s: %w", spec.Name, err)
+	}
+	return nil
+}
+
+func subscriptionConfig(client *gcppubsub.Client, spec pubsub.SubscriptionSpec) gcppubsub.SubscriptionConfig {
+	config := gcppubsub.SubscriptionConfig{
+		Topic:       client.Topic(spec.Topic),
+		AckDeadline: spec.AckDeadline,
+	}
+	if spec.DeadLetterTopic != "" {
+		config.DeadLetterPolicy = &gcppubsub.DeadLetterPolicy{
+			DeadLetterTopic:     client.Topic(spec.DeadLetterTopic).String(),
+			MaxDeliveryAttempts: spec.MaxDeliveryAttempts,
+		}
+	}
+	if policy := spec.RedeliveryPolicy; policy != nil {
+		config.RetryPolicy = &gcppubsub.RetryPolicy{}
+		if policy.MinBackoff > 0 {
+			config.RetryPolicy.MinimumBackoff = policy.MinBackoff
+		}
+		if policy.MaxBackoff > 0 {
+			config.RetryPolicy.MaximumBackoff = policy.MaxBackoff
+		}
+	}
+	return config
+}
+
+// ensure runs create once per name until it succeeds
+func (t *transport) ensure(key string, create func() error) error {
+	if _, ok := t.ensured.Load(key); ok {
+		return nil
+	}
+	if err := create(); err != nil {
+		return err
+	}
+	t.ensured.Store(key, struct{}{})
+	return nil
+}
+
 func (t *transport) Publish(ctx context.Context, topic string, env *pubsub.Envelope) (string, error) {
 	if topic == "" {
 		return "", errors.New("googlepubsub: topic required")
@@ -86,6 +259,11 @@ func (t *transport) Publish(ctx context.Context, topic string, env *pubsub.Envel
 	if env == nil {
 		env = &pubsub.Envelope{}
 	}
+	if t.autoCreate {
+		if err := t.ensure("topic:"+topic, func() error { return ensureTopic(ctx, t.client, topic) }); err != nil {
+			return "", err
+		}
+	}
 	gTopic := t.client.Topic(topic)
 	if env.OrderingKey != "" {
 		gTopic.EnableMessageOrdering = true
@@ -110,6 +288,14 @@ func (t *transport) Subscribe(ctx context.Context, subscription string, opts pub
 	if handler == nil {
 		return errors.New("googlepubsub: handler required")
 	}
+	if spec, ok := t.subscriptions[subscription]; ok && t.autoCreate {
+		err := t.ensure("subscription:"+subscription, func() error {
+			return t.EnsureTopology(ctx, pubsub.TopologySpec{Subscriptions: []pubsub.SubscriptionSpec{spec}})
+		})
+		if err != nil {
+			return err
+		}
+	}
 	sub := t.client.Subscription(subscription)
 	settings := sub.ReceiveSettings
 	if t.receive.NumGoroutines > 0 {
@@ -219,6 +405,26 @@ func (t *transport) Subscribe(ctx context.Context, subscription string, opts pub
 	return nil
 }
 
+// Seek rewinds a subscription to a time or snapshot, messages acked since
+// are redelivered. Seeking to a time requires retained acked messages or
+// messages published after the oldest unacked one.
+func (t *transport) Seek(ctx context.Context, subscription string, target pubsub.SeekTarget) error {
+	if subscription == "" {
+		return errors.New("googlepubsub: subscription required")
+	}
+	sub := t.client.Subscription(subscription)
+	if target.Snapshot != "" {
+		if err := sub.SeekToSnapshot(ctx, t.client.Snapshot(target.Snapshot)); err != nil {
+			return fmt.Errorf("googlepubsub: seek %s to snapshot %s: %w", subscription, target.Snapshot, err)
+		}
+		return nil
+	}
+	if err := sub.SeekToTime(ctx, target.Time); err != nil {
+		return fmt.Errorf("googlepubsub: seek %s to %s: %w", subscription, target.Time.Format(time.RFC3339), err)
+	}
+	return nil
+}
+
 func (t *transport) Close(context.Context) error {
 	if t.ownsClient {
 		return t.client.Close()
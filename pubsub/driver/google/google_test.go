@@ -2,6 +2,7 @@ package google_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -89,3 +90,231 @@ func TestTransportPublishSubscribe(t *testing.T) {
 		t.Fatalf("shutdown: %v", err)
 	}
 }
+
+func TestTransportEmulatorAutoCreate(t *testing.T) {
+	ctx := context.Background()
+	server := pstest.NewServer()
+	defer server.Close()
+
+	transport, err := google.New(ctx, google.Config{
+		ProjectID:    "test-project",
+		EmulatorHost: server.Addr,
+		Topology: pubsub.TopologySpec{
+			Subscriptions: []pubsub.SubscriptionSpec{{Name: "orders-sub", Topic: "orders-topic"}},
+		},
+		Receive: google.ReceiveSettings{NumGoroutines: 1},
+	})
+	if err != nil {
+		t.Fatalf("transport: %v", err)
+	}
+
+	client, err := pubsub.New(ctx, transport)
+	if err != nil {
+		t.Fatalf("pubsub client: %v", err)
+	}
+
+	received := make(chan string, 1)
+	subscription, err := client.Subscribe("orders-sub", pubsub.HandlerFunc(func(ctx context.Context, msg *pubsub.Message) error {
+		received <- string(msg.Data())
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	if _, err := client.Publish(ctx, "orders-topic", map[string]string{"id": "42"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	select {
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for message")
+	case data := <-received:
+		if data == "" {
+			t.Fatal("expected data")
+		}
+	}
+
+	// topics outside the topology are created on first publish
+	if _, err := client.Publish(ctx, "audit-topic", map[string]string{"id": "43"}); err != nil {
+		t.Fatalf("publish to new topic: %v", err)
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := subscription.Stop(stopCtx); err != nil {
+		t.Fatalf("stop subscription: %v", err)
+	}
+	if err := client.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+}
+
+func TestTransportSeek(t *testing.T) {
+	ctx := context.Background()
+	server := pstest.NewServer()
+	defer server.Close()
+
+	transport, err := google.New(ctx, google.Config{
+		ProjectID:    "test-project",
+		EmulatorHost: server.Addr,
+		Topology: pubsub.TopologySpec{
+			Subscriptions: []pubsub.SubscriptionSpec{{Name: "orders-sub", Topic: "orders-topic"}},
+		},
+		Receive: google.ReceiveSettings{NumGoroutines: 1},
+	})
+	if err != nil {
+		t.Fatalf("transport: %v", err)
+	}
+
+	client, err := pubsub.New(ctx, transport)
+	if err != nil {
+		t.Fatalf("pubsub client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	subscription, err := client.Subscribe("orders-sub", pubsub.HandlerFunc(func(ctx context.Context, msg *pubsub.Message) error {
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	// pstest loses the payload of the messages a seek redelivers, so only
+	// the calls reaching the server are checked here
+	if err := subscription.Seek(ctx, pubsub.SeekToTime(time.Now())); err != nil {
+		t.Fatalf("seek to time: %v", err)
+	}
+	if err := subscription.Seek(ctx, pubsub.SeekToSnapshot("before-incident")); err == nil {
+		t.Fatal("expected pstest to reject snapshot seeks")
+	}
+	if err := subscription.Seek(ctx, pubsub.SeekTarget{}); err == nil {
+		t.Fatal("expected error without target")
+	}
+}
+
+func TestTransportEnsureTopology(t *testing.T) {
+	ctx := context.Background()
+	server := pstest.NewServer()
+	defer server.Close()
+
+	conn, err := grpc.DialContext(ctx, server.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	gcpClient, err := gcppubsub.NewClient(ctx, "test-project", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer gcpClient.Close()
+
+	transport, err := google.New(ctx, google.Config{Client: gcpClient})
+	if err != nil {
+		t.Fatalf("transport: %v", err)
+	}
+	client, err := pubsub.New(ctx, transport)
+	if err != nil {
+		t.Fatalf("pubsub client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	spec := pubsub.TopologySpec{
+		Topics: []string{"audit-topic"},
+		Subscriptions: []pubsub.SubscriptionSpec{{
+			Name:                "orders-sub",
+			Topic:               "orders-topic",
+			AckDeadline:         30 * time.Second,
+			DeadLetterTopic:     "orders-dlq",
+			MaxDeliveryAttempts: 5,
+			RedeliveryPolicy:    &pubsub.RedeliveryPolicy{MinBackoff: time.Second, MaxBackoff: time.Minute},
+		}},
+	}
+
+	err = client.ValidateTopology(ctx, spec)
+	if !errors.Is(err, pubsub.ErrTopologyMismatch) {
+		t.Fatalf("expected mismatch before ensure, got %v", err)
+	}
+	if err := client.EnsureTopology(ctx, spec); err != nil {
+		t.Fatalf("ensure topology: %v", err)
+	}
+	if err := client.EnsureTopology(ctx, spec); err != nil {
+		t.Fatalf("ensure existing topology: %v", err)
+	}
+	if err := client.ValidateTopology(ctx, spec); err != nil {
+		t.Fatalf("validate topology: %v", err)
+	}
+
+	for _, topic := range []string{"audit-topic", "orders-topic", "orders-dlq"} {
+		if exists, err := gcpClient.Topic(topic).Exists(ctx); err != nil || !exists {
+			t.Fatalf("topic %s not created: %v", topic, err)
+		}
+	}
+	config, err := gcpClient.Subscription("orders-sub").Config(ctx)
+	if err != nil {
+		t.Fatalf("subscription config: %v", err)
+	}
+	if config.AckDeadline != 30*time.Second {
+		t.Fatalf("unexpected ack deadline %s", config.AckDeadline)
+	}
+	if config.DeadLetterPolicy == nil || config.DeadLetterPolicy.MaxDeliveryAttempts != 5 {
+		t.Fatalf("unexpected dead letter policy %+v", config.DeadLetterPolicy)
+	}
+	if config.RetryPolicy == nil {
+		t.Fatal("expected retry policy")
+	}
+
+	spec.Subscriptions[0].Topic = "audit-topic"
+	if err := client.ValidateTopology(ctx, spec); !errors.Is(err, pubsub.ErrTopologyMismatch) {
+		t.Fatalf("expected mismatch for another topic, got %v", err)
+	}
+}
+
+func TestTransportAutoCreateSubscriptionSpec(t *testing.T) {
+	ctx := context.Background()
+	server := pstest.NewServer()
+	defer server.Close()
+
+	conn, err := grpc.DialContext(ctx, server.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	gcpClient, err := gcppubsub.NewClient(ctx, "test-project", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer gcpClient.Close()
+
+	_, err = google.New(ctx, google.Config{
+		Client:     gcpClient,
+		AutoCreate: true,
+		Topology: pubsub.TopologySpec{
+			Subscriptions: []pubsub.SubscriptionSpec{{
+				Name:                "orders-sub",
+				Topic:               "orders-topic",
+				AckDeadline:         30 * time.Second,
+				DeadLetterTopic:     "orders-dlq",
+				MaxDeliveryAttempts: 5,
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("transport: %v", err)
+	}
+
+	if exists, err := gcpClient.Topic("orders-dlq").Exists(ctx); err != nil || !exists {
+		t.Fatalf("dead-letter topic not created: %v", err)
+	}
+	config, err := gcpClient.Subscription("orders-sub").Config(ctx)
+	if err != nil {
+		t.Fatalf("subscription config: %v", err)
+	}
+	if config.AckDeadline != 30*time.Second {
+		t.Fatalf("unexpected ack deadline %s", config.AckDeadline)
+	}
+	if config.DeadLetterPolicy == nil || config.DeadLetterPolicy.MaxDeliveryAttempts != 5 {
+		t.Fatalf("unexpected dead letter policy %+v", config.DeadLetterPolicy)
+	}
+}
@@ -0,0 +1,446 @@
+// Package memory provides an in-process pubsub.Transport for tests.
+//
+// Unlike driver/inmem, which hands messages straight to the handler, memory
+// mimics broker semantics: messages are queued per subscription, nacked or
+// expired messages are redelivered with an incremented Attempt, ordering keys
+// are delivered one at a time, and topics fan out to every subscription
+// attached to them. Faults can be injected with WithPublishError.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/infigaming-com/go-common/pubsub"
+)
+
+// ErrClosed is returned when publishing to a closed transport.
+var ErrClosed = errors.New("memory: transport closed")
+
+type Option func(*options)
+
+type options struct {
+	maxDeliveryAttempts int
+	redeliveryDelay     time.Duration
+	publishError        func(topic string, env *pubsub.Envelope) error
+}
+
+// WithMaxDeliveryAttempts drops a message after it has been delivered n times
+// without being acked, like a Pub/Sub dead letter policy. Zero (default) keeps
+// redelivering forever.
+func WithMaxDeliveryAttempts(n int) Option {
+	return func(o *options) {
+		if n >= 0 {
+			o.maxDeliveryAttempts = n
+		}
+	}
+}
+
+// WithRedeliveryDelay delays redelivery of nacked or expired messages.
+func WithRedeliveryDelay(d time.Duration) Option {
+	return func(o *options) {
+		if d >= 0 {
+			o.redeliveryDelay = d
+		}
+	}
+}
+
+// WithPublishError injects publish failures. fn is called for every publish and
+// a non-nil error is returned to the caller instead of enqueueing the message.
+func WithPublishError(fn func(topic string, env *pubsub.Envelope) error) Option {
+	return func(o *options) {
+		o.publishError = fn
+	}
+}
+
+// Stats reports delivery counters for a subscription.
+type Stats struct {
+	Pending   int
+	InFlight  int
+	Delivered int
+	Acked     int
+	Nacked    int
+	Expired   int
+	Dropped   int
+}
+
+type Transport struct {
+	opts options
+
+	mu        sync.Mutex
+	seq       int64
+	topics    map[string][]string // topic -> subscription names
+	subs      map[string]*subscription
+	published map[string][]pubsub.Envelope
+	done      chan struct{}
+	closed    bool
+}
+
+type subscription struct {
+	name     string
+	topic    string
+	queue    []*delivery
+	inflight map[*delivery]struct{}
+	keys     map[string]struct{} // ordering keys with a message in flight
+	notify   chan struct{}
+	stats    Stats
+}
+
+type delivery struct {
+	env      pubsub.Envelope
+	attempt  int
+	finished bool
+	timer    *time.Timer
+}
+
+func New(opts ...Option) *Transport {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Transport{
+		opts:      o,
+		topics:    map[string][]string{},
+		subs:      map[string]*subscription{},
+		published: map[string][]pubsub.Envelope{},
+		done:      make(chan struct{}),
+	}
+}
+
+// CreateSubscription attaches a named subscription to topic so that it
+// receives its own copy of every message published afterwards. Subscribing
+// to a name that was never created binds it to the topic of the same name.
+func (t *Transport) CreateSubscription(name, topic string) error {
+	if name == "" || topic == "" {
+		return errors.New("memory: subscription and topic required")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if sub, ok := t.subs[name]; ok {
+		if sub.topic != topic {
+			return fmt.Errorf("memory: subscription %q already bound to topic %q", name, sub.topic)
+		}
+		return nil
+	}
+	t.createLocked(name, topic)
+	return nil
+}
+
+// EnsureTopology creates the subscriptions of spec. Topics need no creation
+// and the subscription settings are ignored, see the transport options.
+func (t *Transport) EnsureTopology(ctx context.Context, spec pubsub.TopologySpec) error {
+	for _, sub := range spec.Subscriptions {
+		if err := t.CreateSubscription(sub.Name, sub.Topic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateTopology checks that the subscriptions of spec were created and
+// bound to their topic.
+func (t *Transport) ValidateTopology(ctx context.Context, spec pubsub.TopologySpec) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var errs []error
+	for _, want := range spec.Subscriptions {
+		sub, ok := t.subs[want.Name]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("%w: subscription %q missing", pubsub.ErrTopologyMismatch, want.Name))
+		case sub.topic != want.Topic:
+			errs = append(errs, fmt.Errorf("%w: subscription %q bound to topic %q, want %q", pubsub.ErrTopologyMismatch, want.Name, sub.topic, want.Topic))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *Transport) Publish(ctx context.Context, topic string, env *pubsub.Envelope) (string, error) {
+	if topic == "" {
+		return "", errors.New("memory: topic required")
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if env == nil {
+		env = &pubsub.Envelope{}
+	}
+	if t.opts.publishError != nil {
+		if err := t.opts.publishError(topic, env); err != nil {
+			return "", err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return "", ErrClosed
+	}
+	t.seq++
+	stored := pubsub.Envelope{
+		ID:          fmt.Sprintf("%d", t.seq),
+		Data:        append([]byte(nil), env.Data...),
+		Attributes:  clone(env.Attributes),
+		OrderingKey: env.OrderingKey,
+	}
+	t.published[topic] = append(t.published[topic], stored)
+
+	// Keep messages published before anyone subscribed, so tests do not race
+	// the asynchronous Subscribe of pubsub.Client.
+	if len(t.topics[topic]) == 0 {
+		t.createLocked(topic, topic)
+	}
+	for _, name := range t.topics[topic] {
+		sub := t.subs[name]
+		sub.queue = append(sub.queue, &delivery{env: stored})
+		sub.signal()
+	}
+	return stored.ID, nil
+}
+
+func (t *Transport) Subscribe(ctx context.Context, name string, opts pubsub.TransportSubscribeOptions, handler pubsub.TransportHandler) error {
+	if name == "" {
+		return errors.New("memory: subscription required")
+	}
+	if handler == nil {
+		return errors.New("memory: handler required")
+	}
+
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	sub, ok := t.subs[name]
+	if !ok {
+		sub = t.createLocked(name, name)
+	}
+	t.mu.Unlock()
+
+	for {
+		t.mu.Lock()
+		d := sub.next()
+		notify := sub.notify
+		if d != nil {
+			sub.stats.Delivered++
+			t.armDeadline(sub, d, opts.AckDeadline)
+		}
+		t.mu.Unlock()
+
+		if d == nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-t.done:
+				return nil
+			case <-notify:
+			}
+			continue
+		}
+
+		msg := t.message(sub, d)
+		if err := handler(ctx, msg); err != nil {
+			_ = msg.Nack()
+			return err
+		}
+	}
+}
+
+func (t *Transport) Close(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	for _, sub := range t.subs {
+		for d := range sub.inflight {
+			if d.timer != nil {
+				d.timer.Stop()
+			}
+		}
+	}
+	close(t.done)
+	return ctx.Err()
+}
+
+// Published returns the messages published to topic, in publish order.
+func (t *Transport) Published(topic string) []pubsub.Envelope {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]pubsub.Envelope, len(t.published[topic]))
+	copy(out, t.published[topic])
+	return out
+}
+
+// Stats returns the delivery counters of a subscription.
+func (t *Transport) Stats(name string) Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sub, ok := t.subs[name]
+	if !ok {
+		return Stats{}
+	}
+	stats := sub.stats
+	stats.Pending = len(sub.queue)
+	stats.InFlight = len(sub.inflight)
+	return stats
+}
+
+func (t *Transport) createLocked(name, topic string) *subscription {
+	sub := &subscription{
+		name:     name,
+		topic:    topic,
+		inflight: map[*delivery]struct{}{},
+		keys:     map[string]struct{}{},
+		notify:   make(chan struct{}),
+	}
+	t.subs[name] = sub
+	t.topics[topic] = append(t.topics[topic], name)
+	return sub
+}
+
+func (t *Transport) message(sub *subscription, d *delivery) *pubsub.TransportMessage {
+	done := make(chan struct{})
+	var once sync.Once
+	finish := func(redeliver bool) error {
+		once.Do(func() {
+			t.mu.Lock()
+			if !d.finished {
+				if redeliver {
+					sub.stats.Nacked++
+				} else {
+					sub.stats.Acked++
+				}
+				t.settle(sub, d, redeliver)
+			}
+			t.mu.Unlock()
+			close(done)
+		})
+		return nil
+	}
+
+	return &pubsub.TransportMessage{
+		Envelope: pubsub.Envelope{
+			ID:          d.env.ID,
+			Data:        append([]byte(nil), d.env.Data...),
+			Attributes:  clone(d.env.Attributes),
+			OrderingKey: d.env.OrderingKey,
+			Attempt:     d.attempt,
+		},
+		ReceivedAt: time.Now(),
+		Ack:        func() error { return finish(false) },
+		Nack:       func() error { return finish(true) },
+		Extend: func(deadline time.Duration) error {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if !d.finished && d.timer != nil {
+				d.timer.Reset(deadline)
+			}
+			return nil
+		},
+		Done: done,
+	}
+}
+
+// armDeadline redelivers d if it is neither acked nor nacked within ackDeadline.
+func (t *Transport) armDeadline(sub *subscription, d *delivery, ackDeadline time.Duration) {
+	if ackDeadline <= 0 {
+		return
+	}
+	d.timer = time.AfterFunc(ackDeadline, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if d.finished || t.closed {
+			return
+		}
+		sub.stats.Expired++
+		t.settle(sub, d, true)
+	})
+}
+
+// settle finishes a delivery and, when redeliver is set, queues a new attempt
+// of the same message. Must be called with t.mu held.
+func (t *Transport) settle(sub *subscription, d *delivery, redeliver bool) {
+	d.finished = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	delete(sub.inflight, d)
+
+	if !redeliver || t.closed {
+		sub.release(d)
+		return
+	}
+
+	next := &delivery{env: d.env, attempt: d.attempt + 1}
+	if t.opts.maxDeliveryAttempts > 0 && next.attempt >= t.opts.maxDeliveryAttempts {
+		sub.stats.Dropped++
+		sub.release(d)
+		return
+	}
+	if t.opts.redeliveryDelay <= 0 {
+		sub.requeue(d, next)
+		return
+	}
+	// The ordering key stays held during the delay so later messages with the
+	// same key cannot overtake the one being retried.
+	time.AfterFunc(t.opts.redeliveryDelay, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.closed {
+			return
+		}
+		sub.requeue(d, next)
+	})
+}
+
+// next pops the first message whose ordering key is not already in flight.
+func (s *subscription) next() *delivery {
+	for i, d := range s.queue {
+		if key := d.env.OrderingKey; key != "" {
+			if _, busy := s.keys[key]; busy {
+				continue
+			}
+			s.keys[key] = struct{}{}
+		}
+		s.queue = append(s.queue[:i], s.queue[i+1:]...)
+		s.inflight[d] = struct{}{}
+		return d
+	}
+	return nil
+}
+
+// requeue puts a redelivery at the front of the queue, so it stays ahead of
+// later messages sharing its ordering key.
+func (s *subscription) requeue(prev, next *delivery) {
+	s.queue = append([]*delivery{next}, s.queue...)
+	s.release(prev)
+}
+
+func (s *subscription) release(d *delivery) {
+	if d.env.OrderingKey != "" {
+		delete(s.keys, d.env.OrderingKey)
+	}
+	s.signal()
+}
+
+// signal wakes every Subscribe loop waiting on this subscription.
+func (s *subscription) signal() {
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+func clone(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,240 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/infigaming-com/go-common/pubsub/driver/memory"
+)
+
+type event struct {
+	ID string `json:"id"`
+}
+
+func newClient(t *testing.T, transport *memory.Transport) *pubsub.Client {
+	t.Helper()
+	client, err := pubsub.New(context.Background(), transport,
+		pubsub.WithRetryPolicy(pubsub.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+		pubsub.WithDeduplication(pubsub.DeduplicationConfig{Enabled: false}),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = client.Shutdown(ctx)
+	})
+	return client
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("condition not met before timeout")
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	transport := memory.New()
+	client := newClient(t, transport)
+	ctx := context.Background()
+
+	if _, err := client.Publish(ctx, "orders", event{ID: "1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	received := make(chan string, 1)
+	_, err := client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		var e event
+		if err := m.Decode(ctx, &e); err != nil {
+			return err
+		}
+		received <- e.ID
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	select {
+	case id := <-received:
+		if id != "1" {
+			t.Fatalf("unexpected id %q", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for message")
+	}
+	waitFor(t, func() bool { return transport.Stats("orders").Acked == 1 })
+
+	if got := len(transport.Published("orders")); got != 1 {
+		t.Fatalf("expected 1 published message, got %d", got)
+	}
+}
+
+func TestRedeliveryOnFailure(t *testing.T) {
+	transport := memory.New()
+	client := newClient(t, transport)
+
+	var (
+		mu       sync.Mutex
+		attempts []int
+	)
+	_, err := client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		mu.Lock()
+		attempts = append(attempts, m.Attempt())
+		mu.Unlock()
+		if m.Attempt() == 0 {
+			return errors.New("transient")
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, err := client.Publish(context.Background(), "orders", event{ID: "1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	waitFor(t, func() bool { return transport.Stats("orders").Acked == 1 })
+	stats := transport.Stats("orders")
+	if stats.Nacked != 1 || stats.Delivered != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 2 || attempts[0] != 0 || attempts[1] != 1 {
+		t.Fatalf("unexpected attempts %v", attempts)
+	}
+}
+
+func TestMaxDeliveryAttempts(t *testing.T) {
+	transport := memory.New(memory.WithMaxDeliveryAttempts(2))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := transport.Publish(ctx, "orders", &pubsub.Envelope{Data: []byte("x")}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	go func() {
+		_ = transport.Subscribe(ctx, "orders", pubsub.TransportSubscribeOptions{}, func(_ context.Context, m *pubsub.TransportMessage) error {
+			return m.Nack()
+		})
+	}()
+
+	waitFor(t, func() bool { return transport.Stats("orders").Dropped == 1 })
+	if stats := transport.Stats("orders"); stats.Delivered != 2 || stats.Pending != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestAckDeadlineExpiry(t *testing.T) {
+	transport := memory.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := transport.Publish(ctx, "orders", &pubsub.Envelope{}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	var deliveries atomic.Int32
+	go func() {
+		_ = transport.Subscribe(ctx, "orders", pubsub.TransportSubscribeOptions{AckDeadline: 20 * time.Millisecond}, func(_ context.Context, m *pubsub.TransportMessage) error {
+			if deliveries.Add(1) > 1 {
+				return m.Ack()
+			}
+			return nil
+		})
+	}()
+
+	waitFor(t, func() bool { return transport.Stats("orders").Acked == 1 })
+	if stats := transport.Stats("orders"); stats.Expired != 1 {
+		t.Fatalf("expected 1 expired delivery, got %+v", stats)
+	}
+}
+
+func TestOrderingKey(t *testing.T) {
+	transport := memory.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, data := range []string{"a1", "a2", "b1"} {
+		key := data[:1]
+		if _, err := transport.Publish(ctx, "orders", &pubsub.Envelope{Data: []byte(data), OrderingKey: key}); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	msgs := make(chan *pubsub.TransportMessage, 3)
+	go func() {
+		_ = transport.Subscribe(ctx, "orders", pubsub.TransportSubscribeOptions{}, func(_ context.Context, m *pubsub.TransportMessage) error {
+			msgs <- m
+			return nil
+		})
+	}()
+
+	first, second := <-msgs, <-msgs
+	if string(first.Data) != "a1" || string(second.Data) != "b1" {
+		t.Fatalf("a2 must wait for a1, got %q then %q", first.Data, second.Data)
+	}
+	select {
+	case m := <-msgs:
+		t.Fatalf("unexpected delivery %q before ack", m.Data)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	_ = first.Nack()
+	if m := <-msgs; string(m.Data) != "a1" || m.Attempt != 1 {
+		t.Fatalf("expected a1 redelivery, got %q attempt %d", m.Data, m.Attempt)
+	} else {
+		_ = m.Ack()
+	}
+	if m := <-msgs; string(m.Data) != "a2" {
+		t.Fatalf("expected a2, got %q", m.Data)
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	transport := memory.New()
+	for _, name := range []string{"orders-billing", "orders-audit"} {
+		if err := transport.CreateSubscription(name, "orders"); err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+	}
+	if _, err := transport.Publish(context.Background(), "orders", &pubsub.Envelope{}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	for _, name := range []string{"orders-billing", "orders-audit"} {
+		if stats := transport.Stats(name); stats.Pending != 1 {
+			t.Fatalf("%s: expected 1 pending message, got %+v", name, stats)
+		}
+	}
+}
+
+func TestPublishError(t *testing.T) {
+	var failures atomic.Int32
+	failures.Store(1)
+	transport := memory.New(memory.WithPublishError(func(string, *pubsub.Envelope) error {
+		if failures.Add(-1) >= 0 {
+			return errors.New("unavailable")
+		}
+		return nil
+	}))
+	client := newClient(t, transport)
+
+	if _, err := client.Publish(context.Background(), "orders", event{ID: "1"}); err != nil {
+		t.Fatalf("publish should succeed after retry: %v", err)
+	}
+	if got := len(transport.Published("orders")); got != 1 {
+		t.Fatalf("expected 1 published message, got %d", got)
+	}
+}
@@ -0,0 +1,412 @@
+// Package nats provides a pubsub.Transport over NATS JetStream.
+//
+// Topics are JetStream subjects and subscriptions are durable pull consumers
+// of Config.Stream, so the client's retry, dedupe and dead letter handling
+// work the same as with Google Pub/Sub. Ack maps to Ack, Nack to Nak and
+// Extend to InProgress, which restarts the consumer AckWait. Messages whose
+// handler panics are terminated with Term so a poison message is not
+// redelivered forever.
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/infigaming-com/go-common/pubsub"
+)
+
+// OrderingKeyHeader carries the pubsub ordering key of a message
+const OrderingKeyHeader = "Pubsub-Ordering-Key"
+
+type Config struct {
+	// URL of the NATS server, used when Conn is not provided
+	URL     string
+	Options []natsgo.Option
+	Conn    *natsgo.Conn
+	// Stream is the JetStream stream holding the consumers. Required to
+	// subscribe.
+	Stream string
+	// FilterSubjects maps a durable consumer name to the subject it filters on
+	// when Subscribe has to create it. Missing entries consume the whole stream.
+	FilterSubjects map[string]string
+	// MaxDeliver limits deliveries of consumers created by Subscribe. Zero
+	// keeps redelivering forever.
+	MaxDeliver int
+	Logger     pubsub.Logger
+	Consume    ConsumeSettings
+}
+
+type ConsumeSettings struct {
+	// MaxMessages is the number of messages buffered by the pull consumer
+	MaxMessages int
+}
+
+type transport struct {
+	conn       *natsgo.Conn
+	ownsConn   bool
+	js         jetstream.JetStream
+	stream     string
+	subjects   map[string]string
+	maxDeliver int
+	logger     pubsub.Logger
+	consume    ConsumeSettings
+}
+
+func New(ctx context.Context, cfg Config) (pubsub.Transport, error) {
+	conn := cfg.Conn
+	owns := false
+	if conn == nil {
+		if cfg.URL == "" {
+			return nil, errors.New("nats: url required when connection is not provided")
+		}
+		var err error
+		conn, err = natsgo.Connect(cfg.URL, cfg.Options...)
+		if err != nil {
+			return nil, fmt.Errorf("nats: connect: %w", err)
+		}
+		owns = true
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		if owns {
+			conn.Close()
+		}
+		return nil, fmt.Errorf("nats: create jetstream context: %w", err)
+	}
+
+	t := &transport{
+		conn:       conn,
+		ownsConn:   owns,
+		js:         js,
+		stream:     cfg.Stream,
+		subjects:   cfg.FilterSubjects,
+		maxDeliver: cfg.MaxDeliver,
+		logger:     cfg.Logger,
+		consume:    cfg.Consume,
+	}
+	if t.logger == nil {
+		t.logger = noopLogger{}
+	}
+	return t, nil
+}
+
+func (t *transport) Publish(ctx context.Context, topic string, env *pubsub.Envelope) (string, error) {
+	if topic == "" {
+		return "", errors.New("nats: topic required")
+	}
+	if env == nil {
+		env = &pubsub.Envelope{}
+	}
+	msg := &natsgo.Msg{
+		Subject: topic,
+		Data:    append([]byte(nil), env.Data...),
+		Header:  natsgo.Header{},
+	}
+	for k, v := range env.Attributes {
+		msg.Header[k] = []string{v}
+	}
+	if env.OrderingKey != "" {
+		msg.Header[OrderingKeyHeader] = []string{env.OrderingKey}
+	}
+
+	var opts []jetstream.PublishOpt
+	if env.ID != "" {
+		// lets the stream drop duplicates within its duplicate window
+		opts = append(opts, jetstream.WithMsgID(env.ID))
+	}
+	ack, err := t.js.PublishMsg(ctx, msg, opts...)
+	if err != nil {
+		return "", fmt.Errorf("nats: publish: %w", err)
+	}
+	return strconv.FormatUint(ack.Sequence, 10), nil
+}
+
+func (t *transport) Subscribe(ctx context.Context, subscription string, opts pubsub.TransportSubscribeOptions, handler pubsub.TransportHandler) error {
+	if subscription == "" {
+		return errors.New("nats: subscription required")
+	}
+	if handler == nil {
+		return errors.New("nats: handler required")
+	}
+	if t.stream == "" {
+		return errors.New("nats: stream required to subscribe")
+	}
+
+	consumer, err := t.consumer(ctx, subscription, opts)
+	if err != nil {
+		return err
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu         sync.Mutex
+		handlerErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if handlerErr == nil {
+			handlerErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	var consumeOpts []jetstream.PullConsumeOpt
+	if t.consume.MaxMessages > 0 {
+		consumeOpts = append(consumeOpts, jetstream.PullMaxMessages(t.consume.MaxMessages))
+	}
+
+	consumeCtx, err := consumer.Consume(func(m jetstream.Msg) {
+		tm, term := t.transportMessage(m)
+
+		defer func() {
+			if r := recover(); r != nil {
+				t.logger.Error(subCtx, "nats handler panic", "subscription", subscription, "panic", r)
+				_ = term()
+				fail(fmt.Errorf("nats: handler panic: %v", r))
+			}
+		}()
+
+		if err := handler(subCtx, tm); err != nil {
+			fail(err)
+		}
+	}, consumeOpts...)
+	if err != nil {
+		return fmt.Errorf("nats: consume %s: %w", subscription, err)
+	}
+	defer consumeCtx.Stop()
+
+	<-subCtx.Done()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if handlerErr != nil {
+		return handlerErr
+	}
+	return ctx.Err()
+}
+
+// consumer returns the durable consumer of the subscription, creating it if
+// it does not exist. Existing consumers are used as configured.
+func (t *transport) consumer(ctx context.Context, name string, opts pubsub.TransportSubscribeOptions) (jetstream.Consumer, error) {
+	consumer, err := t.js.Consumer(ctx, t.stream, name)
+	if err == nil {
+		return consumer, nil
+	}
+	if !errors.Is(err, jetstream.ErrConsumerNotFound) {
+		return nil, fmt.Errorf("nats: get consumer %s: %w", name, err)
+	}
+
+	consumer, err = t.js.CreateOrUpdateConsumer(ctx, t.stream, jetstream.ConsumerConfig{
+		Durable:       name,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       opts.AckDeadline,
+		MaxDeliver:    t.maxDeliver,
+		FilterSubject: t.subjects[name],
+	})
+	if err != nil {
+		return nil, fmt.Errorf("nats: create consumer %s: %w", name, err)
+	}
+	return consumer, nil
+}
+
+// EnsureTopology implements pubsub.TopologyManager on the stream of the
+// transport: topics are subjects of the stream, added to it or to a new
+// stream if no subject of the stream matches them, and subscriptions are
+// durable consumers filtering on their topic. Only the ack deadline of a
+// subscription spec is supported, dead-lettering is left to
+// pubsub.WithSubscriptionDeadLetter.
+func (t *transport) EnsureTopology(ctx context.Context, spec pubsub.TopologySpec) error {
+	if t.stream == "" {
+		return errors.New("nats: stream required")
+	}
+	stream, err := t.js.Stream(ctx, t.stream)
+	switch {
+	case errors.Is(err, jetstream.ErrStreamNotFound):
+		if len(spec.Topics) > 0 {
+			_, err = t.js.CreateStream(ctx, jetstream.StreamConfig{Name: t.stream, Subjects: spec.Topics})
+			if err != nil {
+				return fmt.Errorf("nats: create stream %s: %w", t.stream, err)
+			}
+		}
+	case err != nil:
+		return fmt.Errorf("nats: get stream %s: %w", t.stream, err)
+	default:
+		config := stream.CachedInfo().Config
+		if missing := unmatchedSubjects(config.Subjects, spec.Topics); len(missing) > 0 {
+			config.Subjects = append(config.Subjects, missing...)
+			if _, err := t.js.UpdateStream(ctx, config); err != nil {
+				return fmt.Errorf("nats: add subjects to stream %s: %w", t.stream, err)
+			}
+		}
+	}
+
+	for _, sub := range spec.Subscriptions {
+		_, err := t.js.Consumer(ctx, t.stream, sub.Name)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, jetstream.ErrConsumerNotFound) {
+			return fmt.Errorf("nats: get consumer %s: %w", sub.Name, err)
+		}
+		_, err = t.js.CreateConsumer(ctx, t.stream, jetstream.ConsumerConfig{
+			Durable:       sub.Name,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			AckWait:       sub.AckDeadline,
+			MaxDeliver:    t.maxDeliver,
+			FilterSubject: sub.Topic,
+		})
+		if err != nil && !errors.Is(err, jetstream.ErrConsumerExists) {
+			return fmt.Errorf("nats: create consumer %s: %w", sub.Name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateTopology implements pubsub.TopologyManager, checking that the
+// stream captures the topics and that the consumers filter on their topic or
+// consume the whole stream.
+func (t *transport) ValidateTopology(ctx context.Context, spec pubsub.TopologySpec) error {
+	if t.stream == "" {
+		return errors.New("nats: stream required")
+	}
+	stream, err := t.js.Stream(ctx, t.stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		return fmt.Errorf("%w: stream %s missing", pubsub.ErrTopologyMismatch, t.stream)
+	}
+	if err != nil {
+		return fmt.Errorf("nats: get stream %s: %w", t.stream, err)
+	}
+
+	var errs []error
+	for _, topic := range unmatchedSubjects(stream.CachedInfo().Config.Subjects, spec.Topics) {
+		errs = append(errs, fmt.Errorf("%w: topic %s not captured by stream %s", pubsub.ErrTopologyMismatch, topic, t.stream))
+	}
+	for _, sub := range spec.Subscriptions {
+		consumer, err := stream.Consumer(ctx, sub.Name)
+		if errors.Is(err, jetstream.ErrConsumerNotFound) {
+			errs = append(errs, fmt.Errorf("%w: consumer %s missing", pubsub.ErrTopologyMismatch, sub.Name))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("nats: get consumer %s: %w", sub.Name, err)
+		}
+		if filter := consumer.CachedInfo().Config.FilterSubject; filter != "" && !subjectMatches(filter, sub.Topic) {
+			errs = append(errs, fmt.Errorf("%w: consumer %s filters on %s, want %s", pubsub.ErrTopologyMismatch, sub.Name, filter, sub.Topic))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// unmatchedSubjects returns the subjects matched by none of the patterns
+func unmatchedSubjects(patterns, subjects []string) []string {
+	var unmatched []string
+	for _, subject := range subjects {
+		if !slices.ContainsFunc(patterns, func(pattern string) bool { return subjectMatches(pattern, subject) }) {
+			unmatched = append(unmatched, subject)
+		}
+	}
+	return unmatched
+}
+
+// subjectMatches reports whether pattern, which may use the * and >
+// wildcards, matches subject
+func subjectMatches(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, token := range patternTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) || (token != "*" && token != subjectTokens[i]) {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
+
+// transportMessage wraps m and also returns a func terminating it, settling
+// the message like Ack and Nack do
+func (t *transport) transportMessage(m jetstream.Msg) (*pubsub.TransportMessage, func() error) {
+	var (
+		once sync.Once
+		done = make(chan struct{})
+	)
+	settle := func(fn func() error) func() error {
+		return func() error {
+			var err error
+			once.Do(func() {
+				err = fn()
+				close(done)
+			})
+			return err
+		}
+	}
+
+	tm := &pubsub.TransportMessage{
+		Envelope: pubsub.Envelope{
+			Data:       append([]byte(nil), m.Data()...),
+			Attributes: attributes(m.Headers()),
+		},
+		ReceivedAt: time.Now(),
+		Ack:        settle(m.Ack),
+		Nack:       settle(m.Nak),
+		// InProgress restarts the AckWait of the consumer, the requested
+		// deadline cannot be chosen per message
+		Extend: func(time.Duration) error { return m.InProgress() },
+		Done:   done,
+	}
+	if headers := m.Headers(); headers != nil {
+		tm.OrderingKey = headers.Get(OrderingKeyHeader)
+	}
+	if meta, err := m.Metadata(); err == nil {
+		tm.ID = strconv.FormatUint(meta.Sequence.Stream, 10)
+		tm.ReceivedAt = meta.Timestamp
+		if meta.NumDelivered > 0 {
+			tm.Attempt = int(meta.NumDelivered - 1)
+		}
+	}
+	return tm, settle(m.Term)
+}
+
+func (t *transport) Close(context.Context) error {
+	if t.ownsConn {
+		t.conn.Close()
+	}
+	return nil
+}
+
+// attributes converts message headers back to pubsub attributes, dropping
+// the ones set by the transport or JetStream
+func attributes(headers natsgo.Header) map[string]string {
+	out := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if k == OrderingKeyHeader || k == jetstream.MsgIDHeader || len(v) == 0 {
+			continue
+		}
+		out[k] = v[0]
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(context.Context, string, ...any) {}
+func (noopLogger) Info(context.Context, string, ...any)  {}
+func (noopLogger) Warn(context.Context, string, ...any)  {}
+func (noopLogger) Error(context.Context, string, ...any) {}
@@ -0,0 +1,223 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/infigaming-com/go-common/pubsub/driver/nats"
+)
+
+type event struct {
+	ID string `json:"id"`
+}
+
+// newTransport starts an embedded JetStream server with an ORDERS stream
+// capturing orders.> and returns a transport bound to it
+func newTransport(t *testing.T, cfg nats.Config) (pubsub.Transport, jetstream.JetStream) {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{Port: -1, JetStream: true, StoreDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("nats server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+
+	conn, err := natsgo.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatalf("jetstream: %v", err)
+	}
+	if _, err := js.CreateStream(context.Background(), jetstream.StreamConfig{Name: "ORDERS", Subjects: []string{"orders.>"}}); err != nil {
+		t.Fatalf("create stream: %v", err)
+	}
+
+	cfg.Conn = conn
+	cfg.Stream = "ORDERS"
+	transport, err := nats.New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("transport: %v", err)
+	}
+	return transport, js
+}
+
+func newClient(t *testing.T, transport pubsub.Transport) *pubsub.Client {
+	t.Helper()
+	client, err := pubsub.New(context.Background(), transport,
+		pubsub.WithRetryPolicy(pubsub.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+		pubsub.WithDeduplication(pubsub.DeduplicationConfig{Enabled: false}),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = client.Shutdown(ctx)
+	})
+	return client
+}
+
+func TestTransportPublishSubscribe(t *testing.T) {
+	transport, js := newTransport(t, nats.Config{
+		FilterSubjects: map[string]string{"orders-created": "orders.created"},
+	})
+	client := newClient(t, transport)
+	ctx := context.Background()
+
+	received := make(chan *pubsub.Message, 1)
+	_, err := client.Subscribe("orders-created", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		received <- m
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	if _, err := client.Publish(ctx, "orders.cancelled", event{ID: "0"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if _, err := client.Publish(ctx, "orders.created", event{ID: "1"},
+		pubsub.WithAttributes(map[string]string{"tenant": "42"}),
+		pubsub.WithOrderingKey("user-1"),
+	); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	select {
+	case m := <-received:
+		var e event
+		if err := m.Decode(ctx, &e); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if e.ID != "1" {
+			t.Fatalf("expected only the filtered subject, got %q", e.ID)
+		}
+		if m.Attributes()["tenant"] != "42" || m.Attempt() != 0 || m.ID() != "2" {
+			t.Fatalf("unexpected message id %q attempt %d attributes %v", m.ID(), m.Attempt(), m.Attributes())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for message")
+	}
+
+	consumer, err := js.Consumer(ctx, "ORDERS", "orders-created")
+	if err != nil {
+		t.Fatalf("durable consumer not created: %v", err)
+	}
+	if got := consumer.CachedInfo().Config.AckPolicy; got != jetstream.AckExplicitPolicy {
+		t.Fatalf("unexpected ack policy %v", got)
+	}
+}
+
+func TestTransportRedeliveryOnFailure(t *testing.T) {
+	transport, _ := newTransport(t, nats.Config{})
+	client := newClient(t, transport)
+
+	var (
+		mu       sync.Mutex
+		attempts []int
+		done     = make(chan struct{})
+	)
+	_, err := client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts = append(attempts, m.Attempt())
+		if m.Attempt() == 0 {
+			return errors.New("transient")
+		}
+		close(done)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, err := client.Publish(context.Background(), "orders.created", event{ID: "1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for redelivery")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 2 || attempts[0] != 0 || attempts[1] != 1 {
+		t.Fatalf("unexpected attempts %v", attempts)
+	}
+}
+
+func TestTransportValidation(t *testing.T) {
+	if _, err := nats.New(context.Background(), nats.Config{}); err == nil {
+		t.Fatal("expected error without url or connection")
+	}
+
+	transport, _ := newTransport(t, nats.Config{})
+	handler := func(context.Context, *pubsub.TransportMessage) error { return nil }
+	if err := transport.Subscribe(context.Background(), "", pubsub.TransportSubscribeOptions{}, handler); err == nil {
+		t.Fatal("expected error for empty subscription")
+	}
+	if _, err := transport.Publish(context.Background(), "", nil); err == nil {
+		t.Fatal("expected error for empty topic")
+	}
+}
+
+func TestTransportEnsureTopology(t *testing.T) {
+	transport, js := newTransport(t, nats.Config{})
+	client := newClient(t, transport)
+	ctx := context.Background()
+
+	spec := pubsub.TopologySpec{
+		Topics: []string{"payments.settled"},
+		Subscriptions: []pubsub.SubscriptionSpec{
+			{Name: "orders-created", Topic: "orders.created", AckDeadline: 10 * time.Second},
+		},
+	}
+	if err := client.ValidateTopology(ctx, spec); !errors.Is(err, pubsub.ErrTopologyMismatch) {
+		t.Fatalf("expected mismatch before ensure, got %v", err)
+	}
+	if err := client.EnsureTopology(ctx, spec); err != nil {
+		t.Fatalf("ensure topology: %v", err)
+	}
+	if err := client.EnsureTopology(ctx, spec); err != nil {
+		t.Fatalf("ensure existing topology: %v", err)
+	}
+	if err := client.ValidateTopology(ctx, spec); err != nil {
+		t.Fatalf("validate topology: %v", err)
+	}
+
+	stream, err := js.Stream(ctx, "ORDERS")
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if subjects := stream.CachedInfo().Config.Subjects; len(subjects) != 2 {
+		t.Fatalf("expected payments.settled added next to orders.>, got %v", subjects)
+	}
+	consumer, err := stream.Consumer(ctx, "orders-created")
+	if err != nil {
+		t.Fatalf("consumer: %v", err)
+	}
+	if config := consumer.CachedInfo().Config; config.FilterSubject != "orders.created" || config.AckWait != 10*time.Second {
+		t.Fatalf("unexpected consumer config %+v", config)
+	}
+
+	spec.Subscriptions[0].Topic = "orders.cancelled"
+	if err := client.ValidateTopology(ctx, spec); !errors.Is(err, pubsub.ErrTopologyMismatch) {
+		t.Fatalf("expected mismatch for another topic, got %v", err)
+	}
+}
@@ -10,14 +10,18 @@ type Logger interface {
 }
 
 type Hooks struct {
-	OnReceive       func(ctx context.Context, topic string, meta MessageMetadata)
-	OnSuccess       func(ctx context.Context, topic string, meta MessageMetadata)
-	OnFailure       func(ctx context.Context, topic string, meta MessageMetadata, err error)
-	OnRetry         func(ctx context.Context, topic string, meta MessageMetadata, attempt int, delay string)
-	OnAckExtend     func(ctx context.Context, topic string, meta MessageMetadata, extendBy string)
-	OnPublish       func(ctx context.Context, topic string, meta map[string]string)
-	OnPublishFail   func(ctx context.Context, topic string, meta map[string]string, err error)
-	OnConnectionErr func(ctx context.Context, topic string, err error)
+	OnReceive         func(ctx context.Context, topic string, meta MessageMetadata)
+	OnSuccess         func(ctx context.Context, topic string, meta MessageMetadata)
+	OnFailure         func(ctx context.Context, topic string, meta MessageMetadata, err error)
+	OnRetry           func(ctx context.Context, topic string, meta MessageMetadata, attempt int, delay string)
+	OnAckExtend       func(ctx context.Context, topic string, meta MessageMetadata, extendBy string)
+	OnPublish         func(ctx context.Context, topic string, meta map[string]string)
+	OnPublishFail     func(ctx context.Context, topic string, meta map[string]string, err error)
+	OnConnectionErr   func(ctx context.Context, topic string, err error)
+	OnSchemaViolation func(ctx context.Context, topic string, meta map[string]string, err error)
+	// OnQuarantine is called when a poison message is quarantined, before
+	// OnFailure, see WithSubscriptionQuarantine
+	OnQuarantine func(ctx context.Context, topic string, meta MessageMetadata, err error)
 }
 
 type MessageMetadata struct {
@@ -3,14 +3,17 @@ package worker
 import (
 	"context"
 	"errors"
+	"hash/fnv"
 	"sync"
+	"sync/atomic"
 )
 
 var ErrClosed = errors.New("worker: pool closed")
 
 type Pool struct {
 	size   int
-	ch     chan job
+	chs    []chan job // one shared queue, or one queue per worker when keyed
+	next   atomic.Uint32
 	once   sync.Once
 	wg     sync.WaitGroup
 	mu     sync.RWMutex
@@ -31,21 +34,54 @@ func New(size int, queue int) *Pool {
 	}
 	p := &Pool{
 		size: size,
-		ch:   make(chan job, queue),
+		chs:  []chan job{make(chan job, queue)},
 	}
 	for i := 0; i < size; i++ {
-		p.wg.Add(1)
-		go func() {
-			defer p.wg.Done()
-			for j := range p.ch {
-				j.fn(j.ctx)
-			}
-		}()
+		p.start(p.chs[0])
 	}
 	return p
 }
 
+// NewKeyed creates a pool where every worker has its own queue. Jobs submitted
+// with the same key always run on the same worker, one after another, while
+// different keys still run in parallel. The queue capacity is split between
+// the workers.
+func NewKeyed(size int, queue int) *Pool {
+	if size <= 0 {
+		size = 1
+	}
+	perWorker := queue / size
+	if perWorker <= 0 {
+		perWorker = 1
+	}
+	p := &Pool{
+		size: size,
+		chs:  make([]chan job, size),
+	}
+	for i := range p.chs {
+		p.chs[i] = make(chan job, perWorker)
+		p.start(p.chs[i])
+	}
+	return p
+}
+
+func (p *Pool) start(ch chan job) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for j := range ch {
+			j.fn(j.ctx)
+		}
+	}()
+}
+
 func (p *Pool) Submit(ctx context.Context, fn func(context.Context)) error {
+	return p.SubmitKey(ctx, "", fn)
+}
+
+// SubmitKey queues fn on the worker owning key. An empty key, or a pool
+// created with New, picks any worker.
+func (p *Pool) SubmitKey(ctx context.Context, key string, fn func(context.Context)) error {
 	p.mu.RLock()
 	closed := p.closed
 	p.mu.RUnlock()
@@ -58,19 +94,33 @@ func (p *Pool) Submit(ctx context.Context, fn func(context.Context)) error {
 	default:
 	}
 	select {
-	case p.ch <- job{ctx: ctx, fn: fn}:
+	case p.queue(key) <- job{ctx: ctx, fn: fn}:
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
 	}
 }
 
+func (p *Pool) queue(key string) chan job {
+	if len(p.chs) == 1 {
+		return p.chs[0]
+	}
+	if key == "" {
+		return p.chs[int(p.next.Add(1))%len(p.chs)]
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return p.chs[h.Sum32()%uint32(len(p.chs))]
+}
+
 func (p *Pool) Close() {
 	p.once.Do(func() {
 		p.mu.Lock()
 		p.closed = true
 		p.mu.Unlock()
-		close(p.ch)
+		for _, ch := range p.chs {
+			close(ch)
+		}
 	})
 }
 
@@ -0,0 +1,182 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MetricsRecorder is the subset of observability/metrics.MetricExporter used
+// by MetricsHooks, so pubsub does not depend on OpenTelemetry directly.
+type MetricsRecorder interface {
+	RecordCounter(ctx context.Context, name, description, unit string, value int64, attributes map[string]string) error
+	RecordUpDownCounter(ctx context.Context, name, description, unit string, value int64, attributes map[string]string) error
+	RecordHistogram(ctx context.Context, name, description, unit string, value float64, attributes map[string]string) error
+}
+
+// MetricsHooks returns Hooks recording per topic counters, the number of
+// messages in flight (received but not yet acked or nacked) and the time from
+// receive to completion. Combine with other hooks using ChainHooks.
+//
+//	exporter, shutdown, err := metrics.NewMetricExporter(...)
+//	client, err := pubsub.New(ctx, transport, pubsub.WithHooks(pubsub.MetricsHooks(exporter)))
+func MetricsHooks(recorder MetricsRecorder) Hooks {
+	m := &metricsHooks{recorder: recorder}
+	return Hooks{
+		OnReceive:         m.onReceive,
+		OnSuccess:         m.onSuccess,
+		OnFailure:         m.onFailure,
+		OnRetry:           m.onRetry,
+		OnAckExtend:       m.onAckExtend,
+		OnPublish:         m.onPublish,
+		OnPublishFail:     m.onPublishFail,
+		OnConnectionErr:   m.onConnectionErr,
+		OnSchemaViolation: m.onSchemaViolation,
+		OnQuarantine:      m.onQuarantine,
+	}
+}
+
+type metricsHooks struct {
+	recorder MetricsRecorder
+	started  sync.Map // topic + "/" + message ID -> time.Time
+}
+
+func (m *metricsHooks) onReceive(ctx context.Context, topic string, meta MessageMetadata) {
+	m.started.Store(topic+"/"+meta.ID, time.Now())
+	m.count(ctx, "pubsub_messages_received_total", "Number of messages received", topic)
+	m.inFlight(ctx, topic, 1)
+}
+
+func (m *metricsHooks) onSuccess(ctx context.Context, topic string, meta MessageMetadata) {
+	m.count(ctx, "pubsub_messages_succeeded_total", "Number of messages processed successfully", topic)
+	m.complete(ctx, topic, meta, "success")
+}
+
+func (m *metricsHooks) onFailure(ctx context.Context, topic string, meta MessageMetadata, _ error) {
+	m.count(ctx, "pubsub_messages_failed_total", "Number of failed message processing attempts", topic)
+	m.complete(ctx, topic, meta, "failure")
+}
+
+func (m *metricsHooks) onRetry(ctx context.Context, topic string, _ MessageMetadata, _ int, _ string) {
+	m.count(ctx, "pubsub_messages_retried_total", "Number of messages nacked for redelivery", topic)
+}
+
+func (m *metricsHooks) onAckExtend(ctx context.Context, topic string, _ MessageMetadata, _ string) {
+	m.count(ctx, "pubsub_ack_extensions_total", "Number of ack deadline extensions", topic)
+}
+
+func (m *metricsHooks) onPublish(ctx context.Context, topic string, _ map[string]string) {
+	m.count(ctx, "pubsub_messages_published_total", "Number of messages published", topic)
+}
+
+func (m *metricsHooks) onPublishFail(ctx context.Context, topic string, _ map[string]string, _ error) {
+	m.count(ctx, "pubsub_publish_failures_total", "Number of failed publishes", topic)
+}
+
+func (m *metricsHooks) onConnectionErr(ctx context.Context, topic string, _ error) {
+	m.count(ctx, "pubsub_connection_errors_total", "Number of subscription stream errors", topic)
+}
+
+func (m *metricsHooks) onSchemaViolation(ctx context.Context, topic string, _ map[string]string, _ error) {
+	m.count(ctx, "pubsub_schema_violations_total", "Number of payloads rejected by schema validation", topic)
+}
+
+func (m *metricsHooks) onQuarantine(ctx context.Context, topic string, _ MessageMetadata, _ error) {
+	m.count(ctx, "pubsub_messages_quarantined_total", "Number of poison messages quarantined", topic)
+}
+
+func (m *metricsHooks) complete(ctx context.Context, topic string, meta MessageMetadata, result string) {
+	m.inFlight(ctx, topic, -1)
+	if v, ok := m.started.LoadAndDelete(topic + "/" + meta.ID); ok {
+		_ = m.recorder.RecordHistogram(ctx, "pubsub_message_duration_seconds",
+			"Time from receive to ack or nack, including time queued for a worker", "s",
+			time.Since(v.(time.Time)).Seconds(),
+			map[string]string{"topic": topic, "result": result})
+	}
+}
+
+func (m *metricsHooks) count(ctx context.Context, name, description, topic string) {
+	_ = m.recorder.RecordCounter(ctx, name, description, "1", 1, map[string]string{"topic": topic})
+}
+
+func (m *metricsHooks) inFlight(ctx context.Context, topic string, delta int64) {
+	_ = m.recorder.RecordUpDownCounter(ctx, "pubsub_messages_in_flight",
+		"Number of messages received and not yet acked or nacked", "1", delta,
+		map[string]string{"topic": topic})
+}
+
+// ChainHooks combines several Hooks into one calling each of them in order.
+func ChainHooks(hooks ...Hooks) Hooks {
+	return Hooks{
+		OnReceive: func(ctx context.Context, topic string, meta MessageMetadata) {
+			for _, h := range hooks {
+				if h.OnReceive != nil {
+					h.OnReceive(ctx, topic, meta)
+				}
+			}
+		},
+		OnSuccess: func(ctx context.Context, topic string, meta MessageMetadata) {
+			for _, h := range hooks {
+				if h.OnSuccess != nil {
+					h.OnSuccess(ctx, topic, meta)
+				}
+			}
+		},
+		OnFailure: func(ctx context.Context, topic string, meta MessageMetadata, err error) {
+			for _, h := range hooks {
+				if h.OnFailure != nil {
+					h.OnFailure(ctx, topic, meta, err)
+				}
+			}
+		},
+		OnRetry: func(ctx context.Context, topic string, meta MessageMetadata, attempt int, delay string) {
+			for _, h := range hooks {
+				if h.OnRetry != nil {
+					h.OnRetry(ctx, topic, meta, attempt, delay)
+				}
+			}
+		},
+		OnAckExtend: func(ctx context.Context, topic string, meta MessageMetadata, extendBy string) {
+			for _, h := range hooks {
+				if h.OnAckExtend != nil {
+					h.OnAckExtend(ctx, topic, meta, extendBy)
+				}
+			}
+		},
+		OnPublish: func(ctx context.Context, topic string, meta map[string]string) {
+			for _, h := range hooks {
+				if h.OnPublish != nil {
+					h.OnPublish(ctx, topic, meta)
+				}
+			}
+		},
+		OnPublishFail: func(ctx context.Context, topic string, meta map[string]string, err error) {
+			for _, h := range hooks {
+				if h.OnPublishFail != nil {
+					h.OnPublishFail(ctx, topic, meta, err)
+				}
+			}
+		},
+		OnConnectionErr: func(ctx context.Context, topic string, err error) {
+			for _, h := range hooks {
+				if h.OnConnectionErr != nil {
+					h.OnConnectionErr(ctx, topic, err)
+				}
+			}
+		},
+		OnSchemaViolation: func(ctx context.Context, topic string, meta map[string]string, err error) {
+			for _, h := range hooks {
+				if h.OnSchemaViolation != nil {
+					h.OnSchemaViolation(ctx, topic, meta, err)
+				}
+			}
+		},
+		OnQuarantine: func(ctx context.Context, topic string, meta MessageMetadata, err error) {
+			for _, h := range hooks {
+				if h.OnQuarantine != nil {
+					h.OnQuarantine(ctx, topic, meta, err)
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type recordingMetrics struct {
+	mu         sync.Mutex
+	counters   map[string]int64
+	histograms map[string]int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{counters: map[string]int64{}, histograms: map[string]int{}}
+}
+
+func (r *recordingMetrics) RecordCounter(_ context.Context, name, _, _ string, value int64, attrs map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[name+"/"+attrs["topic"]] += value
+	return nil
+}
+
+func (r *recordingMetrics) RecordUpDownCounter(ctx context.Context, name, description, unit string, value int64, attrs map[string]string) error {
+	return r.RecordCounter(ctx, name, description, unit, value, attrs)
+}
+
+func (r *recordingMetrics) RecordHistogram(_ context.Context, name, _, _ string, _ float64, attrs map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.histograms[name+"/"+attrs["topic"]+"/"+attrs["result"]]++
+	return nil
+}
+
+func TestMetricsHooks(t *testing.T) {
+	recorder := newRecordingMetrics()
+	var chained int
+	hooks := ChainHooks(MetricsHooks(recorder), Hooks{
+		OnSuccess: func(context.Context, string, MessageMetadata) { chained++ },
+	})
+	ctx := context.Background()
+
+	hooks.OnReceive(ctx, "orders", MessageMetadata{ID: "1"})
+	hooks.OnReceive(ctx, "orders", MessageMetadata{ID: "2"})
+	hooks.OnSuccess(ctx, "orders", MessageMetadata{ID: "1"})
+	hooks.OnRetry(ctx, "orders", MessageMetadata{ID: "2"}, 1, "")
+	hooks.OnFailure(ctx, "orders", MessageMetadata{ID: "2"}, errors.New("boom"))
+
+	want := map[string]int64{
+		"pubsub_messages_received_total/orders":  2,
+		"pubsub_messages_succeeded_total/orders": 1,
+		"pubsub_messages_failed_total/orders":    1,
+		"pubsub_messages_retried_total/orders":   1,
+		"pubsub_messages_in_flight/orders":       0,
+	}
+	for name, value := range want {
+		if got := recorder.counters[name]; got != value {
+			t.Errorf("%s: expected %d, got %d", name, value, got)
+		}
+	}
+	if recorder.histograms["pubsub_message_duration_seconds/orders/success"] != 1 ||
+		recorder.histograms["pubsub_message_duration_seconds/orders/failure"] != 1 {
+		t.Errorf("unexpected histograms %v", recorder.histograms)
+	}
+	if chained != 1 {
+		t.Errorf("expected chained hook to run once, got %d", chained)
+	}
+}
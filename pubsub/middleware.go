@@ -0,0 +1,156 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrHandlerPanic is wrapped by the error RecoverMiddleware returns for a
+// handler that panicked.
+var ErrHandlerPanic = errors.New("pubsub: handler panic")
+
+// tracerName is the instrumentation name of the default TracingMiddleware
+// tracer
+const tracerName = "github.com/infigaming-com/go-common/pubsub"
+
+// HandlerMiddleware wraps a Handler with cross-cutting behaviour. Middlewares
+// are applied in order around the user handler: the first one sees the
+// message first and the handler error last.
+type HandlerMiddleware func(next Handler) Handler
+
+// WithHandlerMiddleware wraps the handler of every subscription of the client.
+// Client middlewares run outside those set with
+// WithSubscriptionHandlerMiddleware.
+func WithHandlerMiddleware(middlewares ...HandlerMiddleware) Option {
+	return func(o *options) {
+		o.handlerMiddlewares = append(o.handlerMiddlewares, middlewares...)
+	}
+}
+
+// WithSubscriptionHandlerMiddleware wraps the handler of the subscription.
+func WithSubscriptionHandlerMiddleware(middlewares ...HandlerMiddleware) SubscriptionOption {
+	return func(o *subscriptionOptions) {
+		o.handlerMiddlewares = append(o.handlerMiddlewares, middlewares...)
+	}
+}
+
+// chainHandler wraps handler with middlewares, the first one outermost
+func chainHandler(handler Handler, middlewares ...HandlerMiddleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+type topicContextKey struct{}
+
+// TopicFromContext returns the topic of the message being handled, for use in
+// handlers and middlewares.
+func TopicFromContext(ctx context.Context) string {
+	topic, _ := ctx.Value(topicContextKey{}).(string)
+	return topic
+}
+
+func contextWithTopic(ctx context.Context, topic string) context.Context {
+	return context.WithValue(ctx, topicContextKey{}, topic)
+}
+
+// RecoverMiddleware turns a handler panic into an error wrapping
+// ErrHandlerPanic, logged with its stack trace, so the message is retried and
+// eventually dead-lettered instead of crashing the worker. Place it first so
+// it also covers the other middlewares.
+func RecoverMiddleware(logger Logger) HandlerMiddleware {
+	if logger == nil {
+		logger = noopLogger{}
+	}
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error(ctx, "handler panic", "topic", TopicFromContext(ctx), "message", msg.ID(), "panic", r, "stack", string(debug.Stack()))
+					err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+				}
+			}()
+			return next.Handle(ctx, msg)
+		})
+	}
+}
+
+// LoggingMiddleware logs every handled message with its topic, ID, attempt
+// and duration, at debug level on success and warn level on failure.
+func LoggingMiddleware(logger Logger) HandlerMiddleware {
+	if logger == nil {
+		logger = noopLogger{}
+	}
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			start := time.Now()
+			err := next.Handle(ctx, msg)
+			kv := []any{"topic", TopicFromContext(ctx), "message", msg.ID(), "attempt", msg.Attempt(), "duration", time.Since(start)}
+			if err != nil {
+				logger.Warn(ctx, "message handler failed", append(kv, "err", err)...)
+				return err
+			}
+			logger.Debug(ctx, "message handled", kv...)
+			return nil
+		})
+	}
+}
+
+// TracingMiddleware starts a consumer span for every handled message, as a
+// child of the trace context propagated in the message attributes (e.g. a
+// traceparent attribute) when there is one. The span is named after the topic
+// and records the handler error. A nil tracer uses the global tracer provider.
+func TracingMiddleware(tracer trace.Tracer) HandlerMiddleware {
+	if tracer == nil {
+		tracer = otel.Tracer(tracerName)
+	}
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			topic := TopicFromContext(ctx)
+			ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(msg.Attributes()))
+			ctx, span := tracer.Start(ctx, topic+" process",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("messaging.operation.type", "process"),
+					attribute.String("messaging.destination.name", topic),
+					attribute.String("messaging.message.id", msg.ID()),
+					attribute.Int("messaging.delivery_attempt", msg.Attempt()),
+				),
+			)
+			defer span.End()
+
+			err := next.Handle(ctx, msg)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		})
+	}
+}
+
+// SchemaValidationMiddleware rejects messages whose payload does not match
+// schema, e.g. one built with NewJSONSchema, before they reach the handler.
+// Invalid messages fail permanently with an error wrapping
+// ErrSchemaViolation and are dead-lettered. Use WithSchemaRegistry instead to
+// validate published messages as well.
+func SchemaValidationMiddleware(schema Schema) HandlerMiddleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			if err := schema.Validate(msg.Data()); err != nil {
+				return ErrPermanent(fmt.Errorf("%w: %s: %v", ErrSchemaViolation, TopicFromContext(ctx), err))
+			}
+			return next.Handle(ctx, msg)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func orderMiddleware(name string, calls *[]string, mu *sync.Mutex) HandlerMiddleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, msg *Message) error {
+			mu.Lock()
+			*calls = append(*calls, name)
+			mu.Unlock()
+			return next.Handle(ctx, msg)
+		})
+	}
+}
+
+// TestSubscription_HandlerMiddleware verifies that client middlewares wrap the
+// subscription ones, in order, and that a panicking handler is recovered and
+// dead-lettered once its attempts are exhausted.
+func TestSubscription_HandlerMiddleware(t *testing.T) {
+	var acked atomic.Int32
+	transport := &publishingTransport{}
+	transport.subscribeFn = func(ctx context.Context, h TransportHandler) error {
+		msg := &TransportMessage{
+			Envelope: Envelope{ID: "a", Data: []byte(`{}`), Attempt: 4},
+			Ack:      func() error { acked.Add(1); return nil },
+			Nack:     func() error { return nil },
+		}
+		if err := h(ctx, msg); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	var failures atomic.Int32
+	logger := &recordingLogger{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, err := New(ctx, transport, WithLogger(logger),
+		WithHandlerMiddleware(RecoverMiddleware(logger), orderMiddleware("client", &calls, &mu)),
+		WithHooks(Hooks{
+			OnFailure: func(_ context.Context, _ string, _ MessageMetadata, err error) {
+				if errors.Is(err, ErrHandlerPanic) {
+					failures.Add(1)
+				}
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var topic atomic.Value
+	sub, err := client.Subscribe("orders", HandlerFunc(func(ctx context.Context, _ *Message) error {
+		topic.Store(TopicFromContext(ctx))
+		panic("boom")
+	}), WithSubscriptionHandlerMiddleware(orderMiddleware("subscription", &calls, &mu)),
+		WithSubscriptionDeadLetter("orders-dlq"), WithSubscriptionInactivityTimeout(-1))
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer func() { _ = sub.Stop(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for acked.Load() < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("message not acked")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	if got := strings.Join(calls, ","); got != "client,subscription" {
+		t.Errorf("unexpected middleware order %q", got)
+	}
+	mu.Unlock()
+	if got, _ := topic.Load().(string); got != "orders" {
+		t.Errorf("expected topic in handler context, got %q", got)
+	}
+	if failures.Load() != 1 {
+		t.Errorf("expected 1 panic failure, got %d", failures.Load())
+	}
+	if got := transport.topics(); len(got) != 1 || got[0] != "orders-dlq" {
+		t.Errorf("expected dead letter publish, got %v", got)
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	logger := &recordingLogger{}
+	handlerErr := errors.New("failed")
+	handler := chainHandler(HandlerFunc(func(context.Context, *Message) error { return handlerErr }), LoggingMiddleware(logger))
+
+	msg := newMessage(&TransportMessage{Envelope: Envelope{ID: "a"}}, jsonCodec{})
+	if err := handler.Handle(contextWithTopic(context.Background(), "orders"), msg); !errors.Is(err, handlerErr) {
+		t.Fatalf("expected handler error, got %v", err)
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.entries) != 1 || logger.entries[0].level != "warn" {
+		t.Fatalf("expected one warn entry, got %+v", logger.entries)
+	}
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+
+	// propagate the trace context of a parent span in the attributes
+	parentCtx, parent := tracer.Start(context.Background(), "publish")
+	attributes := map[string]string{}
+	propagation.TraceContext{}.Inject(parentCtx, propagation.MapCarrier(attributes))
+	parent.End()
+
+	msg := newMessage(&TransportMessage{Envelope: Envelope{ID: "a", Attributes: attributes}}, jsonCodec{})
+	handlerErr := errors.New("failed")
+	handler := chainHandler(HandlerFunc(func(context.Context, *Message) error { return handlerErr }), TracingMiddleware(tracer))
+
+	// the middleware extracts with the global propagator, a no-op by default
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previous)
+
+	err := handler.Handle(contextWithTopic(context.Background(), "orders"), msg)
+	if !errors.Is(err, handlerErr) {
+		t.Fatalf("expected handler error, got %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	span := spans[1]
+	if span.Name() != "orders process" {
+		t.Errorf("unexpected span name %q", span.Name())
+	}
+	if span.Parent().TraceID() != parent.SpanContext().TraceID() {
+		t.Error("span is not a child of the propagated trace")
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("expected error status, got %v", span.Status().Code)
+	}
+}
+
+func TestSchemaValidationMiddleware(t *testing.T) {
+	schema, err := NewJSONSchema([]byte(orderSchema))
+	if err != nil {
+		t.Fatalf("NewJSONSchema: %v", err)
+	}
+	var handled int
+	handler := chainHandler(HandlerFunc(func(context.Context, *Message) error {
+		handled++
+		return nil
+	}), SchemaValidationMiddleware(schema))
+
+	valid := newMessage(&TransportMessage{Envelope: Envelope{Data: []byte(`{"id":1,"amount":2.5}`)}}, jsonCodec{})
+	if err := handler.Handle(context.Background(), valid); err != nil {
+		t.Fatalf("valid message rejected: %v", err)
+	}
+	invalid := newMessage(&TransportMessage{Envelope: Envelope{Data: []byte(`{"id":1}`)}}, jsonCodec{})
+	err = handler.Handle(context.Background(), invalid)
+	if !errors.Is(err, ErrSchemaViolation) || !isPermanent(err) {
+		t.Fatalf("expected permanent schema violation, got %v", err)
+	}
+	if handled != 1 {
+		t.Errorf("expected handler to see 1 message, got %d", handled)
+	}
+}
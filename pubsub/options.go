@@ -24,6 +24,12 @@ type options struct {
 	encoder                  Encoder
 	decoder                  Decoder
 	dedupe                   DeduplicationConfig
+	schemaRegistry           SchemaRegistry
+	handlerMiddlewares       []HandlerMiddleware
+	archiveTopic             string
+	maxPayloadSize           int
+	payloadStore             PayloadStore
+	offloadThreshold         int
 }
 
 type subscriptionOptions struct {
@@ -57,13 +63,25 @@ type subscriptionOptions struct {
 	// dedupeStore, if set, is consulted before in-memory dedupe and replaces
 	// it. Use for shared (Redis-backed) dedupe across pods / restarts.
 	dedupeStore DedupeStore
+	// keyOrdering routes messages sharing an ordering key to the same worker
+	// so they are handled one at a time.
+	keyOrdering bool
+	// handlerMiddlewares wrap the handler inside the client middlewares.
+	handlerMiddlewares []HandlerMiddleware
+	// replayArchive is where an emulated Seek replays from.
+	replayArchive *ReplayArchive
+	// quarantine routes poison messages to its topic, if set.
+	quarantine QuarantinePolicy
+	// maxParked bounds the delayed messages held until they are due.
+	maxParked int
 }
 
 type publishOptions struct {
-	orderingKey string
-	attributes  map[string]string
-	retryPolicy RetryPolicy
-	encoder     Encoder
+	orderingKey   string
+	attributes    map[string]string
+	retryPolicy   RetryPolicy
+	encoder       Encoder
+	deliveryDelay time.Duration
 }
 
 type RetryPolicy struct {
@@ -112,6 +130,7 @@ func defaultSubscriptionOptions(parent options, topic string) subscriptionOption
 		inactivityTimeout:     parent.defaultInactivityTimeout,
 		retryPolicy:           parent.retryPolicy,
 		dedupe:                parent.dedupe,
+		maxParked:             defaultMaxParked,
 	}
 }
 
@@ -211,6 +230,15 @@ func WithRetryPolicy(policy RetryPolicy) Option {
 	}
 }
 
+// WithSchemaRegistry validates payloads against the schema registered for
+// their topic. Invalid messages are rejected by Publish and dead-lettered on
+// consume; both report through Hooks.OnSchemaViolation.
+func WithSchemaRegistry(registry SchemaRegistry) Option {
+	return func(o *options) {
+		o.schemaRegistry = registry
+	}
+}
+
 func WithEncoder(enc Encoder) Option {
 	return func(o *options) {
 		o.encoder = enc
@@ -261,6 +289,18 @@ func WithSubscriptionConcurrency(n int) SubscriptionOption {
 	}
 }
 
+// WithSubscriptionMaxParked bounds how many messages published with
+// WithDeliveryDelay the subscription holds at once until they are due, each
+// holding a goroutine and a broker lease. Messages beyond the limit are nacked
+// for the broker to redeliver. Defaults to 1000.
+func WithSubscriptionMaxParked(n int) SubscriptionOption {
+	return func(o *subscriptionOptions) {
+		if n > 0 {
+			o.maxParked = n
+		}
+	}
+}
+
 func WithSubscriptionBuffer(n int) SubscriptionOption {
 	return func(o *subscriptionOptions) {
 		if n > 0 {
@@ -332,6 +372,20 @@ func WithSubscriptionInactivityTimeout(d time.Duration) SubscriptionOption {
 	}
 }
 
+// WithSubscriptionKeyOrdering processes messages with the same ordering key
+// sequentially, in delivery order, by hashing each key to a fixed worker of
+// the pool. Messages with different keys, or without a key, are still handled
+// in parallel. A slow message delays the other keys sharing its worker, so
+// size WithSubscriptionConcurrency for the expected number of hot keys.
+//
+// The broker must deliver same-key messages in order for the processing order
+// to be meaningful (e.g. GCP Pub/Sub with message ordering enabled).
+func WithSubscriptionKeyOrdering() SubscriptionOption {
+	return func(o *subscriptionOptions) {
+		o.keyOrdering = true
+	}
+}
+
 func WithOrderingKey(key string) PublishOption {
 	return func(o *publishOptions) {
 		o.orderingKey = key
@@ -0,0 +1,156 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Diagnostic attributes added to the messages published to the quarantine
+// topic of WithSubscriptionQuarantine, along with their original attributes
+const (
+	QuarantineSourceAttribute    = "pubsub_quarantine_source"
+	QuarantineMessageIDAttribute = "pubsub_quarantine_message_id"
+	// QuarantineReasonAttribute is "panic" or "timeout"
+	QuarantineReasonAttribute  = "pubsub_quarantine_reason"
+	QuarantineStrikesAttribute = "pubsub_quarantine_strikes"
+	QuarantineAttemptAttribute = "pubsub_quarantine_attempt"
+	// QuarantineErrorAttribute holds the last handler error, truncated to
+	// maxQuarantineErrorLen bytes
+	QuarantineErrorAttribute = "pubsub_quarantine_error"
+	QuarantinedAtAttribute   = "pubsub_quarantined_at"
+)
+
+// maxQuarantineErrorLen bounds the error attribute, brokers limiting the size
+// of attribute values
+const maxQuarantineErrorLen = 1024
+
+// Strike reasons of poison messages
+const (
+	strikePanic   = "panic"
+	strikeTimeout = "timeout"
+)
+
+// QuarantinePolicy routes poison messages, which repeatedly crash or time out
+// their handler, to a quarantine topic before they use up the retry budget,
+// each attempt tying up a worker for up to the process timeout.
+type QuarantinePolicy struct {
+	// Topic receives the poison messages with their original data and
+	// attributes plus the Quarantine*Attribute diagnostics
+	Topic string
+	// Strikes is the number of panics and timeouts after which a message is
+	// quarantined, 2 by default
+	Strikes int
+	// Window is how long the strikes of a message are remembered since its
+	// last one, 1 hour by default
+	Window time.Duration
+}
+
+func (p QuarantinePolicy) normalized() QuarantinePolicy {
+	if p.Strikes <= 0 {
+		p.Strikes = 2
+	}
+	if p.Window <= 0 {
+		p.Window = time.Hour
+	}
+	return p
+}
+
+// StrikeCounter is implemented by the DedupeStores able to count the strikes
+// of poison messages, like the in-memory and Redis ones, so that they are
+// tracked by message ID across redeliveries, and across pods for a shared
+// store.
+type StrikeCounter interface {
+	// Strike records a strike of id and returns its number of strikes within
+	// the ttl window
+	Strike(ctx context.Context, id string, ttl time.Duration) (int, error)
+}
+
+// WithSubscriptionQuarantine enables poison message detection: handler
+// panics, turned into errors by RecoverMiddleware, and handler timeouts count
+// as strikes of the message, and a message reaching policy.Strikes is
+// published to policy.Topic and acked instead of being retried. Other errors
+// are retried and dead-lettered as usual.
+//
+// Strikes are counted by the dedupe store of the subscription when it
+// implements StrikeCounter, e.g. NewRedisDedupeStore, and in memory
+// otherwise.
+func WithSubscriptionQuarantine(policy QuarantinePolicy) SubscriptionOption {
+	return func(o *subscriptionOptions) {
+		o.quarantine = policy.normalized()
+	}
+}
+
+// strikeReason returns why err makes the message a poison candidate, or an
+// empty string
+func strikeReason(err error) string {
+	switch {
+	case errors.Is(err, ErrHandlerPanic):
+		return strikePanic
+	case errors.Is(err, context.DeadlineExceeded):
+		return strikeTimeout
+	default:
+		return ""
+	}
+}
+
+// quarantine counts a strike of msg for the handler error err and publishes
+// it to the quarantine topic once it has enough strikes. It returns true if
+// the message was quarantined and acked.
+func (s *subscription) quarantine(ctx context.Context, msg *Message, meta MessageMetadata, err error) bool {
+	policy := s.options.quarantine
+	reason := strikeReason(err)
+	if policy.Topic == "" || reason == "" {
+		return false
+	}
+	// The context of a timed out handler is done
+	ctx = context.WithoutCancel(ctx)
+
+	strikes, strikeErr := s.strikes.Strike(ctx, msg.ID(), policy.Window)
+	if strikeErr != nil {
+		s.logger.Warn(ctx, "subscription strike count failed", "topic", s.Topic(), "message", msg.ID(), "err", strikeErr)
+		return false
+	}
+	if strikes < policy.Strikes {
+		return false
+	}
+
+	errText := err.Error()
+	if len(errText) > maxQuarantineErrorLen {
+		errText = errText[:maxQuarantineErrorLen]
+	}
+	attributes := msg.Attributes()
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+	attributes[QuarantineSourceAttribute] = s.Topic()
+	attributes[QuarantineMessageIDAttribute] = msg.ID()
+	attributes[QuarantineReasonAttribute] = reason
+	attributes[QuarantineStrikesAttribute] = strconv.Itoa(strikes)
+	attributes[QuarantineAttemptAttribute] = strconv.Itoa(meta.Attempt)
+	attributes[QuarantineErrorAttribute] = errText
+	attributes[QuarantinedAtAttribute] = time.Now().UTC().Format(time.RFC3339)
+	env := &Envelope{Data: msg.Data(), Attributes: attributes}
+	// Offloaded payloads stay in the store, referenced by the attributes
+	if _, ok := attributes[ClaimCheckAttribute]; ok {
+		env.Data = nil
+	}
+	if _, pubErr := s.transport.Publish(ctx, policy.Topic, env); pubErr != nil {
+		s.logger.Error(ctx, "quarantine publish failed", "topic", s.Topic(), "message", msg.ID(), "err", pubErr)
+		return false
+	}
+
+	s.logger.Warn(ctx, "message quarantined", "topic", s.Topic(), "message", msg.ID(), "reason", reason, "strikes", strikes, "err", err)
+	if ackErr := msg.Ack(); ackErr != nil {
+		s.logger.Error(ctx, "ack after quarantine", "topic", s.Topic(), "message", msg.ID(), "err", ackErr)
+	}
+	s.recordHealth(meta.ID, false, errText)
+	if s.hooks.OnQuarantine != nil {
+		s.hooks.OnQuarantine(ctx, s.Topic(), meta, err)
+	}
+	if s.hooks.OnFailure != nil {
+		s.hooks.OnFailure(ctx, s.Topic(), meta, err)
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/infigaming-com/go-common/pubsub/driver/memory"
+)
+
+func TestSubscriptionQuarantine(t *testing.T) {
+	ctx := context.Background()
+	transport := memory.New()
+	var quarantined atomic.Int32
+	client, err := pubsub.New(ctx, transport,
+		pubsub.WithHandlerMiddleware(pubsub.RecoverMiddleware(nil)),
+		// Redeliveries reach the handler
+		pubsub.WithDeduplication(pubsub.DeduplicationConfig{}),
+		pubsub.WithHooks(pubsub.Hooks{
+			OnQuarantine: func(context.Context, string, pubsub.MessageMetadata, error) { quarantined.Add(1) },
+		}),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	var calls atomic.Int32
+	_, err = client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		calls.Add(1)
+		panic("corrupt order")
+	}),
+		pubsub.WithSubscriptionQuarantine(pubsub.QuarantinePolicy{Topic: "orders-quarantine", Strikes: 2}),
+		pubsub.WithSubscriptionRetry(pubsub.RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Millisecond}),
+		pubsub.WithSubscriptionDeadLetter("orders-dlq"),
+		pubsub.WithSubscriptionInactivityTimeout(-1),
+	)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, err := client.Publish(ctx, "orders", orderCreated{ID: "1"}, pubsub.WithAttributes(map[string]string{"tenant": "acme"})); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(transport.Published("orders-quarantine")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for the quarantined message")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected the handler to be called twice, got %d", got)
+	}
+	if got := quarantined.Load(); got != 1 {
+		t.Fatalf("expected OnQuarantine once, got %d", got)
+	}
+	if published := transport.Published("orders-dlq"); len(published) != 0 {
+		t.Fatalf("expected no dead letter, got %d", len(published))
+	}
+	msg := transport.Published("orders-quarantine")[0]
+	if string(msg.Data) != `{"id":"1"}` {
+		t.Fatalf("expected the original data, got %s", msg.Data)
+	}
+	for attribute, want := range map[string]string{
+		"tenant":                            "acme",
+		pubsub.QuarantineSourceAttribute:    "orders",
+		pubsub.QuarantineReasonAttribute:    "panic",
+		pubsub.QuarantineStrikesAttribute:   "2",
+		pubsub.QuarantineMessageIDAttribute: transport.Published("orders")[0].ID,
+		pubsub.QuarantineErrorAttribute:     "pubsub: handler panic: corrupt order",
+	} {
+		if got := msg.Attributes[attribute]; got != want {
+			t.Errorf("attribute %s: expected %q, got %q", attribute, want, got)
+		}
+	}
+}
+
+func TestSubscriptionQuarantine_IgnoresOtherErrors(t *testing.T) {
+	ctx := context.Background()
+	transport := memory.New()
+	client, err := pubsub.New(ctx, transport, pubsub.WithDeduplication(pubsub.DeduplicationConfig{}))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	var calls atomic.Int32
+	_, err = client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		calls.Add(1)
+		return errors.New("database unavailable")
+	}),
+		pubsub.WithSubscriptionQuarantine(pubsub.QuarantinePolicy{Topic: "orders-quarantine", Strikes: 2}),
+		pubsub.WithSubscriptionRetry(pubsub.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+		pubsub.WithSubscriptionDeadLetter("orders-dlq"),
+		pubsub.WithSubscriptionInactivityTimeout(-1),
+	)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, err := client.Publish(ctx, "orders", orderCreated{ID: "1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(transport.Published("orders-dlq")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for the dead letter")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected the retry budget to be used, got %d calls", got)
+	}
+	if published := transport.Published("orders-quarantine"); len(published) != 0 {
+		t.Fatalf("expected no quarantined message, got %d", len(published))
+	}
+}
@@ -0,0 +1,250 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Attributes set on the copies published to the archive topic of WithArchive
+const (
+	// ArchivedTopicAttribute holds the topic the message was published to
+	ArchivedTopicAttribute = "pubsub_archived_topic"
+	// PublishedAtAttribute holds the Unix time in milliseconds the message
+	// was published at
+	PublishedAtAttribute = "pubsub_published_at"
+	// ReplayedAttribute is set to "true" on messages republished by an
+	// emulated Seek
+	ReplayedAttribute = "pubsub_replayed"
+)
+
+// ErrSeekNotSupported is returned by Seek when the transport cannot seek and
+// the target cannot be replayed from an archive topic.
+var ErrSeekNotSupported = errors.New("pubsub: seek not supported")
+
+// SeekTarget is where Seek rewinds a subscription to. Snapshot takes
+// precedence over Time.
+type SeekTarget struct {
+	// Time redelivers the messages published at or after it
+	Time time.Time
+	// Snapshot restores the acknowledgment state captured by a broker
+	// snapshot
+	Snapshot string
+}
+
+// SeekToTime targets the messages published at or after t
+func SeekToTime(t time.Time) SeekTarget {
+	return SeekTarget{Time: t}
+}
+
+// SeekToSnapshot targets the state of a broker snapshot
+func SeekToSnapshot(name string) SeekTarget {
+	return SeekTarget{Snapshot: name}
+}
+
+// Seeker is implemented by transports able to rewind a subscription on the
+// broker, e.g. Google Pub/Sub.
+type Seeker interface {
+	Seek(ctx context.Context, subscription string, target SeekTarget) error
+}
+
+// WithArchive publishes a copy of every message to the archive topic, with
+// ArchivedTopicAttribute and PublishedAtAttribute set, so subscriptions
+// configured with WithSubscriptionReplayArchive can replay it. Archiving is
+// best effort: a failed copy is logged and does not fail the publish.
+func WithArchive(topic string) Option {
+	return func(o *options) {
+		o.archiveTopic = topic
+	}
+}
+
+// ReplayArchive configures how Seek replays messages from the archive topic
+// filled by WithArchive when the transport cannot seek.
+type ReplayArchive struct {
+	// Subscription receives the archive topic. It should be dedicated to
+	// replays since a replay consumes it.
+	Subscription string
+	// Topic is the topic the subscription receives, whose archived messages
+	// are republished to it. Defaults to the subscription name.
+	Topic string
+	// IdleTimeout ends the replay once the archive has delivered nothing for
+	// this long, 5 seconds by default
+	IdleTimeout time.Duration
+}
+
+// WithSubscriptionReplayArchive makes Seek replay messages from an archive
+// when the transport cannot seek
+func WithSubscriptionReplayArchive(archive ReplayArchive) SubscriptionOption {
+	return func(o *subscriptionOptions) {
+		if archive.IdleTimeout <= 0 {
+			archive.IdleTimeout = 5 * time.Second
+		}
+		o.replayArchive = &archive
+	}
+}
+
+// Seek rewinds the subscription to target so that incident recovery can
+// replay missed events. Transports implementing Seeker seek on the broker,
+// which redelivers the messages to the running subscription. Otherwise a
+// time target is emulated by republishing the archived messages of the topic
+// published between target.Time and the call to Seek, which every
+// subscription of the topic receives. The emulated Seek returns once the
+// archive is caught up or has been idle for ReplayArchive.IdleTimeout.
+//
+// Redeliveries of message IDs still in the deduplication window are dropped,
+// like any other redelivery.
+func (s *subscription) Seek(ctx context.Context, target SeekTarget) error {
+	if target.Snapshot == "" && target.Time.IsZero() {
+		return errors.New("pubsub: seek target required")
+	}
+	s.mu.RLock()
+	closed := s.closed
+	s.mu.RUnlock()
+	if closed {
+		return errors.New("pubsub: subscription stopped")
+	}
+
+	if seeker, ok := s.transport.(Seeker); ok {
+		if err := seeker.Seek(ctx, s.Topic(), target); err != nil {
+			return err
+		}
+		s.logger.Info(ctx, "subscription seeked", "topic", s.Topic(), "time", target.Time, "snapshot", target.Snapshot)
+		return nil
+	}
+	if target.Snapshot != "" || s.options.replayArchive == nil {
+		return ErrSeekNotSupported
+	}
+	return s.replay(ctx, target.Time)
+}
+
+// replay republishes the archived messages of the topic published between
+// from and now
+func (s *subscription) replay(ctx context.Context, from time.Time) error {
+	archive := s.options.replayArchive
+	topic := archive.Topic
+	if topic == "" {
+		topic = s.Topic()
+	}
+	until := time.Now()
+	// archived publish times have millisecond precision
+	from = from.Truncate(time.Millisecond)
+	replayCtx, activity, stop := watchIdle(ctx, archive.IdleTimeout)
+	defer stop()
+
+	var replayed int
+	err := s.transport.Subscribe(replayCtx, archive.Subscription, TransportSubscribeOptions{Parallelism: 1}, func(msgCtx context.Context, raw *TransportMessage) error {
+		activity()
+		if archived, ok := raw.Attributes[ArchivedTopicAttribute]; ok && archived != topic {
+			return raw.Ack()
+		}
+		publishedAt := archivedAt(raw)
+		if !publishedAt.Before(until) {
+			// caught up, the topic received this message when it was published
+			stop()
+			return raw.Ack()
+		}
+		if publishedAt.Before(from) {
+			return raw.Ack()
+		}
+		env := &Envelope{
+			Data:        raw.Data,
+			Attributes:  replayAttributes(raw.Attributes),
+			OrderingKey: raw.OrderingKey,
+		}
+		if _, err := s.transport.Publish(msgCtx, topic, env); err != nil {
+			return fmt.Errorf("pubsub: replay %s: %w", raw.ID, err)
+		}
+		replayed++
+		return raw.Ack()
+	})
+	stop()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	s.logger.Info(ctx, "subscription replayed", "topic", topic, "archive", archive.Subscription, "from", from, "replayed", replayed)
+	return nil
+}
+
+// archive publishes the copy of a published message to the archive topic
+func (c *Client) archive(ctx context.Context, topic string, env *Envelope) {
+	if c.opts.archiveTopic == "" || topic == c.opts.archiveTopic {
+		return
+	}
+	attributes := cloneMap(env.Attributes)
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+	attributes[ArchivedTopicAttribute] = topic
+	attributes[PublishedAtAttribute] = strconv.FormatInt(time.Now().UnixMilli(), 10)
+	copied := &Envelope{Data: env.Data, Attributes: attributes, OrderingKey: env.OrderingKey}
+	if _, err := c.transport.Publish(ctx, c.opts.archiveTopic, copied); err != nil {
+		c.logger().Warn(ctx, "pubsub archive publish failed", "topic", topic, "archive", c.opts.archiveTopic, "err", err)
+	}
+}
+
+// archivedAt returns the publish time of an archived message, falling back
+// to the time the broker reports
+func archivedAt(raw *TransportMessage) time.Time {
+	if value, ok := raw.Attributes[PublishedAtAttribute]; ok {
+		if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.UnixMilli(ms)
+		}
+	}
+	return raw.ReceivedAt
+}
+
+func replayAttributes(attributes map[string]string) map[string]string {
+	out := make(map[string]string, len(attributes)+1)
+	for k, v := range attributes {
+		if k == ArchivedTopicAttribute || k == PublishedAtAttribute {
+			continue
+		}
+		out[k] = v
+	}
+	out[ReplayedAttribute] = "true"
+	return out
+}
+
+// watchIdle returns a context cancelled once activity has not been called for
+// timeout, and the function stopping the watch and cancelling the context
+func watchIdle(ctx context.Context, timeout time.Duration) (context.Context, func(), func()) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	signal := make(chan struct{}, 1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		idle := time.NewTimer(timeout)
+		defer idle.Stop()
+		for {
+			select {
+			case <-watchCtx.Done():
+				return
+			case <-signal:
+				idle.Reset(timeout)
+			case <-idle.C:
+				cancel()
+				return
+			}
+		}
+	}()
+
+	activity := func() {
+		select {
+		case signal <- struct{}{}:
+		default:
+		}
+	}
+	stop := func() {
+		cancel()
+		wg.Wait()
+	}
+	return watchCtx, activity, stop
+}
@@ -0,0 +1,108 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/infigaming-com/go-common/pubsub/driver/memory"
+)
+
+func TestSubscription_SeekReplaysArchive(t *testing.T) {
+	ctx := context.Background()
+	transport := memory.New()
+	client, err := pubsub.New(ctx, transport, pubsub.WithArchive("archive"))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	type received struct {
+		id       string
+		replayed bool
+	}
+	messages := make(chan received, 10)
+	sub, err := client.Subscribe("orders", pubsub.HandlerFunc(func(ctx context.Context, m *pubsub.Message) error {
+		var order orderCreated
+		if err := m.Decode(ctx, &order); err != nil {
+			return err
+		}
+		messages <- received{id: order.ID, replayed: m.Attributes()[pubsub.ReplayedAttribute] == "true"}
+		return nil
+	}), pubsub.WithSubscriptionReplayArchive(pubsub.ReplayArchive{
+		Subscription: "archive",
+		IdleTimeout:  100 * time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	publish := func(topic, id string) {
+		t.Helper()
+		if _, err := client.Publish(ctx, topic, orderCreated{ID: id}); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	publish("orders", "1")
+	time.Sleep(5 * time.Millisecond)
+	from := time.Now()
+	publish("orders", "2")
+	publish("payments", "p1")
+	publish("orders", "3")
+	for range 3 {
+		select {
+		case <-messages:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for messages")
+		}
+	}
+
+	if err := sub.Seek(ctx, pubsub.SeekToTime(from)); err != nil {
+		t.Fatalf("seek: %v", err)
+	}
+	var replayed []string
+	for range 2 {
+		select {
+		case m := <-messages:
+			if !m.replayed {
+				t.Fatalf("expected replayed message, got %+v", m)
+			}
+			replayed = append(replayed, m.id)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for replayed messages")
+		}
+	}
+	slices.Sort(replayed)
+	if replayed[0] != "2" || replayed[1] != "3" {
+		t.Fatalf("unexpected replayed messages %v", replayed)
+	}
+	select {
+	case m := <-messages:
+		t.Fatalf("unexpected message %+v", m)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := sub.Seek(ctx, pubsub.SeekToSnapshot("before-incident")); !errors.Is(err, pubsub.ErrSeekNotSupported) {
+		t.Fatalf("expected ErrSeekNotSupported, got %v", err)
+	}
+}
+
+func TestSubscription_SeekNotSupported(t *testing.T) {
+	ctx := context.Background()
+	client, err := pubsub.New(ctx, memory.New())
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	sub, err := client.Subscribe("orders", pubsub.HandlerFunc(func(context.Context, *pubsub.Message) error { return nil }))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := sub.Seek(ctx, pubsub.SeekToTime(time.Now().Add(-time.Hour))); !errors.Is(err, pubsub.ErrSeekNotSupported) {
+		t.Fatalf("expected ErrSeekNotSupported, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrSchemaViolation is wrapped by errors returned for messages that do not
+// match the schema registered for their topic.
+var ErrSchemaViolation = errors.New("pubsub: schema violation")
+
+// Schema validates the encoded payload of a message.
+type Schema interface {
+	Validate(data []byte) error
+}
+
+// SchemaFunc adapts a function to the Schema interface.
+type SchemaFunc func(data []byte) error
+
+func (f SchemaFunc) Validate(data []byte) error {
+	return f(data)
+}
+
+// SchemaRegistry resolves the schema messages of a topic must match. Lookup
+// returns a nil Schema when the topic has none, in which case messages are not
+// validated.
+type SchemaRegistry interface {
+	Lookup(ctx context.Context, topic string) (Schema, error)
+}
+
+// InMemorySchemaRegistry is a SchemaRegistry holding schemas in a map. It is
+// safe for concurrent use.
+type InMemorySchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]Schema
+}
+
+func NewInMemorySchemaRegistry() *InMemorySchemaRegistry {
+	return &InMemorySchemaRegistry{schemas: map[string]Schema{}}
+}
+
+// Register sets the schema of topic, replacing any previous one. A nil schema
+// removes it.
+func (r *InMemorySchemaRegistry) Register(topic string, schema Schema) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if schema == nil {
+		delete(r.schemas, topic)
+		return
+	}
+	r.schemas[topic] = schema
+}
+
+func (r *InMemorySchemaRegistry) Lookup(_ context.Context, topic string) (Schema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.schemas[topic], nil
+}
+
+type jsonSchema struct {
+	schema *jsonschema.Schema
+}
+
+// NewJSONSchema compiles a JSON Schema document (draft 4 to 2020-12) into a
+// Schema validating JSON payloads.
+func NewJSONSchema(document []byte) (Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(document))
+	if err != nil {
+		return nil, fmt.Errorf("pubsub: parse json schema: %w", err)
+	}
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("schema.json", doc); err != nil {
+		return nil, fmt.Errorf("pubsub: add json schema: %w", err)
+	}
+	schema, err := c.Compile("schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("pubsub: compile json schema: %w", err)
+	}
+	return jsonSchema{schema: schema}, nil
+}
+
+func (s jsonSchema) Validate(data []byte) error {
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return s.schema.Validate(inst)
+}
+
+type protoSchema struct {
+	msg proto.Message
+}
+
+// NewProtoSchema returns a Schema validating protobuf wire-format payloads
+// against the descriptor of msg. Payloads carrying fields unknown to the
+// descriptor or missing proto2 required fields are rejected.
+func NewProtoSchema(msg proto.Message) Schema {
+	return protoSchema{msg: msg}
+}
+
+func (s protoSchema) Validate(data []byte) error {
+	m := s.msg.ProtoReflect().New()
+	if err := proto.Unmarshal(data, m.Interface()); err != nil {
+		return err
+	}
+	if len(m.GetUnknown()) > 0 {
+		return fmt.Errorf("unknown fields for %s", m.Descriptor().FullName())
+	}
+	return nil
+}
+
+// validateSchema checks data against the schema registered for topic. Errors
+// from the registry itself are returned as is; mismatches wrap
+// ErrSchemaViolation.
+func validateSchema(ctx context.Context, registry SchemaRegistry, topic string, data []byte) error {
+	if registry == nil {
+		return nil
+	}
+	schema, err := registry.Lookup(ctx, topic)
+	if err != nil {
+		return fmt.Errorf("pubsub: schema lookup: %w", err)
+	}
+	if schema == nil {
+		return nil
+	}
+	if err := schema.Validate(data); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrSchemaViolation, topic, err)
+	}
+	return nil
+}
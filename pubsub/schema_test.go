@@ -0,0 +1,181 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// publishingTransport records published topics on top of mockTransport.
+type publishingTransport struct {
+	mockTransport
+	mu        sync.Mutex
+	published []string
+}
+
+func (p *publishingTransport) Publish(_ context.Context, topic string, _ *Envelope) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.published = append(p.published, topic)
+	return "id", nil
+}
+
+func (p *publishingTransport) topics() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.published...)
+}
+
+const orderSchema = `{
+	"type": "object",
+	"properties": {"id": {"type": "integer"}, "amount": {"type": "number"}},
+	"required": ["id", "amount"]
+}`
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := NewJSONSchema([]byte(orderSchema))
+	if err != nil {
+		t.Fatalf("NewJSONSchema: %v", err)
+	}
+	if err := schema.Validate([]byte(`{"id":1,"amount":9.5}`)); err != nil {
+		t.Errorf("valid payload rejected: %v", err)
+	}
+	if err := schema.Validate([]byte(`{"id":"1"}`)); err == nil {
+		t.Error("invalid payload accepted")
+	}
+	if err := schema.Validate([]byte(`not json`)); err == nil {
+		t.Error("malformed payload accepted")
+	}
+	if _, err := NewJSONSchema([]byte(`{"type": 5}`)); err == nil {
+		t.Error("invalid schema compiled")
+	}
+}
+
+func TestProtoSchema(t *testing.T) {
+	schema := NewProtoSchema(&wrapperspb.StringValue{})
+	valid, _ := proto.Marshal(wrapperspb.String("hello"))
+	if err := schema.Validate(valid); err != nil {
+		t.Errorf("valid payload rejected: %v", err)
+	}
+	other, _ := proto.Marshal(wrapperspb.Int64(42))
+	if err := NewProtoSchema(&emptypb.Empty{}).Validate(other); err == nil {
+		t.Error("payload with unknown fields accepted")
+	}
+	if err := schema.Validate([]byte{0xff}); err == nil {
+		t.Error("malformed payload accepted")
+	}
+}
+
+func TestPublish_SchemaViolation(t *testing.T) {
+	schema, err := NewJSONSchema([]byte(orderSchema))
+	if err != nil {
+		t.Fatalf("NewJSONSchema: %v", err)
+	}
+	registry := NewInMemorySchemaRegistry()
+	registry.Register("orders", schema)
+
+	var violations atomic.Int32
+	transport := &publishingTransport{}
+	client, err := New(context.Background(), transport, WithSchemaRegistry(registry), WithHooks(Hooks{
+		OnSchemaViolation: func(_ context.Context, topic string, _ map[string]string, err error) {
+			if topic == "orders" && errors.Is(err, ErrSchemaViolation) {
+				violations.Add(1)
+			}
+		},
+	}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := client.Publish(context.Background(), "orders", map[string]any{"id": 1, "amount": 2.5}); err != nil {
+		t.Fatalf("valid publish: %v", err)
+	}
+	_, err = client.Publish(context.Background(), "orders", map[string]any{"id": 1})
+	if !errors.Is(err, ErrSchemaViolation) {
+		t.Fatalf("expected ErrSchemaViolation, got %v", err)
+	}
+	if _, err := client.Publish(context.Background(), "other", "anything"); err != nil {
+		t.Fatalf("publish to topic without schema: %v", err)
+	}
+
+	if got := transport.topics(); len(got) != 2 || got[0] != "orders" || got[1] != "other" {
+		t.Errorf("unexpected published topics: %v", got)
+	}
+	if violations.Load() != 1 {
+		t.Errorf("expected 1 violation hook call, got %d", violations.Load())
+	}
+}
+
+// TestSubscription_SchemaViolationDeadLetters verifies that invalid messages
+// never reach the handler and are dead-lettered and acked instead.
+func TestSubscription_SchemaViolationDeadLetters(t *testing.T) {
+	registry := NewInMemorySchemaRegistry()
+	registry.Register("orders", SchemaFunc(func(data []byte) error {
+		if string(data) != `{"ok":true}` {
+			return errors.New("unexpected payload")
+		}
+		return nil
+	}))
+
+	var acked atomic.Int32
+	transport := &publishingTransport{}
+	transport.subscribeFn = func(ctx context.Context, h TransportHandler) error {
+		for i, data := range []string{`{"ok":true}`, `{"ok":false}`} {
+			msg := &TransportMessage{
+				Envelope: Envelope{ID: string(rune('a' + i)), Data: []byte(data)},
+				Ack:      func() error { acked.Add(1); return nil },
+				Nack:     func() error { return nil },
+			}
+			if err := h(ctx, msg); err != nil {
+				return err
+			}
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	var violations atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, err := New(ctx, transport, WithLogger(&recordingLogger{}), WithSchemaRegistry(registry), WithHooks(Hooks{
+		OnSchemaViolation: func(context.Context, string, map[string]string, error) { violations.Add(1) },
+	}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var handled atomic.Int32
+	sub, err := client.Subscribe("orders", HandlerFunc(func(context.Context, *Message) error {
+		handled.Add(1)
+		return nil
+	}), WithSubscriptionDeadLetter("orders-dlq"), WithSubscriptionInactivityTimeout(-1))
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer func() { _ = sub.Stop(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for acked.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("acked %d of 2 messages", acked.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if handled.Load() != 1 {
+		t.Errorf("expected handler to see 1 message, got %d", handled.Load())
+	}
+	if violations.Load() != 1 {
+		t.Errorf("expected 1 violation hook call, got %d", violations.Load())
+	}
+	if got := transport.topics(); len(got) != 1 || got[0] != "orders-dlq" {
+		t.Errorf("expected dead letter publish, got %v", got)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/infigaming-com/go-common/pubsub/internal/backoff"
@@ -38,6 +40,15 @@ type Subscription interface {
 	Topic() string
 	Stop(ctx context.Context) error
 	Health() SubscriptionHealth
+	// Pause stops pulling messages without tearing down the subscription.
+	// Buffered messages are nacked instead of dispatched so the broker
+	// redelivers them after Resume; messages already being handled finish.
+	Pause()
+	// Resume reopens the stream after Pause
+	Resume()
+	// Seek rewinds the subscription to replay past messages, see
+	// WithSubscriptionReplayArchive for transports that cannot seek
+	Seek(ctx context.Context, target SeekTarget) error
 }
 
 type SubscriptionHealth struct {
@@ -49,6 +60,7 @@ type SubscriptionHealth struct {
 	LastError     string
 	LastMessageID string
 	LastActivity  time.Time
+	Paused        bool
 }
 
 type subscription struct {
@@ -63,6 +75,7 @@ type subscription struct {
 	breaker   *breaker
 	dedupe    DedupeStore
 	dedupeTTL time.Duration
+	strikes   StrikeCounter
 	hooks     Hooks
 	logger    Logger
 	transport Transport
@@ -71,11 +84,24 @@ type subscription struct {
 	health SubscriptionHealth
 	closed bool
 	wg     sync.WaitGroup
+	// parked counts the delayed messages held until they are due
+	parked atomic.Int64
+
+	// guarded by mu: resumed is closed on Resume, stopStream ends the
+	// current Receive call with a reason for the receiver
+	paused     bool
+	resumed    chan struct{}
+	stopStream func(reason string)
 }
 
 func newSubscription(parent context.Context, client *Client, topic string, handler Handler, opts subscriptionOptions) *subscription {
 	subCtx, cancel := context.WithCancel(parent)
-	p := worker.New(opts.workers, opts.buffer)
+	var p *worker.Pool
+	if opts.keyOrdering {
+		p = worker.NewKeyed(opts.workers, opts.buffer)
+	} else {
+		p = worker.New(opts.workers, opts.buffer)
+	}
 	h := SubscriptionHealth{Topic: topic, Workers: opts.workers}
 
 	// An explicitly-injected DedupeStore wins (typically Redis-backed for
@@ -90,10 +116,23 @@ func newSubscription(parent context.Context, client *Client, topic string, handl
 		dedupe = newInMemoryDedupeStore(opts.dedupe.Size)
 	}
 
+	// Strikes of poison messages share the dedupe store when it can count
+	// them, so that they are tracked across pods with a shared store
+	var strikes StrikeCounter
+	if opts.quarantine.Topic != "" {
+		if counter, ok := dedupe.(StrikeCounter); ok {
+			strikes = counter
+		} else {
+			strikes = newInMemoryDedupeStore(opts.dedupe.Size)
+		}
+	}
+
+	middlewares := append(slices.Clone(client.opts.handlerMiddlewares), opts.handlerMiddlewares...)
+
 	return &subscription{
 		client:    client,
 		options:   opts,
-		handler:   handler,
+		handler:   chainHandler(handler, middlewares...),
 		ctx:       subCtx,
 		cancel:    cancel,
 		pool:      p,
@@ -102,6 +141,7 @@ func newSubscription(parent context.Context, client *Client, topic string, handl
 		breaker:   newBreaker(5, opts.retryPolicy.InitialBackoff*2),
 		dedupe:    dedupe,
 		dedupeTTL: opts.dedupe.TTL,
+		strikes:   strikes,
 		hooks:     client.opts.hooks,
 		logger:    client.logger(),
 		transport: client.transport,
@@ -120,7 +160,7 @@ func (s *subscription) start() {
 func (s *subscription) receiver() {
 	defer s.wg.Done()
 	for {
-		if s.ctx.Err() != nil {
+		if !s.waitResumed() {
 			return
 		}
 
@@ -129,6 +169,17 @@ func (s *subscription) receiver() {
 		receiveCtx, cancelReceive := context.WithCancel(s.ctx)
 		watchdogDone := make(chan struct{})
 		reason := make(chan string, 1)
+		if !s.setStopStream(func(r string) {
+			select {
+			case reason <- r:
+			default:
+			}
+			cancelReceive()
+		}) {
+			// Paused between waitResumed and here
+			cancelReceive()
+			continue
+		}
 		go s.streamWatchdog(receiveCtx, cancelReceive, reason, watchdogDone)
 
 		err := s.transport.Subscribe(receiveCtx, s.options.name, TransportSubscribeOptions{
@@ -138,6 +189,7 @@ func (s *subscription) receiver() {
 		}, s.handleTransportMessage)
 		cancelReceive()
 		<-watchdogDone
+		s.setStopStream(nil)
 
 		// Parent context cancelled => we are shutting down; exit cleanly.
 		if s.ctx.Err() != nil {
@@ -166,7 +218,11 @@ func (s *subscription) receiver() {
 		if watchdogTriggered {
 			select {
 			case r := <-reason:
-				s.logger.Info(s.ctx, "subscription stream refreshed", "topic", s.Topic(), "reason", r)
+				if r == pauseReason {
+					s.logger.Info(s.ctx, "subscription paused", "topic", s.Topic())
+				} else {
+					s.logger.Info(s.ctx, "subscription stream refreshed", "topic", s.Topic(), "reason", r)
+				}
 				s.backoff.Reset()
 				continue
 			default:
@@ -198,6 +254,73 @@ func (s *subscription) receiver() {
 	}
 }
 
+// pauseReason is the reason the receiver is given when Pause ends its stream
+const pauseReason = "paused"
+
+// waitResumed blocks while the subscription is paused. It returns false once
+// the subscription is stopped.
+func (s *subscription) waitResumed() bool {
+	s.mu.RLock()
+	paused, resumed := s.paused, s.resumed
+	s.mu.RUnlock()
+	if !paused {
+		return s.ctx.Err() == nil
+	}
+	select {
+	case <-s.ctx.Done():
+		return false
+	case <-resumed:
+		return s.ctx.Err() == nil
+	}
+}
+
+// setStopStream registers the function ending the current Receive call, or
+// clears it with nil. It returns false if the subscription is paused.
+func (s *subscription) setStopStream(stop func(reason string)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stop != nil && s.paused {
+		return false
+	}
+	s.stopStream = stop
+	return true
+}
+
+func (s *subscription) isPaused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.paused
+}
+
+func (s *subscription) Pause() {
+	s.mu.Lock()
+	if s.closed || s.paused {
+		s.mu.Unlock()
+		return
+	}
+	s.paused = true
+	s.resumed = make(chan struct{})
+	s.health.Paused = true
+	stop := s.stopStream
+	s.mu.Unlock()
+
+	if stop != nil {
+		stop(pauseReason)
+	}
+}
+
+func (s *subscription) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused {
+		return
+	}
+	s.paused = false
+	s.health.Paused = false
+	close(s.resumed)
+	s.logger.Info(s.ctx, "subscription resumed", "topic", s.Topic())
+}
+
 // streamWatchdog forces the active Receive call to terminate when it looks
 // stuck: either after a fixed refresh interval (preventive) or after no
 // message activity for inactivityTimeout (reactive). The goroutine exits as
@@ -329,6 +452,10 @@ func (s *subscription) dispatcher() {
 				s.pool.Wait()
 				return
 			}
+			if s.isPaused() {
+				_ = msg.Nack()
+				continue
+			}
 			s.schedule(msg)
 		}
 	}
@@ -338,10 +465,19 @@ func (s *subscription) handleTransportMessage(ctx context.Context, raw *Transpor
 	if raw == nil {
 		return nil
 	}
+	// Delivered while Pause was ending the stream
+	if s.isPaused() {
+		return raw.Nack()
+	}
 	// Mark the stream as alive the moment a message arrives from the transport,
 	// independent of whether the handler succeeds. The watchdog reads this to
 	// decide whether StreamingPull has gone silent.
 	s.touchActivity()
+	// Checked before dedupe so parking does not mark the message as seen
+	if due, ok := deliverAt(raw.Attributes); ok && time.Until(due) > 0 {
+		s.park(raw, due)
+		return nil
+	}
 	if s.breaker.open() {
 		s.logger.Warn(ctx, "subscription circuit open", "topic", s.Topic(), "message", raw.ID)
 		return raw.Nack()
@@ -386,7 +522,7 @@ func (s *subscription) schedule(raw *TransportMessage) {
 	meta := MessageMetadata{ID: raw.ID, Attempt: raw.Attempt, Attributes: cloneMap(raw.Attributes)}
 	msg := newMessage(raw, s.client.decoder())
 	deadlineCtx, cancel := context.WithTimeout(s.ctx, s.options.processTimeout)
-	err := s.pool.Submit(deadlineCtx, func(execCtx context.Context) {
+	err := s.pool.SubmitKey(deadlineCtx, raw.OrderingKey, func(execCtx context.Context) {
 		defer cancel()
 		s.process(execCtx, msg, meta)
 	})
@@ -402,6 +538,29 @@ func (s *subscription) process(ctx context.Context, msg *Message, meta MessageMe
 	if msg == nil {
 		return
 	}
+	ctx = contextWithTopic(ctx, s.Topic())
+	data, err := s.client.resolvePayload(ctx, msg.attributes, msg.data)
+	if err != nil {
+		var permErr permanentError
+		if errors.As(err, &permErr) {
+			s.onPermanentFailure(ctx, msg, meta, permErr.Err)
+			return
+		}
+		s.onFailure(ctx, msg, meta, err)
+		return
+	}
+	msg.data = data
+	if err := validateSchema(ctx, s.client.opts.schemaRegistry, s.Topic(), msg.Data()); err != nil {
+		if errors.Is(err, ErrSchemaViolation) {
+			if s.hooks.OnSchemaViolation != nil {
+				s.hooks.OnSchemaViolation(ctx, s.Topic(), cloneMap(meta.Attributes), err)
+			}
+			s.onPermanentFailure(ctx, msg, meta, err)
+			return
+		}
+		s.onFailure(ctx, msg, meta, err)
+		return
+	}
 	extendStop := make(chan struct{})
 	var extendWG sync.WaitGroup
 	if s.options.maxExtension > 0 {
@@ -413,7 +572,7 @@ func (s *subscription) process(ctx context.Context, msg *Message, meta MessageMe
 		extendWG.Wait()
 	}()
 
-	err := s.handler.Handle(ctx, msg)
+	err = s.handler.Handle(ctx, msg)
 	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 		err = fmt.Errorf("handler timeout: %w", ctx.Err())
 	}
@@ -426,6 +585,9 @@ func (s *subscription) process(ctx context.Context, msg *Message, meta MessageMe
 		s.onPermanentFailure(ctx, msg, meta, permErr.Err)
 		return
 	}
+	if s.quarantine(ctx, msg, meta, err) {
+		return
+	}
 	s.onFailure(ctx, msg, meta, err)
 }
 
@@ -483,7 +645,7 @@ func (s *subscription) onSuccess(ctx context.Context, msg *Message, meta Message
 
 func (s *subscription) onPermanentFailure(ctx context.Context, msg *Message, meta MessageMetadata, err error) {
 	s.logger.Warn(ctx, "permanent failure", "topic", s.Topic(), "message", msg.ID(), "err", err)
-	s.forwardDeadLetter(ctx, msg, meta)
+	s.forwardDeadLetter(ctx, msg, meta, err)
 	if err := msg.Ack(); err != nil {
 		s.logger.Error(ctx, "ack after permanent failure", "topic", s.Topic(), "message", msg.ID(), "err", err)
 	}
@@ -512,16 +674,24 @@ func (s *subscription) onFailure(ctx context.Context, msg *Message, meta Message
 	}
 }
 
-func (s *subscription) forwardDeadLetter(ctx context.Context, msg *Message, meta MessageMetadata) {
+func (s *subscription) forwardDeadLetter(ctx context.Context, msg *Message, meta MessageMetadata, reason error) {
 	if s.options.deadLetterTopic == "" {
 		return
 	}
-	payload := map[string]any{
-		"message_id": msg.ID(),
-		"attributes": msg.Attributes(),
-		"data":       msg.Data(),
+	payload := &DeadLetter{
+		MessageID:   msg.ID(),
+		Attributes:  msg.Attributes(),
+		Data:        msg.Data(),
+		SourceTopic: s.Topic(),
+		Reason:      reason.Error(),
+		Attempt:     meta.Attempt,
+		FailedAt:    time.Now(),
+	}
+	// Offloaded payloads stay in the store, referenced by the attributes
+	if _, ok := payload.Attributes[ClaimCheckAttribute]; ok {
+		payload.Data = nil
 	}
-	_, err := s.client.Publish(ctx, s.options.deadLetterTopic, payload, WithAttributes(map[string]string{"source": s.Topic()}))
+	_, err := s.client.Publish(ctx, s.options.deadLetterTopic, payload, WithAttributes(map[string]string{DeadLetterSourceAttribute: s.Topic()}))
 	if err != nil {
 		s.logger.Error(ctx, "dead letter publish failed", "topic", s.Topic(), "message", msg.ID(), "err", err)
 	}
@@ -3,6 +3,8 @@ package pubsub
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -503,3 +505,159 @@ func TestReceiveError_TransientLogsAsWarn(t *testing.T) {
 		t.Fatalf("Stop: %v", err)
 	}
 }
+
+// TestSubscriptionKeyOrdering verifies that messages sharing an ordering key
+// are handled one at a time and in delivery order, while other keys proceed.
+func TestSubscriptionKeyOrdering(t *testing.T) {
+	const perKey = 20
+	keys := []string{"a", "b", "c", "d"}
+
+	transport := &mockTransport{
+		subscribeFn: func(ctx context.Context, h TransportHandler) error {
+			for i := 0; i < perKey; i++ {
+				for _, key := range keys {
+					msg := &TransportMessage{
+						Envelope: Envelope{ID: fmt.Sprintf("%s-%d", key, i), Data: []byte("{}"), OrderingKey: key},
+						Ack:      func() error { return nil },
+						Nack:     func() error { return nil },
+					}
+					if err := h(ctx, msg); err != nil {
+						return err
+					}
+				}
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, err := New(ctx, transport, WithLogger(&recordingLogger{}), WithDeduplication(DeduplicationConfig{}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var (
+		mu       sync.Mutex
+		active   = map[string]int{}
+		order    = map[string][]string{}
+		overlap  bool
+		handled  atomic.Int32
+		parallel atomic.Int32
+		running  atomic.Int32
+	)
+	sub, err := client.Subscribe("topic-a", HandlerFunc(func(_ context.Context, m *Message) error {
+		key := strings.SplitN(m.ID(), "-", 2)[0]
+		mu.Lock()
+		active[key]++
+		if active[key] > 1 {
+			overlap = true
+		}
+		order[key] = append(order[key], m.ID())
+		mu.Unlock()
+
+		if n := running.Add(1); n > parallel.Load() {
+			parallel.Store(n)
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+
+		mu.Lock()
+		active[key]--
+		mu.Unlock()
+		handled.Add(1)
+		return nil
+	}), WithSubscriptionKeyOrdering(), WithSubscriptionConcurrency(8), WithSubscriptionInactivityTimeout(-1))
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer func() { _ = sub.Stop(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for handled.Load() < int32(perKey*len(keys)) {
+		if time.Now().After(deadline) {
+			t.Fatalf("handled %d of %d messages", handled.Load(), perKey*len(keys))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if overlap {
+		t.Fatal("messages with the same ordering key were handled concurrently")
+	}
+	for _, key := range keys {
+		for i, id := range order[key] {
+			if want := fmt.Sprintf("%s-%d", key, i); id != want {
+				t.Fatalf("key %s: expected %s at position %d, got %s", key, want, i, id)
+			}
+		}
+	}
+	if parallel.Load() < 2 {
+		t.Fatalf("expected different keys to be handled in parallel, max concurrency %d", parallel.Load())
+	}
+}
+
+// TestSubscriptionPauseResume verifies Pause ends the stream without opening a
+// new one until Resume, and that the state shows in Health.
+func TestSubscriptionPauseResume(t *testing.T) {
+	transport := &mockTransport{}
+	logger := &recordingLogger{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := New(ctx, transport, WithLogger(logger))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	sub, err := client.Subscribe(
+		"topic-a",
+		HandlerFunc(func(_ context.Context, _ *Message) error { return nil }),
+		WithSubscriptionInactivityTimeout(-1),
+	)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	waitFor := func(cond func() bool, what string) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitFor(func() bool { return transport.subscribeCalls.Load() == 1 }, "first Subscribe call")
+
+	sub.Pause()
+	if !sub.Health().Paused {
+		t.Fatal("expected Health().Paused after Pause")
+	}
+	streamCtx := transport.lastCtx.Load().(context.Context)
+	waitFor(func() bool { return streamCtx.Err() != nil }, "stream to be cancelled")
+	waitFor(func() bool { return logger.has("info", "subscription paused") }, "paused log entry")
+
+	time.Sleep(50 * time.Millisecond)
+	if got := transport.subscribeCalls.Load(); got != 1 {
+		t.Fatalf("expected no new Subscribe call while paused, got %d calls", got)
+	}
+
+	sub.Resume()
+	if sub.Health().Paused {
+		t.Fatal("expected Health().Paused to be cleared after Resume")
+	}
+	waitFor(func() bool { return transport.subscribeCalls.Load() == 2 }, "Subscribe call after Resume")
+
+	// Stop must not hang on a paused subscription
+	sub.Pause()
+	stopCtx, stopCancel := context.WithTimeout(ctx, time.Second)
+	defer stopCancel()
+	if err := sub.Stop(stopCtx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	// ErrTopologyNotSupported is returned by EnsureTopology and
+	// ValidateTopology when the transport has no admin API.
+	ErrTopologyNotSupported = errors.New("pubsub: topology management not supported")
+	// ErrTopologyMismatch is returned by ValidateTopology for every topic or
+	// subscription of the spec that is missing or bound to another topic.
+	ErrTopologyMismatch = errors.New("pubsub: topology mismatch")
+)
+
+// TopologySpec declares the topics and subscriptions a service relies on.
+type TopologySpec struct {
+	Topics        []string
+	Subscriptions []SubscriptionSpec
+}
+
+// SubscriptionSpec declares a subscription. The settings other than Topic
+// only apply when the subscription is created, by transports supporting them.
+type SubscriptionSpec struct {
+	Name  string
+	Topic string
+	// AckDeadline is the time the broker waits for an ack before redelivering
+	AckDeadline time.Duration
+	// DeadLetterTopic receives the messages delivered MaxDeliveryAttempts
+	// times without being acked
+	DeadLetterTopic     string
+	MaxDeliveryAttempts int
+	// RedeliveryPolicy delays the redelivery of nacked messages, nil
+	// redelivers them immediately
+	RedeliveryPolicy *RedeliveryPolicy
+}
+
+// RedeliveryPolicy is the broker-side retry policy of a subscription, unlike
+// RetryPolicy which retries publishes in the client.
+type RedeliveryPolicy struct {
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// TopologyManager is implemented by transports able to create and inspect
+// topics and subscriptions, e.g. Google Pub/Sub and NATS JetStream. The spec
+// is validated and its topics include the topics and dead-letter topics of
+// its subscriptions.
+type TopologyManager interface {
+	// EnsureTopology creates the missing topics and subscriptions, leaving
+	// existing ones unchanged.
+	EnsureTopology(ctx context.Context, spec TopologySpec) error
+	// ValidateTopology reports the missing topics and subscriptions and the
+	// subscriptions bound to another topic as ErrTopologyMismatch.
+	ValidateTopology(ctx context.Context, spec TopologySpec) error
+}
+
+// EnsureTopology creates the topics and subscriptions of spec that do not
+// exist yet, so that a service can provision itself at startup, e.g. in
+// development. Topics of subscriptions and dead-letter topics are created as
+// well. It returns ErrTopologyNotSupported if the transport has no admin API.
+func (c *Client) EnsureTopology(ctx context.Context, spec TopologySpec) error {
+	manager, spec, err := c.topologyManager(spec)
+	if err != nil {
+		return err
+	}
+	if err := manager.EnsureTopology(ctx, spec); err != nil {
+		return err
+	}
+	c.logger().Info(ctx, "topology ensured", "topics", len(spec.Topics), "subscriptions", len(spec.Subscriptions))
+	return nil
+}
+
+// ValidateTopology checks that the topics and subscriptions of spec exist,
+// e.g. in production where services do not have admin permissions, so that a
+// misconfiguration fails the startup instead of the first publish. It returns
+// ErrTopologyNotSupported if the transport has no admin API.
+func (c *Client) ValidateTopology(ctx context.Context, spec TopologySpec) error {
+	manager, spec, err := c.topologyManager(spec)
+	if err != nil {
+		return err
+	}
+	return manager.ValidateTopology(ctx, spec)
+}
+
+func (c *Client) topologyManager(spec TopologySpec) (TopologyManager, TopologySpec, error) {
+	if err := c.guard(); err != nil {
+		return nil, spec, err
+	}
+	manager, ok := c.transport.(TopologyManager)
+	if !ok {
+		return nil, spec, ErrTopologyNotSupported
+	}
+	spec, err := normalizeTopology(spec)
+	return manager, spec, err
+}
+
+// normalizeTopology validates spec and adds the topics its subscriptions
+// depend on
+func normalizeTopology(spec TopologySpec) (TopologySpec, error) {
+	topics := slices.Clone(spec.Topics)
+	names := map[string]struct{}{}
+	for _, sub := range spec.Subscriptions {
+		if sub.Name == "" || sub.Topic == "" {
+			return spec, errors.New("pubsub: subscription name and topic required")
+		}
+		if _, ok := names[sub.Name]; ok {
+			return spec, fmt.Errorf("pubsub: duplicate subscription %q", sub.Name)
+		}
+		names[sub.Name] = struct{}{}
+		if sub.MaxDeliveryAttempts > 0 && sub.DeadLetterTopic == "" {
+			return spec, fmt.Errorf("pubsub: subscription %q: max delivery attempts require a dead-letter topic", sub.Name)
+		}
+		if p := sub.RedeliveryPolicy; p != nil && p.MaxBackoff > 0 && p.MaxBackoff < p.MinBackoff {
+			return spec, fmt.Errorf("pubsub: subscription %q: redelivery max backoff below min backoff", sub.Name)
+		}
+		topics = append(topics, sub.Topic)
+		if sub.DeadLetterTopic != "" {
+			topics = append(topics, sub.DeadLetterTopic)
+		}
+	}
+	if slices.Contains(topics, "") {
+		return spec, errors.New("pubsub: topic name required")
+	}
+	slices.Sort(topics)
+	spec.Topics = slices.Compact(topics)
+	return spec, nil
+}
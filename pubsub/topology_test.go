@@ -0,0 +1,72 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/infigaming-com/go-common/pubsub/driver/inmem"
+	"github.com/infigaming-com/go-common/pubsub/driver/memory"
+)
+
+func TestClientEnsureTopology(t *testing.T) {
+	ctx := context.Background()
+	transport := memory.New()
+	client, err := pubsub.New(ctx, transport)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	spec := pubsub.TopologySpec{
+		Subscriptions: []pubsub.SubscriptionSpec{
+			{Name: "orders-billing", Topic: "orders"},
+			{Name: "orders-shipping", Topic: "orders"},
+		},
+	}
+	if err := client.ValidateTopology(ctx, spec); !errors.Is(err, pubsub.ErrTopologyMismatch) {
+		t.Fatalf("expected mismatch before ensure, got %v", err)
+	}
+	if err := client.EnsureTopology(ctx, spec); err != nil {
+		t.Fatalf("ensure topology: %v", err)
+	}
+	if err := client.ValidateTopology(ctx, spec); err != nil {
+		t.Fatalf("validate topology: %v", err)
+	}
+
+	// both subscriptions receive the messages published after ensuring
+	if _, err := client.Publish(ctx, "orders", orderCreated{ID: "1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	for _, name := range []string{"orders-billing", "orders-shipping"} {
+		if stats := transport.Stats(name); stats.Pending != 1 {
+			t.Fatalf("expected 1 pending message on %s, got %+v", name, stats)
+		}
+	}
+
+	invalid := []pubsub.TopologySpec{
+		{Subscriptions: []pubsub.SubscriptionSpec{{Name: "orders-billing"}}},
+		{Subscriptions: []pubsub.SubscriptionSpec{{Name: "a", Topic: "orders"}, {Name: "a", Topic: "orders"}}},
+		{Subscriptions: []pubsub.SubscriptionSpec{{Name: "a", Topic: "orders", MaxDeliveryAttempts: 5}}},
+		{Topics: []string{""}},
+	}
+	for _, spec := range invalid {
+		if err := client.EnsureTopology(ctx, spec); err == nil || errors.Is(err, pubsub.ErrTopologyMismatch) {
+			t.Fatalf("expected invalid spec error for %+v, got %v", spec, err)
+		}
+	}
+}
+
+func TestClientEnsureTopologyNotSupported(t *testing.T) {
+	ctx := context.Background()
+	client, err := pubsub.New(ctx, inmem.New())
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer client.Shutdown(ctx)
+
+	if err := client.EnsureTopology(ctx, pubsub.TopologySpec{Topics: []string{"orders"}}); !errors.Is(err, pubsub.ErrTopologyNotSupported) {
+		t.Fatalf("expected not supported, got %v", err)
+	}
+}
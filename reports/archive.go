@@ -0,0 +1,147 @@
+package reports
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ArchiveManifest describes the parts of a report archive, stored as
+// manifest.json when WithManifest is used
+type ArchiveManifest struct {
+	Format      string                `json:"format"`
+	Headers     []string              `json:"headers"`
+	TotalRows   int                   `json:"total_rows"`
+	GeneratedAt time.Time             `json:"generated_at"`
+	Metadata    *ReportMetadata       `json:"metadata,omitempty"`
+	Parts       []ArchiveManifestPart `json:"parts"`
+}
+
+// ArchiveManifestPart describes one file of a report archive
+type ArchiveManifestPart struct {
+	File     string `json:"file"`
+	Rows     int    `json:"rows"`
+	FirstRow int    `json:"first_row"` // 1-based index of the first data row in the full report, 0 for an empty part
+	LastRow  int    `json:"last_row"`  // 1-based index of the last data row in the full report, 0 for an empty part
+	Size     int    `json:"size"`
+	SHA256   string `json:"sha256"`
+}
+
+// GenerateReportArchive splits data into parts of at most MaxRowsPerFile rows
+// (100000 by default, see WithMaxRowsPerFile), renders each part with
+// GenerateReport and returns them as a zip archive. Parts are named
+// <ArchiveFileName>_part<N>.<ext>. Every part repeats the headers, title and
+// footer; the summary row is only added to the last part. With WithProgress,
+// progress is reported once per part. WithChecksum receives the checksum of
+// the archive, those of the parts are listed in the manifest.
+func GenerateReportArchive(ctx context.Context, format string, headers []string, data [][]string, opts ...ReportOption) ([]byte, error) {
+	// Get default options and apply provided options
+	options := getDefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.MaxRowsPerFile <= 0 {
+		return nil, fmt.Errorf("max rows per file must be positive, got %d", options.MaxRowsPerFile)
+	}
+
+	// Prepare up front so validation errors carry row numbers of the whole report
+	headers, data, err := prepareReport(options, headers, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate archive: %w", err)
+	}
+	opts = append(append([]ReportOption{}, opts...), WithCellSanitizer(nil), WithHeaderTranslator(nil), WithProgress(nil), WithChecksum(nil))
+	// Every part carries the same generation time
+	metadata := resolvedMetadata(options)
+	if metadata != nil {
+		opts = append(opts, WithMetadata(*metadata))
+	}
+
+	partCount := (len(data) + options.MaxRowsPerFile - 1) / options.MaxRowsPerFile
+	if partCount == 0 {
+		partCount = 1
+	}
+
+	// Summary rows belong to the whole report, so only the last part gets one
+	partOpts := append(append([]ReportOption{}, opts...), WithSummaryRow(nil))
+	lastPartOpts := opts
+
+	manifest := ArchiveManifest{
+		Format:      format,
+		Headers:     headers,
+		TotalRows:   len(data),
+		GeneratedAt: time.Now().UTC(),
+		Metadata:    metadata,
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for part := 0; part < partCount; part++ {
+		start := part * options.MaxRowsPerFile
+		end := min(start+options.MaxRowsPerFile, len(data))
+
+		reportOpts := partOpts
+		if part == partCount-1 {
+			reportOpts = lastPartOpts
+		}
+		content, ext, err := GenerateReport(ctx, format, headers, data[start:end], reportOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate part %d: %w", part+1, err)
+		}
+
+		name := fmt.Sprintf("%s_part%d.%s", options.ArchiveFileName, part+1, ext)
+		if err := writeArchiveFile(archive, name, content); err != nil {
+			return nil, err
+		}
+
+		manifestPart := ArchiveManifestPart{
+			File:   name,
+			Rows:   end - start,
+			Size:   len(content),
+			SHA256: checksum(content),
+		}
+		if end > start {
+			manifestPart.FirstRow = start + 1
+			manifestPart.LastRow = end
+		}
+		manifest.Parts = append(manifest.Parts, manifestPart)
+
+		if options.Progress != nil {
+			options.Progress(end, len(data))
+		}
+	}
+
+	if options.IncludeManifest {
+		content, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode manifest: %w", err)
+		}
+		if err := writeArchiveFile(archive, "manifest.json", content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
+	}
+
+	return finishReport(options, buf.Bytes()), nil
+}
+
+func writeArchiveFile(archive *zip.Writer, name string, content []byte) error {
+	w, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	if _, err := w.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package reports
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func readArchive(t *testing.T, content []byte) map[string][]byte {
+	t.Helper()
+
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file.Name, err)
+		}
+		files[file.Name] = data
+	}
+	return files
+}
+
+func TestGenerateReportArchive(t *testing.T) {
+	headers := []string{"ID", "Amount"}
+	data := [][]string{{"1", "10"}, {"2", "20"}, {"3", "30"}, {"4", "40"}, {"5", "50"}}
+
+	content, err := GenerateReportArchive(context.Background(), "csv", headers, data,
+		WithMaxRowsPerFile(2),
+		WithArchiveFileName("transactions"),
+		WithSummaryRow([]string{"Total", "150"}),
+		WithManifest(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to generate archive: %v", err)
+	}
+
+	files := readArchive(t, content)
+	if len(files) != 4 {
+		t.Fatalf("Expected 3 parts and a manifest, got %d files", len(files))
+	}
+
+	expected := map[string]string{
+		"transactions_part1.csv": "ID,Amount\n1,10\n2,20\n",
+		"transactions_part2.csv": "ID,Amount\n3,30\n4,40\n",
+		"transactions_part3.csv": "ID,Amount\n5,50\nTotal,150\n",
+	}
+	for name, want := range expected {
+		if got := string(files[name]); got != want {
+			t.Errorf("Expected %s to be %q, got %q", name, want, got)
+		}
+	}
+
+	var manifest ArchiveManifest
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+	if manifest.Format != "csv" || manifest.TotalRows != 5 || len(manifest.Parts) != 3 {
+		t.Fatalf("Unexpected manifest: %+v", manifest)
+	}
+	last := manifest.Parts[2]
+	if last.File != "transactions_part3.csv" || last.Rows != 1 || last.FirstRow != 5 || last.LastRow != 5 {
+		t.Errorf("Unexpected last part: %+v", last)
+	}
+	if last.Size != len(files[last.File]) || len(last.SHA256) != 64 {
+		t.Errorf("Unexpected size or checksum: %+v", last)
+	}
+}
+
+func TestGenerateReportArchive_Excel(t *testing.T) {
+	content, err := GenerateReportArchive(context.Background(), "excel", []string{"ID"}, [][]string{{"1"}, {"2"}, {"3"}}, WithMaxRowsPerFile(2))
+	if err != nil {
+		t.Fatalf("Failed to generate archive: %v", err)
+	}
+
+	files := readArchive(t, content)
+	if len(files) != 2 || files["report_part1.xlsx"] == nil || files["report_part2.xlsx"] == nil {
+		t.Errorf("Unexpected archive files: %d", len(files))
+	}
+	if _, ok := files["manifest.json"]; ok {
+		t.Error("Manifest should only be added with WithManifest")
+	}
+}
+
+func TestGenerateReportArchive_Empty(t *testing.T) {
+	content, err := GenerateReportArchive(context.Background(), "csv", []string{"ID"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate archive: %v", err)
+	}
+	if files := readArchive(t, content); string(files["report_part1.csv"]) != "ID\n" {
+		t.Errorf("Expected a single header-only part, got %v", files)
+	}
+
+	if _, err := GenerateReportArchive(context.Background(), "csv", []string{"ID"}, nil, WithMaxRowsPerFile(0)); err == nil {
+		t.Error("Expected error for non-positive max rows per file, got nil")
+	}
+}
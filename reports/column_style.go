@@ -0,0 +1,176 @@
+package reports
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Common Excel number formats for ColumnStyle.NumberFormat
+const (
+	NumberFormatInteger  = "#,##0"
+	NumberFormatDecimal  = "#,##0.00"
+	NumberFormatCurrency = "#,##0.00;[Red]-#,##0.00"
+	NumberFormatPercent  = "0.00%"
+	NumberFormatDate     = "yyyy-mm-dd"
+	NumberFormatDateTime = "yyyy-mm-dd hh:mm:ss"
+)
+
+// Column types for ColumnStyle.Type
+const (
+	ColumnTypeText   = "text"   // Kept as text, even with a NumberFormat
+	ColumnTypeNumber = "number" // Stored as a number, e.g. "1,234.50" becomes 1234.5
+	ColumnTypeDate   = "date"   // Stored as a date, displayed with NumberFormatDate or NumberFormatDateTime unless NumberFormat is set
+	ColumnTypeBool   = "bool"   // Stored as TRUE/FALSE
+)
+
+// ColumnStyle configures how the data cells of one column are rendered
+type ColumnStyle struct {
+	Align        string // "left", "center" or "right", defaults to right when NumberFormat is set
+	Bold         bool   // Bold font
+	TextColor    string // Hex text color, e.g. "#CC0000"
+	NumberFormat string // Excel only: number format, numeric and date values are then stored as numbers instead of text
+	Type         string // Excel only: ColumnType* hint of how text values are stored, detected from NumberFormat when empty
+}
+
+// dateLayouts are the layouts tried when storing a value of a column with a
+// NumberFormat as an Excel date
+var dateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC3339,
+}
+
+// alignment returns the horizontal alignment of the column
+func (c ColumnStyle) alignment() string {
+	if c.Align == "" && c.NumberFormat != "" {
+		return "right"
+	}
+	return c.Align
+}
+
+// cellValue converts value to the Excel type of the column, given by Type or
+// detected when the column has a NumberFormat, e.g. "1,234.50" becomes 1234.5.
+// Values that do not parse are kept as text. Numbers and dates are parsed
+// with the conventions of locale when it is not nil.
+func (c ColumnStyle) cellValue(value string, locale *Locale) any {
+	trimmed := strings.TrimSpace(value)
+	parseNumber, parseDate := parseNumber, parseDate
+	if locale != nil {
+		parseNumber, parseDate = locale.parseNumber, locale.parseDate
+	}
+	switch c.Type {
+	case ColumnTypeText:
+		return value
+	case ColumnTypeNumber:
+		if f, ok := parseNumber(trimmed); ok {
+			return f
+		}
+		return value
+	case ColumnTypeDate:
+		if t, ok := parseDate(trimmed); ok {
+			return t
+		}
+		return value
+	case ColumnTypeBool:
+		if b, err := strconv.ParseBool(trimmed); err == nil {
+			return b
+		}
+		return value
+	}
+
+	if c.NumberFormat == "" {
+		return value
+	}
+	if f, ok := parseNumber(trimmed); ok {
+		return f
+	}
+	if t, ok := parseDate(trimmed); ok {
+		return t
+	}
+	return value
+}
+
+func parseNumber(value string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	return f, err == nil
+}
+
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// withDateFormat returns the column style for a date cell, defaulting the
+// number format so Excel shows a date rather than its serial number
+func (c ColumnStyle) withDateFormat(t time.Time) ColumnStyle {
+	if c.NumberFormat != "" {
+		return c
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		c.NumberFormat = NumberFormatDate
+	} else {
+		c.NumberFormat = NumberFormatDateTime
+	}
+	return c
+}
+
+// excelStyle returns the Excel style of the column layered on top of base,
+// which may be nil
+func (c ColumnStyle) excelStyle(base *excelize.Style) *excelize.Style {
+	style := &excelize.Style{}
+	if base != nil {
+		*style = *base
+		if base.Font != nil {
+			font := *base.Font
+			style.Font = &font
+		}
+		if base.Alignment != nil {
+			alignment := *base.Alignment
+			style.Alignment = &alignment
+		}
+	}
+
+	if align := c.alignment(); align != "" {
+		if style.Alignment == nil {
+			style.Alignment = &excelize.Alignment{}
+		}
+		style.Alignment.Horizontal = align
+	}
+	if c.Bold || c.TextColor != "" {
+		if style.Font == nil {
+			style.Font = &excelize.Font{}
+		}
+		if c.Bold {
+			style.Font.Bold = true
+		}
+		if c.TextColor != "" {
+			style.Font.Color = c.TextColor
+		}
+	}
+	if c.NumberFormat != "" {
+		numberFormat := c.NumberFormat
+		style.CustomNumFmt = &numberFormat
+	}
+	return style
+}
+
+// pdfAlign returns the gofpdf alignment string of the column
+func (c ColumnStyle) pdfAlign() string {
+	switch c.alignment() {
+	case "center":
+		return "C"
+	case "right":
+		return "R"
+	case "left":
+		return "L"
+	default:
+		return ""
+	}
+}
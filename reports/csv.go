@@ -1,7 +1,6 @@
 package reports
 
 import (
-	"encoding/csv"
 	"fmt"
 	"io"
 	"os"
@@ -9,18 +8,28 @@ import (
 )
 
 type CSVExporter struct {
-	csvWriter *csv.Writer
-	file      *os.File
-	writer    io.Writer
-	headers   []string
-	hasHeader bool
+	csvWriter  csvRecordWriter
+	file       *os.File
+	writer     io.Writer
+	dialect    CSVDialect
+	bomPending bool
+	headers    []string
+	hasHeader  bool
 }
 
 func NewCSVExporter(w io.Writer) *CSVExporter {
+	return NewCSVExporterWithDialect(w, CSVDialect{})
+}
+
+// NewCSVExporterWithDialect creates a CSVExporter writing the given dialect,
+// e.g. semicolon separated with a BOM for Excel in EU locales
+func NewCSVExporterWithDialect(w io.Writer, dialect CSVDialect) *CSVExporter {
 	return &CSVExporter{
-		csvWriter: csv.NewWriter(w),
-		writer:    w,
-		hasHeader: false,
+		csvWriter:  newCSVRecordWriter(w, dialect),
+		writer:     w,
+		dialect:    dialect,
+		bomPending: dialect.BOM,
+		hasHeader:  false,
 	}
 }
 
@@ -30,18 +39,18 @@ func NewCSVExporterToFile(filename string) (*CSVExporter, error) {
 		return nil, fmt.Errorf("failed to create file %s: %w", filename, err)
 	}
 
-	return &CSVExporter{
-		csvWriter: csv.NewWriter(file),
-		file:      file,
-		writer:    file,
-		hasHeader: false,
-	}, nil
+	exporter := NewCSVExporter(file)
+	exporter.file = file
+	return exporter, nil
 }
 
 func (e *CSVExporter) WriteHeader(headers []string) error {
 	if e.hasHeader {
 		return fmt.Errorf("header has already been written")
 	}
+	if err := e.writeBOM(); err != nil {
+		return err
+	}
 
 	if err := e.csvWriter.Write(headers); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
@@ -68,6 +77,51 @@ func (e *CSVExporter) WriteData(data []string) error {
 	return nil
 }
 
+// WriteTitle writes a single field line above the header. Note that title and
+// footer lines make the file harder to parse for tools expecting a plain table.
+func (e *CSVExporter) WriteTitle(title string) error {
+	if e.hasHeader {
+		return fmt.Errorf("title must be written before header")
+	}
+	if err := e.writeBOM(); err != nil {
+		return err
+	}
+
+	if err := e.csvWriter.Write([]string{title}); err != nil {
+		return fmt.Errorf("failed to write title: %w", err)
+	}
+	return nil
+}
+
+// WriteSummaryRow writes a totals row after the data rows
+func (e *CSVExporter) WriteSummaryRow(data []string) error {
+	return e.WriteData(data)
+}
+
+// WriteFooter writes a single field line after the data rows
+func (e *CSVExporter) WriteFooter(text string) error {
+	if !e.hasHeader {
+		return fmt.Errorf("header must be written before footer")
+	}
+
+	if err := e.csvWriter.Write([]string{text}); err != nil {
+		return fmt.Errorf("failed to write footer: %w", err)
+	}
+	return nil
+}
+
+// writeBOM writes the byte order mark of the dialect ahead of the first line
+func (e *CSVExporter) writeBOM() error {
+	if !e.bomPending {
+		return nil
+	}
+	if _, err := io.WriteString(e.writer, utf8BOM); err != nil {
+		return fmt.Errorf("failed to write BOM: %w", err)
+	}
+	e.bomPending = false
+	return nil
+}
+
 func (e *CSVExporter) WriteDataRow(data []string) error {
 	return e.WriteData(data)
 }
@@ -0,0 +1,160 @@
+package reports
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// utf8BOM is the byte order mark Excel relies on to read a CSV file as UTF-8
+const utf8BOM = "\ufeff"
+
+var errInvalidCSVDelimiter = errors.New("invalid CSV delimiter")
+
+// CSVDialect describes the CSV flavour expected by the consumer of a report.
+// The zero value is comma separated with \n line endings, quoting only the
+// fields that need it.
+type CSVDialect struct {
+	Delimiter   rune // Field delimiter, ',' when zero, e.g. ';' for Excel in EU locales or '\t'
+	CRLF        bool // End lines with \r\n instead of \n
+	BOM         bool // Start the file with a UTF-8 byte order mark
+	AlwaysQuote bool // Quote every field, not only those with delimiters, quotes or line breaks
+}
+
+func (d CSVDialect) delimiter() rune {
+	if d.Delimiter == 0 {
+		return ','
+	}
+	return d.Delimiter
+}
+
+func (d CSVDialect) lineEnding() string {
+	if d.CRLF {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// csvRecordWriter is implemented by csv.Writer and quotingCSVWriter
+type csvRecordWriter interface {
+	Write(record []string) error
+	Flush()
+	Error() error
+}
+
+// newCSVRecordWriter returns a writer of records in the dialect d
+func newCSVRecordWriter(w io.Writer, d CSVDialect) csvRecordWriter {
+	if d.AlwaysQuote {
+		return &quotingCSVWriter{w: bufio.NewWriter(w), comma: d.delimiter(), useCRLF: d.CRLF}
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = d.delimiter()
+	writer.UseCRLF = d.CRLF
+	return writer
+}
+
+// quotingCSVWriter writes every field quoted, which encoding/csv does not
+// support, handling line breaks like csv.Writer
+type quotingCSVWriter struct {
+	w       *bufio.Writer
+	comma   rune
+	useCRLF bool
+}
+
+func (q *quotingCSVWriter) Write(record []string) error {
+	if !validCSVDelimiter(q.comma) {
+		return errInvalidCSVDelimiter
+	}
+	for i, field := range record {
+		if i > 0 {
+			q.w.WriteRune(q.comma)
+		}
+		q.w.WriteByte('"')
+		for _, r := range field {
+			switch r {
+			case '"':
+				q.w.WriteString(`""`)
+			case '\r':
+				if !q.useCRLF {
+					q.w.WriteByte('\r')
+				}
+			case '\n':
+				if q.useCRLF {
+					q.w.WriteString("\r\n")
+				} else {
+					q.w.WriteByte('\n')
+				}
+			default:
+				q.w.WriteRune(r)
+			}
+		}
+		q.w.WriteByte('"')
+	}
+	// bufio.Writer keeps its first error, returned by every later write
+	if q.useCRLF {
+		_, err := q.w.WriteString("\r\n")
+		return err
+	}
+	return q.w.WriteByte('\n')
+}
+
+func (q *quotingCSVWriter) Flush() {
+	_ = q.w.Flush()
+}
+
+func (q *quotingCSVWriter) Error() error {
+	_, err := q.w.Write(nil)
+	return err
+}
+
+// validCSVDelimiter applies the rules of csv.Writer
+func validCSVDelimiter(r rune) bool {
+	return r != 0 && r != '"' && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+}
+
+// WithCSVDialect sets the delimiter, line endings, BOM and quoting of CSV
+// reports at once, e.g. for a partner system
+func WithCSVDialect(dialect CSVDialect) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.CSVDialect = dialect
+	}
+}
+
+// WithCSVDelimiter separates the fields of CSV reports with delimiter instead
+// of a comma, e.g. ';' for Excel in locales using the decimal comma or '\t'
+func WithCSVDelimiter(delimiter rune) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.CSVDialect.Delimiter = delimiter
+	}
+}
+
+// WithCSVCRLF ends the lines of CSV reports with \r\n as in RFC 4180
+func WithCSVCRLF() ReportOption {
+	return func(opts *ReportOptions) {
+		opts.CSVDialect.CRLF = true
+	}
+}
+
+// WithCSVBOM starts CSV reports with a UTF-8 byte order mark, without which
+// Excel reads non-ASCII text as the legacy encoding of the locale
+func WithCSVBOM() ReportOption {
+	return func(opts *ReportOptions) {
+		opts.CSVDialect.BOM = true
+	}
+}
+
+// WithCSVAlwaysQuote quotes every field of CSV reports
+func WithCSVAlwaysQuote() ReportOption {
+	return func(opts *ReportOptions) {
+		opts.CSVDialect.AlwaysQuote = true
+	}
+}
+
+// commentLine returns a "# " comment line ending with eol. Line breaks in
+// text are replaced by spaces.
+func commentLine(text, eol string) string {
+	return "# " + strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(text) + eol
+}
@@ -2,9 +2,11 @@ package reports
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGenerateCSVFromData(t *testing.T) {
@@ -465,3 +467,104 @@ func TestCSVExporter_GenerateCSVWithSpecialCharacters(t *testing.T) {
 	t.Logf("Successfully generated CSV file with special characters: %s", filename)
 	t.Logf("File content:\n%s", string(content))
 }
+
+func TestGenerateCSVReport_TitleFooterSummary(t *testing.T) {
+	headers := []string{"Name", "Amount"}
+	data := [][]string{{"John", "10"}, {"Jane", "20"}}
+
+	content, err := GenerateCSVReport(context.Background(), headers, data,
+		WithTitle("Daily Report"),
+		WithSummaryRow([]string{"Total", "30"}),
+		WithFooter("Generated at 2024-01-01"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to generate CSV report: %v", err)
+	}
+
+	expected := "Daily Report\nName,Amount\nJohn,10\nJane,20\nTotal,30\nGenerated at 2024-01-01\n"
+	if string(content) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, content)
+	}
+}
+
+func TestCSVExporter_TitleAfterHeader(t *testing.T) {
+	exporter := NewCSVExporter(&bytes.Buffer{})
+	if err := exporter.WriteFooter("footer"); err == nil {
+		t.Error("Expected error writing footer before header, got nil")
+	}
+	if err := exporter.WriteHeader([]string{"Name"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := exporter.WriteTitle("title"); err == nil {
+		t.Error("Expected error writing title after header, got nil")
+	}
+}
+
+func TestGenerateCSVReport_Dialect(t *testing.T) {
+	headers := []string{"Name", "Amount"}
+	data := [][]string{{"José", "10,5"}, {"Say \"hi\"", "20"}}
+
+	tests := []struct {
+		name     string
+		opts     []ReportOption
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: "Name,Amount\nJosé,\"10,5\"\n\"Say \"\"hi\"\"\",20\n",
+		},
+		{
+			name:     "semicolon with BOM and CRLF",
+			opts:     []ReportOption{WithCSVDelimiter(';'), WithCSVBOM(), WithCSVCRLF()},
+			expected: "\ufeffName;Amount\r\nJosé;10,5\r\n\"Say \"\"hi\"\"\";20\r\n",
+		},
+		{
+			name:     "tab",
+			opts:     []ReportOption{WithCSVDelimiter('\t')},
+			expected: "Name\tAmount\nJosé\t10,5\n\"Say \"\"hi\"\"\"\t20\n",
+		},
+		{
+			name:     "always quote",
+			opts:     []ReportOption{WithCSVDialect(CSVDialect{Delimiter: ';', CRLF: true, AlwaysQuote: true})},
+			expected: "\"Name\";\"Amount\"\r\n\"José\";\"10,5\"\r\n\"Say \"\"hi\"\"\";\"20\"\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := GenerateCSVReport(context.Background(), headers, data, tt.opts...)
+			if err != nil {
+				t.Fatalf("Failed to generate CSV report: %v", err)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("Expected:\n%q\nGot:\n%q", tt.expected, content)
+			}
+		})
+	}
+}
+
+func TestGenerateCSVReport_DialectWithTitleAndComments(t *testing.T) {
+	content, err := GenerateCSVReport(context.Background(), []string{"Name"}, [][]string{{"line\nbreak"}},
+		WithCSVDialect(CSVDialect{BOM: true, CRLF: true, AlwaysQuote: true}),
+		WithMetadata(ReportMetadata{Generator: "backoffice", GeneratedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}),
+		WithTitle("Players"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to generate CSV report: %v", err)
+	}
+
+	expected := "\ufeff# generator: backoffice\r\n# generated_at: 2024-01-01T00:00:00Z\r\n\"Players\"\r\n\"Name\"\r\n\"line\r\nbreak\"\r\n"
+	if string(content) != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, content)
+	}
+}
+
+func TestGenerateCSVReport_InvalidDelimiter(t *testing.T) {
+	for _, opts := range [][]ReportOption{
+		{WithCSVDelimiter('"')},
+		{WithCSVDelimiter('\n'), WithCSVAlwaysQuote()},
+	} {
+		if _, err := GenerateCSVReport(context.Background(), []string{"Name"}, [][]string{{"John"}}, opts...); err == nil {
+			t.Error("Expected error for an invalid delimiter, got nil")
+		}
+	}
+}
@@ -2,7 +2,11 @@ package reports
 
 import (
 	"fmt"
+	"io"
+	"reflect"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -12,6 +16,11 @@ type ExcelExporter struct {
 	headers   []string
 	hasHeader bool
 	rowIndex  int
+	titleRows []int // Rows holding titles, merged across the columns once the header is known
+
+	columnStyles   map[int]ColumnStyle // Per column data cell styles, by 0-based index
+	columnStyleIDs map[ColumnStyle]int // Cached style IDs of column styles for rows without a row style
+	locale         *Locale             // Separators and date formats used to parse typed columns
 }
 
 func NewExcelExporter() *ExcelExporter {
@@ -56,7 +65,7 @@ func (e *ExcelExporter) WriteHeader(headers []string) error {
 	e.headers = headers
 	e.hasHeader = true
 	e.rowIndex++
-	return nil
+	return e.mergeTitleRows()
 }
 
 func (e *ExcelExporter) WriteHeaderWithStyle(headers []string, style *excelize.Style) error {
@@ -89,35 +98,106 @@ func (e *ExcelExporter) WriteHeaderWithStyle(headers []string, style *excelize.S
 	e.headers = headers
 	e.hasHeader = true
 	e.rowIndex++
-	return nil
+	return e.mergeTitleRows()
 }
 
 func (e *ExcelExporter) WriteData(data []string) error {
-	if !e.hasHeader {
-		return fmt.Errorf("header must be written before data")
+	return e.writeRow(data, nil)
+}
+
+func (e *ExcelExporter) WriteDataRow(data []string) error {
+	return e.WriteData(data)
+}
+
+func (e *ExcelExporter) WriteDataWithStyle(data []string, style *excelize.Style) error {
+	return e.writeRow(data, style)
+}
+
+// WriteTypedRow writes a data row of typed values, so numbers, dates and
+// booleans are stored as such instead of text: integers, floats,
+// decimal.Decimal, time.Time, bool and their pointers. Strings are converted
+// according to the column style like WriteData, nil is an empty cell and
+// other values are written with fmt.Sprint.
+func (e *ExcelExporter) WriteTypedRow(values []any) error {
+	return e.WriteTypedRowWithStyle(values, nil)
+}
+
+// WriteTypedRowWithStyle is WriteTypedRow with a row style
+func (e *ExcelExporter) WriteTypedRowWithStyle(values []any, style *excelize.Style) error {
+	cells := make([]any, len(values))
+	for colIndex, value := range values {
+		cells[colIndex] = e.typedCellValue(colIndex, value)
 	}
+	return e.writeCells(cells, style)
+}
 
-	if len(data) != len(e.headers) {
-		return fmt.Errorf("data length (%d) does not match header length (%d)", len(data), len(e.headers))
+// SetColumnStyle sets the style of the data cells of a column (0-based index)
+// for rows written afterwards. Values of a column with a Type or a
+// NumberFormat are stored as numbers, dates or booleans when they parse as
+// such.
+func (e *ExcelExporter) SetColumnStyle(index int, style ColumnStyle) {
+	if e.columnStyles == nil {
+		e.columnStyles = make(map[int]ColumnStyle)
+		e.columnStyleIDs = make(map[ColumnStyle]int)
 	}
+	e.columnStyles[index] = style
+}
+
+// SetLocale parses the values of typed columns written afterwards with the
+// separators and date formats of locale, e.g. "1.234,50" as 1234.5 for de-DE
+func (e *ExcelExporter) SetLocale(locale Locale) {
+	e.locale = &locale
+}
 
+func (e *ExcelExporter) writeRow(data []string, style *excelize.Style) error {
+	cells := make([]any, len(data))
 	for colIndex, value := range data {
-		cell := fmt.Sprintf("%s%d", getColumnName(colIndex+1), e.rowIndex)
-		err := e.file.SetCellValue(e.sheetName, cell, value)
-		if err != nil {
-			return fmt.Errorf("failed to write data at %s: %w", cell, err)
+		cells[colIndex] = value
+		if columnStyle, ok := e.columnStyles[colIndex]; ok {
+			cells[colIndex] = columnStyle.cellValue(value, e.locale)
 		}
 	}
-
-	e.rowIndex++
-	return nil
+	return e.writeCells(cells, style)
 }
 
-func (e *ExcelExporter) WriteDataRow(data []string) error {
-	return e.WriteData(data)
+// typedCellValue converts a value of WriteTypedRow to one excelize stores
+// with the matching Excel type
+func (e *ExcelExporter) typedCellValue(colIndex int, value any) any {
+	// Pointers, e.g. to nullable columns, hold the cell value, nil ones give
+	// an empty cell
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		return e.typedCellValue(colIndex, rv.Elem().Interface())
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if columnStyle, ok := e.columnStyles[colIndex]; ok {
+			return columnStyle.cellValue(v, e.locale)
+		}
+		return v
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool, time.Time:
+		return v
+	case decimal.Decimal:
+		return v.InexactFloat64()
+	case decimal.NullDecimal:
+		if !v.Valid {
+			return nil
+		}
+		return v.Decimal.InexactFloat64()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
 }
 
-func (e *ExcelExporter) WriteDataWithStyle(data []string, style *excelize.Style) error {
+// writeCells writes a data row of values already converted for excelize
+func (e *ExcelExporter) writeCells(data []any, style *excelize.Style) error {
 	if !e.hasHeader {
 		return fmt.Errorf("header must be written before data")
 	}
@@ -127,6 +207,9 @@ func (e *ExcelExporter) WriteDataWithStyle(data []string, style *excelize.Style)
 	}
 
 	for colIndex, value := range data {
+		if value == nil {
+			continue
+		}
 		cell := fmt.Sprintf("%s%d", getColumnName(colIndex+1), e.rowIndex)
 		err := e.file.SetCellValue(e.sheetName, cell, value)
 		if err != nil {
@@ -148,10 +231,133 @@ func (e *ExcelExporter) WriteDataWithStyle(data []string, style *excelize.Style)
 		}
 	}
 
+	if err := e.applyColumnStyles(data, style); err != nil {
+		return err
+	}
+
+	e.rowIndex++
+	return nil
+}
+
+// applyColumnStyles styles the cells of the current row that belong to
+// columns with a ColumnStyle, or hold a date, on top of the row style if any.
+func (e *ExcelExporter) applyColumnStyles(data []any, rowStyle *excelize.Style) error {
+	for colIndex, value := range data {
+		columnStyle, ok := e.columnStyles[colIndex]
+		if t, isTime := value.(time.Time); isTime {
+			columnStyle, ok = columnStyle.withDateFormat(t), true
+		}
+		if !ok {
+			continue
+		}
+
+		styleID, cached := e.columnStyleIDs[columnStyle]
+		if rowStyle != nil || !cached {
+			var err error
+			styleID, err = e.file.NewStyle(columnStyle.excelStyle(rowStyle))
+			if err != nil {
+				return fmt.Errorf("failed to create column style: %w", err)
+			}
+			if rowStyle == nil {
+				if e.columnStyleIDs == nil {
+					e.columnStyleIDs = make(map[ColumnStyle]int)
+				}
+				e.columnStyleIDs[columnStyle] = styleID
+			}
+		}
+
+		cell := fmt.Sprintf("%s%d", getColumnName(colIndex+1), e.rowIndex)
+		if err := e.file.SetCellStyle(e.sheetName, cell, cell, styleID); err != nil {
+			return fmt.Errorf("failed to apply column style at %s: %w", cell, err)
+		}
+	}
+	return nil
+}
+
+// WriteTitle writes a title line above the header, e.g. the report name or the
+// period it covers. Titles are merged across all columns once the header is
+// written. A nil style uses CreateTitleStyle.
+func (e *ExcelExporter) WriteTitle(title string, style *excelize.Style) error {
+	if e.hasHeader {
+		return fmt.Errorf("title must be written before header")
+	}
+
+	cell := fmt.Sprintf("A%d", e.rowIndex)
+	if err := e.file.SetCellValue(e.sheetName, cell, title); err != nil {
+		return fmt.Errorf("failed to write title at %s: %w", cell, err)
+	}
+	if style == nil {
+		style = CreateTitleStyle()
+	}
+	if err := e.applyStyle(cell, cell, style); err != nil {
+		return fmt.Errorf("failed to apply style to title: %w", err)
+	}
+
+	e.titleRows = append(e.titleRows, e.rowIndex)
 	e.rowIndex++
 	return nil
 }
 
+// WriteSummaryRow writes a totals row after the data rows. A nil style uses
+// CreateSummaryStyle.
+func (e *ExcelExporter) WriteSummaryRow(data []string, style *excelize.Style) error {
+	if style == nil {
+		style = CreateSummaryStyle()
+	}
+	return e.WriteDataWithStyle(data, style)
+}
+
+// WriteFooter writes a note below the data, e.g. generation time, merged
+// across all columns. A nil style uses CreateFooterStyle.
+func (e *ExcelExporter) WriteFooter(text string, style *excelize.Style) error {
+	if !e.hasHeader {
+		return fmt.Errorf("header must be written before footer")
+	}
+
+	startCell := fmt.Sprintf("A%d", e.rowIndex)
+	endCell := fmt.Sprintf("%s%d", getColumnName(len(e.headers)), e.rowIndex)
+	if err := e.file.SetCellValue(e.sheetName, startCell, text); err != nil {
+		return fmt.Errorf("failed to write footer at %s: %w", startCell, err)
+	}
+	if startCell != endCell {
+		if err := e.file.MergeCell(e.sheetName, startCell, endCell); err != nil {
+			return fmt.Errorf("failed to merge footer cells: %w", err)
+		}
+	}
+	if style == nil {
+		style = CreateFooterStyle()
+	}
+	if err := e.applyStyle(startCell, endCell, style); err != nil {
+		return fmt.Errorf("failed to apply style to footer: %w", err)
+	}
+
+	e.rowIndex++
+	return nil
+}
+
+func (e *ExcelExporter) mergeTitleRows() error {
+	if len(e.headers) < 2 {
+		return nil
+	}
+	lastColumn := getColumnName(len(e.headers))
+	for _, row := range e.titleRows {
+		startCell := fmt.Sprintf("A%d", row)
+		endCell := fmt.Sprintf("%s%d", lastColumn, row)
+		if err := e.file.MergeCell(e.sheetName, startCell, endCell); err != nil {
+			return fmt.Errorf("failed to merge title cells: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *ExcelExporter) applyStyle(startCell, endCell string, style *excelize.Style) error {
+	styleID, err := e.file.NewStyle(style)
+	if err != nil {
+		return fmt.Errorf("failed to create style: %w", err)
+	}
+	return e.file.SetCellStyle(e.sheetName, startCell, endCell, styleID)
+}
+
 func (e *ExcelExporter) SetColumnWidth(column int, width float64) error {
 	colName := getColumnName(column)
 	err := e.file.SetColWidth(e.sheetName, colName, colName, width)
@@ -188,6 +394,24 @@ func (e *ExcelExporter) Save(filename string) error {
 	return nil
 }
 
+// WriteTo writes the workbook to w, implementing io.WriterTo
+func (e *ExcelExporter) WriteTo(w io.Writer) (int64, error) {
+	n, err := e.file.WriteTo(w)
+	if err != nil {
+		return n, fmt.Errorf("failed to write Excel file: %w", err)
+	}
+	return n, nil
+}
+
+// Bytes returns the content of the workbook
+func (e *ExcelExporter) Bytes() ([]byte, error) {
+	buf, err := e.file.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write Excel file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 func (e *ExcelExporter) Close() error {
 	return nil
 }
@@ -247,3 +471,42 @@ func CreateDataStyle() *excelize.Style {
 		},
 	}
 }
+
+func CreateTitleStyle() *excelize.Style {
+	return &excelize.Style{
+		Font: &excelize.Font{
+			Bold: true,
+			Size: 14,
+		},
+		Alignment: &excelize.Alignment{
+			Horizontal: "center",
+			Vertical:   "center",
+		},
+	}
+}
+
+func CreateSummaryStyle() *excelize.Style {
+	return &excelize.Style{
+		Font: &excelize.Font{
+			Bold: true,
+		},
+		Border: []excelize.Border{
+			{Type: "left", Color: "000000", Style: 1},
+			{Type: "top", Color: "000000", Style: 6},
+			{Type: "bottom", Color: "000000", Style: 1},
+			{Type: "right", Color: "000000", Style: 1},
+		},
+	}
+}
+
+func CreateFooterStyle() *excelize.Style {
+	return &excelize.Style{
+		Font: &excelize.Font{
+			Italic: true,
+			Color:  "808080",
+		},
+		Alignment: &excelize.Alignment{
+			Horizontal: "left",
+		},
+	}
+}
@@ -1,8 +1,14 @@
 package reports
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
 )
 
 func TestExcelExporter_NewExcelExporter(t *testing.T) {
@@ -462,3 +468,300 @@ func TestExcelExporter_ComprehensiveDemo(t *testing.T) {
 	t.Logf("- Custom column widths")
 	t.Logf("- Custom row heights for different status")
 }
+
+func TestExcelExporter_TitleFooterSummary(t *testing.T) {
+	exporter := NewExcelExporter()
+
+	if err := exporter.WriteTitle("Daily Report", nil); err != nil {
+		t.Fatalf("Failed to write title: %v", err)
+	}
+	if err := exporter.WriteHeader([]string{"Name", "Age", "City"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := exporter.WriteData([]string{"John", "25", "New York"}); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+	if err := exporter.WriteSummaryRow([]string{"Total", "25", ""}, nil); err != nil {
+		t.Fatalf("Failed to write summary row: %v", err)
+	}
+	if err := exporter.WriteFooter("Generated at 2024-01-01", nil); err != nil {
+		t.Fatalf("Failed to write footer: %v", err)
+	}
+	if err := exporter.WriteTitle("Too late", nil); err == nil {
+		t.Error("Expected error writing title after header, got nil")
+	}
+
+	merged, err := exporter.file.GetMergeCells(exporter.sheetName)
+	if err != nil {
+		t.Fatalf("Failed to get merged cells: %v", err)
+	}
+	ranges := map[string]string{}
+	for _, cell := range merged {
+		ranges[cell.GetStartAxis()+":"+cell.GetEndAxis()] = cell.GetCellValue()
+	}
+	if ranges["A1:C1"] != "Daily Report" {
+		t.Errorf("Expected title merged across A1:C1, got %v", ranges)
+	}
+	if ranges["A5:C5"] != "Generated at 2024-01-01" {
+		t.Errorf("Expected footer merged across A5:C5, got %v", ranges)
+	}
+
+	total, err := exporter.file.GetCellValue(exporter.sheetName, "A4")
+	if err != nil || total != "Total" {
+		t.Errorf("Expected summary row at A4, got %q (%v)", total, err)
+	}
+}
+
+func TestGenerateExcelReport_TitleFooterSummary(t *testing.T) {
+	content, err := GenerateExcelReport(context.Background(), []string{"Name", "Amount"}, [][]string{{"John", "10"}},
+		WithTitle("Daily Report"),
+		WithSummaryRow([]string{"Total", "10"}),
+		WithFooter("Generated at 2024-01-01"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to generate Excel report: %v", err)
+	}
+	if len(content) == 0 {
+		t.Error("Generated Excel report is empty")
+	}
+}
+
+func TestExcelExporter_ColumnStyles(t *testing.T) {
+	exporter := NewExcelExporter()
+	exporter.SetColumnStyle(1, ColumnStyle{NumberFormat: NumberFormatCurrency})
+	exporter.SetColumnStyle(2, ColumnStyle{NumberFormat: NumberFormatDate, Align: "center"})
+
+	if err := exporter.WriteHeader([]string{"Name", "Amount", "Date"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := exporter.WriteData([]string{"John", "1,234.50", "2024-01-31"}); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+	if err := exporter.WriteData([]string{"Jane", "n/a", "unknown"}); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+	if err := exporter.WriteSummaryRow([]string{"Total", "1234.5", ""}, nil); err != nil {
+		t.Fatalf("Failed to write summary row: %v", err)
+	}
+
+	file := exporter.file
+	for cell, expected := range map[string]excelize.CellType{
+		"A2": excelize.CellTypeSharedString,
+		"B2": excelize.CellTypeUnset, // numbers are stored without a type attribute
+		"B3": excelize.CellTypeSharedString,
+		"C3": excelize.CellTypeSharedString,
+	} {
+		cellType, err := file.GetCellType(exporter.sheetName, cell)
+		if err != nil || cellType != expected {
+			t.Errorf("Expected %s to have type %v, got %v (%v)", cell, expected, cellType, err)
+		}
+	}
+	if raw, _ := file.GetCellValue(exporter.sheetName, "B2", excelize.Options{RawCellValue: true}); raw != "1234.5" {
+		t.Errorf("Expected B2 to hold 1234.5, got %q", raw)
+	}
+
+	for cell, expected := range map[string]string{"B2": "right", "C2": "center", "B4": "right"} {
+		styleID, err := file.GetCellStyle(exporter.sheetName, cell)
+		if err != nil {
+			t.Fatalf("Failed to get style of %s: %v", cell, err)
+		}
+		style, err := file.GetStyle(styleID)
+		if err != nil {
+			t.Fatalf("Failed to get style %d: %v", styleID, err)
+		}
+		if style.Alignment == nil || style.Alignment.Horizontal != expected {
+			t.Errorf("Expected %s to be aligned %s, got %+v", cell, expected, style.Alignment)
+		}
+		if style.CustomNumFmt == nil {
+			t.Errorf("Expected %s to have a number format", cell)
+		}
+		if cell == "B4" && (style.Font == nil || !style.Font.Bold) {
+			t.Error("Expected summary row style to be kept on styled columns")
+		}
+	}
+}
+
+func TestGenerateExcelReport_ColumnOptions(t *testing.T) {
+	content, err := GenerateExcelReport(context.Background(), []string{"Name", "Amount"}, [][]string{{"John", "10.5"}},
+		WithColumnWidths(30, 12),
+		WithNumberFormat(1, NumberFormatDecimal),
+		WithColumnStyle(0, ColumnStyle{Bold: true}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to generate Excel report: %v", err)
+	}
+
+	file, err := excelize.OpenReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to open generated Excel report: %v", err)
+	}
+	defer file.Close()
+
+	if width, _ := file.GetColWidth("Sheet1", "A"); width != 30 {
+		t.Errorf("Expected column A width 30, got %v", width)
+	}
+	if value, _ := file.GetCellValue("Sheet1", "B2"); value != "10.50" {
+		t.Errorf("Expected B2 to be displayed as 10.50, got %q", value)
+	}
+}
+
+func TestExcelExporter_WriteTypedRow(t *testing.T) {
+	exporter := NewExcelExporter()
+	exporter.SetColumnStyle(5, ColumnStyle{Type: ColumnTypeNumber})
+
+	if err := exporter.WriteHeader([]string{"Name", "Count", "Amount", "Created", "Active", "Score", "Note"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	created := time.Date(2024, 1, 31, 8, 30, 0, 0, time.UTC)
+	amount := decimal.RequireFromString("1234.56")
+	if err := exporter.WriteTypedRow([]any{"John", 3, amount, created, true, "1,000", nil}); err != nil {
+		t.Fatalf("Failed to write typed row: %v", err)
+	}
+	if err := exporter.WriteTypedRow([]any{"Jane"}); err == nil {
+		t.Error("Expected error for mismatched row length, got nil")
+	}
+
+	file := exporter.file
+	for cell, expected := range map[string]excelize.CellType{
+		"A2": excelize.CellTypeSharedString,
+		"B2": excelize.CellTypeUnset,
+		"C2": excelize.CellTypeUnset,
+		"D2": excelize.CellTypeUnset,
+		"E2": excelize.CellTypeBool,
+		"F2": excelize.CellTypeUnset,
+	} {
+		cellType, err := file.GetCellType(exporter.sheetName, cell)
+		if err != nil || cellType != expected {
+			t.Errorf("Expected %s to have type %v, got %v (%v)", cell, expected, cellType, err)
+		}
+	}
+	for cell, expected := range map[string]string{"B2": "3", "C2": "1234.56", "F2": "1000"} {
+		if raw, _ := file.GetCellValue(exporter.sheetName, cell, excelize.Options{RawCellValue: true}); raw != expected {
+			t.Errorf("Expected %s to hold %s, got %q", cell, expected, raw)
+		}
+	}
+	if value, _ := file.GetCellValue(exporter.sheetName, "D2"); value != "2024-01-31 08:30:00" {
+		t.Errorf("Expected D2 to be displayed as a date time, got %q", value)
+	}
+	if value, _ := file.GetCellValue(exporter.sheetName, "G2"); value != "" {
+		t.Errorf("Expected G2 to be empty, got %q", value)
+	}
+}
+
+func TestExcelExporter_WriteTypedRowPointers(t *testing.T) {
+	exporter := NewExcelExporter()
+	if err := exporter.WriteHeader([]string{"Int", "Int32", "Float32", "Uint", "Decimal", "Time", "String", "Nil"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	count, level, rate, id := 3, int32(7), float32(0.5), uint(42)
+	amount := decimal.RequireFromString("10.25")
+	created := time.Date(2024, 1, 31, 8, 30, 0, 0, time.UTC)
+	name := "John"
+	if err := exporter.WriteTypedRow([]any{&count, &level, &rate, &id, &amount, &created, &name, (*int)(nil)}); err != nil {
+		t.Fatalf("Failed to write typed row: %v", err)
+	}
+
+	file := exporter.file
+	for cell, expected := range map[string]string{"A2": "3", "B2": "7", "C2": "0.5", "D2": "42", "E2": "10.25", "G2": "John", "H2": ""} {
+		if raw, _ := file.GetCellValue(exporter.sheetName, cell, excelize.Options{RawCellValue: true}); raw != expected {
+			t.Errorf("Expected %s to hold %q, got %q", cell, expected, raw)
+		}
+	}
+	if value, _ := file.GetCellValue(exporter.sheetName, "F2"); value != "2024-01-31 08:30:00" {
+		t.Errorf("Expected F2 to be displayed as a date time, got %q", value)
+	}
+	for _, cell := range []string{"A2", "B2", "C2", "D2", "E2"} {
+		if cellType, _ := file.GetCellType(exporter.sheetName, cell); cellType != excelize.CellTypeUnset {
+			t.Errorf("Expected %s to be a number, got %v", cell, cellType)
+		}
+	}
+}
+
+func TestGenerateExcelReport_ColumnType(t *testing.T) {
+	content, err := GenerateExcelReport(context.Background(), []string{"Date", "Active", "Code"}, [][]string{{"2024-01-31", "true", "007"}},
+		WithColumnType(0, ColumnTypeDate),
+		WithColumnType(1, ColumnTypeBool),
+		WithColumnType(2, ColumnTypeText),
+		WithNumberFormat(2, NumberFormatInteger),
+	)
+	if err != nil {
+		t.Fatalf("Failed to generate Excel report: %v", err)
+	}
+
+	file, err := excelize.OpenReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to open generated Excel report: %v", err)
+	}
+	defer file.Close()
+
+	if value, _ := file.GetCellValue("Sheet1", "A2"); value != "2024-01-31" {
+		t.Errorf("Expected A2 to be displayed as a date, got %q", value)
+	}
+	if cellType, _ := file.GetCellType("Sheet1", "B2"); cellType != excelize.CellTypeBool {
+		t.Errorf("Expected B2 to be a boolean, got %v", cellType)
+	}
+	if value, _ := file.GetCellValue("Sheet1", "C2"); value != "007" {
+		t.Errorf("Expected C2 to be kept as text, got %q", value)
+	}
+}
+
+func TestExcelExporter_WriteTo(t *testing.T) {
+	exporter := NewExcelExporter()
+	if err := exporter.WriteHeader([]string{"ID", "Name"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := exporter.WriteData([]string{"1", "Alice"}); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := exporter.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo returned %d, wrote %d bytes", n, buf.Len())
+	}
+
+	content, err := exporter.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to open workbook: %v", err)
+	}
+	defer file.Close()
+	if value, _ := file.GetCellValue(file.GetSheetName(0), "B2"); value != "Alice" {
+		t.Errorf("Expected B2 to be Alice, got %q", value)
+	}
+}
+
+// TestGenerateExcelReport_Concurrent checks that concurrent reports do not
+// share any file
+func TestGenerateExcelReport_Concurrent(t *testing.T) {
+	results := make(chan []byte, 2)
+	for _, name := range []string{"Alice", "Bob"} {
+		go func() {
+			content, err := GenerateExcelReport(context.Background(), []string{"Name"}, [][]string{{name}})
+			if err != nil {
+				t.Errorf("Failed to generate report: %v", err)
+			}
+			results <- content
+		}()
+	}
+
+	names := map[string]bool{}
+	for range 2 {
+		file, err := excelize.OpenReader(bytes.NewReader(<-results))
+		if err != nil {
+			t.Fatalf("Failed to open workbook: %v", err)
+		}
+		value, _ := file.GetCellValue(file.GetSheetName(0), "A2")
+		names[value] = true
+		file.Close()
+	}
+	if !names["Alice"] || !names["Bob"] {
+		t.Errorf("Expected one report per name, got %v", names)
+	}
+}
@@ -51,6 +51,7 @@ func (f *FieldFormatter) FormatField(field string, value interface{}) (string, e
 type DateTimeFormatter struct {
 	TimeZone   string // e.g., "UTC+2"
 	TimeFormat string // e.g., "2006-01-02 15:04:05"
+	Locale     string // e.g., "de-DE", sets the default TimeFormat to the locale date time format
 }
 
 func (f *DateTimeFormatter) Format(value interface{}) (string, error) {
@@ -79,7 +80,14 @@ func (f *DateTimeFormatter) Format(value interface{}) (string, error) {
 	t := time.UnixMilli(timestamp).In(location)
 	format := f.TimeFormat
 	if format == "" {
+		locale, err := lookupLocale(f.Locale)
+		if err != nil {
+			return "", err
+		}
 		format = "2006-01-02 15:04:05" // Default format
+		if locale != nil {
+			format = locale.DateTimeFormat
+		}
 	}
 	return t.Format(format), nil
 }
@@ -99,6 +107,7 @@ type CurrencyAmountFormatter struct {
 	DefaultDecimalPlaces    int32  // Default decimal places if currency not found
 	DefaultThousandsSeparator   string // Default thousand separator, e.g., "," for 100,000 or "." for 100.000
 	DefaultDecimalSeparator string // Default decimal separator, e.g., "." for 100.00 or "," for 100,00
+	Locale                  string // e.g., "de-DE", sets the default separators left empty to the locale ones
 
 	// Dynamic currency support
 	CurrencyMap map[string]*Currency     // Map of currency code to currency info
@@ -107,15 +116,40 @@ type CurrencyAmountFormatter struct {
 
 func (f *CurrencyAmountFormatter) Format(value interface{}) (string, error) {
 	// Use default settings when no context available
-	return f.formatAmount(value, f.DefaultDecimalPlaces, f.DefaultThousandsSeparator, f.DefaultDecimalSeparator)
+	commaSep, decimalSep, err := f.defaultSeparators()
+	if err != nil {
+		return "", err
+	}
+	return f.formatAmount(value, f.DefaultDecimalPlaces, commaSep, decimalSep)
+}
+
+// defaultSeparators returns the default separators, filled from the locale
+// when not set
+func (f *CurrencyAmountFormatter) defaultSeparators() (string, string, error) {
+	commaSep, decimalSep := f.DefaultThousandsSeparator, f.DefaultDecimalSeparator
+	locale, err := lookupLocale(f.Locale)
+	if err != nil {
+		return "", "", err
+	}
+	if locale != nil {
+		if commaSep == "" {
+			commaSep = locale.ThousandsSeparator
+		}
+		if decimalSep == "" {
+			decimalSep = locale.DecimalSeparator
+		}
+	}
+	return commaSep, decimalSep, nil
 }
 
 // FormatWithContext formats amount using currency-specific settings if available
 func (f *CurrencyAmountFormatter) FormatWithContext(value interface{}, context interface{}) (string, error) {
 	// Start with defaults
 	decimalPlaces := f.DefaultDecimalPlaces
-	commaSep := f.DefaultThousandsSeparator
-	decimalSep := f.DefaultDecimalSeparator
+	commaSep, decimalSep, err := f.defaultSeparators()
+	if err != nil {
+		return "", err
+	}
 
 	// Override with currency-specific settings if available
 	if f.CurrencyMap != nil && f.GetCurrency != nil && context != nil {
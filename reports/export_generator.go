@@ -2,30 +2,70 @@ package reports
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"os"
+	"time"
 )
 
 // GenerateCSVReport generates a CSV report
-func GenerateCSVReport(headers []string, data [][]string, opts ...ReportOption) ([]byte, error) {
+func GenerateCSVReport(ctx context.Context, headers []string, data [][]string, opts ...ReportOption) ([]byte, error) {
 	// Get default options and apply provided options
 	options := getDefaultOptions()
 	for _, opt := range opts {
 		opt(options)
 	}
+	headers, data, err := prepareReport(options, headers, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CSV: %w", err)
+	}
 
 	var buf bytes.Buffer
-
-	err := WriteCSVToWriterWithHeaders(&buf, headers, data)
-	if err != nil {
+	exporter := NewCSVExporterWithDialect(&buf, options.CSVDialect)
+	if metadata := resolvedMetadata(options); metadata != nil {
+		for _, line := range metadata.commentLines() {
+			if err := exporter.WriteComment(line); err != nil {
+				return nil, fmt.Errorf("failed to generate CSV: %w", err)
+			}
+		}
+	}
+	if options.Title != "" {
+		if err := exporter.WriteTitle(options.Title); err != nil {
+			return nil, fmt.Errorf("failed to generate CSV: %w", err)
+		}
+	}
+	if err := exporter.WriteHeader(headers); err != nil {
+		return nil, fmt.Errorf("failed to generate CSV: %w", err)
+	}
+	progress := newRowProgress(ctx, options, len(data))
+	for _, row := range data {
+		if err := progress.check(); err != nil {
+			return nil, fmt.Errorf("failed to generate CSV: %w", err)
+		}
+		if err := exporter.WriteData(row); err != nil {
+			return nil, fmt.Errorf("failed to generate CSV: %w", err)
+		}
+		progress.add()
+	}
+	progress.finish()
+	if options.SummaryRow != nil {
+		if err := exporter.WriteSummaryRow(options.SummaryRow); err != nil {
+			return nil, fmt.Errorf("failed to generate CSV: %w", err)
+		}
+	}
+	if options.Footer != "" {
+		if err := exporter.WriteFooter(options.Footer); err != nil {
+			return nil, fmt.Errorf("failed to generate CSV: %w", err)
+		}
+	}
+	if err := exporter.Flush(); err != nil {
 		return nil, fmt.Errorf("failed to generate CSV: %w", err)
 	}
 
-	return buf.Bytes(), nil
+	return finishReport(options, buf.Bytes()), nil
 }
 
 // GenerateExcelReport generates an Excel report with customizable header color
-func GenerateExcelReport(headers []string, data [][]string, opts ...ReportOption) ([]byte, error) {
+func GenerateExcelReport(ctx context.Context, headers []string, data [][]string, opts ...ReportOption) ([]byte, error) {
 	exporter := NewExcelExporter()
 
 	// Get default options and apply provided options
@@ -33,6 +73,29 @@ func GenerateExcelReport(headers []string, data [][]string, opts ...ReportOption
 	for _, opt := range opts {
 		opt(options)
 	}
+	headers, data, err := prepareReport(options, headers, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Excel: %w", err)
+	}
+
+	if options.Title != "" {
+		if err := exporter.WriteTitle(options.Title, nil); err != nil {
+			return nil, fmt.Errorf("failed to write Excel title: %w", err)
+		}
+	}
+
+	if metadata := resolvedMetadata(options); metadata != nil {
+		if err := exporter.SetMetadata(*metadata); err != nil {
+			return nil, err
+		}
+	}
+	if options.Locale != "" {
+		locale, _ := LookupLocale(options.Locale) // checked by prepareReport
+		exporter.SetLocale(locale)
+	}
+	for index, style := range options.ColumnStyles {
+		exporter.SetColumnStyle(index, style)
+	}
 
 	// Write headers with style
 	headerStyle := CreateHeaderStyle(options.HeaderColor)
@@ -40,25 +103,49 @@ func GenerateExcelReport(headers []string, data [][]string, opts ...ReportOption
 		return nil, fmt.Errorf("failed to write Excel headers: %w", err)
 	}
 
+	for i, width := range options.ColumnWidths {
+		if err := exporter.SetColumnWidth(i+1, width); err != nil {
+			return nil, fmt.Errorf("failed to set Excel column width: %w", err)
+		}
+	}
+
 	// Write data rows
+	progress := newRowProgress(ctx, options, len(data))
 	for _, row := range data {
+		if err := progress.check(); err != nil {
+			return nil, fmt.Errorf("failed to generate Excel: %w", err)
+		}
 		if err := exporter.WriteDataRow(row); err != nil {
 			return nil, fmt.Errorf("failed to write Excel data row: %w", err)
 		}
+		progress.add()
 	}
+	progress.finish()
 
-	// Save to temporary file and read back
-	tempFile := fmt.Sprintf("/tmp/export_%d.xlsx", os.Getpid())
-	if err := exporter.Save(tempFile); err != nil {
-		return nil, fmt.Errorf("failed to save Excel: %w", err)
+	if options.SummaryRow != nil {
+		if err := exporter.WriteSummaryRow(options.SummaryRow, nil); err != nil {
+			return nil, fmt.Errorf("failed to write Excel summary row: %w", err)
+		}
+	}
+	if options.Footer != "" {
+		if err := exporter.WriteFooter(options.Footer, nil); err != nil {
+			return nil, fmt.Errorf("failed to write Excel footer: %w", err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to generate Excel: %w", err)
 	}
-	defer os.Remove(tempFile)
 
-	return os.ReadFile(tempFile)
+	content, err := exporter.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save Excel: %w", err)
+	}
+	return finishReport(options, content), nil
 }
 
 // GeneratePDFReport generates a PDF report with customizable header color
-func GeneratePDFReport(headers []string, data [][]string, opts ...ReportOption) ([]byte, error) {
+func GeneratePDFReport(ctx context.Context, headers []string, data [][]string, opts ...ReportOption) ([]byte, error) {
 	exporter := NewPDFExporter()
 
 	// Get default options and apply provided options
@@ -66,6 +153,39 @@ func GeneratePDFReport(headers []string, data [][]string, opts ...ReportOption)
 	for _, opt := range opts {
 		opt(options)
 	}
+	headers, data, err := prepareReport(options, headers, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	if options.PDFFont != nil {
+		if err := exporter.SetUTF8Font(options.PDFFont.Family, options.PDFFont.RegularPath, options.PDFFont.BoldPath); err != nil {
+			return nil, err
+		}
+	}
+	if metadata := resolvedMetadata(options); metadata != nil {
+		exporter.SetMetadata(*metadata)
+	}
+
+	if options.Title != "" {
+		if err := exporter.WriteTitle(options.Title, nil); err != nil {
+			return nil, fmt.Errorf("failed to write PDF title: %w", err)
+		}
+	}
+	if options.Footer != "" {
+		if err := exporter.WriteFooter(options.Footer); err != nil {
+			return nil, fmt.Errorf("failed to write PDF footer: %w", err)
+		}
+	}
+
+	for index, style := range options.ColumnStyles {
+		exporter.SetColumnStyle(index, style)
+	}
+	if len(options.ColumnWidths) > 0 {
+		if err := exporter.SetColumnWidths(options.ColumnWidths); err != nil {
+			return nil, fmt.Errorf("failed to set PDF column widths: %w", err)
+		}
+	}
 
 	// Set up header style
 	headerStyle := CreatePDFHeaderStyle(options.HeaderColor)
@@ -76,25 +196,64 @@ func GeneratePDFReport(headers []string, data [][]string, opts ...ReportOption)
 	}
 
 	// Write data rows
+	progress := newRowProgress(ctx, options, len(data))
 	for _, row := range data {
+		if err := progress.check(); err != nil {
+			return nil, fmt.Errorf("failed to generate PDF: %w", err)
+		}
 		if err := exporter.WriteData(row); err != nil {
 			return nil, fmt.Errorf("failed to write PDF data row: %w", err)
 		}
+		progress.add()
 	}
+	progress.finish()
 
-	// Save to temporary file and read back
-	tempFile := fmt.Sprintf("/tmp/export_%d.pdf", os.Getpid())
-	if err := exporter.Save(tempFile); err != nil {
-		return nil, fmt.Errorf("failed to save PDF: %w", err)
+	if options.SummaryRow != nil {
+		if err := exporter.WriteSummaryRow(options.SummaryRow, nil); err != nil {
+			return nil, fmt.Errorf("failed to write PDF summary row: %w", err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
 	}
-	defer os.Remove(tempFile)
 
-	return os.ReadFile(tempFile)
+	content, err := exporter.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save PDF: %w", err)
+	}
+	return finishReport(options, content), nil
 }
 
 // ReportOptions contains all report configuration options
 type ReportOptions struct {
-	HeaderColor string // Hex color for both Excel and PDF (e.g., "#E0E0E0")
+	HeaderColor      string              // Hex color for both Excel and PDF (e.g., "#E0E0E0")
+	FlushRows        int                 // Streaming only: flush after this many rows (0 disables)
+	FlushInterval    time.Duration       // Streaming only: flush when this much time passed since the last flush (0 disables)
+	JSONKeys         []string            // JSON/NDJSON only: object keys to use instead of the headers
+	PDFFont          *PDFFont            // PDF only: UTF-8 TrueType font, required for non-Latin text
+	Title            string              // CSV, Excel and PDF: title line above the header
+	Footer           string              // CSV, Excel and PDF: note below the data, PDF shows it on every page with page numbers
+	SummaryRow       []string            // CSV, Excel and PDF: totals row after the data, must match the header length
+	ColumnStyles     map[int]ColumnStyle // Excel and PDF: per column (0-based) alignment, font, number format and type
+	ColumnWidths     []float64           // Excel and PDF: column widths, in characters for Excel and relative for PDF
+	MaxRowsPerFile   int                 // Archive only: data rows per part file
+	ArchiveFileName  string              // Archive only: base name of the part files, e.g. "report" for report_part1.csv
+	IncludeManifest  bool                // Archive only: add a manifest.json describing each part
+	CellSanitizer    *CellSanitizer      // Cleans header and data cells before writing, nil disables
+	Locale           string              // BCP 47 tag used to translate headers and, in Excel, parse typed columns
+	HeaderTranslator HeaderTranslator    // Translates the headers into Locale
+	Progress         ProgressFunc        // Called as data rows are written, see WithProgress
+	Metadata         *ReportMetadata     // CSV, Excel and PDF: generation details embedded in the file
+	Checksum         *string             // Receives the hex encoded SHA-256 of the generated report
+	CSVDialect       CSVDialect          // CSV only: delimiter, line endings, BOM and quoting
+}
+
+// PDFFont describes a TrueType font to register with the PDF exporter
+type PDFFont struct {
+	Family      string // Name to register the font under, e.g. "NotoSansSC"
+	RegularPath string // Path to the regular .ttf file
+	BoldPath    string // Optional path to the bold .ttf file, defaults to RegularPath
 }
 
 // ReportOption is a function that configures ReportOptions
@@ -107,43 +266,173 @@ func WithHeaderColor(color string) ReportOption {
 	}
 }
 
+// WithFlushRows sets how many rows a StreamingReportWriter buffers before flushing
+func WithFlushRows(rows int) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.FlushRows = rows
+	}
+}
+
+// WithFlushInterval sets the max time a StreamingReportWriter keeps rows buffered
+func WithFlushInterval(interval time.Duration) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.FlushInterval = interval
+	}
+}
+
+// WithJSONKeys sets the object keys used by the JSON and NDJSON formats, e.g.
+// "user_id" instead of the "User ID" header. Must match the header count.
+func WithJSONKeys(keys ...string) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.JSONKeys = keys
+	}
+}
+
+// WithTitle adds a title line above the header
+func WithTitle(title string) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.Title = title
+	}
+}
+
+// WithFooter adds a footer, e.g. "Generated at 2024-01-01 00:00 UTC"
+func WithFooter(footer string) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.Footer = footer
+	}
+}
+
+// WithSummaryRow adds a styled totals row after the data rows
+func WithSummaryRow(row []string) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.SummaryRow = row
+	}
+}
+
+// WithColumnStyle sets the style of the data cells of a column (0-based index)
+func WithColumnStyle(index int, style ColumnStyle) ReportOption {
+	return func(opts *ReportOptions) {
+		if opts.ColumnStyles == nil {
+			opts.ColumnStyles = make(map[int]ColumnStyle)
+		}
+		opts.ColumnStyles[index] = style
+	}
+}
+
+// WithColumnWidths sets the column widths, in characters for Excel and as
+// relative proportions of the page width for PDF
+func WithColumnWidths(widths ...float64) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.ColumnWidths = widths
+	}
+}
+
+// WithNumberFormat stores the values of a column (0-based index) as numbers or
+// dates in Excel, displayed with format, e.g. NumberFormatCurrency. Values that
+// do not parse are kept as text.
+func WithNumberFormat(index int, format string) ReportOption {
+	return func(opts *ReportOptions) {
+		if opts.ColumnStyles == nil {
+			opts.ColumnStyles = make(map[int]ColumnStyle)
+		}
+		style := opts.ColumnStyles[index]
+		style.NumberFormat = format
+		opts.ColumnStyles[index] = style
+	}
+}
+
+// WithColumnType sets how the values of a column (0-based index) are stored
+// in Excel, one of the ColumnType* constants, so they sort and sum as numbers,
+// dates or booleans. Values that do not parse are kept as text.
+func WithColumnType(index int, columnType string) ReportOption {
+	return func(opts *ReportOptions) {
+		if opts.ColumnStyles == nil {
+			opts.ColumnStyles = make(map[int]ColumnStyle)
+		}
+		style := opts.ColumnStyles[index]
+		style.Type = columnType
+		opts.ColumnStyles[index] = style
+	}
+}
+
+// WithMaxRowsPerFile sets how many data rows GenerateReportArchive puts in
+// each part file
+func WithMaxRowsPerFile(rows int) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.MaxRowsPerFile = rows
+	}
+}
+
+// WithArchiveFileName sets the base name of the files in a report archive
+func WithArchiveFileName(name string) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.ArchiveFileName = name
+	}
+}
+
+// WithManifest adds a manifest.json describing each part to a report archive
+func WithManifest() ReportOption {
+	return func(opts *ReportOptions) {
+		opts.IncludeManifest = true
+	}
+}
+
+// WithPDFUTF8Font renders the PDF with the given TrueType font so that
+// non-Latin text (e.g. Chinese) is not garbled
+func WithPDFUTF8Font(family, regularPath, boldPath string) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.PDFFont = &PDFFont{Family: family, RegularPath: regularPath, BoldPath: boldPath}
+	}
+}
+
 // getDefaultOptions returns default report options
 func getDefaultOptions() *ReportOptions {
 	return &ReportOptions{
-		HeaderColor: "#E0E0E0", // Default light gray
+		HeaderColor:     "#E0E0E0", // Default light gray
+		FlushRows:       1000,
+		MaxRowsPerFile:  100000,
+		ArchiveFileName: "report",
 	}
 }
 
 // Example usage:
 //
 // // Use default options (light gray header)
-// data, ext, err := GenerateReport("excel", headers, data)
-// data, err := GenerateExcelReport(headers, data)
-// data, err := GeneratePDFReport(headers, data)
-// data, err := GenerateCSVReport(headers, data)
+// data, ext, err := GenerateReport(ctx, "excel", headers, data)
+// data, err := GenerateExcelReport(ctx, headers, data)
+// data, err := GeneratePDFReport(ctx, headers, data)
+// data, err := GenerateCSVReport(ctx, headers, data)
 //
 // // Use custom header color
-// data, ext, err := GenerateReport("excel", headers, data, WithHeaderColor("#FF5733"))
-// data, err := GenerateExcelReport(headers, data, WithHeaderColor("#FF5733"))
-// data, err := GeneratePDFReport(headers, data, WithHeaderColor("#FF5733"))
-// data, err := GenerateCSVReport(headers, data, WithHeaderColor("#FF5733"))
+// data, ext, err := GenerateReport(ctx, "excel", headers, data, WithHeaderColor("#FF5733"))
+// data, err := GenerateExcelReport(ctx, headers, data, WithHeaderColor("#FF5733"))
+// data, err := GeneratePDFReport(ctx, headers, data, WithHeaderColor("#FF5733"))
+// data, err := GenerateCSVReport(ctx, headers, data, WithHeaderColor("#FF5733"))
 
 // GenerateReport generates a report in the specified format with optional customization
-// Supported formats: csv, excel, xlsx, pdf
+// Supported formats: csv, excel, xlsx, pdf, json, ndjson
 // Defaults to CSV for unknown formats
-func GenerateReport(format string, headers []string, data [][]string, opts ...ReportOption) ([]byte, string, error) {
+// The context is checked between rows, so a canceled export, e.g. when the
+// client disconnects, stops early with the context error.
+func GenerateReport(ctx context.Context, format string, headers []string, data [][]string, opts ...ReportOption) ([]byte, string, error) {
 	switch format {
-	case "excel":
-		content, err := GenerateExcelReport(headers, data, opts...)
+	case "excel", "xlsx":
+		content, err := GenerateExcelReport(ctx, headers, data, opts...)
 		return content, "xlsx", err
 	case "pdf":
-		content, err := GeneratePDFReport(headers, data, opts...)
+		content, err := GeneratePDFReport(ctx, headers, data, opts...)
 		return content, "pdf", err
+	case "json":
+		content, err := GenerateJSONReport(ctx, headers, data, opts...)
+		return content, "json", err
+	case "ndjson":
+		content, err := GenerateNDJSONReport(ctx, headers, data, opts...)
+		return content, "ndjson", err
 	case "csv":
 		fallthrough
 	default:
 		// Default to CSV for any unknown format
-		content, err := GenerateCSVReport(headers, data, opts...)
+		content, err := GenerateCSVReport(ctx, headers, data, opts...)
 		return content, "csv", err
 	}
 }
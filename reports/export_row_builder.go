@@ -1,6 +1,7 @@
 package reports
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -22,11 +23,16 @@ type RowBuilder struct {
 
 // FieldConfig defines how to extract and format a field
 type FieldConfig struct {
-	Field      string    // Field name or path (e.g., "UserId" or "User.Name", could be field's name or json tag name)
-	Formatter  Formatter // Formatter to apply (nil means use OriginalFormatter)
-	UseContext bool      // Whether to use FormatWithContext if available
+	Field      string      // Field name or path (e.g., "UserId" or "User.Name", could be field's name, json tag name or map key)
+	Formatter  Formatter   // Formatter to apply (nil means use OriginalFormatter)
+	UseContext bool        // Whether to use FormatWithContext if available
+	Compute    ComputeFunc // Computes the column from the whole item instead of reading Field
 }
 
+// ComputeFunc derives a column value from a whole item, e.g. a total from
+// several fields
+type ComputeFunc func(item any) (string, error)
+
 // NewRowBuilder creates a new row builder
 func NewRowBuilder() *RowBuilder {
 	return &RowBuilder{
@@ -60,6 +66,16 @@ func (b *RowBuilder) AddWithContext(field string, formatter Formatter) *RowBuild
 	return b
 }
 
+// AddComputed adds a column computed from the whole item. name is only used
+// in error messages.
+func (b *RowBuilder) AddComputed(name string, compute ComputeFunc) *RowBuilder {
+	b.fields = append(b.fields, FieldConfig{
+		Field:   name,
+		Compute: compute,
+	})
+	return b
+}
+
 // AddMultiple adds multiple fields with the same formatter
 func (b *RowBuilder) AddMultiple(fields []string, formatter Formatter) *RowBuilder {
 	for _, field := range fields {
@@ -95,7 +111,10 @@ func getFieldIndexes(t reflect.Type) map[string]fieldIndex {
 	return indexes
 }
 
-// Build processes the data and returns formatted rows
+// Build processes the data and returns formatted rows. data must be a slice
+// of structs, pointers to structs or maps with string keys. Fields are
+// resolved by dotted path through nested structs, pointers and maps; a nil
+// pointer or missing map key along the path yields an empty cell.
 func (b *RowBuilder) Build(data interface{}) ([][]string, error) {
 	// Convert data to slice
 	slice := reflect.ValueOf(data)
@@ -107,95 +126,178 @@ func (b *RowBuilder) Build(data interface{}) ([][]string, error) {
 		return [][]string{}, nil
 	}
 
-	// Get the type from the first element and cache field indexes
-	firstItem := slice.Index(0)
-	itemType := firstItem.Type()
-	if itemType.Kind() == reflect.Ptr {
-		itemType = itemType.Elem()
+	paths, err := b.fieldPaths(slice.Index(0))
+	if err != nil {
+		return nil, err
 	}
-	indexes := getFieldIndexes(itemType)
 
-	// Pre-compile field accessors for all configured fields
-	type fieldAccessor struct {
-		fieldIndex int
-		formatter  Formatter
-		useContext bool
-	}
-	accessors := make([]fieldAccessor, len(b.fields))
+	rows := make([][]string, 0, slice.Len())
 
-	for i, field := range b.fields {
-		if idx, ok := indexes[field.Field]; ok && idx.isValid {
-			accessors[i] = fieldAccessor{
-				fieldIndex: idx.index,
-				formatter:  field.Formatter,
-				useContext: field.UseContext,
-			}
-		} else {
-			return nil, fmt.Errorf("field %s not found", field.Field)
+	// Process each item
+	for i := 0; i < slice.Len(); i++ {
+		row, err := b.buildRow(slice.Index(i), paths)
+		if err != nil {
+			return nil, err
 		}
+		rows = append(rows, row)
 	}
 
-	rows := make([][]string, 0, slice.Len())
+	return rows, nil
+}
 
-	// Process each item
-	for i := 0; i < slice.Len(); i++ {
-		item := slice.Index(i)
-		itemInterface := item.Interface()
-
-		// Handle pointer if necessary
-		itemValue := item
-		if itemValue.Kind() == reflect.Ptr {
-			if itemValue.IsNil() {
-				// Skip nil items or handle as needed
-				row := make([]string, len(b.fields))
-				rows = append(rows, row)
-				continue
+// BuildStream formats the items returned by next one at a time and passes
+// each row to sink, e.g. StreamingReportWriter.WriteRow, so that exports of
+// paginated queries never hold more than a page in memory. next returns false
+// once there are no more items; an error from next or sink stops the build
+// and is returned. Items are resolved like in Build, the field paths being
+// checked against the first non-nil item.
+func (b *RowBuilder) BuildStream(next func() (any, bool, error), sink func(row []string) error) error {
+	var paths [][]string
+	for {
+		item, ok, err := next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+
+		value := reflect.ValueOf(item)
+		if paths == nil && !isNilItem(value) {
+			if paths, err = b.fieldPaths(value); err != nil {
+				return err
 			}
-			itemValue = itemValue.Elem()
-		}
-
-		row := make([]string, len(b.fields))
-
-		for j, accessor := range accessors {
-			// Extract value using cached index
-			value := itemValue.Field(accessor.fieldIndex).Interface()
-
-			// Apply formatter with context support if requested
-			var formatted string
-			var err error
-
-			if accessor.useContext {
-				// User explicitly wants context formatting
-				if contextFormatter, ok := accessor.formatter.(ContextFormatter); ok {
-					formatted, err = contextFormatter.FormatWithContext(value, itemInterface)
-				} else {
-					// Fallback to regular Format if formatter doesn't support context
-					if accessor.formatter != nil {
-						formatted, err = accessor.formatter.Format(value)
-					} else {
-						formatted, err = (&OriginalFormatter{}).Format(value)
-					}
-				}
-			} else {
-				// Use regular Format
-				if accessor.formatter != nil {
-					formatted, err = accessor.formatter.Format(value)
-				} else {
-					formatted, err = (&OriginalFormatter{}).Format(value)
-				}
+		}
+		row, err := b.buildRow(value, paths)
+		if err != nil {
+			return err
+		}
+		if err := sink(row); err != nil {
+			return err
+		}
+	}
+}
+
+// ChannelSource adapts a channel to the next function of BuildStream, which
+// stops once items is closed or ctx is done, with the context error.
+func ChannelSource[T any](ctx context.Context, items <-chan T) func() (any, bool, error) {
+	return func() (any, bool, error) {
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case item, ok := <-items:
+			if !ok {
+				return nil, false, nil
 			}
+			return item, true, nil
+		}
+	}
+}
 
+// fieldPaths pre-splits the field paths and checks them against first so
+// that typos fail fast instead of producing empty columns
+func (b *RowBuilder) fieldPaths(first reflect.Value) ([][]string, error) {
+	paths := make([][]string, len(b.fields))
+	for i, field := range b.fields {
+		if field.Compute != nil {
+			continue
+		}
+		paths[i] = strings.Split(field.Field, ".")
+		if _, _, err := resolveField(first, paths[i]); err != nil {
+			return nil, fmt.Errorf("field %s not found: %w", field.Field, err)
+		}
+	}
+	return paths, nil
+}
+
+// buildRow formats the fields of item, a nil item giving an empty row
+func (b *RowBuilder) buildRow(item reflect.Value, paths [][]string) ([]string, error) {
+	row := make([]string, len(b.fields))
+	if isNilItem(item) {
+		return row, nil
+	}
+	itemInterface := item.Interface()
+
+	for j, field := range b.fields {
+		if field.Compute != nil {
+			computed, err := field.Compute(itemInterface)
 			if err != nil {
-				// Fallback to string representation
-				formatted = fmt.Sprintf("%v", value)
+				return nil, fmt.Errorf("failed to compute %s: %w", field.Field, err)
 			}
+			row[j] = computed
+			continue
+		}
 
-			row[j] = formatted
+		fieldValue, ok, err := resolveField(item, paths[j])
+		if err != nil {
+			return nil, fmt.Errorf("field %s not found: %w", field.Field, err)
+		}
+		if !ok {
+			continue
 		}
+		value := fieldValue.Interface()
 
-		rows = append(rows, row)
+		// Apply formatter with context support if requested
+		var formatted string
+
+		formatter := field.Formatter
+		if formatter == nil {
+			formatter = &OriginalFormatter{}
+		}
+		if contextFormatter, ok := formatter.(ContextFormatter); ok && field.UseContext {
+			formatted, err = contextFormatter.FormatWithContext(value, itemInterface)
+		} else {
+			formatted, err = formatter.Format(value)
+		}
+
+		if err != nil {
+			// Fallback to string representation
+			formatted = fmt.Sprintf("%v", value)
+		}
+
+		row[j] = formatted
 	}
 
-	return rows, nil
+	return row, nil
+}
+
+func isNilItem(item reflect.Value) bool {
+	return !item.IsValid() || (item.Kind() == reflect.Ptr && item.IsNil())
 }
 
+// resolveField returns the value at path within v, following pointers,
+// interfaces, struct fields (by name or json tag) and string-keyed maps. ok is
+// false when a nil pointer or a missing map key is found along the path.
+func resolveField(v reflect.Value, path []string) (reflect.Value, bool, error) {
+	for _, name := range path {
+		if !v.IsValid() {
+			return reflect.Value{}, false, nil
+		}
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, false, nil
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			idx, ok := getFieldIndexes(v.Type())[name]
+			if !ok || !idx.isValid {
+				return reflect.Value{}, false, fmt.Errorf("no field %s in %s", name, v.Type())
+			}
+			v = v.Field(idx.index)
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, false, fmt.Errorf("map key of %s is not a string", v.Type())
+			}
+			v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return reflect.Value{}, false, nil
+			}
+		default:
+			return reflect.Value{}, false, fmt.Errorf("cannot access %s of %s", name, v.Type())
+		}
+	}
+	return v, true, nil
+}
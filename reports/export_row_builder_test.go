@@ -0,0 +1,205 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testAddress struct {
+	Country string `json:"country"`
+}
+
+type testUser struct {
+	Name    string       `json:"name"`
+	Address *testAddress `json:"address"`
+}
+
+type testOrder struct {
+	ID       int64
+	User     testUser
+	Price    float64
+	Quantity int
+	Extra    map[string]any
+}
+
+func TestRowBuilder_NestedFields(t *testing.T) {
+	orders := []*testOrder{
+		{ID: 1, User: testUser{Name: "John", Address: &testAddress{Country: "MT"}}, Extra: map[string]any{"channel": "web"}},
+		{ID: 2, User: testUser{Name: "Jane"}},
+		nil,
+	}
+
+	rows, err := NewRowBuilder().
+		Add("ID", nil).
+		Add("User.Name", &PrefixSuffixFormatter{Prefix: "@"}).
+		Add("User.address.country", nil).
+		Add("Extra.channel", nil).
+		Build(orders)
+	if err != nil {
+		t.Fatalf("Failed to build rows: %v", err)
+	}
+
+	expected := [][]string{
+		{"1", "@John", "MT", "web"},
+		{"2", "@Jane", "", ""},
+		{"", "", "", ""},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %v, got %v", expected, rows)
+	}
+}
+
+func TestRowBuilder_MapSource(t *testing.T) {
+	data := []map[string]any{
+		{"name": "John", "meta": map[string]any{"level": 3}},
+		{"name": "Jane"},
+	}
+
+	rows, err := NewRowBuilder().
+		Add("name", nil).
+		Add("meta.level", &PrefixSuffixFormatter{Prefix: "L"}).
+		Build(data)
+	if err != nil {
+		t.Fatalf("Failed to build rows: %v", err)
+	}
+
+	expected := [][]string{{"John", "L3"}, {"Jane", ""}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %v, got %v", expected, rows)
+	}
+}
+
+func TestRowBuilder_AddComputed(t *testing.T) {
+	orders := []testOrder{{ID: 1, Price: 2.5, Quantity: 4}, {ID: 2, Price: 10, Quantity: 0}}
+
+	rows, err := NewRowBuilder().
+		Add("ID", nil).
+		AddComputed("Total", func(item any) (string, error) {
+			order := item.(testOrder)
+			return fmt.Sprintf("%.2f", order.Price*float64(order.Quantity)), nil
+		}).
+		Build(orders)
+	if err != nil {
+		t.Fatalf("Failed to build rows: %v", err)
+	}
+
+	expected := [][]string{{"1", "10.00"}, {"2", "0.00"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %v, got %v", expected, rows)
+	}
+
+	computeErr := errors.New("boom")
+	_, err = NewRowBuilder().
+		AddComputed("Total", func(any) (string, error) { return "", computeErr }).
+		Build(orders)
+	if !errors.Is(err, computeErr) {
+		t.Errorf("Expected compute error, got %v", err)
+	}
+}
+
+func TestRowBuilder_UnknownField(t *testing.T) {
+	orders := []testOrder{{ID: 1}}
+
+	for _, field := range []string{"Missing", "User.Missing", "ID.Value"} {
+		_, err := NewRowBuilder().Add(field, nil).Build(orders)
+		if err == nil || !strings.Contains(err.Error(), field) {
+			t.Errorf("Expected error for field %s, got %v", field, err)
+		}
+	}
+}
+
+func TestRowBuilder_BuildStream(t *testing.T) {
+	// Two pages of a paginated query
+	pages := [][]*testOrder{
+		{{ID: 1, User: testUser{Name: "John"}}, {ID: 2, User: testUser{Name: "Jane"}}},
+		{nil, {ID: 3, User: testUser{Name: "Joe"}}},
+	}
+	page, index := 0, 0
+	next := func() (any, bool, error) {
+		for page < len(pages) && index >= len(pages[page]) {
+			page, index = page+1, 0
+		}
+		if page == len(pages) {
+			return nil, false, nil
+		}
+		index++
+		return pages[page][index-1], true, nil
+	}
+
+	var rows [][]string
+	err := NewRowBuilder().
+		Add("ID", nil).
+		Add("User.Name", nil).
+		BuildStream(next, func(row []string) error {
+			rows = append(rows, row)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("Failed to build rows: %v", err)
+	}
+
+	expected := [][]string{{"1", "John"}, {"2", "Jane"}, {"", ""}, {"3", "Joe"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %v, got %v", expected, rows)
+	}
+}
+
+func TestRowBuilder_BuildStreamNilFirst(t *testing.T) {
+	items := []any{nil, (*testOrder)(nil), &testOrder{ID: 1}}
+	next := func() (any, bool, error) {
+		if len(items) == 0 {
+			return nil, false, nil
+		}
+		item := items[0]
+		items = items[1:]
+		return item, true, nil
+	}
+
+	var rows [][]string
+	err := NewRowBuilder().Add("ID", nil).BuildStream(next, func(row []string) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to build rows: %v", err)
+	}
+
+	expected := [][]string{{""}, {""}, {"1"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %v, got %v", expected, rows)
+	}
+}
+
+func TestRowBuilder_BuildStreamErrors(t *testing.T) {
+	items := make(chan testOrder, 2)
+	items <- testOrder{ID: 1}
+	items <- testOrder{ID: 2}
+	close(items)
+
+	errSink := errors.New("sink failed")
+	var rows int
+	err := NewRowBuilder().Add("ID", nil).BuildStream(ChannelSource(context.Background(), items), func(row []string) error {
+		rows++
+		return errSink
+	})
+	if !errors.Is(err, errSink) || rows != 1 {
+		t.Errorf("Expected the sink error after one row, got %v after %d rows", err, rows)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = NewRowBuilder().Add("ID", nil).BuildStream(ChannelSource(ctx, make(chan testOrder)), func([]string) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+
+	next := func() (any, bool, error) { return testOrder{}, true, nil }
+	err = NewRowBuilder().Add("Missing", nil).BuildStream(next, func([]string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Errorf("Expected error for field Missing, got %v", err)
+	}
+}
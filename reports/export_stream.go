@@ -0,0 +1,385 @@
+package reports
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// StreamingReportWriter writes report rows incrementally to an io.Writer instead
+// of materializing the whole file in memory like GenerateReport does.
+//
+// Writes are synchronous, so a slow destination (e.g. an io.Pipe feeding an
+// object storage upload) naturally applies backpressure to the producer.
+//
+// Supported formats: csv, json, ndjson, excel (xlsx). CSV and JSON rows are
+// flushed to the writer as they are produced; Excel rows go through excelize's StreamWriter, which spills
+// to a temporary file past its in-memory threshold, and the workbook is written
+// to w on Close.
+type StreamingReportWriter struct {
+	ctx      context.Context
+	format   string
+	writer   io.Writer
+	options  *ReportOptions
+	metadata *ReportMetadata
+	hash     hash.Hash // with WithChecksum, sees everything written to writer
+
+	// csv, json, ndjson
+	buffered     *bufio.Writer
+	csvWriter    csvRecordWriter
+	jsonExporter *JSONExporter
+
+	// excel
+	file         *excelize.File
+	streamWriter *excelize.StreamWriter
+	rowIndex     int
+
+	headers       []string
+	hasHeader     bool
+	rowsWritten   int
+	pendingRows   int
+	lastFlushTime time.Time
+	closed        bool
+}
+
+// NewStreamingReportWriter creates a streaming writer for the given format.
+// PDF is not supported since gofpdf needs the whole document before output,
+// nor are the title, footer and summary row options. Once ctx is done, writes
+// fail with the context error.
+func NewStreamingReportWriter(ctx context.Context, w io.Writer, format string, opts ...ReportOption) (*StreamingReportWriter, error) {
+	if w == nil {
+		return nil, fmt.Errorf("writer cannot be nil")
+	}
+
+	options := getDefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	if _, err := lookupLocale(options.Locale); err != nil {
+		return nil, err
+	}
+	if options.Title != "" || options.Footer != "" || options.SummaryRow != nil {
+		return nil, fmt.Errorf("title, footer and summary row are not supported when streaming")
+	}
+
+	s := &StreamingReportWriter{
+		ctx:           ctx,
+		options:       options,
+		metadata:      resolvedMetadata(options),
+		lastFlushTime: time.Now(),
+	}
+	if options.Checksum != nil {
+		s.hash = sha256.New()
+		w = io.MultiWriter(w, s.hash)
+	}
+	s.writer = w
+
+	switch format {
+	case "excel", "xlsx":
+		file := excelize.NewFile()
+		streamWriter, err := file.NewStreamWriter("Sheet1")
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to create Excel stream writer: %w", err)
+		}
+		if s.metadata != nil {
+			if err := file.SetDocProps(s.metadata.docProperties()); err != nil {
+				_ = file.Close()
+				return nil, fmt.Errorf("failed to set Excel document properties: %w", err)
+			}
+		}
+		s.format = "xlsx"
+		s.file = file
+		s.streamWriter = streamWriter
+		s.rowIndex = 1
+	case "json", "ndjson":
+		s.format = format
+		s.buffered = bufio.NewWriter(w)
+		if format == "json" {
+			s.jsonExporter = NewJSONExporter(s.buffered)
+		} else {
+			s.jsonExporter = NewNDJSONExporter(s.buffered)
+		}
+	case "pdf":
+		return nil, fmt.Errorf("streaming is not supported for pdf format")
+	case "csv":
+		fallthrough
+	default:
+		s.format = "csv"
+		s.buffered = bufio.NewWriter(w)
+		s.csvWriter = newCSVRecordWriter(s.buffered, options.CSVDialect)
+		if options.CSVDialect.BOM {
+			if _, err := s.buffered.WriteString(utf8BOM); err != nil {
+				return nil, fmt.Errorf("failed to write BOM: %w", err)
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// Extension returns the file extension matching the output format
+func (s *StreamingReportWriter) Extension() string {
+	return s.format
+}
+
+// WriteHeader writes the header row, it must be called before any data row
+func (s *StreamingReportWriter) WriteHeader(headers []string) error {
+	if s.closed {
+		return fmt.Errorf("streaming writer is closed")
+	}
+	if s.hasHeader {
+		return fmt.Errorf("header has already been written")
+	}
+	headers = translateHeaders(s.options, headers)
+	if s.options.CellSanitizer != nil {
+		var errs ValidationErrors
+		if headers, errs = s.options.CellSanitizer.SanitizeRow(0, headers); len(errs) > 0 {
+			return errs
+		}
+	}
+
+	switch s.format {
+	case "xlsx":
+		styleID, err := s.file.NewStyle(CreateHeaderStyle(s.options.HeaderColor))
+		if err != nil {
+			return fmt.Errorf("failed to create style: %w", err)
+		}
+		if err := s.streamWriter.SetRow(s.nextCell(), toInterfaceRow(headers), excelize.RowOpts{StyleID: styleID}); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+	case "json", "ndjson":
+		if err := s.jsonExporter.WriteHeader(headers, s.options.JSONKeys...); err != nil {
+			return err
+		}
+	default:
+		if s.metadata != nil {
+			for _, line := range s.metadata.commentLines() {
+				if _, err := s.buffered.WriteString(commentLine(line, s.options.CSVDialect.lineEnding())); err != nil {
+					return fmt.Errorf("failed to write comment: %w", err)
+				}
+			}
+		}
+		if err := s.csvWriter.Write(headers); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+	}
+
+	s.headers = headers
+	s.hasHeader = true
+	return nil
+}
+
+// WriteRow writes a single data row and flushes according to the configured
+// flush row count / interval
+func (s *StreamingReportWriter) WriteRow(row []string) error {
+	if s.closed {
+		return fmt.Errorf("streaming writer is closed")
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if !s.hasHeader {
+		return fmt.Errorf("header must be written before data")
+	}
+	if len(row) != len(s.headers) {
+		return fmt.Errorf("data length (%d) does not match header length (%d)", len(row), len(s.headers))
+	}
+	if s.options.CellSanitizer != nil {
+		var errs ValidationErrors
+		if row, errs = s.options.CellSanitizer.SanitizeRow(s.rowsWritten+1, row); len(errs) > 0 {
+			return errs
+		}
+	}
+
+	switch s.format {
+	case "xlsx":
+		if err := s.streamWriter.SetRow(s.nextCell(), toInterfaceRow(row)); err != nil {
+			return fmt.Errorf("failed to write data row: %w", err)
+		}
+	case "json", "ndjson":
+		if err := s.jsonExporter.WriteData(row); err != nil {
+			return err
+		}
+	default:
+		if err := s.csvWriter.Write(row); err != nil {
+			return fmt.Errorf("failed to write data row: %w", err)
+		}
+	}
+
+	s.rowsWritten++
+	s.pendingRows++
+	if s.options.Progress != nil && s.rowsWritten%progressInterval == 0 {
+		s.options.Progress(s.rowsWritten, 0)
+	}
+
+	if s.shouldFlush() {
+		return s.Flush()
+	}
+	return nil
+}
+
+// WriteRows writes multiple data rows
+func (s *StreamingReportWriter) WriteRows(rows [][]string) error {
+	for _, row := range rows {
+		if err := s.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush pushes buffered rows to the underlying writer. It is a no-op for
+// Excel, whose output is only produced on Close.
+func (s *StreamingReportWriter) Flush() error {
+	s.pendingRows = 0
+	s.lastFlushTime = time.Now()
+
+	switch s.format {
+	case "xlsx":
+		return nil
+	case "json", "ndjson":
+		if err := s.buffered.Flush(); err != nil {
+			return fmt.Errorf("failed to flush JSON writer: %w", err)
+		}
+		return nil
+	}
+
+	s.csvWriter.Flush()
+	if err := s.csvWriter.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV writer: %w", err)
+	}
+	if err := s.buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to flush CSV writer: %w", err)
+	}
+	return nil
+}
+
+// Close flushes any remaining data and finalizes the output. It does not close
+// the underlying writer.
+func (s *StreamingReportWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if s.options.Progress != nil && (s.rowsWritten == 0 || s.rowsWritten%progressInterval != 0) {
+		s.options.Progress(s.rowsWritten, 0)
+	}
+
+	if err := s.finish(); err != nil {
+		return err
+	}
+	if s.hash != nil {
+		*s.options.Checksum = hex.EncodeToString(s.hash.Sum(nil))
+	}
+	return nil
+}
+
+// finish writes the end of the output
+func (s *StreamingReportWriter) finish() error {
+	if s.jsonExporter != nil {
+		if err := s.jsonExporter.Close(); err != nil {
+			return err
+		}
+	}
+	if s.format != "xlsx" {
+		return s.Flush()
+	}
+
+	defer s.file.Close()
+
+	if err := s.streamWriter.Flush(); err != nil {
+		return fmt.Errorf("failed to flush Excel stream writer: %w", err)
+	}
+	if err := s.file.Write(s.writer); err != nil {
+		return fmt.Errorf("failed to write Excel: %w", err)
+	}
+	return nil
+}
+
+// RowsWritten returns the number of data rows written so far
+func (s *StreamingReportWriter) RowsWritten() int {
+	return s.rowsWritten
+}
+
+func (s *StreamingReportWriter) shouldFlush() bool {
+	if s.options.FlushRows > 0 && s.pendingRows >= s.options.FlushRows {
+		return true
+	}
+	if s.options.FlushInterval > 0 && time.Since(s.lastFlushTime) >= s.options.FlushInterval {
+		return true
+	}
+	return false
+}
+
+func (s *StreamingReportWriter) nextCell() string {
+	cell := fmt.Sprintf("A%d", s.rowIndex)
+	s.rowIndex++
+	return cell
+}
+
+func toInterfaceRow(row []string) []interface{} {
+	values := make([]interface{}, len(row))
+	for i, v := range row {
+		values[i] = v
+	}
+	return values
+}
+
+// StreamReport runs write against a StreamingReportWriter whose output is piped
+// into upload, so rows can go straight to object storage without buffering the
+// whole file, e.g.:
+//
+//	err := StreamReport(ctx, "csv", func(r io.Reader) error {
+//		return fileStore.UploadFile(ctx, r, "text/csv", key)
+//	}, func(sw *StreamingReportWriter) error {
+//		if err := sw.WriteHeader(headers); err != nil {
+//			return err
+//		}
+//		for rows.Next() { ... sw.WriteRow(row) ... }
+//		return nil
+//	}, WithFlushRows(1000))
+func StreamReport(ctx context.Context, format string, upload func(r io.Reader) error, write func(sw *StreamingReportWriter) error, opts ...ReportOption) error {
+	pr, pw := io.Pipe()
+
+	sw, err := NewStreamingReportWriter(ctx, pw, format, opts...)
+	if err != nil {
+		return err
+	}
+
+	writeErr := make(chan error, 1)
+	go func() {
+		err := write(sw)
+		if closeErr := sw.Close(); err == nil {
+			err = closeErr
+		}
+		// Closing with a nil error signals EOF to the reader
+		pw.CloseWithError(err)
+		writeErr <- err
+	}()
+
+	uploadErr := upload(pr)
+	// Unblock the producer if the upload stopped reading early
+	pr.CloseWithError(io.ErrClosedPipe)
+
+	err = <-writeErr
+	switch {
+	case err != nil && !errors.Is(err, io.ErrClosedPipe):
+		return fmt.Errorf("failed to write streaming report: %w", err)
+	case uploadErr != nil:
+		return fmt.Errorf("failed to upload streaming report: %w", uploadErr)
+	case err != nil:
+		// The upload returned before reading the whole report, which is truncated
+		return fmt.Errorf("failed to write streaming report: upload stopped reading: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// countingWriter records how many times data reached the destination
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestStreamingReportWriter_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	sw, err := NewStreamingReportWriter(context.Background(), &buf, "csv")
+	if err != nil {
+		t.Fatalf("Failed to create streaming writer: %v", err)
+	}
+
+	if err := sw.WriteHeader([]string{"Name", "Age"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := sw.WriteRows([][]string{{"John", "25"}, {"Jane", "30"}}); err != nil {
+		t.Fatalf("Failed to write rows: %v", err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Failed to close streaming writer: %v", err)
+	}
+
+	expected := "Name,Age\nJohn,25\nJane,30\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+	if sw.RowsWritten() != 2 {
+		t.Errorf("Expected 2 rows written, got %d", sw.RowsWritten())
+	}
+	if sw.Extension() != "csv" {
+		t.Errorf("Expected csv extension, got %s", sw.Extension())
+	}
+}
+
+func TestStreamingReportWriter_FlushRows(t *testing.T) {
+	w := &countingWriter{}
+	sw, err := NewStreamingReportWriter(context.Background(), w, "csv", WithFlushRows(2))
+	if err != nil {
+		t.Fatalf("Failed to create streaming writer: %v", err)
+	}
+
+	if err := sw.WriteHeader([]string{"ID"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := sw.WriteRow([]string{"1"}); err != nil {
+		t.Fatalf("Failed to write row: %v", err)
+	}
+	if w.writes != 0 {
+		t.Errorf("Expected no flush before threshold, got %d writes", w.writes)
+	}
+	if err := sw.WriteRow([]string{"2"}); err != nil {
+		t.Fatalf("Failed to write row: %v", err)
+	}
+	if w.String() != "ID\n1\n2\n" {
+		t.Errorf("Expected rows flushed after threshold, got %q", w.String())
+	}
+}
+
+func TestStreamingReportWriter_Errors(t *testing.T) {
+	if _, err := NewStreamingReportWriter(context.Background(), &bytes.Buffer{}, "pdf"); err == nil {
+		t.Error("Expected error for pdf format, got nil")
+	}
+	for name, opt := range map[string]ReportOption{
+		"title":       WithTitle("Report"),
+		"footer":      WithFooter("Generated"),
+		"summary row": WithSummaryRow([]string{"Total", "3"}),
+	} {
+		if _, err := NewStreamingReportWriter(context.Background(), &bytes.Buffer{}, "csv", opt); err == nil {
+			t.Errorf("Expected error for %s option, got nil", name)
+		}
+	}
+
+	sw, err := NewStreamingReportWriter(context.Background(), &bytes.Buffer{}, "csv")
+	if err != nil {
+		t.Fatalf("Failed to create streaming writer: %v", err)
+	}
+	if err := sw.WriteRow([]string{"1"}); err == nil {
+		t.Error("Expected error when writing data before header, got nil")
+	}
+	if err := sw.WriteHeader([]string{"A", "B"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := sw.WriteRow([]string{"1"}); err == nil {
+		t.Error("Expected error for wrong row length, got nil")
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Failed to close streaming writer: %v", err)
+	}
+	if err := sw.WriteRow([]string{"1", "2"}); err == nil {
+		t.Error("Expected error when writing after close, got nil")
+	}
+}
+
+func TestStreamingReportWriter_Excel(t *testing.T) {
+	var buf bytes.Buffer
+	sw, err := NewStreamingReportWriter(context.Background(), &buf, "excel", WithHeaderColor("#4472C4"))
+	if err != nil {
+		t.Fatalf("Failed to create streaming writer: %v", err)
+	}
+
+	if err := sw.WriteHeader([]string{"ID", "Name"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	for i := 1; i <= 100; i++ {
+		if err := sw.WriteRow([]string{fmt.Sprintf("%d", i), fmt.Sprintf("User %d", i)}); err != nil {
+			t.Fatalf("Failed to write row: %v", err)
+		}
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Failed to close streaming writer: %v", err)
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Failed to open generated workbook: %v", err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows("Sheet1")
+	if err != nil {
+		t.Fatalf("Failed to read rows: %v", err)
+	}
+	if len(rows) != 101 {
+		t.Fatalf("Expected 101 rows, got %d", len(rows))
+	}
+	if rows[100][1] != "User 100" {
+		t.Errorf("Expected last row name 'User 100', got %s", rows[100][1])
+	}
+}
+
+func TestStreamReport(t *testing.T) {
+	var uploaded bytes.Buffer
+	err := StreamReport(context.Background(), "csv", func(r io.Reader) error {
+		_, err := io.Copy(&uploaded, r)
+		return err
+	}, func(sw *StreamingReportWriter) error {
+		if err := sw.WriteHeader([]string{"ID"}); err != nil {
+			return err
+		}
+		for i := 0; i < 5000; i++ {
+			if err := sw.WriteRow([]string{fmt.Sprintf("%d", i)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, WithFlushRows(100))
+	if err != nil {
+		t.Fatalf("Failed to stream report: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(uploaded.String()), "\n")
+	if len(lines) != 5001 {
+		t.Errorf("Expected 5001 lines, got %d", len(lines))
+	}
+}
+
+func TestStreamReport_UploadFailure(t *testing.T) {
+	err := StreamReport(context.Background(), "csv", func(r io.Reader) error {
+		return fmt.Errorf("upload rejected")
+	}, func(sw *StreamingReportWriter) error {
+		if err := sw.WriteHeader([]string{"ID"}); err != nil {
+			return err
+		}
+		for i := 0; i < 5000; i++ {
+			if err := sw.WriteRow([]string{fmt.Sprintf("%d", i)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, WithFlushRows(1))
+	if err == nil || !strings.Contains(err.Error(), "upload rejected") {
+		t.Errorf("Expected upload error, got %v", err)
+	}
+}
+
+func TestStreamReport_UploadStopsReading(t *testing.T) {
+	err := StreamReport(context.Background(), "csv", func(r io.Reader) error {
+		_, err := r.Read(make([]byte, 10))
+		return err
+	}, func(sw *StreamingReportWriter) error {
+		if err := sw.WriteHeader([]string{"ID"}); err != nil {
+			return err
+		}
+		for i := 0; i < 5000; i++ {
+			if err := sw.WriteRow([]string{fmt.Sprintf("%d", i)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, WithFlushRows(1))
+	if !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Expected %v for a truncated upload, got %v", io.ErrClosedPipe, err)
+	}
+}
+
+func TestStreamingReportWriter_CSVDialect(t *testing.T) {
+	var buf bytes.Buffer
+	sw, err := NewStreamingReportWriter(context.Background(), &buf, "csv", WithCSVDelimiter(';'), WithCSVBOM(), WithCSVCRLF(), WithCSVAlwaysQuote())
+	if err != nil {
+		t.Fatalf("Failed to create streaming writer: %v", err)
+	}
+	if err := sw.WriteHeader([]string{"Name", "Age"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := sw.WriteRow([]string{"John", "25"}); err != nil {
+		t.Fatalf("Failed to write row: %v", err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Failed to close streaming writer: %v", err)
+	}
+
+	expected := "\ufeff\"Name\";\"Age\"\r\n\"John\";\"25\"\r\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, buf.String())
+	}
+}
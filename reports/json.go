@@ -0,0 +1,188 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONExporter writes rows as JSON objects keyed by header, either as a single
+// JSON array or as newline delimited JSON (one object per line). Keys keep the
+// header order.
+type JSONExporter struct {
+	writer    io.Writer
+	ndjson    bool
+	headers   []string
+	keys      [][]byte
+	hasHeader bool
+	rows      int
+	closed    bool
+}
+
+// NewJSONExporter creates an exporter writing a JSON array of objects
+func NewJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{writer: w}
+}
+
+// NewNDJSONExporter creates an exporter writing one JSON object per line
+func NewNDJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{writer: w, ndjson: true}
+}
+
+// WriteHeader sets the object keys. They default to the headers, keys can be
+// used to emit machine friendly names instead (see WithJSONKeys).
+func (e *JSONExporter) WriteHeader(headers []string, keys ...string) error {
+	if e.hasHeader {
+		return fmt.Errorf("header has already been written")
+	}
+	if len(keys) == 0 {
+		keys = headers
+	}
+	if len(keys) != len(headers) {
+		return fmt.Errorf("keys length (%d) does not match header length (%d)", len(keys), len(headers))
+	}
+
+	e.keys = make([][]byte, len(keys))
+	for i, key := range keys {
+		encoded, err := json.Marshal(key)
+		if err != nil {
+			return fmt.Errorf("failed to encode key %q: %w", key, err)
+		}
+		e.keys[i] = encoded
+	}
+
+	if !e.ndjson {
+		if _, err := io.WriteString(e.writer, "["); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+	}
+
+	e.headers = headers
+	e.hasHeader = true
+	return nil
+}
+
+func (e *JSONExporter) WriteData(data []string) error {
+	if !e.hasHeader {
+		return fmt.Errorf("header must be written before data")
+	}
+	if e.closed {
+		return fmt.Errorf("exporter is closed")
+	}
+	if len(data) != len(e.headers) {
+		return fmt.Errorf("data length (%d) does not match header length (%d)", len(data), len(e.headers))
+	}
+
+	var buf bytes.Buffer
+	if !e.ndjson && e.rows > 0 {
+		buf.WriteByte(',')
+	}
+	buf.WriteByte('{')
+	for i, value := range data {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(e.keys[i])
+		buf.WriteByte(':')
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode value: %w", err)
+		}
+		buf.Write(encoded)
+	}
+	buf.WriteByte('}')
+	if e.ndjson {
+		buf.WriteByte('\n')
+	}
+
+	if _, err := e.writer.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write data row: %w", err)
+	}
+	e.rows++
+	return nil
+}
+
+func (e *JSONExporter) WriteDataRow(data []string) error {
+	return e.WriteData(data)
+}
+
+// Close terminates the JSON array. It does not close the underlying writer.
+func (e *JSONExporter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	if e.ndjson {
+		return nil
+	}
+	closing := "]\n"
+	if !e.hasHeader {
+		closing = "[]\n"
+	}
+	if _, err := io.WriteString(e.writer, closing); err != nil {
+		return fmt.Errorf("failed to close JSON array: %w", err)
+	}
+	return nil
+}
+
+func (e *JSONExporter) GetHeaders() []string {
+	return e.headers
+}
+
+func (e *JSONExporter) HasHeader() bool {
+	return e.hasHeader
+}
+
+// GenerateJSONReport generates a JSON array report
+func GenerateJSONReport(ctx context.Context, headers []string, data [][]string, opts ...ReportOption) ([]byte, error) {
+	options := getDefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSONReport(ctx, NewJSONExporter(&buf), headers, data, options); err != nil {
+		return nil, fmt.Errorf("failed to generate JSON: %w", err)
+	}
+	return finishReport(options, buf.Bytes()), nil
+}
+
+// GenerateNDJSONReport generates a newline delimited JSON report
+func GenerateNDJSONReport(ctx context.Context, headers []string, data [][]string, opts ...ReportOption) ([]byte, error) {
+	options := getDefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSONReport(ctx, NewNDJSONExporter(&buf), headers, data, options); err != nil {
+		return nil, fmt.Errorf("failed to generate NDJSON: %w", err)
+	}
+	return finishReport(options, buf.Bytes()), nil
+}
+
+func writeJSONReport(ctx context.Context, exporter *JSONExporter, headers []string, data [][]string, options *ReportOptions) error {
+	headers, data, err := prepareReport(options, headers, data)
+	if err != nil {
+		return err
+	}
+
+	if err := exporter.WriteHeader(headers, options.JSONKeys...); err != nil {
+		return err
+	}
+	progress := newRowProgress(ctx, options, len(data))
+	for _, row := range data {
+		if err := progress.check(); err != nil {
+			return err
+		}
+		if err := exporter.WriteData(row); err != nil {
+			return err
+		}
+		progress.add()
+	}
+	progress.finish()
+	return exporter.Close()
+}
@@ -0,0 +1,105 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerateJSONReport(t *testing.T) {
+	headers := []string{"Name", "Age", "Note"}
+	data := [][]string{
+		{"John", "25", `said "hi"`},
+		{"Jane", "30", "line1\nline2"},
+	}
+
+	content, err := GenerateJSONReport(context.Background(), headers, data)
+	if err != nil {
+		t.Fatalf("Failed to generate JSON report: %v", err)
+	}
+
+	expected := `[{"Name":"John","Age":"25","Note":"said \"hi\""},{"Name":"Jane","Age":"30","Note":"line1\nline2"}]` + "\n"
+	if string(content) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, content)
+	}
+
+	var records []map[string]string
+	if err := json.Unmarshal(content, &records); err != nil {
+		t.Fatalf("Generated JSON is invalid: %v", err)
+	}
+	if len(records) != 2 || records[1]["Note"] != "line1\nline2" {
+		t.Errorf("Unexpected records: %v", records)
+	}
+}
+
+func TestGenerateJSONReport_Empty(t *testing.T) {
+	content, err := GenerateJSONReport(context.Background(), []string{"Name"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate JSON report: %v", err)
+	}
+	if string(content) != "[]\n" {
+		t.Errorf("Expected empty array, got %q", content)
+	}
+}
+
+func TestGenerateNDJSONReport(t *testing.T) {
+	headers := []string{"User ID", "Amount"}
+	data := [][]string{{"1", "10.50"}, {"2", "20.00"}}
+
+	content, err := GenerateNDJSONReport(context.Background(), headers, data, WithJSONKeys("user_id", "amount"))
+	if err != nil {
+		t.Fatalf("Failed to generate NDJSON report: %v", err)
+	}
+
+	expected := "{\"user_id\":\"1\",\"amount\":\"10.50\"}\n{\"user_id\":\"2\",\"amount\":\"20.00\"}\n"
+	if string(content) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, content)
+	}
+
+	if _, err := GenerateNDJSONReport(context.Background(), headers, data, WithJSONKeys("user_id")); err == nil {
+		t.Error("Expected error for mismatched JSON keys, got nil")
+	}
+}
+
+func TestGenerateReport_JSONFormats(t *testing.T) {
+	headers := []string{"Name"}
+	data := [][]string{{"John"}}
+
+	for format, ext := range map[string]string{"json": "json", "ndjson": "ndjson"} {
+		content, gotExt, err := GenerateReport(context.Background(), format, headers, data)
+		if err != nil {
+			t.Fatalf("Failed to generate %s report: %v", format, err)
+		}
+		if gotExt != ext {
+			t.Errorf("Expected extension %s, got %s", ext, gotExt)
+		}
+		if !strings.Contains(string(content), `"Name":"John"`) {
+			t.Errorf("Unexpected %s content: %s", format, content)
+		}
+	}
+}
+
+func TestStreamingReportWriter_NDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	sw, err := NewStreamingReportWriter(context.Background(), &buf, "ndjson", WithFlushRows(1))
+	if err != nil {
+		t.Fatalf("Failed to create streaming writer: %v", err)
+	}
+	if err := sw.WriteHeader([]string{"ID"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := sw.WriteRow([]string{"1"}); err != nil {
+		t.Fatalf("Failed to write row: %v", err)
+	}
+	if buf.String() != "{\"ID\":\"1\"}\n" {
+		t.Errorf("Expected row flushed, got %q", buf.String())
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Failed to close streaming writer: %v", err)
+	}
+	if sw.Extension() != "ndjson" {
+		t.Errorf("Expected ndjson extension, got %s", sw.Extension())
+	}
+}
@@ -0,0 +1,156 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Locale holds the number and date conventions of a language and region
+type Locale struct {
+	Tag                string // BCP 47 tag, e.g. "de-DE"
+	ThousandsSeparator string // e.g. "." for 100.000
+	DecimalSeparator   string // e.g. "," for 100,00
+	DateFormat         string // Go layout, e.g. "02.01.2006"
+	DateTimeFormat     string // Go layout, e.g. "02.01.2006 15:04:05"
+}
+
+var locales = map[string]Locale{
+	"en-US": {Tag: "en-US", ThousandsSeparator: ",", DecimalSeparator: ".", DateFormat: "01/02/2006", DateTimeFormat: "01/02/2006 03:04:05 PM"},
+	"en-GB": {Tag: "en-GB", ThousandsSeparator: ",", DecimalSeparator: ".", DateFormat: "02/01/2006", DateTimeFormat: "02/01/2006 15:04:05"},
+	"de-DE": {Tag: "de-DE", ThousandsSeparator: ".", DecimalSeparator: ",", DateFormat: "02.01.2006", DateTimeFormat: "02.01.2006 15:04:05"},
+	"fr-FR": {Tag: "fr-FR", ThousandsSeparator: " ", DecimalSeparator: ",", DateFormat: "02/01/2006", DateTimeFormat: "02/01/2006 15:04:05"},
+	"es-ES": {Tag: "es-ES", ThousandsSeparator: ".", DecimalSeparator: ",", DateFormat: "02/01/2006", DateTimeFormat: "02/01/2006 15:04:05"},
+	"it-IT": {Tag: "it-IT", ThousandsSeparator: ".", DecimalSeparator: ",", DateFormat: "02/01/2006", DateTimeFormat: "02/01/2006 15:04:05"},
+	"pt-BR": {Tag: "pt-BR", ThousandsSeparator: ".", DecimalSeparator: ",", DateFormat: "02/01/2006", DateTimeFormat: "02/01/2006 15:04:05"},
+	"nl-NL": {Tag: "nl-NL", ThousandsSeparator: ".", DecimalSeparator: ",", DateFormat: "02-01-2006", DateTimeFormat: "02-01-2006 15:04:05"},
+	"ru-RU": {Tag: "ru-RU", ThousandsSeparator: " ", DecimalSeparator: ",", DateFormat: "02.01.2006", DateTimeFormat: "02.01.2006 15:04:05"},
+	"ja-JP": {Tag: "ja-JP", ThousandsSeparator: ",", DecimalSeparator: ".", DateFormat: "2006/01/02", DateTimeFormat: "2006/01/02 15:04:05"},
+	"zh-CN": {Tag: "zh-CN", ThousandsSeparator: ",", DecimalSeparator: ".", DateFormat: "2006-01-02", DateTimeFormat: "2006-01-02 15:04:05"},
+}
+
+// languageLocales picks the locale used for a bare language tag such as "pt"
+var languageLocales = map[string]string{
+	"en": "en-US",
+	"de": "de-DE",
+	"fr": "fr-FR",
+	"es": "es-ES",
+	"it": "it-IT",
+	"pt": "pt-BR",
+	"nl": "nl-NL",
+	"ru": "ru-RU",
+	"ja": "ja-JP",
+	"zh": "zh-CN",
+}
+
+// LookupLocale returns the locale of tag, e.g. "pt-BR", "pt_br" or "pt".
+// Unknown regions fall back to the default locale of their language.
+func LookupLocale(tag string) (Locale, bool) {
+	language, region, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	language = strings.ToLower(language)
+	if locale, ok := locales[language+"-"+strings.ToUpper(region)]; ok {
+		return locale, true
+	}
+	if defaultTag, ok := languageLocales[language]; ok {
+		return locales[defaultTag], true
+	}
+	return Locale{}, false
+}
+
+// lookupLocale returns the locale of tag, nil for an empty tag
+func lookupLocale(tag string) (*Locale, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	locale, ok := LookupLocale(tag)
+	if !ok {
+		return nil, fmt.Errorf("unsupported locale: %q", tag)
+	}
+	return &locale, nil
+}
+
+// parseNumber parses a number written with the separators of the locale
+func (l Locale) parseNumber(value string) (float64, bool) {
+	value = strings.ReplaceAll(value, l.ThousandsSeparator, "")
+	return parseNumber(strings.ReplaceAll(value, l.DecimalSeparator, "."))
+}
+
+// parseDate parses a date written in the formats of the locale or in one of
+// the default layouts
+func (l Locale) parseDate(value string) (time.Time, bool) {
+	for _, layout := range []string{l.DateTimeFormat, l.DateFormat} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return parseDate(value)
+}
+
+// HeaderTranslator translates report headers into the locale set with
+// WithLocale
+type HeaderTranslator interface {
+	TranslateHeader(locale, header string) string
+}
+
+// HeaderTranslatorFunc adapts a function to a HeaderTranslator
+type HeaderTranslatorFunc func(locale, header string) string
+
+func (f HeaderTranslatorFunc) TranslateHeader(locale, header string) string {
+	return f(locale, header)
+}
+
+// HeaderTranslations translates headers by locale then header, e.g.
+// {"pt-BR": {"Amount": "Valor"}}. A locale missing from the map falls back to
+// its language, e.g. "pt". Headers without a translation are kept as is.
+type HeaderTranslations map[string]map[string]string
+
+func (t HeaderTranslations) TranslateHeader(locale, header string) string {
+	translations, ok := t[locale]
+	if !ok {
+		language, _, _ := strings.Cut(locale, "-")
+		translations = t[language]
+	}
+	if translated, ok := translations[header]; ok {
+		return translated
+	}
+	return header
+}
+
+// WithLocale sets the locale of the report, e.g. "pt-BR". Headers are
+// translated with the HeaderTranslator and Excel parses the values of typed
+// columns with the separators and date formats of the locale.
+func WithLocale(tag string) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.Locale = tag
+	}
+}
+
+// WithHeaderTranslator translates the headers into the locale set with
+// WithLocale. Translated headers are also the JSON keys unless WithJSONKeys
+// is used.
+func WithHeaderTranslator(translator HeaderTranslator) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.HeaderTranslator = translator
+	}
+}
+
+// translateHeaders returns the headers translated into the report locale
+func translateHeaders(options *ReportOptions, headers []string) []string {
+	if options.HeaderTranslator == nil || options.Locale == "" {
+		return headers
+	}
+	translated := make([]string, len(headers))
+	for i, header := range headers {
+		translated[i] = options.HeaderTranslator.TranslateHeader(options.Locale, header)
+	}
+	return translated
+}
+
+// prepareReport checks the locale and returns the translated and sanitized
+// headers and data
+func prepareReport(options *ReportOptions, headers []string, data [][]string) ([]string, [][]string, error) {
+	if _, err := lookupLocale(options.Locale); err != nil {
+		return nil, nil, err
+	}
+	return sanitizeReport(options, translateHeaders(options, headers), data)
+}
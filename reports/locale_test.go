@@ -0,0 +1,150 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestLookupLocale(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+		ok   bool
+	}{
+		{"pt-BR", "pt-BR", true},
+		{"pt_br", "pt-BR", true},
+		{"de", "de-DE", true},
+		{"de-AT", "de-DE", true},
+		{"xx-YY", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		locale, ok := LookupLocale(tt.tag)
+		if ok != tt.ok || locale.Tag != tt.want {
+			t.Errorf("LookupLocale(%q) = %q, %v, want %q, %v", tt.tag, locale.Tag, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFormatters_Locale(t *testing.T) {
+	amount := &CurrencyAmountFormatter{DefaultDecimalPlaces: 2, Locale: "de-DE"}
+	got, err := amount.Format("1234567.891")
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if got != "1.234.567,89" {
+		t.Errorf("Expected 1.234.567,89, got %s", got)
+	}
+
+	// explicit separators take precedence over the locale
+	amount.DefaultThousandsSeparator = " "
+	if got, _ := amount.Format("1234.5"); got != "1 234,50" {
+		t.Errorf("Expected 1 234,50, got %s", got)
+	}
+
+	amount.Locale = "xx"
+	if _, err := amount.Format("1"); err == nil {
+		t.Error("Expected an error for an unsupported locale")
+	}
+
+	timestamp := time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC).UnixMilli()
+	for locale, want := range map[string]string{
+		"":      "2024-03-15 14:30:00",
+		"pt-BR": "15/03/2024 14:30:00",
+		"de-DE": "15.03.2024 14:30:00",
+		"en-US": "03/15/2024 02:30:00 PM",
+	} {
+		got, err := (&DateTimeFormatter{Locale: locale}).Format(timestamp)
+		if err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("Locale %q: expected %s, got %s", locale, want, got)
+		}
+	}
+}
+
+func TestGenerateReport_HeaderTranslator(t *testing.T) {
+	headers := []string{"Name", "Amount"}
+	data := [][]string{{"alice", "10"}}
+	translations := HeaderTranslations{"pt": {"Name": "Nome", "Amount": "Valor"}}
+
+	content, _, err := GenerateReport(context.Background(), "csv", headers, data, WithLocale("pt-BR"), WithHeaderTranslator(translations))
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "Nome,Valor\n") {
+		t.Errorf("Expected translated headers, got:\n%s", content)
+	}
+
+	// no locale, no translation
+	content, _, err = GenerateReport(context.Background(), "csv", headers, data, WithHeaderTranslator(translations))
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "Name,Amount\n") {
+		t.Errorf("Expected original headers, got:\n%s", content)
+	}
+
+	upper := HeaderTranslatorFunc(func(locale, header string) string {
+		return strings.ToUpper(header)
+	})
+	var buf bytes.Buffer
+	sw, err := NewStreamingReportWriter(context.Background(), &buf, "csv", WithLocale("de-DE"), WithHeaderTranslator(upper))
+	if err != nil {
+		t.Fatalf("NewStreamingReportWriter failed: %v", err)
+	}
+	if err := sw.WriteHeader(headers); err != nil {
+		t.Fatalf("WriteHeader failed: %v", err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if buf.String() != "NAME,AMOUNT\n" {
+		t.Errorf("Expected translated headers, got:\n%s", buf.String())
+	}
+
+	if _, _, err := GenerateReport(context.Background(), "csv", headers, data, WithLocale("xx-YY")); err == nil {
+		t.Error("Expected an error for an unsupported locale")
+	}
+}
+
+func TestGenerateExcelReport_Locale(t *testing.T) {
+	headers := []string{"Amount", "Date"}
+	data := [][]string{{"1.234,50", "15/03/2024"}}
+
+	content, err := GenerateExcelReport(context.Background(), headers, data,
+		WithLocale("pt-BR"),
+		WithColumnType(0, ColumnTypeNumber),
+		WithColumnType(1, ColumnTypeDate),
+	)
+	if err != nil {
+		t.Fatalf("GenerateExcelReport failed: %v", err)
+	}
+
+	f, err := excelize.OpenReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to open Excel: %v", err)
+	}
+	defer f.Close()
+
+	amount, err := f.GetCellValue("Sheet1", "A2", excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatalf("GetCellValue failed: %v", err)
+	}
+	if amount != "1234.5" {
+		t.Errorf("Expected 1234.5, got %s", amount)
+	}
+	cellType, err := f.GetCellType("Sheet1", "B2")
+	if err != nil {
+		t.Fatalf("GetCellType failed: %v", err)
+	}
+	if cellType == excelize.CellTypeSharedString || cellType == excelize.CellTypeInlineString {
+		t.Errorf("Expected the date to be stored as a date, got type %v", cellType)
+	}
+}
@@ -0,0 +1,142 @@
+package reports
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ReportMetadata describes how a report was generated, for audit and
+// reconciliation. It is embedded as document properties in Excel, as document
+// information in PDF and as "# " comment lines above the CSV content. JSON
+// formats do not embed it.
+type ReportMetadata struct {
+	Generator   string            `json:"generator"`    // e.g. "backoffice/transactions-export"
+	GeneratedAt time.Time         `json:"generated_at"` // defaults to the time of generation
+	Filters     map[string]string `json:"filters,omitempty"`
+}
+
+// WithMetadata embeds metadata in the generated report
+func WithMetadata(metadata ReportMetadata) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.Metadata = &metadata
+	}
+}
+
+// WithChecksum stores the hex encoded SHA-256 checksum of the generated report
+// in sum. With a StreamingReportWriter, sum is set by Close.
+func WithChecksum(sum *string) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.Checksum = sum
+	}
+}
+
+// resolvedMetadata returns the metadata of options with GeneratedAt set, or
+// nil without metadata
+func resolvedMetadata(options *ReportOptions) *ReportMetadata {
+	if options.Metadata == nil {
+		return nil
+	}
+	metadata := *options.Metadata
+	if metadata.GeneratedAt.IsZero() {
+		metadata.GeneratedAt = time.Now().UTC()
+	}
+	return &metadata
+}
+
+func (m ReportMetadata) filterKeys() []string {
+	keys := make([]string, 0, len(m.Filters))
+	for key := range m.Filters {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// filterString formats the filters sorted by key, e.g. "currency=EUR, status=paid"
+func (m ReportMetadata) filterString() string {
+	keys := m.filterKeys()
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + "=" + m.Filters[key]
+	}
+	return strings.Join(parts, ", ")
+}
+
+// commentLines returns the CSV comment lines of the metadata
+func (m ReportMetadata) commentLines() []string {
+	lines := []string{
+		"generator: " + m.Generator,
+		"generated_at: " + m.GeneratedAt.Format(time.RFC3339),
+	}
+	for _, key := range m.filterKeys() {
+		lines = append(lines, fmt.Sprintf("filter.%s: %s", key, m.Filters[key]))
+	}
+	return lines
+}
+
+// docProperties returns the Excel document properties of the metadata
+func (m ReportMetadata) docProperties() *excelize.DocProperties {
+	return &excelize.DocProperties{
+		Creator:     m.Generator,
+		Created:     m.GeneratedAt.UTC().Format(time.RFC3339),
+		Description: m.filterString(),
+	}
+}
+
+// SetMetadata stores metadata as the document properties of the workbook
+func (e *ExcelExporter) SetMetadata(metadata ReportMetadata) error {
+	if err := e.file.SetDocProps(metadata.docProperties()); err != nil {
+		return fmt.Errorf("failed to set Excel document properties: %w", err)
+	}
+	return nil
+}
+
+// SetMetadata stores metadata as the document information of the PDF, the
+// filters going to the subject
+func (e *PDFExporter) SetMetadata(metadata ReportMetadata) {
+	e.pdf.SetCreator(metadata.Generator, true)
+	e.pdf.SetCreationDate(metadata.GeneratedAt)
+	if len(metadata.Filters) > 0 {
+		e.pdf.SetSubject(metadata.filterString(), true)
+	}
+}
+
+// WriteComment writes a "# " comment line, it must be called before the title
+// and header. Line breaks in text are replaced by spaces.
+func (e *CSVExporter) WriteComment(text string) error {
+	if e.hasHeader {
+		return fmt.Errorf("comment must be written before header")
+	}
+	if err := e.writeBOM(); err != nil {
+		return err
+	}
+	// Keep the comment ahead of anything buffered by the csv writer
+	if err := e.Flush(); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(e.writer, commentLine(text, e.dialect.lineEnding())); err != nil {
+		return fmt.Errorf("failed to write comment: %w", err)
+	}
+	return nil
+}
+
+// checksum returns the hex encoded SHA-256 of content
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// finishReport records the checksum of the generated content
+func finishReport(options *ReportOptions, content []byte) []byte {
+	if options.Checksum != nil {
+		*options.Checksum = checksum(content)
+	}
+	return content
+}
@@ -0,0 +1,137 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var testMetadata = ReportMetadata{
+	Generator:   "backoffice/transactions",
+	GeneratedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	Filters:     map[string]string{"status": "paid", "currency": "EUR"},
+}
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCSVMetadataAndChecksum(t *testing.T) {
+	var sum string
+	content, err := GenerateCSVReport(context.Background(), []string{"ID"}, [][]string{{"1"}},
+		WithMetadata(testMetadata), WithTitle("Transactions"), WithChecksum(&sum))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "# generator: backoffice/transactions\n" +
+		"# generated_at: 2024-03-01T12:00:00Z\n" +
+		"# filter.currency: EUR\n" +
+		"# filter.status: paid\n" +
+		"Transactions\nID\n1\n"
+	if string(content) != want {
+		t.Errorf("Unexpected CSV:\n%s", content)
+	}
+	if sum != sha256Hex(content) {
+		t.Errorf("Checksum = %s, want %s", sum, sha256Hex(content))
+	}
+}
+
+func TestExcelMetadata(t *testing.T) {
+	var sum string
+	content, err := GenerateExcelReport(context.Background(), []string{"ID"}, [][]string{{"1"}},
+		WithMetadata(testMetadata), WithChecksum(&sum))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sum != sha256Hex(content) {
+		t.Errorf("Checksum = %s, want %s", sum, sha256Hex(content))
+	}
+
+	file, err := excelize.OpenReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to open Excel: %v", err)
+	}
+	defer file.Close()
+	props, err := file.GetDocProps()
+	if err != nil {
+		t.Fatalf("Failed to read document properties: %v", err)
+	}
+	if props.Creator != "backoffice/transactions" || props.Description != "currency=EUR, status=paid" {
+		t.Errorf("Unexpected document properties: %+v", props)
+	}
+	if !strings.HasPrefix(props.Created, "2024-03-01T12:00:00") {
+		t.Errorf("Created = %s", props.Created)
+	}
+}
+
+func TestPDFMetadata(t *testing.T) {
+	var sum string
+	content, err := GeneratePDFReport(context.Background(), []string{"ID"}, [][]string{{"1"}},
+		WithMetadata(testMetadata), WithChecksum(&sum))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sum != sha256Hex(content) {
+		t.Errorf("Checksum = %s, want %s", sum, sha256Hex(content))
+	}
+	for _, want := range []string{"/Creator", "/Subject", "/CreationDate (D:20240301120000"} {
+		if !bytes.Contains(content, []byte(want)) {
+			t.Errorf("PDF does not contain %q", want)
+		}
+	}
+}
+
+func TestArchiveMetadataAndChecksum(t *testing.T) {
+	var sum string
+	content, err := GenerateReportArchive(context.Background(), "csv", []string{"ID"}, [][]string{{"1"}, {"2"}},
+		WithMaxRowsPerFile(1), WithManifest(), WithMetadata(ReportMetadata{Generator: "test"}), WithChecksum(&sum))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sum != sha256Hex(content) {
+		t.Errorf("Checksum = %s, want %s", sum, sha256Hex(content))
+	}
+
+	files := readArchive(t, content)
+	first := strings.SplitN(string(files["report_part1.csv"]), "\n", 3)
+	second := strings.SplitN(string(files["report_part2.csv"]), "\n", 3)
+	if first[0] != "# generator: test" || first[1] != second[1] {
+		t.Errorf("Parts should share the metadata, got %q and %q", first[:2], second[:2])
+	}
+	if !bytes.Contains(files["manifest.json"], []byte(`"generator": "test"`)) {
+		t.Errorf("Manifest does not contain the metadata:\n%s", files["manifest.json"])
+	}
+}
+
+func TestStreamingReportWriterMetadataAndChecksum(t *testing.T) {
+	var sum string
+	var buf bytes.Buffer
+	sw, err := NewStreamingReportWriter(context.Background(), &buf, "csv", WithMetadata(testMetadata), WithChecksum(&sum))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := sw.WriteHeader([]string{"ID"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := sw.WriteRow([]string{"1"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "# generator: backoffice/transactions\n") {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+	if sum != sha256Hex(buf.Bytes()) {
+		t.Errorf("Checksum = %s, want %s", sum, sha256Hex(buf.Bytes()))
+	}
+}
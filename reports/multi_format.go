@@ -0,0 +1,109 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupportedFormat is reported by GenerateReports for formats other than
+// csv, excel, xlsx, pdf, json and ndjson
+var ErrUnsupportedFormat = errors.New("unsupported report format")
+
+// FormatErrors maps the formats GenerateReports failed to generate to their
+// error
+type FormatErrors map[string]error
+
+func (e FormatErrors) Error() string {
+	formats := make([]string, 0, len(e))
+	for format := range e {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+
+	messages := make([]string, len(formats))
+	for i, format := range formats {
+		messages[i] = fmt.Sprintf("%s: %v", format, e[format])
+	}
+	return fmt.Sprintf("failed to generate %d formats: %s", len(e), strings.Join(messages, "; "))
+}
+
+func (e FormatErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// GenerateReports renders the same data in every format concurrently, e.g.
+// csv, excel and pdf for a dashboard export, and returns the content of each
+// format keyed by the format as given. The headers and data are translated
+// and sanitized once and shared by all formats, which carry the same
+// generation time in their metadata. Formats that fail are left out of the
+// map and reported in a FormatErrors, so the other formats can still be used.
+// Unlike GenerateReport, unknown formats are not generated as CSV but fail
+// with ErrUnsupportedFormat.
+// WithProgress and WithChecksum are ignored, since every format would report
+// to them.
+func GenerateReports(ctx context.Context, formats []string, headers []string, data [][]string, opts ...ReportOption) (map[string][]byte, error) {
+	// Get default options and apply provided options
+	options := getDefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	headers, data, err := prepareReport(options, headers, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate reports: %w", err)
+	}
+	opts = append(append([]ReportOption{}, opts...), WithCellSanitizer(nil), WithHeaderTranslator(nil), WithProgress(nil), WithChecksum(nil))
+	if metadata := resolvedMetadata(options); metadata != nil {
+		opts = append(opts, WithMetadata(*metadata))
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		contents = make(map[string][]byte, len(formats))
+		errs     = FormatErrors{}
+	)
+	for _, format := range slices.Compact(slices.Sorted(slices.Values(formats))) {
+		if !supportedFormat(format) {
+			errs[format] = fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			content, _, err := GenerateReport(ctx, format, headers, data, opts...)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[format] = err
+				return
+			}
+			contents[format] = content
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return contents, errs
+	}
+	return contents, nil
+}
+
+// supportedFormat reports whether GenerateReport has a generator for format
+func supportedFormat(format string) bool {
+	switch format {
+	case "csv", "excel", "xlsx", "pdf", "json", "ndjson":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,79 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGenerateReports(t *testing.T) {
+	headers := []string{"ID", "Name", "Amount"}
+	data := [][]string{{"1", "Alice", "10.50"}, {"2", "=Bob", "20"}}
+	opts := []ReportOption{WithTitle("Deposits"), WithCellSanitizer(DefaultCellSanitizer())}
+
+	contents, err := GenerateReports(context.Background(), []string{"csv", "excel", "pdf", "json", "csv"}, headers, data, opts...)
+	if err != nil {
+		t.Fatalf("Failed to generate reports: %v", err)
+	}
+	if len(contents) != 4 {
+		t.Fatalf("Expected 4 formats, got %d", len(contents))
+	}
+
+	for _, format := range []string{"csv", "json"} {
+		want, _, err := GenerateReport(context.Background(), format, headers, data, opts...)
+		if err != nil {
+			t.Fatalf("Failed to generate %s report: %v", format, err)
+		}
+		if !bytes.Equal(contents[format], want) {
+			t.Errorf("Unexpected %s content:\n%s\nwant:\n%s", format, contents[format], want)
+		}
+	}
+	if !bytes.HasPrefix(contents["excel"], []byte("PK")) {
+		t.Error("Expected excel content to be a zip file")
+	}
+	if !bytes.HasPrefix(contents["pdf"], []byte("%PDF")) {
+		t.Error("Expected pdf content to be a PDF file")
+	}
+}
+
+func TestGenerateReports_Errors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	contents, err := GenerateReports(ctx, []string{"csv", "pdf"}, []string{"ID"}, [][]string{{"1"}})
+	var formatErrs FormatErrors
+	if !errors.As(err, &formatErrs) {
+		t.Fatalf("Expected FormatErrors, got %v", err)
+	}
+	if len(formatErrs) != 2 || len(contents) != 0 {
+		t.Errorf("Expected both formats to fail, got errors %v and contents for %d formats", formatErrs, len(contents))
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	if _, err := GenerateReports(context.Background(), []string{"csv"}, []string{"ID"}, nil, WithLocale("xx-XX")); err == nil {
+		t.Error("Expected an error for an unknown locale")
+	}
+}
+
+func TestGenerateReports_UnsupportedFormat(t *testing.T) {
+	contents, err := GenerateReports(context.Background(), []string{"csv", "xlsx", "exel"}, []string{"ID"}, [][]string{{"1"}})
+	var formatErrs FormatErrors
+	if !errors.As(err, &formatErrs) {
+		t.Fatalf("Expected FormatErrors, got %v", err)
+	}
+	if len(formatErrs) != 1 || !errors.Is(formatErrs["exel"], ErrUnsupportedFormat) {
+		t.Errorf("Expected only exel to be unsupported, got %v", formatErrs)
+	}
+	if _, ok := contents["exel"]; ok {
+		t.Error("Expected no content for the unsupported format")
+	}
+	if !bytes.HasPrefix(contents["xlsx"], []byte("PK")) {
+		t.Error("Expected xlsx content to be a zip file")
+	}
+	if string(contents["csv"]) == "" {
+		t.Error("Expected csv content")
+	}
+}
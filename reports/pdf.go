@@ -1,7 +1,10 @@
 package reports
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 	"strings"
 
@@ -18,6 +21,11 @@ type PDFExporter struct {
 	margin      float64
 	currentY    float64
 	headerStyle *PDFStyle // Store header style for consistent rendering across pages
+	fontFamily  string    // Font family used when a style does not override it, "Arial" unless a UTF-8 font is set
+	utf8Font    bool      // Whether fontFamily is a registered UTF-8 font
+	footerText  string    // Text drawn at the bottom of every page next to the page number
+
+	columnStyles map[int]ColumnStyle // Per column data cell styles, by 0-based index
 }
 
 // NewPDFExporter creates a new PDF exporter instance
@@ -31,12 +39,13 @@ func NewPDFExporter() *PDFExporter {
 	margin := 10.0
 
 	return &PDFExporter{
-		pdf:       pdf,
-		hasHeader: false,
-		rowIndex:  0,
-		pageWidth: pageWidth,
-		margin:    margin,
-		currentY:  margin,
+		pdf:        pdf,
+		hasHeader:  false,
+		rowIndex:   0,
+		pageWidth:  pageWidth,
+		margin:     margin,
+		currentY:   margin,
+		fontFamily: "Arial",
 	}
 }
 
@@ -50,12 +59,13 @@ func NewPDFExporterToFile(filename string) (*PDFExporter, error) {
 	margin := 10.0
 
 	exporter := &PDFExporter{
-		pdf:       pdf,
-		hasHeader: false,
-		rowIndex:  0,
-		pageWidth: pageWidth,
-		margin:    margin,
-		currentY:  margin,
+		pdf:        pdf,
+		hasHeader:  false,
+		rowIndex:   0,
+		pageWidth:  pageWidth,
+		margin:     margin,
+		currentY:   margin,
+		fontFamily: "Arial",
 	}
 
 	return exporter, nil
@@ -164,6 +174,53 @@ func (e *PDFExporter) WriteDataWithStyle(data []string, style *PDFStyle) error {
 	return nil
 }
 
+// WriteTitle writes a centered title above the header. A nil style uses a
+// bold 14pt font without background.
+func (e *PDFExporter) WriteTitle(title string, style *PDFStyle) error {
+	if e.hasHeader {
+		return fmt.Errorf("title must be written before header")
+	}
+
+	if style != nil {
+		e.pdf.SetFont(e.resolveFontFamily(style.FontFamily), style.FontStyle, style.FontSize)
+		e.pdf.SetTextColor(style.TextColor.R, style.TextColor.G, style.TextColor.B)
+	} else {
+		e.pdf.SetFont(e.fontFamily, "B", 14)
+		e.pdf.SetTextColor(0, 0, 0)
+	}
+
+	e.pdf.SetXY(e.margin, e.currentY)
+	e.pdf.MultiCell(e.pageWidth-2*e.margin, 8, title, "", "C", false)
+	e.currentY = e.pdf.GetY() + 2
+
+	return nil
+}
+
+// WriteSummaryRow writes a totals row after the data rows. A nil style uses
+// CreatePDFSummaryStyle.
+func (e *PDFExporter) WriteSummaryRow(data []string, style *PDFStyle) error {
+	if style == nil {
+		style = CreatePDFSummaryStyle()
+	}
+	return e.WriteDataWithStyle(data, style)
+}
+
+// WriteFooter sets a footer drawn at the bottom of every page together with
+// the page number ("Page 1 of 3"). It can be called at any time before Save.
+func (e *PDFExporter) WriteFooter(text string) error {
+	e.footerText = text
+	e.pdf.AliasNbPages("")
+	e.pdf.SetFooterFunc(func() {
+		e.pdf.SetY(-e.margin)
+		e.pdf.SetFont(e.fontFamily, "", 8)
+		e.pdf.SetTextColor(128, 128, 128)
+		width := (e.pageWidth - 2*e.margin) / 2
+		e.pdf.CellFormat(width, 6, e.footerText, "", 0, "L", false, 0, "")
+		e.pdf.CellFormat(width, 6, fmt.Sprintf("Page %d of {nb}", e.pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	return nil
+}
+
 func (e *PDFExporter) SetColumnWidths(widths []float64) error {
 	if len(e.headers) == 0 {
 		e.colWidths = make([]float64, len(widths))
@@ -195,17 +252,102 @@ func (e *PDFExporter) SetFont(family, style string, size float64) {
 func (e *PDFExporter) SetHeaderFont(family, style string, size float64) {
 }
 
+// SetUTF8Font registers a TrueType font and uses it for all text, which is
+// required for non-Latin content (e.g. Chinese) since the built-in Arial only
+// covers cp1252. boldPath is optional, the regular font is reused for bold
+// text when it is empty. For CJK use a font such as Noto Sans CJK / Noto Sans SC.
+// It must be called before writing the header.
+func (e *PDFExporter) SetUTF8Font(family, regularPath, boldPath string) error {
+	// Fonts are loaded here rather than with AddUTF8Font, which resolves paths
+	// relative to the gofpdf font directory
+	regular, err := os.ReadFile(regularPath)
+	if err != nil {
+		return fmt.Errorf("failed to read font file %s: %w", regularPath, err)
+	}
+	var bold []byte
+	if boldPath != "" {
+		if bold, err = os.ReadFile(boldPath); err != nil {
+			return fmt.Errorf("failed to read font file %s: %w", boldPath, err)
+		}
+	}
+	return e.SetUTF8FontFromBytes(family, regular, bold)
+}
+
+// SetUTF8FontFromBytes is like SetUTF8Font with the font files already loaded,
+// e.g. from an embed.FS
+func (e *PDFExporter) SetUTF8FontFromBytes(family string, regular, bold []byte) error {
+	if len(bold) == 0 {
+		bold = regular
+	}
+	e.pdf.AddUTF8FontFromBytes(family, "", regular)
+	e.pdf.AddUTF8FontFromBytes(family, "B", bold)
+	return e.useUTF8Font(family)
+}
+
+func (e *PDFExporter) useUTF8Font(family string) error {
+	if err := e.pdf.Error(); err != nil {
+		return fmt.Errorf("failed to load UTF-8 font %s: %w", family, err)
+	}
+	e.fontFamily = family
+	e.utf8Font = true
+	e.pdf.SetFont(family, "", 10)
+	return nil
+}
+
+// resolveFontFamily maps the core font families used by the default styles to
+// the registered UTF-8 font, so CreatePDFHeaderStyle and friends keep working
+// once SetUTF8Font is used
+func (e *PDFExporter) resolveFontFamily(family string) string {
+	if family == "" {
+		return e.fontFamily
+	}
+	if e.utf8Font {
+		switch strings.ToLower(family) {
+		case "arial", "helvetica", "times", "courier":
+			return e.fontFamily
+		}
+	}
+	return family
+}
+
+// calculateCellHeight returns the height MultiCell takes to draw text in the
+// current font, wrapping it like MultiCell at the last space or, for words
+// wider than the cell, e.g. CJK text, anywhere
 func (e *PDFExporter) calculateCellHeight(text string, width, lineHeight float64) float64 {
-	charPerLine := width / 3.0
-	estimatedLines := float64(len(text)) / charPerLine
-	if estimatedLines < 1 {
-		estimatedLines = 1
+	maxWidth := width - 2*e.pdf.GetCellMargin()
+	lines := 0
+	for _, paragraph := range strings.Split(strings.TrimRight(strings.ReplaceAll(text, "\r", ""), "\n"), "\n") {
+		lines++
+		lineWidth, wordWidth, hasSpace := 0.0, 0.0, false
+		for _, r := range paragraph {
+			charWidth := e.pdf.GetStringWidth(string(r))
+			lineWidth += charWidth
+			if r == ' ' {
+				wordWidth, hasSpace = 0, true
+			} else {
+				wordWidth += charWidth
+			}
+			if lineWidth <= maxWidth {
+				continue
+			}
+			lines++
+			switch {
+			case hasSpace:
+				lineWidth = wordWidth
+			case lineWidth > charWidth:
+				lineWidth = charWidth
+			default:
+				// A character wider than the cell is drawn on a line of its own
+				lineWidth = 0
+			}
+			wordWidth, hasSpace = lineWidth, false
+		}
 	}
-	return estimatedLines*lineHeight + 1.0
+	return float64(lines)*lineHeight + 1.0
 }
 
 func (e *PDFExporter) drawHeader(headers []string) {
-	e.pdf.SetFont("Arial", "B", 12)
+	e.pdf.SetFont(e.fontFamily, "B", 12)
 	e.pdf.SetFillColor(240, 240, 240)
 
 	y := e.currentY
@@ -238,11 +380,11 @@ func (e *PDFExporter) drawHeader(headers []string) {
 
 func (e *PDFExporter) drawHeaderWithStyle(headers []string, style *PDFStyle) {
 	if style != nil {
-		e.pdf.SetFont(style.FontFamily, style.FontStyle, style.FontSize)
+		e.pdf.SetFont(e.resolveFontFamily(style.FontFamily), style.FontStyle, style.FontSize)
 		e.pdf.SetFillColor(style.BackgroundColor.R, style.BackgroundColor.G, style.BackgroundColor.B)
 		e.pdf.SetTextColor(style.TextColor.R, style.TextColor.G, style.TextColor.B)
 	} else {
-		e.pdf.SetFont("Arial", "B", 12)
+		e.pdf.SetFont(e.fontFamily, "B", 12)
 		e.pdf.SetFillColor(240, 240, 240)
 		e.pdf.SetTextColor(0, 0, 0)
 	}
@@ -278,6 +420,7 @@ func (e *PDFExporter) drawHeaderWithStyle(headers []string, style *PDFStyle) {
 func (e *PDFExporter) drawDataRow(data []string, style *PDFStyle) {
 	maxHeight := 8.0
 	for i, value := range data {
+		e.setColumnFont(style, e.columnStyles[i])
 		cellHeight := e.calculateCellHeight(value, e.colWidths[i]-4, 6)
 		if cellHeight > maxHeight {
 			maxHeight = cellHeight
@@ -287,11 +430,11 @@ func (e *PDFExporter) drawDataRow(data []string, style *PDFStyle) {
 	e.checkPageBreakWithHeight(maxHeight)
 
 	if style != nil {
-		e.pdf.SetFont(style.FontFamily, style.FontStyle, style.FontSize)
+		e.pdf.SetFont(e.resolveFontFamily(style.FontFamily), style.FontStyle, style.FontSize)
 		e.pdf.SetFillColor(style.BackgroundColor.R, style.BackgroundColor.G, style.BackgroundColor.B)
 		e.pdf.SetTextColor(style.TextColor.R, style.TextColor.G, style.TextColor.B)
 	} else {
-		e.pdf.SetFont("Arial", "", 10)
+		e.pdf.SetFont(e.fontFamily, "", 10)
 		e.pdf.SetFillColor(255, 255, 255)
 		e.pdf.SetTextColor(0, 0, 0)
 	}
@@ -307,8 +450,13 @@ func (e *PDFExporter) drawDataRow(data []string, style *PDFStyle) {
 
 	x = e.margin
 	for i, value := range data {
+		align := ""
+		if columnStyle, ok := e.columnStyles[i]; ok {
+			e.setColumnFont(style, columnStyle)
+			align = columnStyle.pdfAlign()
+		}
 		e.pdf.SetXY(x+2, y+2)
-		e.pdf.MultiCell(e.colWidths[i]-4, 6, value, "", "", false)
+		e.pdf.MultiCell(e.colWidths[i]-4, 6, value, "", align, false)
 		x += e.colWidths[i]
 	}
 
@@ -316,6 +464,38 @@ func (e *PDFExporter) drawDataRow(data []string, style *PDFStyle) {
 	e.rowIndex++
 }
 
+// SetColumnStyle sets the alignment and font of the data cells of a column
+// (0-based index). NumberFormat is ignored, values are printed as given.
+func (e *PDFExporter) SetColumnStyle(index int, style ColumnStyle) {
+	if e.columnStyles == nil {
+		e.columnStyles = make(map[int]ColumnStyle)
+	}
+	e.columnStyles[index] = style
+}
+
+// setColumnFont sets the font of a data cell from the row style and the
+// column style
+func (e *PDFExporter) setColumnFont(rowStyle *PDFStyle, columnStyle ColumnStyle) {
+	family, fontStyle, size := e.fontFamily, "", 10.0
+	textColor := Color{R: 0, G: 0, B: 0}
+	if rowStyle != nil {
+		family = e.resolveFontFamily(rowStyle.FontFamily)
+		fontStyle = rowStyle.FontStyle
+		size = rowStyle.FontSize
+		textColor = rowStyle.TextColor
+	}
+	if columnStyle.Bold && !strings.Contains(fontStyle, "B") {
+		fontStyle += "B"
+	}
+	if columnStyle.TextColor != "" {
+		if color, err := ParseHexColor(columnStyle.TextColor); err == nil {
+			textColor = color
+		}
+	}
+	e.pdf.SetFont(family, fontStyle, size)
+	e.pdf.SetTextColor(textColor.R, textColor.G, textColor.B)
+}
+
 func (e *PDFExporter) checkPageBreakWithHeight(rowHeight float64) {
 	// A4 page height is approximately 297mm, minus top and bottom margins
 	pageHeight := 297.0
@@ -348,6 +528,25 @@ func (e *PDFExporter) Save(filename string) error {
 	return nil
 }
 
+// WriteTo closes the document and writes it to w, implementing io.WriterTo
+func (e *PDFExporter) WriteTo(w io.Writer) (int64, error) {
+	content, err := e.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(content)
+	return int64(n), err
+}
+
+// Bytes closes the document and returns its content
+func (e *PDFExporter) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := e.pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to output PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 func (e *PDFExporter) Close() error {
 	return nil
 }
@@ -434,6 +633,17 @@ func CreatePDFDataStyle(backgroundColor ...interface{}) *PDFStyle {
 	}
 }
 
+// CreatePDFSummaryStyle creates a bold style for totals rows
+func CreatePDFSummaryStyle() *PDFStyle {
+	return &PDFStyle{
+		FontFamily:      "Arial",
+		FontStyle:       "B",
+		FontSize:        10,
+		BackgroundColor: Color{R: 230, G: 230, B: 230},
+		TextColor:       Color{R: 0, G: 0, B: 0},
+	}
+}
+
 // CreatePDFAlternatingDataStyle creates an alternating data row style with optional background color
 // backgroundColor can be either a Color struct or a hex color string (e.g., "#F8F8F8")
 func CreatePDFAlternatingDataStyle(backgroundColor ...interface{}) *PDFStyle {
@@ -0,0 +1,285 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// ReportTemplate describes the layout of a document-style PDF such as an
+// invoice or a statement: a title block with an optional logo, a list of
+// sections and a footer with "Page X of Y" on every page.
+type ReportTemplate struct {
+	Title       string
+	Subtitle    string    // Smaller line(s) under the title, e.g. the invoice number or statement period
+	Logo        *PDFImage // Drawn at the top left, the title block is then right aligned next to it
+	Sections    []Section // KeyValueSection and TableSection, drawn in order
+	Footer      string    // Drawn at the bottom left of every page, next to the page number
+	HeaderStyle *PDFStyle // Style of the table headers, CreatePDFHeaderStyle of the report header color when nil
+}
+
+// PDFImage is an image embedded in a PDF
+type PDFImage struct {
+	Data  []byte  // PNG, JPEG or GIF content
+	Width float64 // Width in mm, the height keeps the aspect ratio; 30mm when 0
+}
+
+// Section is a block of a ReportTemplate, either a KeyValueSection or a
+// TableSection
+type Section interface {
+	drawSection(e *PDFExporter, tmpl *ReportTemplate) error
+}
+
+// KeyValue is a labelled value of a KeyValueSection
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// KeyValueSection draws labelled values one per line, e.g. the billing
+// details or the totals of an invoice
+type KeyValueSection struct {
+	Title string
+	Items []KeyValue
+}
+
+// TableSection draws a table like GeneratePDFReport, repeating the header on
+// every page it spans
+type TableSection struct {
+	Title        string
+	Headers      []string
+	Rows         [][]string
+	ColumnWidths []float64           // Relative column widths, equal widths when empty
+	ColumnStyles map[int]ColumnStyle // Per column (0-based) alignment and font
+	SummaryRow   []string            // Totals row after the data, must match the header length
+}
+
+// Space left between sections, in mm
+const templateSectionSpacing = 4.0
+
+// Width of the logo when PDFImage.Width is not set, in mm
+const defaultLogoWidth = 30.0
+
+// WriteTemplate draws tmpl on the exporter. It must be called on a new
+// exporter, after setting the font.
+func (e *PDFExporter) WriteTemplate(tmpl *ReportTemplate) error {
+	if e.hasHeader {
+		return fmt.Errorf("template must be written before header")
+	}
+
+	if err := e.WriteFooter(tmpl.Footer); err != nil {
+		return err
+	}
+	if err := e.drawTitleBlock(tmpl); err != nil {
+		return err
+	}
+	for i, section := range tmpl.Sections {
+		if err := section.drawSection(e, tmpl); err != nil {
+			return fmt.Errorf("failed to write section %d: %w", i, err)
+		}
+	}
+	return e.pdf.Error()
+}
+
+// GeneratePDFFromTemplate generates a PDF laid out by tmpl. The PDF font,
+// header color, metadata and checksum options apply.
+func GeneratePDFFromTemplate(ctx context.Context, tmpl *ReportTemplate, opts ...ReportOption) ([]byte, error) {
+	exporter := NewPDFExporter()
+
+	options := getDefaultOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.PDFFont != nil {
+		if err := exporter.SetUTF8Font(options.PDFFont.Family, options.PDFFont.RegularPath, options.PDFFont.BoldPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if metadata := resolvedMetadata(options); metadata != nil {
+		exporter.SetMetadata(*metadata)
+	}
+
+	if tmpl.HeaderStyle == nil {
+		withStyle := *tmpl
+		withStyle.HeaderStyle = CreatePDFHeaderStyle(options.HeaderColor)
+		tmpl = &withStyle
+	}
+	if err := exporter.WriteTemplate(tmpl); err != nil {
+		return nil, fmt.Errorf("failed to write PDF template: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	content, err := exporter.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return finishReport(options, content), nil
+}
+
+// drawTitleBlock draws the logo, title and subtitle
+func (e *PDFExporter) drawTitleBlock(tmpl *ReportTemplate) error {
+	if tmpl.Logo == nil && tmpl.Title == "" && tmpl.Subtitle == "" {
+		return nil
+	}
+
+	top := e.currentY
+	bottom := top
+	textX := e.margin
+	textWidth := e.pageWidth - 2*e.margin
+	align := "C"
+
+	if tmpl.Logo != nil {
+		width, height, err := e.drawImage(tmpl.Logo, e.margin, top)
+		if err != nil {
+			return err
+		}
+		bottom = top + height
+		textX += width + 5
+		textWidth -= width + 5
+		align = "R"
+	}
+
+	e.pdf.SetXY(textX, top)
+	if tmpl.Title != "" {
+		e.pdf.SetFont(e.fontFamily, "B", 16)
+		e.pdf.SetTextColor(0, 0, 0)
+		e.pdf.MultiCell(textWidth, 8, tmpl.Title, "", align, false)
+	}
+	if tmpl.Subtitle != "" {
+		e.pdf.SetFont(e.fontFamily, "", 10)
+		e.pdf.SetTextColor(96, 96, 96)
+		e.pdf.SetX(textX)
+		e.pdf.MultiCell(textWidth, 5, tmpl.Subtitle, "", align, false)
+	}
+
+	e.currentY = max(bottom, e.pdf.GetY()) + 2*templateSectionSpacing
+	return nil
+}
+
+// drawImage draws img with its top left corner at x, y and returns its size
+func (e *PDFExporter) drawImage(img *PDFImage, x, y float64) (float64, float64, error) {
+	var imageType string
+	switch http.DetectContentType(img.Data) {
+	case "image/png":
+		imageType = "PNG"
+	case "image/jpeg":
+		imageType = "JPG"
+	case "image/gif":
+		imageType = "GIF"
+	default:
+		return 0, 0, fmt.Errorf("unsupported image type, expected PNG, JPEG or GIF")
+	}
+
+	name := fmt.Sprintf("image-%x", sha1.Sum(img.Data))
+	options := gofpdf.ImageOptions{ImageType: imageType}
+	info := e.pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(img.Data))
+	if err := e.pdf.Error(); err != nil {
+		return 0, 0, fmt.Errorf("failed to load image: %w", err)
+	}
+
+	width := img.Width
+	if width <= 0 {
+		width = defaultLogoWidth
+	}
+	height := width * info.Height() / info.Width()
+	e.pdf.ImageOptions(name, x, y, width, height, false, options, 0, "")
+	return width, height, nil
+}
+
+// drawSectionTitle draws the title of a section, moving to a new page first
+// if the title would be left alone at the bottom of the page
+func (e *PDFExporter) drawSectionTitle(title string) {
+	if title == "" {
+		return
+	}
+	e.checkPageBreakWithHeight(16)
+	e.pdf.SetFont(e.fontFamily, "B", 11)
+	e.pdf.SetTextColor(0, 0, 0)
+	e.pdf.SetXY(e.margin, e.currentY)
+	e.pdf.CellFormat(e.pageWidth-2*e.margin, 7, title, "", 0, "L", false, 0, "")
+	e.currentY += 8
+}
+
+func (s KeyValueSection) drawSection(e *PDFExporter, _ *ReportTemplate) error {
+	e.drawSectionTitle(s.Title)
+
+	width := e.pageWidth - 2*e.margin
+	keyWidth := width * 0.35
+	valueWidth := width - keyWidth
+
+	for _, item := range s.Items {
+		e.pdf.SetFont(e.fontFamily, "B", 10)
+		height := e.calculateCellHeight(item.Key, keyWidth, 5)
+		e.pdf.SetFont(e.fontFamily, "", 10)
+		height = max(height, e.calculateCellHeight(item.Value, valueWidth, 5))
+		e.checkPageBreakWithHeight(height)
+
+		e.pdf.SetTextColor(0, 0, 0)
+		e.pdf.SetFont(e.fontFamily, "B", 10)
+		e.pdf.SetXY(e.margin, e.currentY)
+		e.pdf.MultiCell(keyWidth, 5, item.Key, "", "L", false)
+		e.pdf.SetFont(e.fontFamily, "", 10)
+		e.pdf.SetXY(e.margin+keyWidth, e.currentY)
+		e.pdf.MultiCell(valueWidth, 5, item.Value, "", "L", false)
+
+		e.currentY += height
+	}
+
+	e.currentY += templateSectionSpacing
+	return nil
+}
+
+func (s TableSection) drawSection(e *PDFExporter, tmpl *ReportTemplate) error {
+	e.drawSectionTitle(s.Title)
+
+	// Each table has its own columns, reset those of the previous one
+	e.headers = nil
+	e.hasHeader = false
+	e.colWidths = nil
+	e.columnStyles = s.ColumnStyles
+	defer func() {
+		e.headers = nil
+		e.hasHeader = false
+		e.colWidths = nil
+		e.columnStyles = nil
+		e.headerStyle = nil
+	}()
+
+	if len(s.ColumnWidths) > 0 {
+		if err := e.SetColumnWidths(s.ColumnWidths); err != nil {
+			return err
+		}
+	}
+
+	headerStyle := tmpl.HeaderStyle
+	if headerStyle == nil {
+		headerStyle = CreatePDFHeaderStyle(getDefaultOptions().HeaderColor)
+	}
+
+	// Keep the header with at least one row
+	e.checkPageBreakWithHeight(16)
+	if err := e.WriteHeaderWithStyle(s.Headers, headerStyle); err != nil {
+		return err
+	}
+	for _, row := range s.Rows {
+		if err := e.WriteData(row); err != nil {
+			return err
+		}
+	}
+	if s.SummaryRow != nil {
+		if err := e.WriteSummaryRow(s.SummaryRow, nil); err != nil {
+			return err
+		}
+	}
+
+	e.currentY += templateSectionSpacing
+	return nil
+}
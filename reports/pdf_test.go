@@ -1,8 +1,14 @@
 package reports
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"image"
+	"image/png"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -884,3 +890,208 @@ func TestPDFExporter_DirectHexColorSupport(t *testing.T) {
 	t.Logf("- Data rows with different color input methods")
 	t.Logf("- Support for Color struct, hex strings, and default colors")
 }
+
+// testUTF8FontPath is a subset of the M+ 1p font with the ASCII, Cyrillic,
+// Greek and CJK characters used by the tests
+const testUTF8FontPath = "testdata/mplus-1p-regular-subset.ttf"
+
+func TestPDFExporter_SetUTF8Font(t *testing.T) {
+	exporter := NewPDFExporter()
+	if err := exporter.SetUTF8Font("MPlus", testUTF8FontPath, ""); err != nil {
+		t.Fatalf("Failed to set UTF-8 font: %v", err)
+	}
+
+	if err := exporter.WriteHeaderWithStyle([]string{"Имя", "Ελληνικά"}, CreatePDFHeaderStyle("#E0E0E0")); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := exporter.WriteData([]string{"Дмитрий", "Αθήνα"}); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "utf8.pdf")
+	if err := exporter.Save(filename); err != nil {
+		t.Fatalf("Failed to save PDF file: %v", err)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read PDF file: %v", err)
+	}
+	if !bytes.Contains(content, []byte("/Type0")) {
+		t.Error("Expected the UTF-8 font to be embedded in the PDF")
+	}
+}
+
+func TestPDFExporter_SetUTF8FontCJK(t *testing.T) {
+	exporter := NewPDFExporter()
+	if err := exporter.SetUTF8Font("MPlus", testUTF8FontPath, ""); err != nil {
+		t.Fatalf("Failed to set UTF-8 font: %v", err)
+	}
+
+	if err := exporter.WriteHeader([]string{"名称", "日期"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := exporter.WriteData([]string{"北京", "上海"}); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+
+	content, err := exporter.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+	if !bytes.Contains(content, []byte("/Type0")) {
+		t.Error("Expected the UTF-8 font to be embedded in the PDF")
+	}
+}
+
+func TestPDFExporter_CalculateCellHeightMultibyte(t *testing.T) {
+	exporter := NewPDFExporter()
+	if err := exporter.SetUTF8Font("MPlus", testUTF8FontPath, ""); err != nil {
+		t.Fatalf("Failed to set UTF-8 font: %v", err)
+	}
+
+	// 30 bytes of CJK text, measured by width rather than length
+	cjk := strings.Repeat("中文", 5)
+	width := exporter.pdf.GetStringWidth(cjk) + 2*exporter.pdf.GetCellMargin() + 1
+	if height := exporter.calculateCellHeight(cjk, width, 6); height != 7 {
+		t.Errorf("Expected CJK text fitting the width on one line, got height %v", height)
+	}
+	if height := exporter.calculateCellHeight(cjk+cjk, width, 6); height != 13 {
+		t.Errorf("Expected CJK text twice the width on two lines, got height %v", height)
+	}
+	// Words are wrapped whole, at the last space
+	word := strings.Repeat("a", 6)
+	wordWidth := exporter.pdf.GetStringWidth(word + " " + word)
+	if height := exporter.calculateCellHeight(word+" "+word+" "+word, wordWidth+2*exporter.pdf.GetCellMargin()+0.1, 6); height != 13 {
+		t.Errorf("Expected three words on two lines, got height %v", height)
+	}
+	if height := exporter.calculateCellHeight("名称\n日期\n", width, 6); height != 13 {
+		t.Errorf("Expected one line per paragraph, got height %v", height)
+	}
+}
+
+func TestPDFExporter_SetUTF8FontMissingFile(t *testing.T) {
+	exporter := NewPDFExporter()
+	if err := exporter.SetUTF8Font("Missing", "/nonexistent/font.ttf", ""); err == nil {
+		t.Error("Expected error for missing font file, got nil")
+	}
+}
+
+func TestGeneratePDFReport_WithUTF8Font(t *testing.T) {
+	content, err := GeneratePDFReport(context.Background(), []string{"Имя"}, [][]string{{"Дмитрий"}}, WithPDFUTF8Font("MPlus", testUTF8FontPath, ""))
+	if err != nil {
+		t.Fatalf("Failed to generate PDF report: %v", err)
+	}
+	if !bytes.HasPrefix(content, []byte("%PDF")) {
+		t.Error("Generated content is not a PDF")
+	}
+}
+
+func TestGeneratePDFReport_TitleFooterSummary(t *testing.T) {
+	content, err := GeneratePDFReport(context.Background(), []string{"Name", "Amount"}, [][]string{{"John", "10"}},
+		WithTitle("Daily Report"),
+		WithSummaryRow([]string{"Total", "10"}),
+		WithFooter("Generated at 2024-01-01"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to generate PDF report: %v", err)
+	}
+	if !bytes.HasPrefix(content, []byte("%PDF")) {
+		t.Error("Generated content is not a PDF")
+	}
+
+	exporter := NewPDFExporter()
+	if err := exporter.WriteHeader([]string{"Name"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := exporter.WriteTitle("Too late", nil); err == nil {
+		t.Error("Expected error writing title after header, got nil")
+	}
+}
+
+func TestGeneratePDFReport_ColumnOptions(t *testing.T) {
+	content, err := GeneratePDFReport(context.Background(), []string{"Name", "Amount"}, [][]string{{"John", "10.50"}},
+		WithColumnWidths(3, 1),
+		WithNumberFormat(1, NumberFormatDecimal),
+		WithColumnStyle(0, ColumnStyle{Bold: true, TextColor: "#CC0000"}),
+		WithSummaryRow([]string{"Total", "10.50"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to generate PDF report: %v", err)
+	}
+	if !bytes.HasPrefix(content, []byte("%PDF")) {
+		t.Error("Generated content is not a PDF")
+	}
+
+	if _, err := GeneratePDFReport(context.Background(), []string{"Name", "Amount"}, nil, WithColumnWidths(1, 2, 3)); err == nil {
+		t.Error("Expected error for mismatched column widths, got nil")
+	}
+}
+
+func TestGeneratePDFFromTemplate(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	var logoPNG bytes.Buffer
+	if err := png.Encode(&logoPNG, logo); err != nil {
+		t.Fatalf("Failed to encode logo: %v", err)
+	}
+
+	rows := make([][]string, 80)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprintf("Item %d", i+1), "1", "9.99"}
+	}
+
+	content, err := GeneratePDFFromTemplate(context.Background(), &ReportTemplate{
+		Title:    "Invoice",
+		Subtitle: "INV-2024-0001\nIssued 2024-01-01",
+		Logo:     &PDFImage{Data: logoPNG.Bytes(), Width: 20},
+		Sections: []Section{
+			KeyValueSection{Title: "Bill To", Items: []KeyValue{{Key: "Customer", Value: "John Doe"}, {Key: "Account", Value: "1001"}}},
+			TableSection{
+				Title:        "Items",
+				Headers:      []string{"Description", "Qty", "Amount"},
+				Rows:         rows,
+				ColumnWidths: []float64{3, 1, 1},
+				ColumnStyles: map[int]ColumnStyle{2: {Align: "right"}},
+				SummaryRow:   []string{"Total", "80", "799.20"},
+			},
+			KeyValueSection{Items: []KeyValue{{Key: "Amount Due", Value: "799.20"}}},
+		},
+		Footer: "Thank you for your business",
+	}, WithHeaderColor("#4F81BD"))
+	if err != nil {
+		t.Fatalf("Failed to generate PDF from template: %v", err)
+	}
+	if !bytes.HasPrefix(content, []byte("%PDF")) {
+		t.Error("Generated content is not a PDF")
+	}
+
+	_, err = GeneratePDFFromTemplate(context.Background(), &ReportTemplate{
+		Sections: []Section{TableSection{Headers: []string{"A", "B"}, Rows: [][]string{{"1"}}}},
+	})
+	if err == nil {
+		t.Error("Expected error for mismatched row length, got nil")
+	}
+
+	_, err = GeneratePDFFromTemplate(context.Background(), &ReportTemplate{Logo: &PDFImage{Data: []byte("not an image")}})
+	if err == nil {
+		t.Error("Expected error for unsupported logo, got nil")
+	}
+}
+
+func TestPDFExporter_WriteTo(t *testing.T) {
+	exporter := NewPDFExporter()
+	if err := exporter.WriteHeader([]string{"ID", "Name"}); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	if err := exporter.WriteData([]string{"1", "Alice"}); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := exporter.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != int64(buf.Len()) || !bytes.HasPrefix(buf.Bytes(), []byte("%PDF")) {
+		t.Errorf("Expected a PDF of %d bytes, got %d bytes", n, buf.Len())
+	}
+}
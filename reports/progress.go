@@ -0,0 +1,53 @@
+package reports
+
+import "context"
+
+// progressInterval is the number of data rows between two progress reports
+const progressInterval = 1000
+
+// ProgressFunc receives the number of data rows written so far and the total
+// number of data rows, 0 when it is not known in advance as with
+// StreamingReportWriter.
+type ProgressFunc func(written, total int)
+
+// WithProgress calls fn every 1000 data rows and once all rows are written,
+// e.g. to report the progress of a large export to the UI. fn is called from
+// the goroutine generating the report.
+func WithProgress(fn ProgressFunc) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.Progress = fn
+	}
+}
+
+// rowProgress checks for cancellation and reports progress while the data
+// rows of a report are written
+type rowProgress struct {
+	ctx     context.Context
+	fn      ProgressFunc
+	total   int
+	written int
+}
+
+func newRowProgress(ctx context.Context, options *ReportOptions, total int) *rowProgress {
+	return &rowProgress{ctx: ctx, fn: options.Progress, total: total}
+}
+
+// check returns the context error once the report is canceled
+func (p *rowProgress) check() error {
+	return p.ctx.Err()
+}
+
+// add counts a written row
+func (p *rowProgress) add() {
+	p.written++
+	if p.fn != nil && p.written%progressInterval == 0 {
+		p.fn(p.written, p.total)
+	}
+}
+
+// finish reports the final count unless it was just reported
+func (p *rowProgress) finish() {
+	if p.fn != nil && (p.written == 0 || p.written%progressInterval != 0) {
+		p.fn(p.written, p.total)
+	}
+}
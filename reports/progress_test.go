@@ -0,0 +1,101 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func progressTestData(rows int) ([]string, [][]string) {
+	data := make([][]string, rows)
+	for i := range data {
+		data[i] = []string{fmt.Sprint(i + 1), "10"}
+	}
+	return []string{"ID", "Amount"}, data
+}
+
+func TestWithProgress(t *testing.T) {
+	headers, data := progressTestData(2500)
+
+	for _, format := range []string{"csv", "excel", "json", "pdf"} {
+		var calls [][2]int
+		_, _, err := GenerateReport(context.Background(), format, headers, data, WithProgress(func(written, total int) {
+			calls = append(calls, [2]int{written, total})
+		}))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		want := [][2]int{{1000, 2500}, {2000, 2500}, {2500, 2500}}
+		if !reflect.DeepEqual(calls, want) {
+			t.Errorf("%s: progress calls = %v, want %v", format, calls, want)
+		}
+	}
+}
+
+func TestGenerateReportCanceled(t *testing.T) {
+	headers, data := progressTestData(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, format := range []string{"csv", "excel", "json", "ndjson", "pdf"} {
+		if _, _, err := GenerateReport(ctx, format, headers, data); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", format, err)
+		}
+	}
+	if _, err := GenerateReportArchive(ctx, "csv", headers, data); !errors.Is(err, context.Canceled) {
+		t.Errorf("archive: expected context.Canceled, got %v", err)
+	}
+}
+
+func TestGenerateReportArchiveProgress(t *testing.T) {
+	headers, data := progressTestData(5)
+
+	var calls [][2]int
+	_, err := GenerateReportArchive(context.Background(), "csv", headers, data, WithMaxRowsPerFile(2), WithProgress(func(written, total int) {
+		calls = append(calls, [2]int{written, total})
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := [][2]int{{2, 5}, {4, 5}, {5, 5}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("progress calls = %v, want %v", calls, want)
+	}
+}
+
+func TestStreamingReportWriterProgressAndCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls [][2]int
+	var buf bytes.Buffer
+	sw, err := NewStreamingReportWriter(ctx, &buf, "csv", WithProgress(func(written, total int) {
+		calls = append(calls, [2]int{written, total})
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	headers, data := progressTestData(1500)
+	if err := sw.WriteHeader(headers); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := sw.WriteRows(data); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cancel()
+	if err := sw.WriteRow([]string{"1501", "10"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := [][2]int{{1000, 0}, {1500, 0}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("progress calls = %v, want %v", calls, want)
+	}
+}
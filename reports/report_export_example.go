@@ -1,6 +1,7 @@
 package reports
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -88,7 +89,8 @@ func ExampleExportCustomerRecords() {
 	fmt.Printf("Formatted %d rows of data\n", len(formattedRows))
 
 	// 3. Step 2: Use the unified GenerateReport function
-	unifiedData, ext, err := GenerateReport("excel", CustomerRecordsHeaders, formattedRows, WithHeaderColor("#FFE6E6"))
+	ctx := context.Background()
+	unifiedData, ext, err := GenerateReport(ctx, "excel", CustomerRecordsHeaders, formattedRows, WithHeaderColor("#FFE6E6"))
 	if err != nil {
 		fmt.Printf("Error generating unified report: %v\n", err)
 		return
@@ -99,7 +101,7 @@ func ExampleExportCustomerRecords() {
 	// This step converts the 2D string array into the final file format (CSV, Excel, PDF)
 
 	// Generate CSV report
-	csvData, err := GenerateCSVReport(CustomerRecordsHeaders, formattedRows)
+	csvData, err := GenerateCSVReport(ctx, CustomerRecordsHeaders, formattedRows)
 	if err != nil {
 		fmt.Printf("Error generating CSV: %v\n", err)
 		return
@@ -107,7 +109,7 @@ func ExampleExportCustomerRecords() {
 	fmt.Printf("Generated CSV report: %d bytes\n", len(csvData))
 
 	// Generate Excel report with custom header color
-	excelData, err := GenerateExcelReport(CustomerRecordsHeaders, formattedRows, WithHeaderColor("#E6F3FF"))
+	excelData, err := GenerateExcelReport(ctx, CustomerRecordsHeaders, formattedRows, WithHeaderColor("#E6F3FF"))
 	if err != nil {
 		fmt.Printf("Error generating Excel: %v\n", err)
 		return
@@ -115,7 +117,7 @@ func ExampleExportCustomerRecords() {
 	fmt.Printf("Generated Excel report: %d bytes\n", len(excelData))
 
 	// Generate PDF report
-	pdfData, err := GeneratePDFReport(CustomerRecordsHeaders, formattedRows, WithHeaderColor("#F0F8FF"))
+	pdfData, err := GeneratePDFReport(ctx, CustomerRecordsHeaders, formattedRows, WithHeaderColor("#F0F8FF"))
 	if err != nil {
 		fmt.Printf("Error generating PDF: %v\n", err)
 		return
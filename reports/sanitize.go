@@ -0,0 +1,169 @@
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ExcelMaxCellLength is the maximum number of characters Excel accepts in a cell
+const ExcelMaxCellLength = 32767
+
+var (
+	// ErrInvalidUTF8 is reported for cells that are not valid UTF-8
+	ErrInvalidUTF8 = errors.New("cell is not valid UTF-8")
+	// ErrCellTooLong is reported for cells longer than MaxCellLength
+	ErrCellTooLong = errors.New("cell exceeds max length")
+)
+
+// formulaPrefixes are the leading characters that make spreadsheet
+// applications evaluate a cell as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+// CellSanitizer cleans the header and data cells of a report before they are
+// written. Cells that cannot be fixed are reported as ValidationErrors
+// instead of producing a file that is broken or rejected by Excel.
+type CellSanitizer struct {
+	// StripControlChars removes control characters other than tab, newline
+	// and carriage return, which are invalid in xlsx files
+	StripControlChars bool
+	// EscapeFormulas prefixes cells starting with '=', '+', '-', '@', tab or
+	// carriage return with a single quote, so a spreadsheet opening the file
+	// does not evaluate them (CSV injection). Numbers such as "-12.5" are
+	// left as is.
+	EscapeFormulas bool
+	// MaxCellLength is the maximum number of characters of a cell, 0 for no
+	// limit
+	MaxCellLength int
+	// TruncateLongCells cuts cells to MaxCellLength instead of reporting them
+	TruncateLongCells bool
+}
+
+// DefaultCellSanitizer returns a sanitizer stripping control characters,
+// escaping formulas and rejecting cells longer than Excel allows
+func DefaultCellSanitizer() *CellSanitizer {
+	return &CellSanitizer{
+		StripControlChars: true,
+		EscapeFormulas:    true,
+		MaxCellLength:     ExcelMaxCellLength,
+	}
+}
+
+// ValidationError describes a cell rejected by a CellSanitizer. Row is 0 for
+// the header and 1-based for data rows, Column is 0-based.
+type ValidationError struct {
+	Row    int
+	Column int
+	Err    error
+}
+
+func (e ValidationError) Error() string {
+	if e.Row == 0 {
+		return fmt.Sprintf("header column %d: %v", e.Column, e.Err)
+	}
+	return fmt.Sprintf("row %d column %d: %v", e.Row, e.Column, e.Err)
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// maxReportedValidationErrors bounds the errors listed in the message of
+// ValidationErrors, the slice itself keeps all of them
+const maxReportedValidationErrors = 10
+
+// ValidationErrors lists every cell rejected while sanitizing a report
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, min(len(e), maxReportedValidationErrors))
+	for i := 0; i < len(e) && i < maxReportedValidationErrors; i++ {
+		messages = append(messages, e[i].Error())
+	}
+	message := fmt.Sprintf("%d invalid cells: %s", len(e), strings.Join(messages, "; "))
+	if len(e) > maxReportedValidationErrors {
+		message += "; ..."
+	}
+	return message
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i := range e {
+		errs[i] = e[i]
+	}
+	return errs
+}
+
+// WithCellSanitizer cleans the header and data cells with sanitizer before
+// writing, see DefaultCellSanitizer. Reports with cells that cannot be fixed
+// fail with ValidationErrors.
+func WithCellSanitizer(sanitizer *CellSanitizer) ReportOption {
+	return func(opts *ReportOptions) {
+		opts.CellSanitizer = sanitizer
+	}
+}
+
+// SanitizeCell returns the sanitized value of a cell
+func (s *CellSanitizer) SanitizeCell(value string) (string, error) {
+	if !utf8.ValidString(value) {
+		return value, ErrInvalidUTF8
+	}
+	if s.StripControlChars {
+		value = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+				return -1
+			}
+			return r
+		}, value)
+	}
+	if s.EscapeFormulas && value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		if _, ok := parseNumber(value); !ok {
+			value = "'" + value
+		}
+	}
+	if s.MaxCellLength > 0 && utf8.RuneCountInString(value) > s.MaxCellLength {
+		if !s.TruncateLongCells {
+			return value, fmt.Errorf("%w of %d characters", ErrCellTooLong, s.MaxCellLength)
+		}
+		value = string([]rune(value)[:s.MaxCellLength])
+	}
+	return value, nil
+}
+
+// SanitizeRow returns a sanitized copy of row, reporting its rejected cells
+// under the given row number
+func (s *CellSanitizer) SanitizeRow(rowNumber int, row []string) ([]string, ValidationErrors) {
+	sanitized := make([]string, len(row))
+	var errs ValidationErrors
+	for i, value := range row {
+		cell, err := s.SanitizeCell(value)
+		if err != nil {
+			errs = append(errs, ValidationError{Row: rowNumber, Column: i, Err: err})
+		}
+		sanitized[i] = cell
+	}
+	return sanitized, errs
+}
+
+// sanitizeReport returns sanitized copies of headers and data, or them as is
+// without a sanitizer
+func sanitizeReport(options *ReportOptions, headers []string, data [][]string) ([]string, [][]string, error) {
+	if options.CellSanitizer == nil {
+		return headers, data, nil
+	}
+
+	headers, errs := options.CellSanitizer.SanitizeRow(0, headers)
+	sanitized := make([][]string, len(data))
+	for i, row := range data {
+		var rowErrs ValidationErrors
+		sanitized[i], rowErrs = options.CellSanitizer.SanitizeRow(i+1, row)
+		errs = append(errs, rowErrs...)
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	return headers, sanitized, nil
+}
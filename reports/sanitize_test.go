@@ -0,0 +1,152 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCellSanitizer_SanitizeCell(t *testing.T) {
+	sanitizer := DefaultCellSanitizer()
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain", "plain"},
+		{"=SUM(A1:A2)", "'=SUM(A1:A2)"},
+		{"+1-2", "'+1-2"},
+		{"@cmd", "'@cmd"},
+		{"-12.5", "-12.5"},
+		{"-1,234", "-1,234"},
+		{"a\x00b\x1fc", "abc"},
+		{"line1\nline2\tend", "line1\nline2\tend"},
+		{"\x07=1+1", "'=1+1"},
+		{"\t=1+1", "'\t=1+1"},
+	}
+	for _, tt := range tests {
+		got, err := sanitizer.SanitizeCell(tt.value)
+		if err != nil {
+			t.Errorf("SanitizeCell(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("SanitizeCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := sanitizer.SanitizeCell("bad \xff utf8"); !errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("Expected ErrInvalidUTF8, got %v", err)
+	}
+}
+
+func TestCellSanitizer_MaxCellLength(t *testing.T) {
+	sanitizer := &CellSanitizer{MaxCellLength: 3}
+	if _, err := sanitizer.SanitizeCell("你好世界"); !errors.Is(err, ErrCellTooLong) {
+		t.Errorf("Expected ErrCellTooLong, got %v", err)
+	}
+
+	sanitizer.TruncateLongCells = true
+	got, err := sanitizer.SanitizeCell("你好世界")
+	if err != nil {
+		t.Fatalf("SanitizeCell failed: %v", err)
+	}
+	if got != "你好世" {
+		t.Errorf("Expected truncated cell, got %q", got)
+	}
+}
+
+func TestGenerateReport_CellSanitizer(t *testing.T) {
+	headers := []string{"Name", "Note"}
+	data := [][]string{{"alice", "=HYPERLINK(\"http://evil\")"}, {"bob", "-5"}}
+
+	content, err := GenerateCSVReport(context.Background(), headers, data, WithCellSanitizer(DefaultCellSanitizer()))
+	if err != nil {
+		t.Fatalf("GenerateCSVReport failed: %v", err)
+	}
+	want := "Name,Note\nalice,\"'=HYPERLINK(\"\"http://evil\"\")\"\nbob,-5\n"
+	if string(content) != want {
+		t.Errorf("Unexpected CSV:\n%s", content)
+	}
+
+	// the input is not modified
+	if data[0][1] != "=HYPERLINK(\"http://evil\")" {
+		t.Errorf("Input data was modified: %q", data[0][1])
+	}
+}
+
+func TestGenerateReport_ValidationErrors(t *testing.T) {
+	headers := []string{"Name", "Note"}
+	data := [][]string{
+		{"alice", "ok"},
+		{"bob", strings.Repeat("x", 11)},
+		{"carol\xff", strings.Repeat("x", 11)},
+	}
+	sanitizer := &CellSanitizer{MaxCellLength: 10}
+
+	for _, format := range []string{"csv", "excel", "pdf", "json"} {
+		_, _, err := GenerateReport(context.Background(), format, headers, data, WithCellSanitizer(sanitizer))
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("%s: expected ValidationErrors, got %v", format, err)
+		}
+		if len(errs) != 3 {
+			t.Fatalf("%s: expected 3 validation errors, got %v", format, errs)
+		}
+		want := []ValidationError{
+			{Row: 2, Column: 1, Err: ErrCellTooLong},
+			{Row: 3, Column: 0, Err: ErrInvalidUTF8},
+			{Row: 3, Column: 1, Err: ErrCellTooLong},
+		}
+		for i, e := range want {
+			if errs[i].Row != e.Row || errs[i].Column != e.Column || !errors.Is(errs[i], e.Err) {
+				t.Errorf("%s: error %d = %v, want row %d column %d: %v", format, i, errs[i], e.Row, e.Column, e.Err)
+			}
+		}
+	}
+	if _, _, err := GenerateReport(context.Background(), "csv", headers, data, WithCellSanitizer(sanitizer)); !errors.Is(err, ErrCellTooLong) {
+		t.Errorf("Expected the error to match ErrCellTooLong, got %v", err)
+	}
+}
+
+func TestGenerateReportArchive_ValidationErrors(t *testing.T) {
+	headers := []string{"ID", "Note"}
+	data := [][]string{{"1", "ok"}, {"2", "ok"}, {"3", "too long"}}
+
+	_, err := GenerateReportArchive(context.Background(), "csv", headers, data,
+		WithMaxRowsPerFile(2),
+		WithCellSanitizer(&CellSanitizer{MaxCellLength: 5}),
+	)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Row != 3 {
+		t.Fatalf("Expected a validation error on row 3, got %v", err)
+	}
+}
+
+func TestStreamingReportWriter_CellSanitizer(t *testing.T) {
+	var buf bytes.Buffer
+	sw, err := NewStreamingReportWriter(context.Background(), &buf, "csv", WithCellSanitizer(DefaultCellSanitizer()))
+	if err != nil {
+		t.Fatalf("NewStreamingReportWriter failed: %v", err)
+	}
+	if err := sw.WriteHeader([]string{"Formula"}); err != nil {
+		t.Fatalf("WriteHeader failed: %v", err)
+	}
+	if err := sw.WriteRow([]string{"@SUM(1)"}); err != nil {
+		t.Fatalf("WriteRow failed: %v", err)
+	}
+
+	err = sw.WriteRow([]string{"\xff"})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || errs[0].Row != 2 {
+		t.Fatalf("Expected a validation error on row 2, got %v", err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if buf.String() != "Formula\n'@SUM(1)\n" {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,119 @@
+package request
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"go.uber.org/zap"
+)
+
+// defaultGzipMinSize is the smallest request body WithGzipRequestBody compresses
+const defaultGzipMinSize = 1 << 20
+
+// acceptEncoding is sent unless the request sets its own Accept-Encoding,
+// decodeResponseBody handles each of these
+const acceptEncoding = "gzip, deflate, br"
+
+// WithGzipRequestBody gzips request bodies of at least 1MB and sets
+// Content-Encoding: gzip, use WithGzipMinSize to change the threshold.
+// Signers see the uncompressed body. Multipart bodies are never compressed.
+func WithGzipRequestBody() Option {
+	return optionFunc(func(option *requestOption) error {
+		option.gzipRequestBody = true
+		return nil
+	})
+}
+
+// WithGzipMinSize gzips request bodies of at least minSize bytes, see
+// WithGzipRequestBody. A minSize of 0 compresses every non-empty body.
+func WithGzipMinSize(minSize int) Option {
+	return optionFunc(func(option *requestOption) error {
+		if minSize < 0 {
+			option.lg.Error("[HTTP-REQUEST-ERROR: invalid gzip min size]",
+				zap.Int("minSize", minSize),
+			)
+			return fmt.Errorf("invalid gzip min size: %d", minSize)
+		}
+		option.gzipRequestBody = true
+		option.gzipMinSize = minSize
+		return nil
+	})
+}
+
+// compressRequestBody gzips the request body into option.encodedBody when it
+// reaches the configured size, once for all attempts
+func compressRequestBody(option *requestOption) error {
+	if !option.gzipRequestBody || option.multipartBody != nil || option.requestBody == nil {
+		return nil
+	}
+	body := *option.requestBody
+	if len(body) == 0 || len(body) < option.gzipMinSize {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return fmt.Errorf("failed to gzip request body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to gzip request body: %w", err)
+	}
+
+	encodedBody := buf.Bytes()
+	option.encodedBody = &encodedBody
+	if option.requestHeaders == nil {
+		option.requestHeaders = &map[string]string{}
+	}
+	(*option.requestHeaders)["Content-Encoding"] = "gzip"
+	return nil
+}
+
+// decodeResponseBody undoes the Content-Encoding of a response body. The
+// encodings are applied in the listed order, so they are undone in reverse.
+// A limit above 0 caps the size of the decoded body.
+func decodeResponseBody(contentEncoding string, body []byte, limit int64) ([]byte, error) {
+	encodings := strings.Split(contentEncoding, ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+
+		var reader io.Reader
+		switch encoding {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			gzipReader, err := gzip.NewReader(bytes.NewReader(body))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode gzip response body: %w", err)
+			}
+			defer gzipReader.Close()
+			reader = gzipReader
+		case "deflate":
+			// deflate is meant to be zlib wrapped, but some servers send raw deflate
+			zlibReader, err := zlib.NewReader(bytes.NewReader(body))
+			if err != nil {
+				reader = flate.NewReader(bytes.NewReader(body))
+			} else {
+				defer zlibReader.Close()
+				reader = zlibReader
+			}
+		case "br":
+			reader = brotli.NewReader(bytes.NewReader(body))
+		default:
+			return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+		}
+
+		decoded, err := readResponseBody(reader, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s response body: %w", encoding, err)
+		}
+		body = decoded
+	}
+	return body, nil
+}
@@ -0,0 +1,86 @@
+package request
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipRequestBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = reader
+		}
+		data, _ := io.ReadAll(body)
+		w.Header().Set("X-Content-Encoding", r.Header.Get("Content-Encoding"))
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	large := []byte(`{"data":"` + strings.Repeat("a", 2048) + `"}`)
+
+	var recorded *RequestRecordData
+	statusCode, responseBody, err := Post(context.Background(), server.URL, large,
+		WithGzipMinSize(1024),
+		WithRequestRecorder(func(data *RequestRecordData) { recorded = data }),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, large, responseBody)
+	assert.Contains(t, recorded.RequestHeaders, `"Content-Encoding":"gzip"`)
+	assert.Equal(t, string(large), recorded.RequestBody)
+
+	// below the default 1MB threshold the body is sent as is
+	var headers http.Header
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+	})
+	_, _, err = Post(context.Background(), server.URL, large, WithGzipRequestBody())
+	require.NoError(t, err)
+	assert.Empty(t, headers.Get("Content-Encoding"))
+}
+
+func TestDecodeResponseBody(t *testing.T) {
+	payload := []byte(`{"id":1,"name":"John"}`)
+
+	encode := map[string]func(w io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"br":      func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+	}
+	for encoding, newWriter := range encode {
+		t.Run(encoding, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, acceptEncoding, r.Header.Get("Accept-Encoding"))
+				var buf bytes.Buffer
+				writer := newWriter(&buf)
+				_, _ = writer.Write(payload)
+				_ = writer.Close()
+				w.Header().Set("Content-Encoding", encoding)
+				_, _ = w.Write(buf.Bytes())
+			}))
+			defer server.Close()
+
+			statusCode, responseBody, err := Get(context.Background(), server.URL)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, statusCode)
+			assert.Equal(t, payload, responseBody)
+		})
+	}
+
+	_, err := decodeResponseBody("zstd", payload, 0)
+	assert.Error(t, err)
+}
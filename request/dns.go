@@ -0,0 +1,179 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// WithDNSCache caches the addresses of the hosts dialed for ttl instead of
+// resolving them for every new connection. Concurrent lookups of a host are
+// coalesced, and the last addresses are reused while the resolver fails.
+// Requests using the same ttl, overrides and pooling settings share one
+// transport, and so one cache.
+func WithDNSCache(ttl time.Duration) Option {
+	return optionFunc(func(option *requestOption) error {
+		ensureTransportConfig(option).dnsCacheTTL = ttl
+		return nil
+	})
+}
+
+// WithHostOverride dials ip instead of resolving host, e.g. to pin a provider
+// endpoint during an incident or to reach a test server. The URL, Host header
+// and TLS server name still use host. With a proxy, it applies to the proxy
+// host since that is the host dialed. Requests using the same overrides and
+// pooling settings share one transport.
+func WithHostOverride(host, ip string) Option {
+	return optionFunc(func(option *requestOption) error {
+		var err error
+		switch {
+		case host == "":
+			err = fmt.Errorf("missing host")
+		case net.ParseIP(ip) == nil:
+			err = fmt.Errorf("invalid ip %q", ip)
+		}
+		if err != nil {
+			option.lg.Error("[HTTP-REQUEST-ERROR: invalid host override]",
+				zap.String("host", host),
+				zap.Error(err),
+			)
+			return fmt.Errorf("invalid host override: %w", err)
+		}
+		config := ensureTransportConfig(option)
+		overrides := parseHostOverrides(config.hostOverrides)
+		overrides[strings.ToLower(host)] = ip
+		config.hostOverrides = formatHostOverrides(overrides)
+		return nil
+	})
+}
+
+// formatHostOverrides encodes overrides as sorted "host=ip" pairs joined by
+// commas, so that transportConfig stays comparable
+func formatHostOverrides(overrides map[string]string) string {
+	pairs := make([]string, 0, len(overrides))
+	for _, host := range slices.Sorted(maps.Keys(overrides)) {
+		pairs = append(pairs, host+"="+overrides[host])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func parseHostOverrides(s string) map[string]string {
+	overrides := make(map[string]string)
+	if s == "" {
+		return overrides
+	}
+	for pair := range strings.SplitSeq(s, ",") {
+		host, ip, _ := strings.Cut(pair, "=")
+		overrides[host] = ip
+	}
+	return overrides
+}
+
+// resolvingDialer dials the overridden or cached addresses of a host
+type resolvingDialer struct {
+	dialer    *net.Dialer
+	overrides map[string]string
+	cache     *dnsCache // nil without WithDNSCache
+}
+
+func newResolvingDialer(dialer *net.Dialer, config transportConfig) *resolvingDialer {
+	d := &resolvingDialer{dialer: dialer, overrides: parseHostOverrides(config.hostOverrides)}
+	if config.dnsCacheTTL > 0 {
+		d.cache = newDNSCache(config.dnsCacheTTL, net.DefaultResolver.LookupHost)
+	}
+	return d
+}
+
+func (d *resolvingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return d.dialer.DialContext(ctx, network, address)
+	}
+	if ip, ok := d.overrides[strings.ToLower(host)]; ok {
+		return d.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+	}
+	if d.cache == nil || net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, address)
+	}
+
+	addrs, err := d.cache.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	// Try every address like the default dialer, the first error being the
+	// most relevant one
+	var firstErr error
+	for _, addr := range addrs {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
+
+type dnsEntry struct {
+	addrs     []string
+	expiresAt time.Time
+}
+
+// dnsCache caches the addresses of hosts for ttl
+type dnsCache struct {
+	ttl        time.Duration
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	group      singleflight.Group
+
+	mu      sync.RWMutex
+	entries map[string]dnsEntry
+}
+
+func newDNSCache(ttl time.Duration, lookupHost func(ctx context.Context, host string) ([]string, error)) *dnsCache {
+	return &dnsCache{ttl: ttl, lookupHost: lookupHost, entries: make(map[string]dnsEntry)}
+}
+
+// lookup returns the addresses of host, resolving it once its entry expired.
+// If the resolution fails, the expired addresses are returned if any.
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[host]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.addrs, nil
+	}
+
+	result, err, _ := c.group.Do(host, func() (any, error) {
+		// Not bound to the context of the first caller, whose cancellation
+		// would fail the lookup of the others
+		lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		addrs, err := c.lookupHost(lookupCtx, host)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.entries[host] = dnsEntry{addrs: addrs, expiresAt: time.Now().Add(c.ttl)}
+		c.mu.Unlock()
+		return addrs, nil
+	})
+	if err != nil {
+		if ok {
+			return entry.addrs, nil
+		}
+		return nil, err
+	}
+	return result.([]string), nil
+}
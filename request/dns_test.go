@@ -0,0 +1,104 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestWithHostOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.Host)
+		assert.Equal(t, "provider.invalid", host, "the Host header should keep the overridden host")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	statusCode, _, err := Get(context.Background(), "http://provider.invalid:"+serverURL.Port(), WithHostOverride("Provider.invalid", serverURL.Hostname()))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+func TestWithHostOverride(t *testing.T) {
+	option := defaultRequestOption()
+	assert.NoError(t, WithHostOverride("b.example", "10.0.0.2").apply(option))
+	assert.NoError(t, WithHostOverride("a.example", "10.0.0.1").apply(option))
+	assert.Equal(t, "a.example=10.0.0.1,b.example=10.0.0.2", option.transportConfig.hostOverrides)
+
+	// The same overrides in any order share a transport
+	other := defaultRequestOption()
+	assert.NoError(t, WithHostOverride("a.example", "10.0.0.1").apply(other))
+	assert.NoError(t, WithHostOverride("b.example", "10.0.0.2").apply(other))
+	assert.Same(t, resolveHttpClient(option), resolveHttpClient(other))
+
+	assert.Error(t, WithHostOverride("a.example", "not-an-ip").apply(defaultRequestOption()))
+	assert.Error(t, WithHostOverride("", "10.0.0.1").apply(defaultRequestOption()))
+}
+
+func TestRequestWithDNSCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	statusCode, _, err := Get(context.Background(), "http://localhost:"+serverURL.Port(), WithDNSCache(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+func TestDNSCache(t *testing.T) {
+	var lookups atomic.Int32
+	var fail atomic.Bool
+	cache := newDNSCache(50*time.Millisecond, func(ctx context.Context, host string) ([]string, error) {
+		lookups.Add(1)
+		if fail.Load() {
+			return nil, errors.New("resolver unavailable")
+		}
+		time.Sleep(10 * time.Millisecond)
+		return []string{"10.0.0.1"}, nil
+	})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			addrs, err := cache.lookup(ctx, "provider.example")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"10.0.0.1"}, addrs)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), lookups.Load(), "concurrent lookups should be coalesced")
+
+	_, err := cache.lookup(ctx, "provider.example")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), lookups.Load(), "cached addresses should be reused")
+
+	time.Sleep(60 * time.Millisecond)
+	fail.Store(true)
+	addrs, err := cache.lookup(ctx, "provider.example")
+	assert.NoError(t, err, "expired addresses should be reused while the resolver fails")
+	assert.Equal(t, []string{"10.0.0.1"}, addrs)
+	assert.Equal(t, int32(2), lookups.Load())
+
+	_, err = cache.lookup(ctx, "other.example")
+	assert.Error(t, err)
+}
@@ -22,6 +22,8 @@ const (
 	ErrCodeRequestTimeout
 	ErrCodeFailedToReadResponseBody
 	ErrCodeInvalidSignerKeys
+	ErrCodeUnexpectedStatusCode
+	ErrCodeFailedToDecodeResponse
 )
 
 type requestErrorOption func(*RequestError)
@@ -0,0 +1,109 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/infigaming-com/go-common/util"
+	"go.uber.org/zap"
+)
+
+// WithHedging sends up to maxHedges extra identical requests when no response
+// has arrived within delay of the previous one. The first successful response
+// is returned and the requests still in flight are cancelled; responses with
+// a 429 or 5xx status count as failures, returned only when every request
+// fails. Hedging is
+// meant for idempotent, latency-sensitive calls and is ignored for multipart
+// requests, whose body cannot be replayed.
+func WithHedging(delay time.Duration, maxHedges int) Option {
+	return optionFunc(func(option *requestOption) error {
+		if delay <= 0 || maxHedges < 0 {
+			option.lg.Error("[HTTP-REQUEST-ERROR: invalid hedging settings]",
+				zap.Duration("delay", delay),
+				zap.Int("maxHedges", maxHedges),
+			)
+			return fmt.Errorf("invalid hedging settings: delay %v, max hedges %d", delay, maxHedges)
+		}
+		option.hedgeDelay = delay
+		option.maxHedges = maxHedges
+		return nil
+	})
+}
+
+type hedgeResult struct {
+	httpStatusCode  int
+	responseHeaders http.Header
+	responseBody    []byte
+	err             error
+}
+
+// failed reports whether another hedge may still do better than the result
+func (r hedgeResult) failed() bool {
+	return r.err != nil || r.httpStatusCode == http.StatusTooManyRequests || r.httpStatusCode >= http.StatusInternalServerError
+}
+
+// doHedgedRequest performs a single hedged attempt: the request is sent once
+// and again every hedgeDelay without a response, up to maxHedges times. A
+// failed request fires the next hedge right away instead of waiting.
+func doHedgedRequest(ctx context.Context, method string, requestUrl string, option *requestOption) (int, http.Header, []byte, error) {
+	// all hedges must carry the same correlation id
+	if option.correlationId == "" {
+		if _, err := util.CorrelationIdFromCtx(ctx); err != nil {
+			ctx = util.CorrelationIdToCtx(ctx, uuid.New().String())
+		}
+	}
+
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, option.maxHedges+1)
+	sent, inFlight := 0, 0
+	send := func() {
+		sent++
+		inFlight++
+		go func() {
+			httpStatusCode, responseHeaders, responseBody, err := doRequest(hedgeCtx, method, requestUrl, option)
+			results <- hedgeResult{httpStatusCode: httpStatusCode, responseHeaders: responseHeaders, responseBody: responseBody, err: err}
+		}()
+	}
+
+	timer := time.NewTimer(option.hedgeDelay)
+	defer timer.Stop()
+
+	send()
+	var last hedgeResult
+	for {
+		select {
+		case <-timer.C:
+			if sent > option.maxHedges {
+				continue
+			}
+			option.lg.Info("[HTTP-REQUEST-HEDGE]",
+				zap.Int("hedge", sent),
+				zap.Int("maxHedges", option.maxHedges),
+				zap.Duration("delay", option.hedgeDelay),
+				zap.String("method", method),
+				zap.String("url", requestUrl),
+			)
+			send()
+			timer.Reset(option.hedgeDelay)
+		case result := <-results:
+			inFlight--
+			if !result.failed() {
+				return result.httpStatusCode, result.responseHeaders, result.responseBody, nil
+			}
+			last = result
+			if inFlight > 0 {
+				continue
+			}
+			if sent > option.maxHedges || ctx.Err() != nil {
+				return last.httpStatusCode, last.responseHeaders, last.responseBody, last.err
+			}
+			send()
+			timer.Reset(option.hedgeDelay)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestWithHedging(t *testing.T) {
+	var calls atomic.Int32
+	cancelled := make(chan struct{})
+	var correlationIds sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationIds.Store(r.Header.Get("X-Correlation-ID"), true)
+		if calls.Add(1) == 1 {
+			// the first request stalls until the hedge wins and cancels it
+			<-r.Context().Done()
+			close(cancelled)
+			return
+		}
+		w.Write([]byte("hedge"))
+	}))
+	defer server.Close()
+
+	statusCode, responseBody, err := Get(context.Background(), server.URL,
+		WithHedging(50*time.Millisecond, 2),
+		WithRequestTimeout(5*time.Second),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "hedge", string(responseBody))
+	assert.Equal(t, int32(2), calls.Load())
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("slow request was not cancelled")
+	}
+
+	ids := 0
+	correlationIds.Range(func(any, any) bool { ids++; return true })
+	assert.Equal(t, 1, ids, "hedges should share the correlation id")
+}
+
+func TestRequestWithHedgingFastResponse(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, _, err := Get(context.Background(), server.URL, WithHedging(time.Second, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRequestWithHedgingServerError(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// the primary fails fast, the hedge succeeds
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("hedge"))
+	}))
+	defer server.Close()
+
+	statusCode, responseBody, err := Get(context.Background(), server.URL, WithHedging(time.Second, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "hedge", string(responseBody))
+	assert.Equal(t, int32(2), calls.Load())
+
+}
+
+func TestRequestWithHedgingAllServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	statusCode, _, err := Get(context.Background(), server.URL, WithHedging(time.Second, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+	assert.Equal(t, int32(3), calls.Load(), "failed responses should fire the next hedge right away")
+}
+
+func TestRequestWithHedgingAllFail(t *testing.T) {
+	transport := &failingTransport{}
+	_, _, err := Get(context.Background(), "http://example.invalid",
+		WithHedging(time.Second, 2),
+		WithTransport(transport),
+	)
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), transport.calls.Load(), "failed requests should fire the next hedge right away")
+}
+
+func TestWithHedgingInvalid(t *testing.T) {
+	_, _, err := Get(context.Background(), "http://example.invalid", WithHedging(0, 1))
+	assert.Error(t, err)
+	_, _, err = Get(context.Background(), "http://example.invalid", WithHedging(time.Second, -1))
+	assert.Error(t, err)
+}
+
+type failingTransport struct {
+	calls atomic.Int32
+}
+
+func (t *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls.Add(1)
+	return nil, assert.AnError
+}
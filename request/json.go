@@ -0,0 +1,163 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// maxErrorBodySnippet caps how much of the response body is kept in HTTPError
+const maxErrorBodySnippet = 512
+
+// HTTPError is returned by the JSON helpers when the response status code is
+// not accepted
+type HTTPError struct {
+	Method     string `json:"method,omitempty"`
+	URL        string `json:"url,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Body       []byte `json:"body,omitempty"`
+}
+
+func newHTTPError(method, requestUrl string, statusCode int, responseBody []byte) *HTTPError {
+	body := responseBody
+	if len(body) > maxErrorBodySnippet {
+		body = body[:maxErrorBodySnippet]
+	}
+	return &HTTPError{
+		Method:     method,
+		URL:        requestUrl,
+		StatusCode: statusCode,
+		Body:       body,
+	}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected status code %d from %s %s: %s", e.StatusCode, e.Method, e.URL, e.Body)
+}
+
+func (e *HTTPError) GetCode() int64 {
+	return ErrCodeUnexpectedStatusCode
+}
+
+func (e *HTTPError) GetStatusCode() int {
+	return e.StatusCode
+}
+
+// WithAcceptedStatusCodes sets the status codes the JSON helpers treat as
+// success. Defaults to any 2xx status code. It has no effect on Request, which
+// never fails on the status code.
+func WithAcceptedStatusCodes(statusCodes ...int) Option {
+	return optionFunc(func(option *requestOption) error {
+		option.acceptedStatusCodes = append(option.acceptedStatusCodes, statusCodes...)
+		return nil
+	})
+}
+
+func isAcceptedStatusCode(option *requestOption, statusCode int) bool {
+	if len(option.acceptedStatusCodes) == 0 {
+		return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+	}
+	return slices.Contains(option.acceptedStatusCodes, statusCode)
+}
+
+// RequestJSON sends the request and decodes the JSON response into T.
+// A non accepted status code returns an *HTTPError, a body that cannot be
+// decoded returns a *RequestError. An empty body decodes to the zero value.
+func RequestJSON[T any](ctx context.Context, method string, requestUrl string, options ...Option) (T, error) {
+	var result T
+
+	option, err := newRequestOption(options)
+	if err != nil {
+		return result, err
+	}
+
+	httpStatusCode, responseBody, err := requestWithOption(ctx, method, requestUrl, option)
+	if err != nil {
+		return result, err
+	}
+
+	if !isAcceptedStatusCode(option, httpStatusCode) {
+		return result, newHTTPError(method, requestUrl, httpStatusCode, responseBody)
+	}
+
+	if len(responseBody) == 0 {
+		return result, nil
+	}
+
+	if err := json.Unmarshal(responseBody, &result); err != nil {
+		return result, newDecodeError(method, requestUrl, httpStatusCode, responseBody, err)
+	}
+	return result, nil
+}
+
+func newDecodeError(method, requestUrl string, statusCode int, responseBody []byte, err error) *RequestError {
+	return NewRequestError(
+		ErrCodeFailedToDecodeResponse,
+		"failed to decode response body",
+		err,
+		nil,
+		withMethod(method),
+		withURL(requestUrl),
+		withStatusCode(statusCode),
+		withResponseBody(responseBody),
+	)
+}
+
+// GetJSON sends a GET request and decodes the JSON response into T
+func GetJSON[T any](ctx context.Context, requestUrl string, options ...Option) (T, error) {
+	return RequestJSON[T](ctx, http.MethodGet, requestUrl, options...)
+}
+
+// PostJSONAs sends v as a JSON body and decodes the JSON response into T
+func PostJSONAs[T any](ctx context.Context, requestUrl string, v any, options ...Option) (T, error) {
+	defaultHeader := map[string]string{"Content-Type": "application/json"}
+	options = append(options, WithRequestHeaders(defaultHeader), WithRequestBodyFromJson(v))
+	return RequestJSON[T](ctx, http.MethodPost, requestUrl, options...)
+}
+
+// ProviderError is returned by DoAndBind when the response status code is not
+// accepted. Envelope is the errTarget passed to DoAndBind, holding the error
+// body of the provider, or nil when the body is empty or not valid JSON.
+type ProviderError struct {
+	*HTTPError
+	Envelope any
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.HTTPError
+}
+
+// DoAndBind sends the request and decodes the JSON response body into
+// okTarget when the status code is accepted (any 2xx by default, see
+// WithAcceptedStatusCodes), or into errTarget otherwise, in which case it
+// returns a *ProviderError. Either target may be nil to skip decoding. An
+// accepted body that cannot be decoded returns a *RequestError.
+func DoAndBind(ctx context.Context, method string, requestUrl string, okTarget any, errTarget any, options ...Option) (httpStatusCode int, err error) {
+	option, err := newRequestOption(options)
+	if err != nil {
+		return 0, err
+	}
+
+	httpStatusCode, responseBody, err := requestWithOption(ctx, method, requestUrl, option)
+	if err != nil {
+		return httpStatusCode, err
+	}
+
+	if !isAcceptedStatusCode(option, httpStatusCode) {
+		providerErr := &ProviderError{HTTPError: newHTTPError(method, requestUrl, httpStatusCode, responseBody)}
+		if errTarget != nil && len(responseBody) > 0 && json.Unmarshal(responseBody, errTarget) == nil {
+			providerErr.Envelope = errTarget
+		}
+		return httpStatusCode, providerErr
+	}
+
+	if okTarget == nil || len(responseBody) == 0 {
+		return httpStatusCode, nil
+	}
+	if err := json.Unmarshal(responseBody, okTarget); err != nil {
+		return httpStatusCode, newDecodeError(method, requestUrl, httpStatusCode, responseBody, err)
+	}
+	return httpStatusCode, nil
+}
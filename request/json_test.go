@@ -0,0 +1,145 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestGetJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(testUser{ID: 1, Name: "John"})
+	}))
+	defer server.Close()
+
+	user, err := GetJSON[testUser](context.Background(), server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, testUser{ID: 1, Name: "John"}, user)
+}
+
+func TestPostJSONAs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		var user testUser
+		_ = json.Unmarshal(body, &user)
+		user.ID = 42
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(user)
+	}))
+	defer server.Close()
+
+	user, err := PostJSONAs[testUser](context.Background(), server.URL, testUser{Name: "Jane"})
+	require.NoError(t, err)
+	assert.Equal(t, testUser{ID: 42, Name: "Jane"}, user)
+}
+
+func TestGetJSONHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(strings.Repeat("x", 1024)))
+	}))
+	defer server.Close()
+
+	_, err := GetJSON[testUser](context.Background(), server.URL)
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusNotFound, httpErr.GetStatusCode())
+	assert.Len(t, httpErr.Body, maxErrorBodySnippet)
+
+	_, err = GetJSON[testUser](context.Background(), server.URL, WithAcceptedStatusCodes(http.StatusNotFound))
+	var requestErr *RequestError
+	require.True(t, errors.As(err, &requestErr))
+	assert.Equal(t, int64(ErrCodeFailedToDecodeResponse), requestErr.GetCode())
+}
+
+func TestGetJSONEmptyBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	user, err := GetJSON[*testUser](context.Background(), server.URL)
+	require.NoError(t, err)
+	assert.Nil(t, user)
+}
+
+type testProviderError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func TestDoAndBind(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			_ = json.NewEncoder(w).Encode(testUser{ID: 1, Name: "John"})
+		case "/error":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_ = json.NewEncoder(w).Encode(testProviderError{Code: "INSUFFICIENT_FUNDS", Message: "balance too low"})
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("<html>bad gateway</html>"))
+		}
+	}))
+	defer server.Close()
+
+	var user testUser
+	var providerErr testProviderError
+	statusCode, err := DoAndBind(context.Background(), http.MethodGet, server.URL+"/ok", &user, &providerErr)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, testUser{ID: 1, Name: "John"}, user)
+
+	statusCode, err = DoAndBind(context.Background(), http.MethodGet, server.URL+"/error", &user, &providerErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, statusCode)
+	var pe *ProviderError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, http.StatusUnprocessableEntity, pe.GetStatusCode())
+	assert.Equal(t, &providerErr, pe.Envelope)
+	assert.Equal(t, "INSUFFICIENT_FUNDS", providerErr.Code)
+	var httpErr *HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+
+	// a body that is not an envelope still returns a ProviderError
+	_, err = DoAndBind(context.Background(), http.MethodGet, server.URL+"/gateway", &user, &testProviderError{})
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, http.StatusBadGateway, pe.StatusCode)
+	assert.Nil(t, pe.Envelope)
+	assert.Equal(t, "<html>bad gateway</html>", string(pe.Body))
+}
+
+func TestJSONHelpersApplyOptionsOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(testUser{ID: 1, Name: "John"})
+	}))
+	defer server.Close()
+
+	var applied int
+	countingOption := optionFunc(func(option *requestOption) error {
+		applied++
+		return nil
+	})
+
+	_, err := GetJSON[testUser](context.Background(), server.URL, countingOption)
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied)
+
+	applied = 0
+	_, err = DoAndBind(context.Background(), http.MethodGet, server.URL, &testUser{}, nil, countingOption)
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied)
+}
@@ -0,0 +1,68 @@
+package request
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// Doer sends an HTTP request, *http.Client is a Doer
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function to a Doer
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the sending of every attempt, for cross-cutting concerns
+// such as auth injection, tracing headers, error mapping or response caching.
+// It sees the request once headers, correlation id and authorization are set,
+// and the response before its body is read, still encoded as received. It may
+// answer without calling next.
+type Middleware func(next Doer) Doer
+
+var (
+	defaultMiddlewares   []Middleware
+	defaultMiddlewaresMu sync.RWMutex
+)
+
+// SetDefaultMiddleware replaces the middlewares applied to every request,
+// around those set with WithMiddleware. Calling it without middlewares
+// removes them.
+func SetDefaultMiddleware(middlewares ...Middleware) {
+	defaultMiddlewaresMu.Lock()
+	defer defaultMiddlewaresMu.Unlock()
+	defaultMiddlewares = slices.Clone(middlewares)
+}
+
+// WithMiddleware adds middlewares to the request. The first middleware is the
+// outermost one, it sees the request first and the response last.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return optionFunc(func(option *requestOption) error {
+		option.middlewares = append(option.middlewares, middlewares...)
+		return nil
+	})
+}
+
+// resolveDoer wraps the client of the request with the default middlewares,
+// then the ones of the request and its response cache, innermost so cached
+// responses are keyed by the request as sent
+func resolveDoer(option *requestOption) Doer {
+	var doer Doer = resolveHttpClient(option)
+	if option.responseCache != nil {
+		doer = option.responseCache.middleware(option.lg)(doer)
+	}
+
+	defaultMiddlewaresMu.RLock()
+	middlewares := append(slices.Clone(defaultMiddlewares), option.middlewares...)
+	defaultMiddlewaresMu.RUnlock()
+
+	for _, middleware := range slices.Backward(middlewares) {
+		doer = middleware(doer)
+	}
+	return doer
+}
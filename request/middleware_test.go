@@ -0,0 +1,74 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func headerMiddleware(key, value string, calls *[]string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			*calls = append(*calls, value)
+			req.Header.Add(key, value)
+			return next.Do(req)
+		})
+	}
+}
+
+func TestRequestWithMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Join(r.Header.Values("X-Chain"), ",")))
+	}))
+	defer server.Close()
+
+	var calls []string
+	SetDefaultMiddleware(headerMiddleware("X-Chain", "default", &calls))
+	defer SetDefaultMiddleware()
+
+	statusCode, responseBody, err := Get(context.Background(), server.URL,
+		WithMiddleware(headerMiddleware("X-Chain", "first", &calls)),
+		WithMiddleware(headerMiddleware("X-Chain", "second", &calls)),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "default,first,second", string(responseBody))
+	assert.Equal(t, []string{"default", "first", "second"}, calls)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("server should not be called")
+	}))
+	defer server.Close()
+
+	cached := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("cached")),
+				Request:    req,
+			}, nil
+		})
+	}
+	statusCode, responseBody, err := Get(context.Background(), server.URL, WithMiddleware(cached))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "cached", string(responseBody))
+
+	errUnavailable := errors.New("service unavailable")
+	failing := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errUnavailable
+		})
+	}
+	_, _, err = Get(context.Background(), server.URL, WithMiddleware(failing))
+	assert.ErrorIs(t, err, errUnavailable)
+}
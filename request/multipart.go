@@ -0,0 +1,118 @@
+package request
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// FileField is a file part of a multipart/form-data body. Content is streamed
+// into the request, so large files are never buffered in memory.
+type FileField struct {
+	FieldName   string
+	FileName    string
+	ContentType string // defaults to application/octet-stream
+	Content     io.Reader
+}
+
+type multipartBody struct {
+	fields   map[string]string
+	files    []FileField
+	boundary string
+}
+
+func newMultipartBody(fields map[string]string, files []FileField) *multipartBody {
+	return &multipartBody{
+		fields:   fields,
+		files:    files,
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+}
+
+func (b *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// reader streams the encoded body through a pipe. The returned reader must be
+// closed to release the writing goroutine if the body is not fully consumed.
+func (b *multipartBody) reader() io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(b.write(pw))
+	}()
+	return pr
+}
+
+func (b *multipartBody) write(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(b.fields))
+	for k := range b.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := mw.WriteField(k, b.fields[k]); err != nil {
+			return fmt.Errorf("failed to write field %s: %w", k, err)
+		}
+	}
+
+	for _, file := range b.files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(file.FieldName), escapeQuotes(file.FileName)))
+		header.Set("Content-Type", contentType)
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return fmt.Errorf("failed to create part for file %s: %w", file.FileName, err)
+		}
+		if file.Content != nil {
+			if _, err := io.Copy(part, file.Content); err != nil {
+				return fmt.Errorf("failed to write file %s: %w", file.FileName, err)
+			}
+		}
+	}
+
+	return mw.Close()
+}
+
+// describe summarizes the body for logs and the request recorder, since the
+// file contents are streamed and not kept.
+func (b *multipartBody) describe() string {
+	var sb strings.Builder
+	sb.WriteString("multipart/form-data")
+	if len(b.fields) > 0 {
+		keys := make([]string, 0, len(b.fields))
+		for k := range b.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		sb.WriteString(" fields=")
+		for i, k := range keys {
+			if i > 0 {
+				sb.WriteString("&")
+			}
+			sb.WriteString(k + "=" + b.fields[k])
+		}
+	}
+	for _, file := range b.files {
+		sb.WriteString(fmt.Sprintf(" file=%s(%s)", file.FieldName, file.FileName))
+	}
+	return sb.String()
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,57 @@
+package request
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostMultipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "kyc", r.FormValue("type"))
+		assert.Equal(t, "42", r.FormValue("user_id"))
+		assert.NotEmpty(t, r.Header.Get("X-SIGNATURE"))
+
+		file, header, err := r.FormFile("document")
+		require.NoError(t, err)
+		defer file.Close()
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, "passport.pdf", header.Filename)
+		assert.Equal(t, "application/pdf", header.Header.Get("Content-Type"))
+		assert.Equal(t, "%PDF-1.4", string(content))
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	var recorded *RequestRecordData
+	statusCode, _, err := PostMultipart(context.Background(), server.URL,
+		map[string]string{"type": "kyc", "user_id": "42"},
+		[]FileField{{
+			FieldName:   "document",
+			FileName:    "passport.pdf",
+			ContentType: "application/pdf",
+			Content:     strings.NewReader("%PDF-1.4"),
+		}},
+		WithRequestSigner(HmacSha256Signer, HmacSha256SignerKeys{
+			ApiKeyHeader:    "X-API-KEY",
+			SignatureHeader: "X-SIGNATURE",
+			ApiKey:          "key",
+			ApiKeySecret:    "secret",
+		}),
+		WithRequestRecorder(func(data *RequestRecordData) { recorded = data }),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, statusCode)
+
+	require.NotNil(t, recorded)
+	assert.Equal(t, "multipart/form-data fields=type=kyc&user_id=42 file=document(passport.pdf)", recorded.RequestBody)
+}
@@ -0,0 +1,192 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// defaultTokenRefreshMargin is how long before expiry a cached token is
+// refreshed, so it does not expire while a request is in flight
+const defaultTokenRefreshMargin = time.Minute
+
+// Token is a bearer token returned by a TokenSource
+type Token struct {
+	AccessToken string
+	TokenType   string    // "Bearer" when empty
+	Expiry      time.Time // Zero if the token does not expire
+}
+
+// TokenSource supplies the token sent in the Authorization header of every
+// attempt. Implementations must be safe for concurrent use and should cache
+// tokens, Token is called once per attempt.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// clientCredentialsConfig identifies a shared client credentials token
+// source. It is used as a map key, so it must stay comparable.
+type clientCredentialsConfig struct {
+	tokenURL      string
+	clientID      string
+	clientSecret  string
+	scopes        string
+	refreshMargin time.Duration
+}
+
+// client credentials token sources built from WithOAuth2ClientCredentials,
+// shared by every request using the same settings so tokens are cached per
+// issuer and client
+var clientCredentialsTokenSources sync.Map
+
+// WithOAuth2ClientCredentials sends a bearer token obtained from tokenURL with
+// the OAuth2 client credentials grant. Tokens are cached across requests with
+// the same settings and refreshed a minute before they expire, see
+// WithTokenRefreshMargin.
+func WithOAuth2ClientCredentials(tokenURL, clientID, clientSecret string, scopes ...string) Option {
+	return optionFunc(func(option *requestOption) error {
+		if tokenURL == "" || clientID == "" {
+			option.lg.Error("[HTTP-REQUEST-ERROR: invalid oauth2 client credentials]",
+				zap.String("tokenURL", tokenURL),
+				zap.String("clientID", clientID),
+			)
+			return fmt.Errorf("oauth2 token url and client id are required")
+		}
+		refreshMargin := defaultTokenRefreshMargin
+		if option.clientCredentials != nil {
+			refreshMargin = option.clientCredentials.refreshMargin
+		}
+		option.clientCredentials = &clientCredentialsConfig{
+			tokenURL:      tokenURL,
+			clientID:      clientID,
+			clientSecret:  clientSecret,
+			scopes:        strings.Join(scopes, " "),
+			refreshMargin: refreshMargin,
+		}
+		return nil
+	})
+}
+
+// WithTokenRefreshMargin sets how long before expiry WithOAuth2ClientCredentials
+// refreshes its token
+func WithTokenRefreshMargin(margin time.Duration) Option {
+	return optionFunc(func(option *requestOption) error {
+		if margin < 0 {
+			option.lg.Error("[HTTP-REQUEST-ERROR: invalid token refresh margin]",
+				zap.Duration("margin", margin),
+			)
+			return fmt.Errorf("invalid token refresh margin: %v", margin)
+		}
+		if option.clientCredentials == nil {
+			option.clientCredentials = &clientCredentialsConfig{}
+		}
+		option.clientCredentials.refreshMargin = margin
+		return nil
+	})
+}
+
+// WithTokenSource sends a bearer token from tokenSource, taking precedence
+// over WithOAuth2ClientCredentials
+func WithTokenSource(tokenSource TokenSource) Option {
+	return optionFunc(func(option *requestOption) error {
+		if tokenSource == nil {
+			option.lg.Error("[HTTP-REQUEST-ERROR: nil token source]")
+			return fmt.Errorf("token source is required")
+		}
+		option.tokenSource = tokenSource
+		return nil
+	})
+}
+
+// NewClientCredentialsTokenSource returns a TokenSource using the OAuth2
+// client credentials grant, caching its token until refreshMargin before it
+// expires. The client authentication style is detected from the first
+// response of the token endpoint.
+func NewClientCredentialsTokenSource(tokenURL, clientID, clientSecret string, scopes []string, refreshMargin time.Duration) TokenSource {
+	return &cachedTokenSource{
+		fetch: (&clientcredentials.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			TokenURL:     tokenURL,
+			Scopes:       scopes,
+		}).Token,
+		refreshMargin: refreshMargin,
+		now:           time.Now,
+	}
+}
+
+// cachedTokenSource caches the token of fetch, fetching a new one at most
+// once at a time
+type cachedTokenSource struct {
+	fetch         func(ctx context.Context) (*oauth2.Token, error)
+	refreshMargin time.Duration
+	now           func() time.Time
+
+	mu    sync.Mutex
+	token *Token
+}
+
+func (s *cachedTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && (s.token.Expiry.IsZero() || s.now().Add(s.refreshMargin).Before(s.token.Expiry)) {
+		return s.token, nil
+	}
+
+	token, err := s.fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch oauth2 token: %w", err)
+	}
+	s.token = &Token{
+		AccessToken: token.AccessToken,
+		TokenType:   token.Type(),
+		Expiry:      token.Expiry,
+	}
+	return s.token, nil
+}
+
+// resolveTokenSource returns the token source of the request, or nil
+func resolveTokenSource(option *requestOption) TokenSource {
+	if option.tokenSource != nil {
+		return option.tokenSource
+	}
+	config := option.clientCredentials
+	if config == nil || config.tokenURL == "" {
+		return nil
+	}
+	if tokenSource, ok := clientCredentialsTokenSources.Load(*config); ok {
+		return tokenSource.(TokenSource)
+	}
+	var scopes []string
+	if config.scopes != "" {
+		scopes = strings.Split(config.scopes, " ")
+	}
+	tokenSource, _ := clientCredentialsTokenSources.LoadOrStore(*config,
+		NewClientCredentialsTokenSource(config.tokenURL, config.clientID, config.clientSecret, scopes, config.refreshMargin))
+	return tokenSource.(TokenSource)
+}
+
+// authorizationHeader returns the Authorization header of the request, or ""
+// without a token source
+func authorizationHeader(ctx context.Context, option *requestOption) (string, error) {
+	tokenSource := resolveTokenSource(option)
+	if tokenSource == nil {
+		return "", nil
+	}
+	token, err := tokenSource.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	tokenType := token.TokenType
+	if tokenType == "" {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + token.AccessToken, nil
+}
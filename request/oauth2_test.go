@@ -0,0 +1,110 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTokenServer(t *testing.T, expiresIn int, fetches *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "payments:read payments:write", r.PostForm.Get("scope"))
+		clientID, clientSecret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "client-id", clientID)
+		assert.Equal(t, "client-secret", clientSecret)
+
+		n := fetches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+}
+
+func TestWithOAuth2ClientCredentials(t *testing.T) {
+	var fetches atomic.Int32
+	tokenServer := newTokenServer(t, 3600, &fetches)
+	defer tokenServer.Close()
+
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		_, _, err := Get(context.Background(), server.URL,
+			WithOAuth2ClientCredentials(tokenServer.URL, "client-id", "client-secret", "payments:read", "payments:write"),
+		)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1"}, authorizations)
+	assert.Equal(t, int32(1), fetches.Load())
+}
+
+func TestWithTokenRefreshMargin(t *testing.T) {
+	var fetches atomic.Int32
+	// expires within the default one minute margin, so it is never reused
+	tokenServer := newTokenServer(t, 30, &fetches)
+	defer tokenServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	get := func(options ...Option) {
+		options = append(options, WithOAuth2ClientCredentials(tokenServer.URL, "client-id", "client-secret", "payments:read", "payments:write"))
+		_, _, err := Get(context.Background(), server.URL, options...)
+		require.NoError(t, err)
+	}
+
+	get()
+	get()
+	assert.Equal(t, int32(2), fetches.Load())
+
+	get(WithTokenRefreshMargin(10 * time.Second))
+	get(WithTokenRefreshMargin(10 * time.Second))
+	assert.Equal(t, int32(3), fetches.Load())
+}
+
+type staticTokenSource struct {
+	token *Token
+	err   error
+}
+
+func (s staticTokenSource) Token(context.Context) (*Token, error) {
+	return s.token, s.err
+}
+
+func TestWithTokenSource(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	_, _, err := Get(context.Background(), server.URL,
+		WithTokenSource(staticTokenSource{token: &Token{AccessToken: "static"}}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer static", authorization)
+
+	errTokenSource := errors.New("token endpoint down")
+	_, _, err = Get(context.Background(), server.URL,
+		WithTokenSource(staticTokenSource{err: errTokenSource}),
+	)
+	assert.ErrorIs(t, err, errTokenSource)
+}
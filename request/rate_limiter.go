@@ -0,0 +1,209 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter throttles requests sharing a key. Implementations must be safe
+// for concurrent use.
+type RateLimiter interface {
+	// Wait blocks until a request for key may be sent or ctx is done
+	Wait(ctx context.Context, key string) error
+}
+
+// WithRateLimit makes every attempt of the request (retries and hedges
+// included) wait for limiter before it is sent. Requests using the same
+// limiter and key share the limit; an empty key uses the host of the request
+// url, so a single limiter can throttle each provider separately.
+func WithRateLimit(key string, limiter RateLimiter) Option {
+	return optionFunc(func(option *requestOption) error {
+		if limiter == nil {
+			option.lg.Error("[HTTP-REQUEST-ERROR: nil rate limiter]", zap.String("key", key))
+			return fmt.Errorf("rate limiter is required")
+		}
+		option.rateLimitKey = key
+		option.rateLimiter = limiter
+		return nil
+	})
+}
+
+// waitRateLimit blocks until the configured rate limiter allows the request
+func waitRateLimit(ctx context.Context, requestUrl string, option *requestOption) error {
+	if option.rateLimiter == nil {
+		return nil
+	}
+
+	key := option.rateLimitKey
+	if key == "" {
+		parsedUrl, err := url.Parse(requestUrl)
+		if err != nil {
+			return fmt.Errorf("failed to parse url for rate limit key: %w", err)
+		}
+		key = parsedUrl.Host
+	}
+
+	waitStart := time.Now()
+	if err := option.rateLimiter.Wait(ctx, key); err != nil {
+		option.lg.Error("[HTTP-REQUEST-ERROR: rate limit wait failed]",
+			zap.Error(err),
+			zap.String("key", key),
+			zap.String("url", requestUrl),
+		)
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+	if option.debugEnabled {
+		option.lg.Debug("[HTTP-REQUEST-RATE-LIMIT]",
+			zap.String("key", key),
+			zap.String("url", requestUrl),
+			zap.Duration("wait", time.Since(waitStart)),
+		)
+	}
+	return nil
+}
+
+// tokenBucketSweepInterval is how often the token bucket limiter drops the
+// limiters of idle keys
+const tokenBucketSweepInterval = time.Minute
+
+type tokenBucketLimiter struct {
+	limit     rate.Limit
+	burst     int
+	now       func() time.Time
+	mu        sync.Mutex
+	limiters  map[string]*rate.Limiter
+	lastSweep time.Time
+}
+
+// NewTokenBucketLimiter returns an in-process RateLimiter allowing
+// requestsPerSecond requests per key on average, with bursts of up to burst
+// requests. Limits are shared by all goroutines of the process. The limiters
+// of keys idle long enough for their bucket to refill are dropped, so keys
+// may be unbounded, e.g. built from paths with ids.
+func NewTokenBucketLimiter(requestsPerSecond float64, burst int) (RateLimiter, error) {
+	if requestsPerSecond <= 0 || burst <= 0 {
+		return nil, fmt.Errorf("invalid token bucket settings: %v requests per second, burst %d", requestsPerSecond, burst)
+	}
+	return &tokenBucketLimiter{
+		limit:     rate.Limit(requestsPerSecond),
+		burst:     burst,
+		now:       time.Now,
+		limiters:  make(map[string]*rate.Limiter),
+		lastSweep: time.Now(),
+	}, nil
+}
+
+func (l *tokenBucketLimiter) Wait(ctx context.Context, key string) error {
+	l.mu.Lock()
+	if now := l.now(); now.Sub(l.lastSweep) >= tokenBucketSweepInterval {
+		l.sweep(now)
+	}
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[key] = limiter
+	}
+	l.mu.Unlock()
+
+	return limiter.Wait(ctx)
+}
+
+// sweep drops the limiters with a full bucket, which behave like new ones
+func (l *tokenBucketLimiter) sweep(now time.Time) {
+	for key, limiter := range l.limiters {
+		if limiter.TokensAt(now) >= float64(l.burst) {
+			delete(l.limiters, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// slidingWindowScript records a request in the window of KEYS[1] if it has
+// room and returns 0, otherwise it returns the milliseconds until the oldest
+// request leaves the window.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+if redis.call('ZCARD', key) < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return 0
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local wait = tonumber(oldest[2]) + window - now
+if wait < 1 then
+	wait = 1
+end
+return wait
+`)
+
+type redisSlidingWindowLimiter struct {
+	client redis.Cmdable
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+// NewRedisSlidingWindowLimiter returns a RateLimiter allowing at most limit
+// requests per key in any window, tracked in Redis so the limit is shared by
+// every instance using the same keyPrefix.
+//
+// The redis.Cmdable parameter accepts both *redis.Client and
+// redis.UniversalClient. Instances should have reasonably synchronized
+// clocks, as request times are taken from the local clock.
+func NewRedisSlidingWindowLimiter(client redis.Cmdable, keyPrefix string, limit int, window time.Duration) (RateLimiter, error) {
+	if client == nil {
+		return nil, fmt.Errorf("redis client is required")
+	}
+	if limit <= 0 || window < time.Millisecond {
+		return nil, fmt.Errorf("invalid sliding window settings: limit %d, window %v", limit, window)
+	}
+	return &redisSlidingWindowLimiter{
+		client: client,
+		prefix: strings.TrimRight(keyPrefix, ":"),
+		limit:  limit,
+		window: window,
+	}, nil
+}
+
+func (l *redisSlidingWindowLimiter) Wait(ctx context.Context, key string) error {
+	redisKey := l.prefix + ":" + key
+	member := uuid.New().String()
+
+	for {
+		wait, err := slidingWindowScript.Run(ctx, l.client, []string{redisKey},
+			time.Now().UnixMilli(),
+			l.window.Milliseconds(),
+			l.limit,
+			member,
+		).Int64()
+		if err != nil {
+			return fmt.Errorf("failed to check rate limit: %w", err)
+		}
+		if wait == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(time.Duration(wait) * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
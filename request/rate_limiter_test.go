@@ -0,0 +1,126 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingLimiter struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (l *recordingLimiter) Wait(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.keys = append(l.keys, key)
+	return nil
+}
+
+func TestRequestWithRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	limiter := &recordingLimiter{}
+	_, _, err := Get(context.Background(), server.URL, WithRateLimit("", limiter))
+	assert.NoError(t, err)
+	_, _, err = Get(context.Background(), server.URL, WithRateLimit("provider", limiter))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{server.Listener.Addr().String(), "provider"}, limiter.keys)
+
+	_, _, err = Get(context.Background(), server.URL, WithRateLimit("provider", nil))
+	assert.Error(t, err)
+}
+
+func TestTokenBucketLimiter(t *testing.T) {
+	limiter, err := NewTokenBucketLimiter(20, 1)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	start := time.Now()
+	assert.NoError(t, limiter.Wait(ctx, "a"))
+	assert.NoError(t, limiter.Wait(ctx, "b"), "keys should have separate buckets")
+	assert.Less(t, time.Since(start), 25*time.Millisecond)
+
+	assert.NoError(t, limiter.Wait(ctx, "a"))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Error(t, limiter.Wait(cancelled, "a"))
+
+	_, err = NewTokenBucketLimiter(0, 1)
+	assert.Error(t, err)
+}
+
+func TestTokenBucketLimiterEvictsIdleKeys(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	limiter, err := NewTokenBucketLimiter(1, 1)
+	require.NoError(t, err)
+	bucket := limiter.(*tokenBucketLimiter)
+	bucket.now = func() time.Time { return now }
+	bucket.lastSweep = now
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, limiter.Wait(ctx, fmt.Sprintf("/users/%d", i)))
+	}
+	assert.Equal(t, 1000, len(bucket.limiters))
+
+	// Buckets refilled since their last use are dropped on the next sweep
+	now = now.Add(tokenBucketSweepInterval)
+	require.NoError(t, limiter.Wait(ctx, "/users/new"))
+	assert.Equal(t, 1, len(bucket.limiters))
+
+	// Buckets still refilling keep their limit
+	limiter, err = NewTokenBucketLimiter(0.001, 1)
+	require.NoError(t, err)
+	bucket = limiter.(*tokenBucketLimiter)
+	bucket.now = func() time.Time { return now }
+	bucket.lastSweep = now
+	require.NoError(t, limiter.Wait(ctx, "busy"))
+	now = now.Add(tokenBucketSweepInterval)
+	require.NoError(t, limiter.Wait(ctx, "other"))
+	assert.Equal(t, 2, len(bucket.limiters))
+}
+
+func TestRedisSlidingWindowLimiter(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	limiter, err := NewRedisSlidingWindowLimiter(client, "test:ratelimit:", 2, 100*time.Millisecond)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	start := time.Now()
+	assert.NoError(t, limiter.Wait(ctx, "provider"))
+	assert.NoError(t, limiter.Wait(ctx, "provider"))
+	assert.NoError(t, limiter.Wait(ctx, "other"))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	assert.True(t, mr.Exists("test:ratelimit:provider"))
+
+	assert.NoError(t, limiter.Wait(ctx, "provider"))
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, limiter.Wait(timeoutCtx, "provider"))
+	assert.ErrorIs(t, limiter.Wait(timeoutCtx, "provider"), context.DeadlineExceeded)
+
+	_, err = NewRedisSlidingWindowLimiter(client, "test", 0, time.Second)
+	assert.Error(t, err)
+}
@@ -3,41 +3,58 @@ package request
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"strings"
-	"sync"
 	"time"
 
 	"maps"
 
 	"github.com/google/uuid"
+	"github.com/infigaming-com/go-common/observability/slow"
 	"github.com/infigaming-com/go-common/util"
 	"go.uber.org/zap"
 )
 
-var (
-	httpClient *http.Client
-	once       sync.Once
-)
-
 type requestOption struct {
 	lg                   *zap.Logger
 	debugEnabled         bool
 	queryParams          *map[string]string
 	requestHeaders       *map[string]string
 	requestBody          *[]byte
+	multipartBody        *multipartBody
 	signer               RequestSigner
 	recorder             RequestRecorder
 	signerKeys           any
+	verifier             ResponseVerifier
+	verifierKeys         any
 	correlationIdKey     string
 	correlationId        string
 	requestTimeout       time.Duration
 	slowRequestThreshold time.Duration
-	maxRetries           int
+	retryPolicy          *RetryPolicy
+	attempts             int
+	hedgeDelay           time.Duration
+	maxHedges            int
+	rateLimitKey         string
+	rateLimiter          RateLimiter
+	httpClient           *http.Client
+	transport            http.RoundTripper
+	transportConfig      *transportConfig
+	tlsConfig            *tls.Config
+	cookieJar            http.CookieJar
+	acceptedStatusCodes  []int
+	gzipRequestBody      bool
+	gzipMinSize          int
+	encodedBody          *[]byte
+	responseBodyLimit    int64
+	tokenSource          TokenSource
+	clientCredentials    *clientCredentialsConfig
+	middlewares          []Middleware
+	responseCache        *responseCache
 }
 
 type Option interface {
@@ -67,7 +84,24 @@ func defaultRequestOption() *requestOption {
 		correlationId:        "",
 		requestTimeout:       3 * time.Second,
 		slowRequestThreshold: 5 * time.Second,
+		gzipMinSize:          defaultGzipMinSize,
+	}
+}
+
+// newRequestOption applies options to the default request options
+func newRequestOption(options []Option) (*requestOption, error) {
+	option := defaultRequestOption()
+	for _, opt := range options {
+		if err := opt.apply(option); err != nil {
+			return nil, err
+		}
 	}
+	// The proxy is set on the transports built by this package, a request
+	// silently going out direct would be worse than failing
+	if option.transportConfig != nil && option.transportConfig.proxyURL != "" && (option.httpClient != nil || option.transport != nil) {
+		return nil, fmt.Errorf("proxy cannot be combined with a custom http client or transport")
+	}
+	return option, nil
 }
 
 func WithLogger(lg *zap.Logger) Option {
@@ -142,6 +176,22 @@ func WithRequestBodyFromJson(requestBody any) Option {
 	})
 }
 
+// WithMultipartForm sends a multipart/form-data body built from fields and
+// files. File contents are streamed from their readers, so the request is not
+// retried and signers see an empty request body.
+func WithMultipartForm(fields map[string]string, files []FileField) Option {
+	return optionFunc(func(option *requestOption) error {
+		option.multipartBody = newMultipartBody(fields, files)
+		body := []byte{}
+		option.requestBody = &body
+		if option.requestHeaders == nil {
+			option.requestHeaders = &map[string]string{}
+		}
+		(*option.requestHeaders)["Content-Type"] = option.multipartBody.contentType()
+		return nil
+	})
+}
+
 func WithJsonAsQueryParamsAndRequestBody(requestBody any) Option {
 	return optionFunc(func(option *requestOption) error {
 		queryParams, requestBody, err := generateRequestData(requestBody)
@@ -166,6 +216,16 @@ func WithRequestSigner(requestSigner RequestSigner, signerKeys any) Option {
 	})
 }
 
+// WithResponseVerifier rejects responses whose signature does not verify,
+// see HmacSha256ResponseVerifier and JwtResponseVerifier
+func WithResponseVerifier(responseVerifier ResponseVerifier, verifierKeys any) Option {
+	return optionFunc(func(option *requestOption) error {
+		option.verifier = responseVerifier
+		option.verifierKeys = verifierKeys
+		return nil
+	})
+}
+
 func WithRequestRecorder(requestRecord RequestRecorder) Option {
 	return optionFunc(func(option *requestOption) error {
 		option.recorder = requestRecord
@@ -193,122 +253,153 @@ func WithSlowRequestThreshold(slowRequestThreshold time.Duration) Option {
 	})
 }
 
-// WithRetry enables retry with specified max attempts.
-// Default is 0 (no retry). If maxRetries > 0, the request will be retried
-// up to maxRetries times on transient errors (timeout, connection refused, etc.)
-func WithRetry(maxRetries int) Option {
-	return optionFunc(func(option *requestOption) error {
-		if maxRetries < 0 {
-			maxRetries = 0
-		}
-		option.maxRetries = maxRetries
-		return nil
-	})
+func Request(ctx context.Context, method string, requestUrl string, options ...Option) (httpStatusCode int, responseBody []byte, err error) {
+	option, err := newRequestOption(options)
+	if err != nil {
+		return 0, nil, err
+	}
+	return requestWithOption(ctx, method, requestUrl, option)
 }
 
-func getHttpClient() *http.Client {
-	once.Do(func() {
-		httpClient = &http.Client{
-			Timeout: 0,
-		}
-	})
-	return httpClient
-}
+// requestWithOption is Request with the options already applied
+func requestWithOption(ctx context.Context, method string, requestUrl string, option *requestOption) (httpStatusCode int, responseBody []byte, err error) {
+	start := time.Now()
+
+	defer func() {
+		finishRequest(option, method, requestUrl, start, httpStatusCode, responseBody, err)
+	}()
 
-// isRetryableError checks if the error is a transient error that can be retried
-func isRetryableError(err error) bool {
-	if err == nil {
-		return false
+	if err := prepareRequestBody(method, requestUrl, option); err != nil {
+		return 0, nil, err
 	}
-	errStr := err.Error()
-	return strings.Contains(errStr, "context deadline exceeded") ||
-		strings.Contains(errStr, "connection refused") ||
-		strings.Contains(errStr, "connection reset") ||
-		strings.Contains(errStr, "i/o timeout") ||
-		strings.Contains(errStr, "no such host") ||
-		strings.Contains(errStr, "network is unreachable")
-}
 
-func Request(ctx context.Context, method string, requestUrl string, options ...Option) (httpStatusCode int, responseBody []byte, err error) {
-	start := time.Now()
+	policy := option.retryPolicy
+	if policy == nil || option.multipartBody != nil {
+		// streamed file contents cannot be replayed
+		policy = &RetryPolicy{}
+	}
+	if policy.AttemptTimeout > 0 {
+		option.requestTimeout = policy.AttemptTimeout
+	}
+	hedged := option.maxHedges > 0 && option.multipartBody == nil
+
+	// attempt = 1 is the initial attempt, subsequent attempts are retries
+	for attempt := 1; ; attempt++ {
+		option.attempts = attempt
+		var responseHeaders http.Header
+		if hedged {
+			httpStatusCode, responseHeaders, responseBody, err = doHedgedRequest(ctx, method, requestUrl, option)
+		} else {
+			httpStatusCode, responseHeaders, responseBody, err = doRequest(ctx, method, requestUrl, option)
+		}
 
-	option := defaultRequestOption()
-	for _, opt := range options {
-		if err := opt.apply(option); err != nil {
-			return 0, nil, err
+		if attempt > policy.MaxRetries || ctx.Err() != nil || !policy.shouldRetry(method, option, httpStatusCode, err) {
+			return httpStatusCode, responseBody, err
+		}
+		backoff, ok := policy.retryDelay(ctx, attempt, responseHeaders)
+		if !ok {
+			return httpStatusCode, responseBody, err
 		}
-	}
 
-	defer func() {
-		if option.recorder != nil {
-			var queryParams, requestHeaders []byte
-			if option.queryParams != nil {
-				queryParams, _ = json.Marshal(*option.queryParams)
-			}
-			if option.requestHeaders != nil {
-				requestHeaders, _ = json.Marshal(*option.requestHeaders)
-			}
-			errorStr := ""
-			if err != nil {
-				errorStr = err.Error()
-			}
-			option.recorder(&RequestRecordData{
-				Method:         method,
-				Url:            requestUrl,
-				QueryParams:    string(queryParams),
-				RequestHeaders: string(requestHeaders),
-				RequestBody: func() string {
-					if option.requestBody != nil {
-						return string(*option.requestBody)
-					}
-					return ""
-				}(),
-				HttpStatusCode: httpStatusCode,
-				ResponseBody:   string(responseBody),
-				Error:          errorStr,
-				Duration:       time.Since(start).Milliseconds(),
-			})
+		option.lg.Warn("[HTTP-REQUEST-RETRY]",
+			zap.Error(err),
+			zap.Int("httpStatusCode", httpStatusCode),
+			zap.Int("attempt", attempt),
+			zap.Int("maxAttempts", policy.MaxRetries+1),
+			zap.Duration("backoff", backoff),
+			zap.String("method", method),
+			zap.String("url", requestUrl),
+		)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, nil, ctx.Err()
+		case <-timer.C:
 		}
+	}
+}
 
+// finishRequest records the request and logs its outcome
+func finishRequest(option *requestOption, method string, requestUrl string, start time.Time, httpStatusCode int, responseBody []byte, err error) {
+	if option.recorder != nil {
+		var queryParams, requestHeaders []byte
+		if option.queryParams != nil {
+			queryParams, _ = json.Marshal(*option.queryParams)
+		}
+		if option.requestHeaders != nil {
+			requestHeaders, _ = json.Marshal(*option.requestHeaders)
+		}
+		errorStr := ""
 		if err != nil {
-			option.lg.Error("[HTTP-REQUEST-ERROR]",
-				zap.Error(err),
-				zap.String("method", method),
-				zap.String("url", requestUrl),
-				zap.Any("queryParams", option.queryParams),
-				zap.Any("requestHeaders", option.requestHeaders),
-				zap.ByteString("requestBody", func() []byte {
-					if option.requestBody != nil {
-						return *option.requestBody
-					}
-					return nil
-				}()),
-				zap.Int("httpStatusCode", httpStatusCode),
-				zap.ByteString("responseBody", responseBody),
-				zap.Duration("duration", time.Since(start)),
-			)
-			return
+			errorStr = err.Error()
 		}
+		option.recorder(&RequestRecordData{
+			Method:         method,
+			Url:            requestUrl,
+			QueryParams:    string(queryParams),
+			RequestHeaders: string(requestHeaders),
+			RequestBody: func() string {
+				if option.multipartBody != nil {
+					return option.multipartBody.describe()
+				}
+				if option.requestBody != nil {
+					return string(*option.requestBody)
+				}
+				return ""
+			}(),
+			HttpStatusCode: httpStatusCode,
+			ResponseBody:   string(responseBody),
+			Error:          errorStr,
+			Duration:       time.Since(start).Milliseconds(),
+			Attempts:       option.attempts,
+		})
+	}
 
-		if option.debugEnabled {
-			option.lg.Debug("[HTTP-REQUEST-DEBUG]",
-				zap.String("method", method),
-				zap.String("url", requestUrl),
-				zap.Any("queryParams", option.queryParams),
-				zap.Any("requestHeaders", option.requestHeaders),
-				zap.ByteString("requestBody", func() []byte {
-					if option.requestBody != nil {
-						return *option.requestBody
-					}
-					return nil
-				}()),
-				zap.Int("httpStatusCode", httpStatusCode),
-				zap.ByteString("responseBody", responseBody),
-				zap.Duration("duration", time.Since(start)),
-			)
-		}
-	}()
+	if err != nil {
+		option.lg.Error("[HTTP-REQUEST-ERROR]",
+			zap.Error(err),
+			zap.String("method", method),
+			zap.String("url", requestUrl),
+			zap.Any("queryParams", option.queryParams),
+			zap.Any("requestHeaders", option.requestHeaders),
+			zap.ByteString("requestBody", func() []byte {
+				if option.requestBody != nil {
+					return *option.requestBody
+				}
+				return nil
+			}()),
+			zap.Int("httpStatusCode", httpStatusCode),
+			zap.ByteString("responseBody", responseBody),
+			zap.Int("attempts", option.attempts),
+			zap.Duration("duration", time.Since(start)),
+		)
+		return
+	}
 
+	if option.debugEnabled {
+		option.lg.Debug("[HTTP-REQUEST-DEBUG]",
+			zap.String("method", method),
+			zap.String("url", requestUrl),
+			zap.Any("queryParams", option.queryParams),
+			zap.Any("requestHeaders", option.requestHeaders),
+			zap.ByteString("requestBody", func() []byte {
+				if option.requestBody != nil {
+					return *option.requestBody
+				}
+				return nil
+			}()),
+			zap.Int("httpStatusCode", httpStatusCode),
+			zap.ByteString("responseBody", responseBody),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+}
+
+// prepareRequestBody signs the request and compresses its body, once for all
+// attempts
+func prepareRequestBody(method string, requestUrl string, option *requestOption) error {
 	// sign the request
 	if option.signer != nil {
 		if err := option.signer(&RequestSigningData{
@@ -331,66 +422,38 @@ func Request(ctx context.Context, method string, requestUrl string, options ...O
 					return nil
 				}()),
 			)
-			return 0, nil, fmt.Errorf("failed to sign request: %w", err)
+			return fmt.Errorf("failed to sign request: %w", err)
 		}
 	}
 
-	// Retry loop: attempt = 1 is the initial attempt, subsequent attempts are retries
-	maxAttempts := option.maxRetries + 1
-	var lastErr error
-
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		// Backoff before retry (not on first attempt)
-		if attempt > 1 {
-			backoff := time.Duration(attempt-1) * time.Second
-			option.lg.Info("[HTTP-REQUEST-RETRY]",
-				zap.Int("attempt", attempt),
-				zap.Int("maxAttempts", maxAttempts),
-				zap.Duration("backoff", backoff),
-				zap.String("method", method),
-				zap.String("url", requestUrl),
-			)
-
-			select {
-			case <-ctx.Done():
-				return 0, nil, ctx.Err()
-			case <-time.After(backoff):
-			}
-		}
-
-		httpStatusCode, responseBody, err = doRequest(ctx, method, requestUrl, option)
-		if err == nil {
-			return httpStatusCode, responseBody, nil
-		}
-
-		// Check if error is retryable and we have more attempts
-		if !isRetryableError(err) || attempt == maxAttempts {
-			return httpStatusCode, responseBody, err
-		}
-
-		lastErr = err
-		option.lg.Warn("[HTTP-REQUEST-RETRYABLE-ERROR]",
+	// compress after signing, signers see the uncompressed body
+	if err := compressRequestBody(option); err != nil {
+		option.lg.Error("[HTTP-REQUEST-ERROR: failed to compress request body]",
 			zap.Error(err),
-			zap.Int("attempt", attempt),
-			zap.Int("maxAttempts", maxAttempts),
 			zap.String("method", method),
 			zap.String("url", requestUrl),
 		)
+		return err
 	}
 
-	return 0, nil, fmt.Errorf("max retries exceeded: %w", lastErr)
+	return nil
 }
 
-// doRequest performs a single HTTP request attempt
-func doRequest(ctx context.Context, method string, requestUrl string, option *requestOption) (httpStatusCode int, responseBody []byte, err error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx, option.requestTimeout)
-	defer cancel()
-
+// newHTTPRequest builds the request of an attempt. closeBody releases the
+// multipart body stream and must be called once the request is done.
+func newHTTPRequest(ctx context.Context, method string, requestUrl string, option *requestOption) (req *http.Request, closeBody func(), err error) {
+	closeBody = func() {}
 	var bodyReader io.Reader
-	if option.requestBody != nil {
+	if option.multipartBody != nil {
+		multipartReader := option.multipartBody.reader()
+		closeBody = func() { multipartReader.Close() }
+		bodyReader = multipartReader
+	} else if option.encodedBody != nil {
+		bodyReader = bytes.NewReader(*option.encodedBody)
+	} else if option.requestBody != nil {
 		bodyReader = bytes.NewReader(*option.requestBody)
 	}
-	req, err := http.NewRequestWithContext(timeoutCtx, method, requestUrl, bodyReader)
+	req, err = http.NewRequestWithContext(ctx, method, requestUrl, bodyReader)
 	if err != nil {
 		option.lg.Error("[HTTP-REQUEST-ERROR: failed to create request]",
 			zap.Error(err),
@@ -403,7 +466,8 @@ func doRequest(ctx context.Context, method string, requestUrl string, option *re
 				return nil
 			}()),
 		)
-		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+		closeBody()
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	query := req.URL.Query()
@@ -431,8 +495,48 @@ func doRequest(ctx context.Context, method string, requestUrl string, option *re
 		}
 	}
 
+	// fetched for each attempt, a retry may outlive the token
+	authorization, err := authorizationHeader(ctx, option)
+	if err != nil {
+		option.lg.Error("[HTTP-REQUEST-ERROR: failed to get authorization token]",
+			zap.Error(err),
+			zap.String("method", method),
+			zap.String("url", requestUrl),
+		)
+		closeBody()
+		return nil, nil, fmt.Errorf("failed to get authorization token: %w", err)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	return req, closeBody, nil
+}
+
+// doRequest performs a single HTTP request attempt. The response headers are
+// returned for the retry loop to honor Retry-After.
+func doRequest(ctx context.Context, method string, requestUrl string, option *requestOption) (httpStatusCode int, responseHeaders http.Header, responseBody []byte, err error) {
+	// wait outside of the request timeout, time spent throttled is not latency
+	if err := waitRateLimit(ctx, requestUrl, option); err != nil {
+		return 0, nil, nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, option.requestTimeout)
+	defer cancel()
+
+	req, closeBody, err := newHTTPRequest(timeoutCtx, method, requestUrl, option)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer closeBody()
+
+	// responses are decoded below, which also takes over the transport's gzip handling
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
 	requestStart := time.Now()
-	resp, err := getHttpClient().Do(req)
+	resp, err := resolveDoer(option).Do(req)
 	if err == context.DeadlineExceeded {
 		option.lg.Error("[HTTP-REQUEST-ERROR: request timeout]",
 			zap.Error(err),
@@ -443,7 +547,7 @@ func doRequest(ctx context.Context, method string, requestUrl string, option *re
 				return nil
 			}()),
 		)
-		return 0, nil, fmt.Errorf("request timeout: %w", err)
+		return 0, nil, nil, fmt.Errorf("request timeout: %w", err)
 	}
 	if err != nil {
 		option.lg.Error("[HTTP-REQUEST-ERROR: failed to send request]",
@@ -455,14 +559,14 @@ func doRequest(ctx context.Context, method string, requestUrl string, option *re
 				return nil
 			}()),
 		)
-		return 0, nil, fmt.Errorf("failed to send request: %w", err)
+		return 0, nil, nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 	requestDuration := time.Since(requestStart)
 
 	httpStatusCode = resp.StatusCode
 
-	responseBody, err = io.ReadAll(resp.Body)
+	responseBody, err = readResponseBody(resp.Body, option.responseBodyLimit)
 	if err != nil {
 		option.lg.Error("[HTTP-REQUEST-ERROR: failed to read response body]",
 			zap.Error(err),
@@ -473,7 +577,41 @@ func doRequest(ctx context.Context, method string, requestUrl string, option *re
 				return nil
 			}()),
 		)
-		return 0, nil, fmt.Errorf("failed to read response body: %w", err)
+		return 0, nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if contentEncoding := resp.Header.Get("Content-Encoding"); contentEncoding != "" && len(responseBody) > 0 {
+		responseBody, err = decodeResponseBody(contentEncoding, responseBody, option.responseBodyLimit)
+		if err != nil {
+			option.lg.Error("[HTTP-REQUEST-ERROR: failed to decode response body]",
+				zap.Error(err),
+				zap.String("method", method),
+				zap.String("url", requestUrl),
+				zap.Int("httpStatusCode", httpStatusCode),
+				zap.String("contentEncoding", contentEncoding),
+			)
+			return httpStatusCode, resp.Header, nil, err
+		}
+	}
+
+	// verify the response
+	if option.verifier != nil {
+		if err := option.verifier(&ResponseVerificationData{
+			Method:          method,
+			Url:             requestUrl,
+			StatusCode:      httpStatusCode,
+			ResponseHeaders: resp.Header,
+			ResponseBody:    responseBody,
+		}, option.verifierKeys); err != nil {
+			option.lg.Error("[HTTP-REQUEST-ERROR: failed to verify response]",
+				zap.Error(err),
+				zap.String("method", method),
+				zap.String("url", requestUrl),
+				zap.Int("httpStatusCode", httpStatusCode),
+				zap.ByteString("responseBody", responseBody),
+			)
+			return httpStatusCode, resp.Header, responseBody, fmt.Errorf("failed to verify response: %w", err)
+		}
 	}
 
 	if requestDuration > option.slowRequestThreshold {
@@ -492,9 +630,14 @@ func doRequest(ctx context.Context, method string, requestUrl string, option *re
 			zap.ByteString("responseBody", responseBody),
 			zap.Duration("duration", requestDuration),
 		)
+		slow.FromContext(ctx).Record(ctx, slow.Operation{
+			Name:      "http " + method + " " + req.URL.Host,
+			Duration:  requestDuration,
+			Threshold: option.slowRequestThreshold,
+		})
 	}
 
-	return httpStatusCode, responseBody, nil
+	return httpStatusCode, resp.Header, responseBody, nil
 }
 
 func Get(ctx context.Context, requestUrl string, options ...Option) (httpStatusCode int, responseBody []byte, err error) {
@@ -507,6 +650,11 @@ func Post(ctx context.Context, requestUrl string, requestBody []byte, options ..
 	return Request(ctx, http.MethodPost, requestUrl, options...)
 }
 
+func PostMultipart(ctx context.Context, requestUrl string, fields map[string]string, files []FileField, options ...Option) (httpStatusCode int, responseBody []byte, err error) {
+	options = append(options, WithMultipartForm(fields, files))
+	return Request(ctx, http.MethodPost, requestUrl, options...)
+}
+
 func PostJson(ctx context.Context, requestUrl string, v any, options ...Option) (httpStatusCode int, responseBody []byte, err error) {
 	defaultHeader := map[string]string{"Content-Type": "application/json"}
 	options = append(options, WithRequestHeaders(defaultHeader), WithJsonAsQueryParamsAndRequestBody(v))
@@ -12,4 +12,5 @@ type RequestRecordData struct {
 	ResponseBody   string
 	Error          string
 	Duration       int64
+	Attempts       int // Number of attempts sent, retries included
 }
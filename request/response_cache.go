@@ -0,0 +1,247 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/infigaming-com/go-common/cache"
+	"go.uber.org/zap"
+)
+
+// responseCacheKeyPrefix prefixes the cache keys of cached responses
+const responseCacheKeyPrefix = "request:response:"
+
+// responseCache holds the settings of WithResponseCache
+type responseCache struct {
+	cache      cache.Cache
+	ttl        time.Duration
+	keyHeaders []string
+}
+
+// cachedResponse is a response stored in the cache, its body as received,
+// possibly still compressed
+type cachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	FreshUntil time.Time   `json:"fresh_until"`
+}
+
+// WithResponseCache caches successful GET responses in c for up to ttl, keyed
+// by URL, query and the values of keyHeaders, e.g. "Accept-Language".
+// Response Cache-Control directives are honored: max-age shortens the
+// lifetime, no-cache forces revalidation and no-store or private responses
+// are not cached, c being shared. Stale responses with an ETag are kept until
+// ttl and revalidated with If-None-Match, a 304 serving the cached response.
+// Requests with a Cache-Control of no-cache or no-store bypass the cache.
+// Cache failures are logged and do not fail the request. It has no effect on
+// RequestStream.
+func WithResponseCache(c cache.Cache, ttl time.Duration, keyHeaders ...string) Option {
+	return optionFunc(func(option *requestOption) error {
+		if c == nil || ttl <= 0 {
+			option.lg.Error("[HTTP-REQUEST-ERROR: invalid response cache settings]",
+				zap.Bool("cache", c != nil),
+				zap.Duration("ttl", ttl),
+			)
+			return fmt.Errorf("invalid response cache settings: cache set %t, ttl %v", c != nil, ttl)
+		}
+		headers := make([]string, len(keyHeaders))
+		for i, header := range keyHeaders {
+			headers[i] = http.CanonicalHeaderKey(header)
+		}
+		slices.Sort(headers)
+		option.responseCache = &responseCache{
+			cache:      c,
+			ttl:        ttl,
+			keyHeaders: slices.Compact(headers),
+		}
+		return nil
+	})
+}
+
+// middleware serves and stores the responses of next
+func (rc *responseCache) middleware(lg *zap.Logger) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet {
+				return next.Do(req)
+			}
+			if directives := cacheControl(req.Header); directives.has("no-cache") || directives.has("no-store") {
+				return next.Do(req)
+			}
+
+			ctx := req.Context()
+			key := rc.key(req)
+			entry, err := rc.load(ctx, key)
+			if err != nil {
+				lg.Warn("[HTTP-REQUEST-CACHE-ERROR: failed to load response]", zap.Error(err), zap.String("url", req.URL.String()))
+			}
+			if entry != nil && time.Now().Before(entry.FreshUntil) {
+				return entry.response(req), nil
+			}
+
+			conditional := false
+			if etag := entryETag(entry); etag != "" && req.Header.Get("If-None-Match") == "" {
+				req = req.Clone(ctx)
+				req.Header.Set("If-None-Match", etag)
+				conditional = true
+			}
+
+			resp, err := next.Do(req)
+			if err != nil {
+				return resp, err
+			}
+
+			if conditional && resp.StatusCode == http.StatusNotModified {
+				resp.Body.Close()
+				if lifetime, ok := rc.lifetime(resp.Header); ok {
+					entry.FreshUntil = time.Now().Add(lifetime)
+					rc.store(ctx, lg, key, entry)
+				}
+				return entry.response(req), nil
+			}
+
+			if resp.StatusCode != http.StatusOK {
+				return resp, nil
+			}
+			lifetime, ok := rc.lifetime(resp.Header)
+			if !ok || (lifetime == 0 && resp.Header.Get("ETag") == "") {
+				return resp, nil
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", err)
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+
+			rc.store(ctx, lg, key, &cachedResponse{
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header.Clone(),
+				Body:       body,
+				FreshUntil: time.Now().Add(lifetime),
+			})
+			return resp, nil
+		})
+	}
+}
+
+// key hashes the URL, with its query, and the key headers of req
+func (rc *responseCache) key(req *http.Request) string {
+	hash := sha256.New()
+	io.WriteString(hash, req.URL.String())
+	for _, header := range rc.keyHeaders {
+		fmt.Fprintf(hash, "\n%s: %s", header, strings.Join(req.Header.Values(header), ", "))
+	}
+	return responseCacheKeyPrefix + hex.EncodeToString(hash.Sum(nil))
+}
+
+// lifetime returns how long a response with header stays fresh, false when
+// it must not be cached
+func (rc *responseCache) lifetime(header http.Header) (time.Duration, bool) {
+	directives := cacheControl(header)
+	if directives.has("no-store") || directives.has("private") {
+		return 0, false
+	}
+	if directives.has("no-cache") {
+		return 0, true
+	}
+	if maxAge, ok := directives["max-age"]; ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil || seconds < 0 {
+			return 0, true
+		}
+		return min(time.Duration(seconds)*time.Second, rc.ttl), true
+	}
+	return rc.ttl, true
+}
+
+func (rc *responseCache) load(ctx context.Context, key string) (*cachedResponse, error) {
+	value, err := rc.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal([]byte(value), &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached response: %w", err)
+	}
+	return &entry, nil
+}
+
+// store keeps entry until ttl when it can be revalidated, until it goes
+// stale otherwise
+func (rc *responseCache) store(ctx context.Context, lg *zap.Logger, key string, entry *cachedResponse) {
+	expiry := rc.ttl
+	if entryETag(entry) == "" {
+		expiry = time.Until(entry.FreshUntil)
+	}
+	if expiry <= 0 {
+		return
+	}
+	value, err := json.Marshal(entry)
+	if err == nil {
+		err = rc.cache.Set(ctx, key, string(value), expiry)
+	}
+	if err != nil {
+		lg.Warn("[HTTP-REQUEST-CACHE-ERROR: failed to store response]", zap.Error(err), zap.String("key", key))
+	}
+}
+
+// response returns a response to req serving entry
+func (entry *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.StatusCode, http.StatusText(entry.StatusCode)),
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
+
+func entryETag(entry *cachedResponse) string {
+	if entry == nil {
+		return ""
+	}
+	return entry.Header.Get("ETag")
+}
+
+// cacheDirectives are the Cache-Control directives of a header, by lowercase
+// name, with their unquoted values
+type cacheDirectives map[string]string
+
+func cacheControl(header http.Header) cacheDirectives {
+	directives := make(cacheDirectives)
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+			}
+		}
+	}
+	return directives
+}
+
+func (d cacheDirectives) has(name string) bool {
+	_, ok := d[name]
+	return ok
+}
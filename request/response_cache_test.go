@@ -0,0 +1,86 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/infigaming-com/go-common/cache"
+)
+
+func TestRequestWithResponseCache(t *testing.T) {
+	var calls, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+			w.Write([]byte("fresh " + r.Header.Get("Accept-Language")))
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Write([]byte("etag"))
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+			w.Write([]byte("no-store"))
+		}
+	}))
+	defer server.Close()
+
+	responseCache := cache.NewFreeCache(freecache.NewCache(1024 * 1024))
+	get := func(path string, options ...Option) string {
+		options = append(options, WithResponseCache(responseCache, time.Minute, "Accept-Language"))
+		statusCode, responseBody, err := Get(context.Background(), server.URL+path, options...)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		return string(responseBody)
+	}
+
+	t.Run("fresh responses are served from the cache", func(t *testing.T) {
+		calls.Store(0)
+		assert.Equal(t, "fresh ", get("/fresh"))
+		assert.Equal(t, "fresh ", get("/fresh", WithQueryParams(map[string]string{})))
+		assert.Equal(t, int32(1), calls.Load())
+
+		// key headers and query params are part of the key
+		assert.Equal(t, "fresh de", get("/fresh", WithRequestHeaders(map[string]string{"Accept-Language": "de"})))
+		get("/fresh", WithQueryParams(map[string]string{"page": "2"}))
+		assert.Equal(t, int32(3), calls.Load())
+
+		// requests may bypass the cache
+		get("/fresh", WithRequestHeaders(map[string]string{"Cache-Control": "no-cache"}))
+		assert.Equal(t, int32(4), calls.Load())
+	})
+
+	t.Run("responses with an etag are revalidated", func(t *testing.T) {
+		calls.Store(0)
+		assert.Equal(t, "etag", get("/etag"))
+		assert.Equal(t, "etag", get("/etag"))
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, int32(1), notModified.Load())
+	})
+
+	t.Run("no-store responses are not cached", func(t *testing.T) {
+		calls.Store(0)
+		get("/no-store")
+		get("/no-store")
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestWithResponseCacheInvalidSettings(t *testing.T) {
+	responseCache := cache.NewFreeCache(freecache.NewCache(1024 * 1024))
+	assert.Error(t, WithResponseCache(nil, time.Minute).apply(defaultRequestOption()))
+	assert.Error(t, WithResponseCache(responseCache, 0).apply(defaultRequestOption()))
+}
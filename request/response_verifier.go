@@ -0,0 +1,104 @@
+package request
+
+import (
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/infigaming-com/go-common/util"
+)
+
+// ResponseVerifier validates the signature of a provider response. A non-nil
+// error makes the request fail even if the status code is successful.
+type ResponseVerifier func(responseVerificationData *ResponseVerificationData, keys any) error
+
+type ResponseVerificationData struct {
+	Method          string
+	Url             string
+	StatusCode      int
+	ResponseHeaders http.Header
+	ResponseBody    []byte
+}
+
+type HmacSha256VerifierKeys struct {
+	SignatureHeader string
+	Secret          string
+}
+
+type JwtVerifierKeys struct {
+	SignatureHeader string
+	PublicKey       string // base64 encoded PKIX DER public key
+}
+
+// HmacSha256ResponseVerifier checks that the signature header holds the hex
+// encoded hmac sha256 of the response body, the counterpart of
+// HmacSha256RequestBodySigner
+func HmacSha256ResponseVerifier(responseVerificationData *ResponseVerificationData, keys any) error {
+	hmacSha256VerifierKeys, ok := keys.(HmacSha256VerifierKeys)
+	if !ok {
+		return fmt.Errorf("invalid verifier keys for hmac sha256 verifier: %v", keys)
+	}
+
+	signature, err := hex.DecodeString(responseVerificationData.ResponseHeaders.Get(hmacSha256VerifierKeys.SignatureHeader))
+	if err != nil || len(signature) == 0 {
+		return fmt.Errorf("missing or malformed signature header %s", hmacSha256VerifierKeys.SignatureHeader)
+	}
+
+	expected := util.HmacSha256Hash(responseVerificationData.ResponseBody, []byte(hmacSha256VerifierKeys.Secret))
+	if !hmac.Equal(signature, expected) {
+		return fmt.Errorf("response signature mismatch")
+	}
+	return nil
+}
+
+// JwtResponseVerifier checks that the signature header holds an RS256 JWT whose
+// claims equal the JSON response body, the counterpart of JwtSigner
+func JwtResponseVerifier(responseVerificationData *ResponseVerificationData, keys any) error {
+	jwtVerifierKeys, ok := keys.(JwtVerifierKeys)
+	if !ok {
+		return fmt.Errorf("invalid verifier keys for jwt verifier: %v", keys)
+	}
+
+	base64DecodedPublicKey, err := base64.StdEncoding.DecodeString(jwtVerifierKeys.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key for jwt verifier: %v", err)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(base64DecodedPublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key for jwt verifier: %v", err)
+	}
+
+	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("invalid public key for jwt verifier: not an rsa key")
+	}
+
+	tokenString := responseVerificationData.ResponseHeaders.Get(jwtVerifierKeys.SignatureHeader)
+	if tokenString == "" {
+		return fmt.Errorf("missing signature header %s", jwtVerifierKeys.SignatureHeader)
+	}
+
+	var claims jwt.MapClaims
+	if _, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (any, error) {
+		return rsaPublicKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()})); err != nil {
+		return fmt.Errorf("invalid response signature: %v", err)
+	}
+
+	var body jwt.MapClaims
+	if err := json.Unmarshal(responseVerificationData.ResponseBody, &body); err != nil {
+		return fmt.Errorf("invalid response body for jwt verifier: %v", err)
+	}
+	if !reflect.DeepEqual(claims, body) {
+		return fmt.Errorf("response body does not match signed claims")
+	}
+	return nil
+}
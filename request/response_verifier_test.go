@@ -0,0 +1,71 @@
+package request
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/infigaming-com/go-common/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestWithHmacSha256ResponseVerifier(t *testing.T) {
+	body := []byte(`{"balance":100}`)
+	signature := hex.EncodeToString(util.HmacSha256Hash(body, []byte("secret")))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Signature", signature)
+		if r.URL.Path == "/tampered" {
+			_, _ = w.Write([]byte(`{"balance":1000}`))
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	keys := HmacSha256VerifierKeys{SignatureHeader: "X-Signature", Secret: "secret"}
+
+	statusCode, responseBody, err := Get(context.Background(), server.URL, WithResponseVerifier(HmacSha256ResponseVerifier, keys))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, body, responseBody)
+
+	_, _, err = Get(context.Background(), server.URL+"/tampered", WithResponseVerifier(HmacSha256ResponseVerifier, keys))
+	assert.ErrorContains(t, err, "failed to verify response")
+}
+
+func TestRequestWithJwtResponseVerifier(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"balance": 100}).SignedString(privateKey)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Signature", token)
+		if r.URL.Path == "/tampered" {
+			_, _ = w.Write([]byte(`{"balance":1000}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"balance":100}`))
+	}))
+	defer server.Close()
+
+	keys := JwtVerifierKeys{SignatureHeader: "X-Signature", PublicKey: base64.StdEncoding.EncodeToString(publicKey)}
+
+	_, _, err = Get(context.Background(), server.URL, WithResponseVerifier(JwtResponseVerifier, keys))
+	assert.NoError(t, err)
+
+	_, _, err = Get(context.Background(), server.URL+"/tampered", WithResponseVerifier(JwtResponseVerifier, keys))
+	assert.ErrorContains(t, err, "response body does not match signed claims")
+}
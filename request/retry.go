@@ -0,0 +1,205 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// IdempotencyKeyHeader marks a non-idempotent request as safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy controls how Request retries failed attempts. Zero fields take
+// the values of DefaultRetryPolicy.
+//
+// An attempt is retried when it fails with a transient network error or
+// responds with one of RetryableStatusCodes. Requests with a non-idempotent
+// method, e.g. POST, may have been processed by the server, so they are only
+// retried when the connection could not be established, unless they carry an
+// Idempotency-Key header or RetryNonIdempotent is set.
+type RetryPolicy struct {
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry, multiplied by
+	// Multiplier for every further retry up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter randomizes each delay by up to this fraction, e.g. 0.2 for ±20%
+	Jitter               float64
+	RetryableStatusCodes []int
+	// MaxRetryAfter caps the Retry-After delay the server may ask for. The
+	// response is returned as is when it asks for more.
+	MaxRetryAfter time.Duration
+	// AttemptTimeout bounds each attempt, overriding WithRequestTimeout
+	AttemptTimeout     time.Duration
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the retry settings used by WithRetry
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		MaxRetryAfter: 30 * time.Second,
+	}
+}
+
+// WithRetryPolicy retries failed attempts according to policy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return optionFunc(func(option *requestOption) error {
+		if policy.MaxRetries < 0 || policy.InitialBackoff < 0 || policy.MaxBackoff < 0 ||
+			policy.Jitter < 0 || policy.Jitter > 1 || policy.AttemptTimeout < 0 {
+			option.lg.Error("[HTTP-REQUEST-ERROR: invalid retry policy]",
+				zap.Any("policy", policy),
+			)
+			return fmt.Errorf("invalid retry policy: %+v", policy)
+		}
+		defaults := DefaultRetryPolicy()
+		if policy.InitialBackoff == 0 {
+			policy.InitialBackoff = defaults.InitialBackoff
+		}
+		if policy.MaxBackoff == 0 {
+			policy.MaxBackoff = defaults.MaxBackoff
+		}
+		if policy.Multiplier < 1 {
+			policy.Multiplier = defaults.Multiplier
+		}
+		if policy.RetryableStatusCodes == nil {
+			policy.RetryableStatusCodes = defaults.RetryableStatusCodes
+		}
+		if policy.MaxRetryAfter == 0 {
+			policy.MaxRetryAfter = defaults.MaxRetryAfter
+		}
+		option.retryPolicy = &policy
+		return nil
+	})
+}
+
+// WithRetry retries failed attempts up to maxRetries times with
+// DefaultRetryPolicy. Default is 0 (no retry).
+func WithRetry(maxRetries int) Option {
+	policy := DefaultRetryPolicy()
+	policy.MaxRetries = max(maxRetries, 0)
+	return WithRetryPolicy(policy)
+}
+
+// backoff returns the jittered delay before the given retry, starting at 1
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < retry && delay < float64(p.MaxBackoff); i++ {
+		delay *= p.Multiplier
+	}
+	delay = min(delay, float64(p.MaxBackoff))
+	if p.Jitter > 0 {
+		delay += (rand.Float64()*2 - 1) * p.Jitter * delay
+	}
+	return time.Duration(delay)
+}
+
+// shouldRetry reports whether an attempt ending with httpStatusCode and err
+// may be retried
+func (p *RetryPolicy) shouldRetry(method string, option *requestOption, httpStatusCode int, err error) bool {
+	if err == nil {
+		if !slices.Contains(p.RetryableStatusCodes, httpStatusCode) {
+			return false
+		}
+	} else if !isRetryableError(err) {
+		return false
+	}
+	if p.RetryNonIdempotent || isIdempotent(method, option) {
+		return true
+	}
+	// the server never saw a request that could not connect
+	return err != nil && isDialError(err)
+}
+
+// isRetryableError checks if the error is a transient network error
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ENETUNREACH) ||
+		errors.Is(err, syscall.EHOSTUNREACH) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &dnsErr) ||
+		isDialError(err)
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func isIdempotent(method string, option *requestOption) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	if option.requestHeaders != nil {
+		for key, value := range *option.requestHeaders {
+			if http.CanonicalHeaderKey(key) == IdempotencyKeyHeader && value != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseRetryAfter returns the delay of a Retry-After header, in seconds or as
+// an HTTP date, and false without a valid header
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// retryDelay returns the delay before the given retry, honoring the
+// Retry-After header of the last response, and false when the server asks to
+// wait longer than MaxRetryAfter or the delay would outlast ctx
+func (p *RetryPolicy) retryDelay(ctx context.Context, retry int, responseHeaders http.Header) (time.Duration, bool) {
+	delay := p.backoff(retry)
+	if retryAfter, ok := parseRetryAfter(responseHeaders, time.Now()); ok {
+		if retryAfter > p.MaxRetryAfter {
+			return 0, false
+		}
+		delay = max(delay, retryAfter)
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return 0, false
+	}
+	return delay, true
+}
@@ -0,0 +1,205 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fastRetryPolicy(maxRetries int) RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     maxRetries,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}
+}
+
+func TestRequestRetryPolicyStatusCodes(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var record *RequestRecordData
+	statusCode, responseBody, err := Get(context.Background(), server.URL,
+		WithRetryPolicy(fastRetryPolicy(3)),
+		WithRequestRecorder(func(data *RequestRecordData) { record = data }),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "ok", string(responseBody))
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, 3, record.Attempts)
+
+	// the last response is returned once retries are exhausted
+	calls.Store(0)
+	statusCode, _, err = Get(context.Background(), server.URL, WithRetryPolicy(fastRetryPolicy(1)))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRequestRetryPolicyNotRetryable(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	statusCode, _, err := Get(context.Background(), server.URL, WithRetryPolicy(fastRetryPolicy(3)))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, statusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRequestRetryPolicyIdempotency(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, _, err := Post(context.Background(), server.URL, []byte("{}"), WithRetryPolicy(fastRetryPolicy(2)))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load(), "POST must not be retried without an idempotency key")
+
+	calls.Store(0)
+	_, _, err = Post(context.Background(), server.URL, []byte("{}"),
+		WithRetryPolicy(fastRetryPolicy(2)),
+		WithRequestHeaders(map[string]string{"idempotency-key": "deposit-1"}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+
+	calls.Store(0)
+	policy := fastRetryPolicy(2)
+	policy.RetryNonIdempotent = true
+	_, _, err = Post(context.Background(), server.URL, []byte("{}"), WithRetryPolicy(policy))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRequestRetryPolicyConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	url := "http://" + listener.Addr().String()
+	listener.Close()
+
+	var record *RequestRecordData
+	_, _, err = Post(context.Background(), url, []byte("{}"),
+		WithRetryPolicy(fastRetryPolicy(2)),
+		WithRequestRecorder(func(data *RequestRecordData) { record = data }),
+	)
+	assert.Error(t, err)
+	assert.Equal(t, 3, record.Attempts, "unsent requests are retried whatever their method")
+}
+
+func TestRequestRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var retryAt time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			retryAt = time.Now().Add(time.Second)
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		assert.False(t, time.Now().Before(retryAt), "Retry-After was not honored")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	statusCode, _, err := Get(context.Background(), server.URL, WithRetryPolicy(fastRetryPolicy(1)))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// a Retry-After beyond MaxRetryAfter ends the retries
+	calls.Store(0)
+	policy := fastRetryPolicy(1)
+	policy.MaxRetryAfter = 500 * time.Millisecond
+	statusCode, _, err = Get(context.Background(), server.URL, WithRetryPolicy(policy))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, statusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRequestRetryAttemptTimeout(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	policy := fastRetryPolicy(1)
+	policy.AttemptTimeout = 50 * time.Millisecond
+	statusCode, _, err := Get(context.Background(), server.URL, WithRetryPolicy(policy))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 800*time.Millisecond, policy.backoff(4))
+	assert.Equal(t, time.Second, policy.backoff(10))
+
+	policy.Jitter = 0.5
+	for range 100 {
+		delay := policy.backoff(1)
+		assert.True(t, delay >= 50*time.Millisecond && delay <= 150*time.Millisecond, "delay %v", delay)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		delay time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q", tt.value), func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+			delay, ok := parseRetryAfter(header, now)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.delay, delay)
+		})
+	}
+}
+
+func TestWithRetryPolicyValidation(t *testing.T) {
+	_, _, err := Get(context.Background(), "http://localhost", WithRetryPolicy(RetryPolicy{Jitter: 2}))
+	assert.Error(t, err)
+	_, _, err = Get(context.Background(), "http://localhost", WithRetryPolicy(RetryPolicy{MaxRetries: -1}))
+	assert.Error(t, err)
+}
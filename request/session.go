@@ -0,0 +1,94 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+)
+
+// Session shares a cookie jar, a base URL and default options, e.g. headers
+// or a request signer, across requests to the same provider. Options passed
+// to a call are applied after the session options, so they add to or override
+// them. A Session is safe for concurrent use.
+type Session struct {
+	baseURL string
+	jar     http.CookieJar
+	options []Option
+}
+
+// NewSession creates a session with an empty in-memory cookie jar. baseURL
+// may be empty, in which case every call must use an absolute URL.
+func NewSession(baseURL string, options ...Option) (*Session, error) {
+	if baseURL != "" {
+		if _, err := url.ParseRequestURI(baseURL); err != nil {
+			return nil, fmt.Errorf("invalid session base url: %w", err)
+		}
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
+	// Fail early on invalid options instead of on every call
+	if _, err := newRequestOption(options); err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		jar:     jar,
+		options: append([]Option{WithCookieJar(jar)}, options...),
+	}, nil
+}
+
+// Jar returns the cookie jar of the session, e.g. to seed or inspect cookies.
+func (s *Session) Jar() http.CookieJar {
+	return s.jar
+}
+
+// URL resolves path against the base URL. Absolute URLs are returned as is.
+func (s *Session) URL(path string) string {
+	if s.baseURL == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	if path == "" {
+		return s.baseURL
+	}
+	return s.baseURL + "/" + strings.TrimLeft(path, "/")
+}
+
+// Options returns the session options followed by options, for use with the
+// package functions such as RequestJSON.
+func (s *Session) Options(options ...Option) []Option {
+	merged := make([]Option, 0, len(s.options)+len(options))
+	merged = append(merged, s.options...)
+	return append(merged, options...)
+}
+
+func (s *Session) Request(ctx context.Context, method string, path string, options ...Option) (httpStatusCode int, responseBody []byte, err error) {
+	return Request(ctx, method, s.URL(path), s.Options(options...)...)
+}
+
+func (s *Session) RequestStream(ctx context.Context, method string, path string, options ...Option) (httpStatusCode int, responseHeaders http.Header, responseBody io.ReadCloser, err error) {
+	return RequestStream(ctx, method, s.URL(path), s.Options(options...)...)
+}
+
+func (s *Session) Get(ctx context.Context, path string, options ...Option) (httpStatusCode int, responseBody []byte, err error) {
+	return Get(ctx, s.URL(path), s.Options(options...)...)
+}
+
+func (s *Session) Post(ctx context.Context, path string, requestBody []byte, options ...Option) (httpStatusCode int, responseBody []byte, err error) {
+	return Post(ctx, s.URL(path), requestBody, s.Options(options...)...)
+}
+
+func (s *Session) PostJson(ctx context.Context, path string, v any, options ...Option) (httpStatusCode int, responseBody []byte, err error) {
+	return PostJson(ctx, s.URL(path), v, s.Options(options...)...)
+}
+
+func (s *Session) PostForm(ctx context.Context, path string, requestBody url.Values, options ...Option) (httpStatusCode int, responseBody []byte, err error) {
+	return PostForm(ctx, s.URL(path), requestBody, s.Options(options...)...)
+}
@@ -0,0 +1,97 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestWithCookieJar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "abc", Path: "/"})
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{}
+
+	_, _, err = Get(context.Background(), server.URL, WithHTTPClient(client), WithCookieJar(jar))
+	require.NoError(t, err)
+
+	serverURL, _ := url.Parse(server.URL)
+	cookies := jar.Cookies(serverURL)
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "abc", cookies[0].Value)
+	assert.Nil(t, client.Jar, "the caller's client must not be modified")
+}
+
+func TestSession(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "session-1", Path: "/"})
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/api/balance", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("sid")
+		if err != nil || cookie.Value != "session-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"agent":"` + r.Header.Get("X-Agent") + `","trace":"` + r.Header.Get("X-Trace") +
+			`","signed":` + boolString(r.Header.Get("X-SIGNATURE") != "") + `}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	session, err := NewSession(server.URL+"/api/",
+		WithRequestHeaders(map[string]string{"X-Agent": "legacy", "X-Trace": "default"}),
+		WithRequestSigner(HmacSha256Signer, HmacSha256SignerKeys{
+			ApiKeyHeader:    "X-API-KEY",
+			SignatureHeader: "X-SIGNATURE",
+			ApiKey:          "test-api-key",
+			ApiKeySecret:    "test-api-key-secret",
+		}),
+	)
+	require.NoError(t, err)
+
+	statusCode, _, err := session.Get(context.Background(), "/balance", WithAcceptedStatusCodes(http.StatusUnauthorized))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, statusCode)
+
+	statusCode, _, err = session.PostForm(context.Background(), "login", url.Values{"user": {"u"}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+
+	type balance struct {
+		Agent  string `json:"agent"`
+		Trace  string `json:"trace"`
+		Signed bool   `json:"signed"`
+	}
+	got, err := GetJSON[balance](context.Background(), session.URL("balance"),
+		session.Options(WithRequestHeaders(map[string]string{"X-Trace": "call"}))...)
+	require.NoError(t, err)
+	assert.Equal(t, balance{Agent: "legacy", Trace: "call", Signed: true}, got)
+
+	assert.Equal(t, "https://other.example/x", session.URL("https://other.example/x"))
+}
+
+func TestNewSessionInvalidBaseURL(t *testing.T) {
+	_, err := NewSession("not a url")
+	assert.Error(t, err)
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
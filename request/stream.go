@@ -0,0 +1,131 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrResponseBodyTooLarge is returned when a response body exceeds the
+// WithResponseBodyLimit limit
+var ErrResponseBodyTooLarge = errors.New("response body too large")
+
+// WithResponseBodyLimit fails requests whose response body, after
+// decompression, is larger than limit bytes with ErrResponseBodyTooLarge.
+// It has no effect on RequestStream.
+func WithResponseBodyLimit(limit int64) Option {
+	return optionFunc(func(option *requestOption) error {
+		if limit <= 0 {
+			option.lg.Error("[HTTP-REQUEST-ERROR: invalid response body limit]",
+				zap.Int64("limit", limit),
+			)
+			return fmt.Errorf("invalid response body limit: %d", limit)
+		}
+		option.responseBodyLimit = limit
+		return nil
+	})
+}
+
+// readResponseBody reads r fully, failing with ErrResponseBodyTooLarge past
+// limit bytes when limit is above 0
+func readResponseBody(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseBodyTooLarge, limit)
+	}
+	return body, nil
+}
+
+// RequestStream sends a request like Request but returns the response body
+// unread, for large downloads and server-sent events. The request timeout
+// only covers receiving the response headers, reading the body is bounded by
+// ctx alone. The caller must close the body. Gzip responses are decompressed
+// by the transport. Retries, hedging, response caches and verifiers do not
+// apply, and recorders see an empty response body.
+func RequestStream(ctx context.Context, method string, requestUrl string, options ...Option) (httpStatusCode int, responseHeaders http.Header, responseBody io.ReadCloser, err error) {
+	start := time.Now()
+
+	option, err := newRequestOption(options)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	defer func() {
+		finishRequest(option, method, requestUrl, start, httpStatusCode, nil, err)
+	}()
+
+	// streamed bodies are not buffered for the response cache
+	option.responseCache = nil
+
+	if err := prepareRequestBody(method, requestUrl, option); err != nil {
+		return 0, nil, nil, err
+	}
+	if err := waitRateLimit(ctx, requestUrl, option); err != nil {
+		return 0, nil, nil, err
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	req, closeBody, err := newHTTPRequest(streamCtx, method, requestUrl, option)
+	if err != nil {
+		cancel()
+		return 0, nil, nil, err
+	}
+
+	// cancel the request if the headers do not arrive in time, the timer is
+	// stopped once they do so reading the body is not cut short
+	timedOut := make(chan struct{})
+	timer := time.AfterFunc(option.requestTimeout, func() {
+		close(timedOut)
+		cancel()
+	})
+
+	option.attempts = 1
+	resp, err := resolveDoer(option).Do(req)
+	if !timer.Stop() {
+		<-timedOut
+		if err == nil {
+			resp.Body.Close()
+		}
+		closeBody()
+		cancel()
+		return 0, nil, nil, fmt.Errorf("request timeout: %w", context.DeadlineExceeded)
+	}
+	if err != nil {
+		closeBody()
+		cancel()
+		return 0, nil, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return resp.StatusCode, resp.Header, &streamBody{
+		ReadCloser: resp.Body,
+		release: func() {
+			closeBody()
+			cancel()
+		},
+	}, nil
+}
+
+// streamBody releases the request resources once the response body is closed
+type streamBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *streamBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
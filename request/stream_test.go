@@ -0,0 +1,75 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		for i := 0; i < 3; i++ {
+			_, _ = w.Write([]byte("data: tick\n\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	// the body takes longer than the request timeout, which only covers headers
+	statusCode, headers, body, err := RequestStream(context.Background(), http.MethodGet, server.URL,
+		WithRequestTimeout(100*time.Millisecond),
+	)
+	require.NoError(t, err)
+	defer body.Close()
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "text/event-stream", headers.Get("Content-Type"))
+
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("data: tick\n\n", 3), string(data))
+	assert.NoError(t, body.Close())
+}
+
+func TestRequestStream_HeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	_, _, body, err := RequestStream(context.Background(), http.MethodGet, server.URL,
+		WithRequestTimeout(50*time.Millisecond),
+	)
+	assert.Nil(t, body)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWithResponseBodyLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer server.Close()
+
+	_, responseBody, err := Get(context.Background(), server.URL, WithResponseBodyLimit(100))
+	require.NoError(t, err)
+	assert.Len(t, responseBody, 100)
+
+	_, _, err = Get(context.Background(), server.URL, WithResponseBodyLimit(99))
+	assert.True(t, errors.Is(err, ErrResponseBodyTooLarge))
+
+	_, _, err = Get(context.Background(), server.URL, WithResponseBodyLimit(0))
+	assert.Error(t, err)
+}
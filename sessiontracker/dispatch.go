@@ -0,0 +1,143 @@
+package sessiontracker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what happens to a change event when the dispatch
+// queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest drops the new event. Drops are counted by
+	// Tracker.Dropped and reported to the drop handler.
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest queued event to make room for the
+	// new one. Drops are counted like OverflowDropNewest.
+	OverflowDropOldest
+	// OverflowBlock blocks Track until the queue has room, the Track context
+	// is done or the tracker is stopped. Events given up on are dropped.
+	OverflowBlock
+)
+
+// dispatcher delivers change events to the callback from a bounded queue
+// served by a fixed pool of workers.
+type dispatcher struct {
+	onChange OnChangeFunc
+	onDrop   func(event *ChangeEvent)
+	policy   OverflowPolicy
+
+	// mu guards closing queue against concurrent sends
+	mu     sync.RWMutex
+	closed bool
+	queue  chan *ChangeEvent
+	// closing is closed before mu is locked by close, releasing blocked sends
+	closing chan struct{}
+	dropped atomic.Uint64
+	abandon atomic.Bool
+	wg      sync.WaitGroup
+}
+
+func newDispatcher(onChange OnChangeFunc, onDrop func(*ChangeEvent), policy OverflowPolicy, workers, queueSize int) *dispatcher {
+	d := &dispatcher{
+		onChange: onChange,
+		onDrop:   onDrop,
+		policy:   policy,
+		queue:    make(chan *ChangeEvent, queueSize),
+		closing:  make(chan struct{}),
+	}
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+	return d
+}
+
+func (d *dispatcher) worker() {
+	defer d.wg.Done()
+	for event := range d.queue {
+		// Stop gave up draining, discard what is left
+		if d.abandon.Load() {
+			d.drop(event)
+			continue
+		}
+		d.onChange(event)
+	}
+}
+
+// dispatch queues event according to the overflow policy. Events dispatched
+// after close, or given up on by a blocked send, are dropped.
+func (d *dispatcher) dispatch(ctx context.Context, event *ChangeEvent) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		d.drop(event)
+		return
+	}
+
+	switch d.policy {
+	case OverflowBlock:
+		select {
+		case d.queue <- event:
+		case <-ctx.Done():
+			d.drop(event)
+		case <-d.closing:
+			d.drop(event)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case d.queue <- event:
+				return
+			default:
+			}
+			select {
+			case oldest := <-d.queue:
+				d.drop(oldest)
+			default:
+			}
+		}
+	default:
+		select {
+		case d.queue <- event:
+		default:
+			d.drop(event)
+		}
+	}
+}
+
+func (d *dispatcher) drop(event *ChangeEvent) {
+	d.dropped.Add(1)
+	if d.onDrop != nil {
+		d.onDrop(event)
+	}
+}
+
+// close stops accepting events and waits up to timeout for the queued ones to
+// be delivered. Events still queued after the timeout are dropped.
+func (d *dispatcher) close(timeout time.Duration) {
+	// Release sends blocked on a full queue, they hold mu for reading
+	close(d.closing)
+	d.mu.Lock()
+	d.closed = true
+	close(d.queue)
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		d.abandon.Store(true)
+	}
+}
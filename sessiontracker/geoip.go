@@ -0,0 +1,80 @@
+package sessiontracker
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// Location is the geolocation of an IP address.
+type Location struct {
+	Country string // ISO 3166-1 alpha-2 code, e.g. "DE"
+	City    string // English name, e.g. "Berlin"
+	ASN     uint   // autonomous system number, 0 if unknown
+}
+
+// Resolver enriches tracked sessions with the location of their IP. It must
+// be safe for concurrent use.
+type Resolver interface {
+	ResolveIP(ip string) (Location, error)
+}
+
+// MaxMindResolver resolves IPs with MaxMind GeoLite2 (or GeoIP2) databases.
+type MaxMindResolver struct {
+	city *geoip2.Reader
+	asn  *geoip2.Reader
+}
+
+// NewMaxMindResolver opens the City database at cityPath and, if asnPath is
+// not empty, the ASN database at asnPath, e.g. GeoLite2-City.mmdb and
+// GeoLite2-ASN.mmdb.
+func NewMaxMindResolver(cityPath, asnPath string) (*MaxMindResolver, error) {
+	city, err := geoip2.Open(cityPath)
+	if err != nil {
+		return nil, fmt.Errorf("open geoip city database: %w", err)
+	}
+	r := &MaxMindResolver{city: city}
+	if asnPath != "" {
+		if r.asn, err = geoip2.Open(asnPath); err != nil {
+			_ = city.Close()
+			return nil, fmt.Errorf("open geoip asn database: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// ResolveIP looks up ip in the databases.
+func (r *MaxMindResolver) ResolveIP(ip string) (Location, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}, fmt.Errorf("invalid ip %q", ip)
+	}
+
+	var loc Location
+	record, err := r.city.City(parsed)
+	if err != nil {
+		return Location{}, fmt.Errorf("geoip city lookup: %w", err)
+	}
+	loc.Country = record.Country.IsoCode
+	loc.City = record.City.Names["en"]
+
+	if r.asn != nil {
+		asn, err := r.asn.ASN(parsed)
+		if err != nil {
+			return loc, fmt.Errorf("geoip asn lookup: %w", err)
+		}
+		loc.ASN = asn.AutonomousSystemNumber
+	}
+	return loc, nil
+}
+
+// Close closes the databases.
+func (r *MaxMindResolver) Close() error {
+	err := r.city.Close()
+	if r.asn != nil {
+		err = errors.Join(err, r.asn.Close())
+	}
+	return err
+}
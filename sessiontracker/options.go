@@ -36,3 +36,100 @@ func WithCleanupInterval(d time.Duration) Option {
 		t.cleanupInterval = d
 	}
 }
+
+// WithFlushInterval enables write-behind of L2 (Redis) writes, flushed every
+// d: updates are coalesced per user and written in a single pipeline, at the
+// cost of losing those not flushed yet if the process dies. Zero writes to
+// Redis synchronously on every change.
+// Default: 0 (write-through).
+func WithFlushInterval(d time.Duration) Option {
+	return func(t *Tracker) {
+		t.flushInterval = d
+	}
+}
+
+// WithFlushSize sets the number of buffered users that triggers a flush
+// before the flush interval elapses, with WithFlushInterval.
+// Default: 1000.
+func WithFlushSize(n int) Option {
+	return func(t *Tracker) {
+		if n > 0 {
+			t.flushSize = n
+		}
+	}
+}
+
+// WithRules adds suspicious-activity rules, such as ImpossibleTravelRule and
+// LoginMethodSwitchRule, evaluated whenever a session differs from the cached
+// one. The last seen time used by the rules is refreshed on every L1 miss.
+func WithRules(rules ...Rule) Option {
+	return func(t *Tracker) {
+		t.rules = append(t.rules, rules...)
+	}
+}
+
+// WithDispatchWorkers sets the number of workers invoking the onChange
+// callback, and delivering to each sink.
+// Default: 16.
+func WithDispatchWorkers(n int) Option {
+	return func(t *Tracker) {
+		if n > 0 {
+			t.dispatchWorkers = n
+		}
+	}
+}
+
+// WithDispatchQueueSize sets how many change events can wait for a worker
+// before the overflow policy applies, per sink.
+// Default: 10000.
+func WithDispatchQueueSize(n int) Option {
+	return func(t *Tracker) {
+		if n > 0 {
+			t.dispatchQueueSize = n
+		}
+	}
+}
+
+// WithOverflowPolicy sets what happens to change events when the dispatch
+// queue is full.
+// Default: OverflowDropNewest.
+func WithOverflowPolicy(p OverflowPolicy) Option {
+	return func(t *Tracker) {
+		t.overflowPolicy = p
+	}
+}
+
+// WithDropHandler sets a callback invoked synchronously with every dropped
+// change event, e.g. to increment a metric.
+func WithDropHandler(fn func(event *ChangeEvent)) Option {
+	return func(t *Tracker) {
+		t.onDrop = fn
+	}
+}
+
+// WithDrainTimeout sets how long Stop waits for queued change events to be
+// delivered. Events still queued after it are dropped.
+// Default: 5 seconds.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(t *Tracker) {
+		t.drainTimeout = d
+	}
+}
+
+// WithResolver enriches every tracked request with the location of its IP,
+// e.g. from NewMaxMindResolver, so ChangeEvent and rules such as
+// ImpossibleTravelRule get consistent location data. Location fields set by
+// the caller take precedence.
+func WithResolver(r Resolver) Option {
+	return func(t *Tracker) {
+		t.resolver = r
+	}
+}
+
+// WithAggregator maintains the session statistics of a, e.g. the daily active
+// users per operator, on every L1 miss of Track.
+func WithAggregator(a *Aggregator) Option {
+	return func(t *Tracker) {
+		t.aggregator = a
+	}
+}
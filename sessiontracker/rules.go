@@ -0,0 +1,84 @@
+package sessiontracker
+
+import "time"
+
+// Trigger names of the built-in rules.
+const (
+	TriggerImpossibleTravel  = "impossible_travel"
+	TriggerLoginMethodSwitch = "login_method_switch"
+)
+
+// RuleFunc reports whether the change from prev to curr is suspicious, along
+// with metadata describing it. prev is rebuilt from the stored session, so
+// only UserID, IP, Country, City, ASN, ClientSource and LoginMethod are set.
+// elapsed is the time since prev was last seen, or negative if it is unknown.
+type RuleFunc func(prev, curr *TrackRequest, elapsed time.Duration) (metadata map[string]string, matched bool)
+
+// Rule is a suspicious-activity check evaluated by the Tracker whenever a
+// session differs from the cached one. When Match matches, Trigger is added to
+// ChangeEvent.Triggers and the metadata to ChangeEvent.Metadata[Trigger].
+type Rule struct {
+	Trigger string
+	Match   RuleFunc
+}
+
+// ImpossibleTravelRule matches a country change within window of the previous
+// session being seen.
+func ImpossibleTravelRule(window time.Duration) Rule {
+	return Rule{
+		Trigger: TriggerImpossibleTravel,
+		Match: func(prev, curr *TrackRequest, elapsed time.Duration) (map[string]string, bool) {
+			if prev.Country == "" || curr.Country == "" || prev.Country == curr.Country {
+				return nil, false
+			}
+			if elapsed < 0 || elapsed > window {
+				return nil, false
+			}
+			return map[string]string{
+				"prev_country": prev.Country,
+				"country":      curr.Country,
+				"elapsed":      elapsed.String(),
+			}, true
+		},
+	}
+}
+
+// LoginMethodSwitchRule matches a change of login method, e.g. from password
+// to a social login.
+func LoginMethodSwitchRule() Rule {
+	return Rule{
+		Trigger: TriggerLoginMethodSwitch,
+		Match: func(prev, curr *TrackRequest, _ time.Duration) (map[string]string, bool) {
+			if prev.LoginMethod == "" || curr.LoginMethod == "" || prev.LoginMethod == curr.LoginMethod {
+				return nil, false
+			}
+			return map[string]string{
+				"prev_login_method": prev.LoginMethod,
+				"login_method":      curr.LoginMethod,
+			}, true
+		},
+	}
+}
+
+// evaluateRules runs the configured rules and returns the matched triggers
+// with their metadata.
+func (t *Tracker) evaluateRules(prev, curr *TrackRequest, elapsed time.Duration) ([]string, map[string]map[string]string) {
+	var (
+		triggers []string
+		metadata map[string]map[string]string
+	)
+	for _, rule := range t.rules {
+		md, ok := rule.Match(prev, curr, elapsed)
+		if !ok {
+			continue
+		}
+		triggers = append(triggers, rule.Trigger)
+		if md != nil {
+			if metadata == nil {
+				metadata = make(map[string]map[string]string)
+			}
+			metadata[rule.Trigger] = md
+		}
+	}
+	return triggers, metadata
+}
@@ -0,0 +1,190 @@
+package sessiontracker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/infigaming-com/go-common/pubsub"
+)
+
+// ErrSinkFull is returned by ChannelSink.Send when its buffer is full.
+var ErrSinkFull = errors.New("sessiontracker: sink buffer full")
+
+// Sink receives change events. Each sink configured with WithSinks has its
+// own dispatch queue and workers, so a slow or failing sink does not hold back
+// the others. Events are shared between sinks and must not be modified.
+type Sink interface {
+	Send(ctx context.Context, event *ChangeEvent) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(ctx context.Context, event *ChangeEvent) error
+
+func (f SinkFunc) Send(ctx context.Context, event *ChangeEvent) error {
+	return f(ctx, event)
+}
+
+// SinkOption configures how change events are delivered to a sink.
+type SinkOption func(*sinkConfig)
+
+type sinkConfig struct {
+	maxAttempts int
+	backoff     time.Duration
+	timeout     time.Duration
+	onError     func(event *ChangeEvent, err error)
+}
+
+// configuredSink carries the delivery settings of a sink to WithSinks
+type configuredSink struct {
+	Sink
+	config sinkConfig
+}
+
+// SinkRetries sets how many times an event is sent before giving up, waiting
+// backoff before the first retry and doubling it for each further one.
+// Default: 3 attempts, 100 milliseconds.
+func SinkRetries(maxAttempts int, backoff time.Duration) SinkOption {
+	return func(c *sinkConfig) {
+		if maxAttempts > 0 {
+			c.maxAttempts = maxAttempts
+		}
+		if backoff >= 0 {
+			c.backoff = backoff
+		}
+	}
+}
+
+// SinkTimeout bounds each attempt to send an event.
+// Default: 5 seconds.
+func SinkTimeout(d time.Duration) SinkOption {
+	return func(c *sinkConfig) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// SinkErrorHandler sets a callback invoked with every event the sink failed
+// to receive after all attempts, e.g. to log it or increment a metric.
+func SinkErrorHandler(fn func(event *ChangeEvent, err error)) SinkOption {
+	return func(c *sinkConfig) {
+		c.onError = fn
+	}
+}
+
+// ConfigureSink returns sink with its delivery settings, to pass to WithSinks.
+func ConfigureSink(sink Sink, opts ...SinkOption) Sink {
+	config := defaultSinkConfig()
+	if configured, ok := sink.(*configuredSink); ok {
+		sink, config = configured.Sink, configured.config
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	return &configuredSink{Sink: sink, config: config}
+}
+
+// WithSinks fans change events out to sinks, in addition to the onChange
+// callback. Use ConfigureSink to change the retries and error handling of a
+// sink.
+func WithSinks(sinks ...Sink) Option {
+	return func(t *Tracker) {
+		t.sinks = append(t.sinks, sinks...)
+	}
+}
+
+func defaultSinkConfig() sinkConfig {
+	return sinkConfig{
+		maxAttempts: 3,
+		backoff:     100 * time.Millisecond,
+		timeout:     5 * time.Second,
+	}
+}
+
+// sinkHandler returns the dispatch callback delivering events to sink
+func sinkHandler(sink Sink) OnChangeFunc {
+	config := defaultSinkConfig()
+	if configured, ok := sink.(*configuredSink); ok {
+		sink, config = configured.Sink, configured.config
+	}
+	return func(event *ChangeEvent) {
+		backoff := config.backoff
+		var err error
+		for attempt := 1; ; attempt++ {
+			ctx, cancel := context.WithTimeout(context.Background(), config.timeout)
+			err = sink.Send(ctx, event)
+			cancel()
+			if err == nil || attempt >= config.maxAttempts {
+				break
+			}
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err != nil && config.onError != nil {
+			config.onError(event, err)
+		}
+	}
+}
+
+// ChannelSink buffers change events in a channel read with Events.
+type ChannelSink struct {
+	events chan *ChangeEvent
+}
+
+// NewChannelSink returns a sink buffering up to size events. Sending to a
+// full sink fails with ErrSinkFull, so the event is retried and then handed to
+// the error handler.
+func NewChannelSink(size int) *ChannelSink {
+	return &ChannelSink{events: make(chan *ChangeEvent, size)}
+}
+
+func (s *ChannelSink) Send(ctx context.Context, event *ChangeEvent) error {
+	select {
+	case s.events <- event:
+		return nil
+	default:
+		return ErrSinkFull
+	}
+}
+
+// Events returns the channel the events are buffered in.
+func (s *ChannelSink) Events() <-chan *ChangeEvent {
+	return s.events
+}
+
+// Close closes the events channel. It must only be called once the tracker
+// is stopped.
+func (s *ChannelSink) Close() {
+	close(s.events)
+}
+
+// Attributes set on the messages published by PubSubSink.
+const (
+	PubSubUserIDAttribute   = "user_id"
+	PubSubTriggersAttribute = "triggers" // comma separated
+)
+
+type pubSubSink struct {
+	client *pubsub.Client
+	topic  string
+	opts   []pubsub.PublishOption
+}
+
+// NewPubSubSink returns a sink publishing every change event to topic with
+// client, encoded by the client encoder, JSON by default. The user ID and
+// triggers are set as attributes for subscription filters.
+func NewPubSubSink(client *pubsub.Client, topic string, opts ...pubsub.PublishOption) Sink {
+	return &pubSubSink{client: client, topic: topic, opts: opts}
+}
+
+func (s *pubSubSink) Send(ctx context.Context, event *ChangeEvent) error {
+	opts := append([]pubsub.PublishOption{pubsub.WithAttributes(map[string]string{
+		PubSubUserIDAttribute:   strconv.FormatInt(event.UserID, 10),
+		PubSubTriggersAttribute: strings.Join(event.Triggers, ","),
+	})}, s.opts...)
+	_, err := s.client.Publish(ctx, s.topic, event, opts...)
+	return err
+}
@@ -0,0 +1,171 @@
+package sessiontracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const dateLayout = "2006-01-02"
+
+// Aggregator maintains session statistics in Redis as the Tracker runs, so
+// analytics do not have to rebuild them from the change events:
+//   - the daily active users of each operator, in a HyperLogLog per operator
+//     and UTC day, whose counts are estimates with a 0.81% standard error
+//   - the IPs and devices (user agent hashes) of each user, in sorted sets
+//     scored by the time they were last seen
+//
+// A user counts as active for every operator level of their requests: the
+// real, company, retailer and system operators.
+type Aggregator struct {
+	client    *redis.Client
+	keyPrefix string
+	retention time.Duration
+}
+
+type AggregatorOption func(*Aggregator)
+
+// WithAggregatorKeyPrefix sets the prefix of the statistics keys.
+// Default: "session_stats".
+func WithAggregatorKeyPrefix(p string) AggregatorOption {
+	return func(a *Aggregator) {
+		a.keyPrefix = p
+	}
+}
+
+// WithRetention sets how long the statistics are kept: daily active users
+// per day, and the IPs and devices of a user since they were last seen.
+// Default: 90 days.
+func WithRetention(d time.Duration) AggregatorOption {
+	return func(a *Aggregator) {
+		if d > 0 {
+			a.retention = d
+		}
+	}
+}
+
+// NewAggregator creates an Aggregator storing its statistics in Redis. Pass
+// it to the Tracker with WithAggregator.
+func NewAggregator(client *redis.Client, opts ...AggregatorOption) *Aggregator {
+	a := &Aggregator{
+		client:    client,
+		keyPrefix: "session_stats",
+		retention: 90 * 24 * time.Hour,
+	}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+// record adds a session activity to the statistics in a single round trip.
+func (a *Aggregator) record(ctx context.Context, req *TrackRequest, uaHash string, now time.Time) error {
+	date := now.UTC().Format(dateLayout)
+	pipe := a.client.Pipeline()
+	for _, operatorID := range operatorIDs(req) {
+		key := a.dauKey(operatorID, date)
+		pipe.PFAdd(ctx, key, req.UserID)
+		pipe.Expire(ctx, key, a.retention)
+	}
+	a.addSeen(ctx, pipe, a.ipsKey(req.UserID), req.IP, now)
+	if req.UserAgent != "" {
+		a.addSeen(ctx, pipe, a.devicesKey(req.UserID), uaHash, now)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// addSeen records member as seen at now in the sorted set key, dropping the
+// members not seen within the retention.
+func (a *Aggregator) addSeen(ctx context.Context, pipe redis.Pipeliner, key, member string, now time.Time) {
+	if member == "" {
+		return
+	}
+	expired := "(" + strconv.FormatInt(now.Add(-a.retention).UnixMilli(), 10)
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixMilli()), Member: member})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", expired)
+	pipe.Expire(ctx, key, a.retention)
+}
+
+// GetDailyActiveUsers returns the estimated number of users active for
+// operatorID on the UTC day of date.
+func (a *Aggregator) GetDailyActiveUsers(ctx context.Context, operatorID int64, date time.Time) (int64, error) {
+	return a.client.PFCount(ctx, a.dauKey(operatorID, date.UTC().Format(dateLayout))).Result()
+}
+
+// GetActiveUsers returns the estimated number of distinct users active for
+// operatorID between the UTC days of from and to included, e.g. the monthly
+// active users.
+func (a *Aggregator) GetActiveUsers(ctx context.Context, operatorID int64, from, to time.Time) (int64, error) {
+	from, to = from.UTC(), to.UTC()
+	if to.Before(from) {
+		return 0, errors.New("sessiontracker: to is before from")
+	}
+	var keys []string
+	for day := from; day.Format(dateLayout) <= to.Format(dateLayout); day = day.AddDate(0, 0, 1) {
+		keys = append(keys, a.dauKey(operatorID, day.Format(dateLayout)))
+	}
+	// The days are merged into a temporary key rather than counted together
+	// by PFCOUNT, which some Redis compatible servers do not union.
+	tmp := a.dauKey(operatorID, "tmp:"+uuid.NewString())
+	pipe := a.client.TxPipeline()
+	pipe.PFMerge(ctx, tmp, keys...)
+	count := pipe.PFCount(ctx, tmp)
+	pipe.Del(ctx, tmp)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
+
+// GetUniqueIPCount returns the number of distinct IPs userID was seen with
+// since the given time.
+func (a *Aggregator) GetUniqueIPCount(ctx context.Context, userID int64, since time.Time) (int64, error) {
+	return a.client.ZCount(ctx, a.ipsKey(userID), strconv.FormatInt(since.UnixMilli(), 10), "+inf").Result()
+}
+
+// GetUserIPs returns the IPs userID was seen with since the given time, most
+// recently seen first.
+func (a *Aggregator) GetUserIPs(ctx context.Context, userID int64, since time.Time) ([]string, error) {
+	return a.client.ZRevRangeByScore(ctx, a.ipsKey(userID), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+}
+
+// GetDeviceCount returns the number of distinct devices, told apart by their
+// user agent, userID was seen with since the given time.
+func (a *Aggregator) GetDeviceCount(ctx context.Context, userID int64, since time.Time) (int64, error) {
+	return a.client.ZCount(ctx, a.devicesKey(userID), strconv.FormatInt(since.UnixMilli(), 10), "+inf").Result()
+}
+
+// dauKey hash tags the operator so that the days of an operator can be
+// counted together on Redis Cluster.
+func (a *Aggregator) dauKey(operatorID int64, date string) string {
+	return fmt.Sprintf("%s:dau:{%d}:%s", a.keyPrefix, operatorID, date)
+}
+
+func (a *Aggregator) ipsKey(userID int64) string {
+	return fmt.Sprintf("%s:ips:%d", a.keyPrefix, userID)
+}
+
+func (a *Aggregator) devicesKey(userID int64) string {
+	return fmt.Sprintf("%s:devices:%d", a.keyPrefix, userID)
+}
+
+// operatorIDs returns the distinct non-zero operator levels of req.
+func operatorIDs(req *TrackRequest) []int64 {
+	ids := make([]int64, 0, 4)
+	for _, id := range []int64{req.RealOperatorID, req.CompanyOperatorID, req.RetailerOperatorID, req.SystemOperatorID} {
+		if id != 0 && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,138 @@
+package sessiontracker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SessionStore is the L2 backend holding the last known session state of each
+// user as a set of string fields.
+type SessionStore interface {
+	// Get returns the fields stored under key, or an empty map if the key
+	// does not exist.
+	Get(ctx context.Context, key string) (map[string]string, error)
+	// Set merges fields into the entry stored under key.
+	Set(ctx context.Context, key string, fields map[string]string) error
+	// Expire sets the time-to-live of key.
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// BatchSessionStore is optionally implemented by stores that can write many
+// entries in one round trip. The Tracker uses it to flush buffered writes.
+type BatchSessionStore interface {
+	SessionStore
+	// SetBatch sets the fields and time-to-live of every key in entries.
+	SetBatch(ctx context.Context, entries map[string]map[string]string, ttl time.Duration) error
+}
+
+// RedisStore stores sessions as Redis hashes.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a SessionStore backed by Redis.
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (map[string]string, error) {
+	return s.client.HGetAll(ctx, key).Result()
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, fields map[string]string) error {
+	return s.client.HSet(ctx, key, fields).Err()
+}
+
+func (s *RedisStore) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return s.client.Expire(ctx, key, ttl).Err()
+}
+
+// SetBatch writes all entries in a single MULTI/EXEC pipeline.
+func (s *RedisStore) SetBatch(ctx context.Context, entries map[string]map[string]string, ttl time.Duration) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, fields := range entries {
+			pipe.HSet(ctx, key, fields)
+			pipe.Expire(ctx, key, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+type memoryEntry struct {
+	fields map[string]string
+	expiry time.Time // zero means no expiry
+}
+
+// MemoryStore is an in-process SessionStore, for tests and single instance
+// deployments. Expired entries are removed lazily on access.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory SessionStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*memoryEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.load(key)
+	if entry == nil {
+		return map[string]string{}, nil
+	}
+	fields := make(map[string]string, len(entry.fields))
+	for k, v := range entry.fields {
+		fields[k] = v
+	}
+	return fields, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, fields map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.load(key)
+	if entry == nil {
+		entry = &memoryEntry{fields: make(map[string]string, len(fields))}
+		s.entries[key] = entry
+	}
+	for k, v := range fields {
+		entry.fields[k] = v
+	}
+	return nil
+}
+
+func (s *MemoryStore) Expire(_ context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry := s.load(key); entry != nil {
+		entry.expiry = s.now().Add(ttl)
+	}
+	return nil
+}
+
+// load returns the live entry for key, dropping it if expired. Must be called
+// with s.mu held.
+func (s *MemoryStore) load(key string) *memoryEntry {
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+	if !entry.expiry.IsZero() && !s.now().Before(entry.expiry) {
+		delete(s.entries, key)
+		return nil
+	}
+	return entry
+}
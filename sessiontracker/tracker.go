@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -21,8 +22,11 @@ type TrackRequest struct {
 	OperatorType       string // "operator" | "company" | "retailer" | "system"
 	IP                 string
 	UserAgent          string
-	Country            string
+	Country            string // filled by the Resolver when empty
+	City               string // filled by the Resolver when empty
+	ASN                uint   // filled by the Resolver when 0
 	ClientSource       string // client source from the request (e.g. "pwa")
+	LoginMethod        string // how the user authenticated (e.g. "password", "google")
 }
 
 // Trigger name constants for session activity change detection.
@@ -42,7 +46,8 @@ type ChangeEvent struct {
 	RetailerOperatorID int64
 	SystemOperatorID   int64
 	OperatorType       string
-	Triggers           []string // e.g. ["daily_visit", "ip_change", "device_change", "client_source_change"]
+	Triggers           []string                     // e.g. ["daily_visit", "ip_change", "device_change", "client_source_change"]
+	Metadata           map[string]map[string]string // rule metadata keyed by trigger name
 	IP                 string
 	PrevIP             string
 	UserAgent          string
@@ -50,29 +55,45 @@ type ChangeEvent struct {
 	PrevUAHash         string
 	Country            string
 	PrevCountry        string
+	City               string
+	PrevCity           string
+	ASN                uint
+	PrevASN            uint
 	ClientSource       string
 	PrevClientSource   string
+	LoginMethod        string
+	PrevLoginMethod    string
 	Timestamp          int64
 }
 
-// OnChangeFunc is called asynchronously when a change is detected.
+// OnChangeFunc is called asynchronously, from the dispatch worker pool, when a
+// change is detected.
 type OnChangeFunc func(event *ChangeEvent)
 
 type l1Entry struct {
 	ip           string
 	uaHash       string
 	country      string
+	city         string
+	asn          uint
 	date         string
 	clientSource string
+	loginMethod  string
 	expiry       time.Time
 }
 
-// Tracker provides two-level caching (L1 in-process, L2 Redis) for session
-// activity tracking. When a change is detected (new day, IP change, device
-// change), it invokes the registered callback asynchronously.
+// Tracker provides two-level caching (L1 in-process, L2 SessionStore, Redis by
+// default) for session activity tracking. When a change is detected (new day,
+// IP change, device change or a matched Rule), it invokes the registered
+// callback and sinks asynchronously.
 type Tracker struct {
-	redisClient *redis.Client
-	onChange    OnChangeFunc
+	store      SessionStore
+	onChange   OnChangeFunc
+	sinks      []Sink
+	rules      []Rule
+	resolver   Resolver
+	aggregator *Aggregator
+	now        func() time.Time
 
 	l1    sync.Map // map[int64]*l1Entry
 	l1TTL time.Duration
@@ -81,58 +102,126 @@ type Tracker struct {
 	l2TTL           time.Duration
 	cleanupInterval time.Duration
 
+	// Write-behind buffer of L2 updates, coalesced per user.
+	pendingMu     sync.Mutex
+	pending       map[int64]map[string]string
+	flushInterval time.Duration
+	flushSize     int
+	flushCh       chan struct{}
+
+	// Bounded dispatch of change events to onChange and each sink.
+	dispatchers       []*dispatcher
+	dispatchWorkers   int
+	dispatchQueueSize int
+	overflowPolicy    OverflowPolicy
+	onDrop            func(event *ChangeEvent)
+	drainTimeout      time.Duration
+
 	stopCh chan struct{}
 	wg     sync.WaitGroup
 }
 
-// New creates a new Tracker. The onChange callback is invoked in a separate
-// goroutine whenever a trackable change is detected.
+// New creates a new Tracker backed by Redis. The onChange callback is invoked
+// in a separate goroutine whenever a trackable change is detected.
 func New(redisClient *redis.Client, onChange OnChangeFunc, opts ...Option) *Tracker {
+	return NewWithStore(NewRedisStore(redisClient), onChange, opts...)
+}
+
+// NewWithStore creates a new Tracker using store as L2.
+func NewWithStore(store SessionStore, onChange OnChangeFunc, opts ...Option) *Tracker {
 	t := &Tracker{
-		redisClient:     redisClient,
-		onChange:        onChange,
-		l1TTL:           5 * time.Minute,
-		redisKeyPrefix:  "session_ctx",
-		l2TTL:           30 * 24 * time.Hour,
-		cleanupInterval: 10 * time.Minute,
-		stopCh:          make(chan struct{}),
+		store:             store,
+		onChange:          onChange,
+		l1TTL:             5 * time.Minute,
+		redisKeyPrefix:    "session_ctx",
+		l2TTL:             30 * 24 * time.Hour,
+		cleanupInterval:   10 * time.Minute,
+		pending:           make(map[int64]map[string]string),
+		flushSize:         1000,
+		flushCh:           make(chan struct{}, 1),
+		dispatchWorkers:   16,
+		dispatchQueueSize: 10000,
+		overflowPolicy:    OverflowDropNewest,
+		drainTimeout:      5 * time.Second,
+		stopCh:            make(chan struct{}),
+		now:               time.Now,
 	}
 	for _, o := range opts {
 		o(t)
 	}
 
+	if t.onChange != nil {
+		t.dispatchers = append(t.dispatchers, newDispatcher(t.onChange, t.onDrop, t.overflowPolicy, t.dispatchWorkers, t.dispatchQueueSize))
+	}
+	for _, sink := range t.sinks {
+		t.dispatchers = append(t.dispatchers, newDispatcher(sinkHandler(sink), t.onDrop, t.overflowPolicy, t.dispatchWorkers, t.dispatchQueueSize))
+	}
+
 	// Start L1 cleanup goroutine.
 	t.wg.Add(1)
 	go t.cleanupLoop(t.cleanupInterval)
 
+	// Start L2 write-behind flush goroutine.
+	if t.flushInterval > 0 {
+		t.wg.Add(1)
+		go t.flushLoop(t.flushInterval)
+	}
+
 	return t
 }
 
 // Track records a session activity for the given user. It is safe to call
 // concurrently from multiple goroutines.
 func (t *Tracker) Track(ctx context.Context, req *TrackRequest) {
+	now := t.now()
 	uaHash := hashUA(req.UserAgent)
-	date := time.Now().UTC().Format("2006-01-02")
+	date := now.UTC().Format("2006-01-02")
 
-	// L1 lookup
+	var entry *l1Entry
 	if v, ok := t.l1.Load(req.UserID); ok {
-		entry := v.(*l1Entry)
-		if time.Now().Before(entry.expiry) &&
+		entry = v.(*l1Entry)
+	}
+	req = t.resolveLocation(req, entry, now)
+
+	// L1 lookup
+	if entry != nil {
+		if now.Before(entry.expiry) &&
 			entry.date == date &&
 			entry.ip == req.IP &&
 			entry.uaHash == uaHash &&
-			entry.clientSource == req.ClientSource {
+			entry.country == req.Country &&
+			entry.clientSource == req.ClientSource &&
+			entry.loginMethod == req.LoginMethod {
 			return // no change
 		}
 	}
 
+	if t.aggregator != nil {
+		// Statistics are best effort, like the L2 writes
+		_ = t.aggregator.record(ctx, req, uaHash, now)
+	}
+
 	// L2 lookup
-	redisKey := fmt.Sprintf("%s:%d", t.redisKeyPrefix, req.UserID)
-	cached, err := t.redisClient.HGetAll(ctx, redisKey).Result()
+	redisKey := t.redisKey(req.UserID)
+	cached, err := t.loadL2(ctx, req.UserID, redisKey)
+
+	fields := map[string]string{
+		"ip":            req.IP,
+		"ua_hash":       uaHash,
+		"country":       req.Country,
+		"city":          req.City,
+		"asn":           strconv.FormatUint(uint64(req.ASN), 10),
+		"date":          date,
+		"client_source": req.ClientSource,
+		"login_method":  req.LoginMethod,
+		"seen_at":       strconv.FormatInt(now.UnixMilli(), 10),
+	}
 
 	var triggers []string
-	var prevIP, prevUAHash, prevCountry string
-	var prevClientSource string
+	var metadata map[string]map[string]string
+	var prevIP, prevUAHash, prevCountry, prevCity string
+	var prevClientSource, prevLoginMethod string
+	var prevASN uint
 
 	if err != nil || len(cached) == 0 {
 		// No L2 entry — first time or expired
@@ -141,8 +230,13 @@ func (t *Tracker) Track(ctx context.Context, req *TrackRequest) {
 		prevIP = cached["ip"]
 		prevUAHash = cached["ua_hash"]
 		prevCountry = cached["country"]
+		prevCity = cached["city"]
+		if asn, err := strconv.ParseUint(cached["asn"], 10, 0); err == nil {
+			prevASN = uint(asn)
+		}
 		cachedDate := cached["date"]
 		prevClientSource = cached["client_source"]
+		prevLoginMethod = cached["login_method"]
 
 		if cachedDate != date {
 			triggers = append(triggers, TriggerDailyVisit)
@@ -158,42 +252,45 @@ func (t *Tracker) Track(ctx context.Context, req *TrackRequest) {
 			triggers = append(triggers, TriggerClientSourceChange)
 		}
 
+		if len(t.rules) > 0 {
+			prev := &TrackRequest{
+				UserID:       req.UserID,
+				IP:           prevIP,
+				Country:      prevCountry,
+				City:         prevCity,
+				ASN:          prevASN,
+				ClientSource: prevClientSource,
+				LoginMethod:  prevLoginMethod,
+			}
+			elapsed := time.Duration(-1)
+			if seenAt, err := strconv.ParseInt(cached["seen_at"], 10, 64); err == nil {
+				elapsed = now.Sub(time.UnixMilli(seenAt))
+			}
+			var ruleTriggers []string
+			ruleTriggers, metadata = t.evaluateRules(prev, req, elapsed)
+			triggers = append(triggers, ruleTriggers...)
+		}
+
 		// If L2 exists but nothing changed, just refresh L1 and return.
 		if len(triggers) == 0 {
-			t.l1.Store(req.UserID, &l1Entry{
-				ip:           req.IP,
-				uaHash:       uaHash,
-				country:      req.Country,
-				date:         date,
-				clientSource: req.ClientSource,
-				expiry:       time.Now().Add(t.l1TTL),
-			})
+			t.storeL1(req, uaHash, date, now)
+			// Rules comparing against the last seen time need it refreshed
+			// on every L1 miss, at the cost of an L2 write per L1 TTL.
+			if len(t.rules) > 0 {
+				t.storeL2(ctx, req.UserID, redisKey, fields)
+			}
 			return
 		}
 	}
 
 	// Update L1
-	t.l1.Store(req.UserID, &l1Entry{
-		ip:           req.IP,
-		uaHash:       uaHash,
-		country:      req.Country,
-		date:         date,
-		clientSource: req.ClientSource,
-		expiry:       time.Now().Add(t.l1TTL),
-	})
+	t.storeL1(req, uaHash, date, now)
 
 	// Update L2
-	t.redisClient.HSet(ctx, redisKey, map[string]interface{}{
-		"ip":            req.IP,
-		"ua_hash":       uaHash,
-		"country":       req.Country,
-		"date":          date,
-		"client_source": req.ClientSource,
-	})
-	t.redisClient.Expire(ctx, redisKey, t.l2TTL)
+	t.storeL2(ctx, req.UserID, redisKey, fields)
 
 	// Fire callback asynchronously
-	if t.onChange != nil && len(triggers) > 0 {
+	if len(t.dispatchers) > 0 && len(triggers) > 0 {
 		event := &ChangeEvent{
 			UserID:             req.UserID,
 			OperatorID:         req.RealOperatorID,
@@ -203,6 +300,7 @@ func (t *Tracker) Track(ctx context.Context, req *TrackRequest) {
 			SystemOperatorID:   req.SystemOperatorID,
 			OperatorType:       req.OperatorType,
 			Triggers:           triggers,
+			Metadata:           metadata,
 			IP:                 req.IP,
 			PrevIP:             prevIP,
 			UserAgent:          req.UserAgent,
@@ -210,18 +308,177 @@ func (t *Tracker) Track(ctx context.Context, req *TrackRequest) {
 			PrevUAHash:         prevUAHash,
 			Country:            req.Country,
 			PrevCountry:        prevCountry,
+			City:               req.City,
+			PrevCity:           prevCity,
+			ASN:                req.ASN,
+			PrevASN:            prevASN,
 			ClientSource:       req.ClientSource,
 			PrevClientSource:   prevClientSource,
-			Timestamp:          time.Now().UnixMilli(),
+			LoginMethod:        req.LoginMethod,
+			PrevLoginMethod:    prevLoginMethod,
+			Timestamp:          now.UnixMilli(),
+		}
+		for _, d := range t.dispatchers {
+			d.dispatch(ctx, event)
 		}
-		go t.onChange(event)
 	}
 }
 
-// Stop shuts down the background cleanup goroutine.
+// resolveLocation returns req with its empty location fields filled by the
+// Resolver. The location cached in L1 is reused while the IP is unchanged, so
+// the Resolver is only called on IP changes and L1 expiry.
+func (t *Tracker) resolveLocation(req *TrackRequest, entry *l1Entry, now time.Time) *TrackRequest {
+	if t.resolver == nil || req.IP == "" || (req.Country != "" && req.City != "" && req.ASN != 0) {
+		return req
+	}
+
+	var loc Location
+	if entry != nil && entry.ip == req.IP && now.Before(entry.expiry) {
+		loc = Location{Country: entry.country, City: entry.city, ASN: entry.asn}
+	} else {
+		// Unresolvable IPs, e.g. private ones, are tracked without location
+		loc, _ = t.resolver.ResolveIP(req.IP)
+	}
+
+	enriched := *req
+	if enriched.Country == "" {
+		enriched.Country = loc.Country
+	}
+	if enriched.City == "" {
+		enriched.City = loc.City
+	}
+	if enriched.ASN == 0 {
+		enriched.ASN = loc.ASN
+	}
+	return &enriched
+}
+
+func (t *Tracker) storeL1(req *TrackRequest, uaHash, date string, now time.Time) {
+	t.l1.Store(req.UserID, &l1Entry{
+		ip:           req.IP,
+		uaHash:       uaHash,
+		country:      req.Country,
+		city:         req.City,
+		asn:          req.ASN,
+		date:         date,
+		clientSource: req.ClientSource,
+		loginMethod:  req.LoginMethod,
+		expiry:       now.Add(t.l1TTL),
+	})
+}
+
+// Stop shuts down the background goroutines, flushes buffered L2 writes and
+// waits up to the drain timeout for queued change events to be delivered.
 func (t *Tracker) Stop() {
 	close(t.stopCh)
 	t.wg.Wait()
+	t.flush()
+	var drained sync.WaitGroup
+	for _, d := range t.dispatchers {
+		drained.Add(1)
+		go func() {
+			defer drained.Done()
+			d.close(t.drainTimeout)
+		}()
+	}
+	drained.Wait()
+}
+
+// Dropped returns the number of change events dropped because a dispatch
+// queue was full or the tracker was stopped, counted once per sink.
+func (t *Tracker) Dropped() uint64 {
+	var dropped uint64
+	for _, d := range t.dispatchers {
+		dropped += d.dropped.Load()
+	}
+	return dropped
+}
+
+func (t *Tracker) redisKey(userID int64) string {
+	return fmt.Sprintf("%s:%d", t.redisKeyPrefix, userID)
+}
+
+// loadL2 returns the L2 state of a user, preferring a buffered write that has
+// not been flushed yet.
+func (t *Tracker) loadL2(ctx context.Context, userID int64, redisKey string) (map[string]string, error) {
+	t.pendingMu.Lock()
+	fields, ok := t.pending[userID]
+	t.pendingMu.Unlock()
+	if ok {
+		return fields, nil
+	}
+	return t.store.Get(ctx, redisKey)
+}
+
+// storeL2 writes the L2 state of a user, either directly or through the
+// write-behind buffer when a flush interval is configured.
+func (t *Tracker) storeL2(ctx context.Context, userID int64, redisKey string, fields map[string]string) {
+	if t.flushInterval <= 0 {
+		if err := t.store.Set(ctx, redisKey, fields); err == nil {
+			_ = t.store.Expire(ctx, redisKey, t.l2TTL)
+		}
+		return
+	}
+
+	t.pendingMu.Lock()
+	t.pending[userID] = fields
+	full := len(t.pending) >= t.flushSize
+	t.pendingMu.Unlock()
+
+	if full {
+		select {
+		case t.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (t *Tracker) flushLoop(interval time.Duration) {
+	defer t.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.flush()
+		case <-t.flushCh:
+			t.flush()
+		case <-t.stopCh:
+			return
+		}
+	}
+}
+
+// flush writes all buffered L2 updates, in a single round trip if the store
+// implements BatchSessionStore.
+func (t *Tracker) flush() {
+	t.pendingMu.Lock()
+	if len(t.pending) == 0 {
+		t.pendingMu.Unlock()
+		return
+	}
+	batch := t.pending
+	t.pending = make(map[int64]map[string]string, len(batch))
+	t.pendingMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if bs, ok := t.store.(BatchSessionStore); ok {
+		entries := make(map[string]map[string]string, len(batch))
+		for userID, fields := range batch {
+			entries[t.redisKey(userID)] = fields
+		}
+		_ = bs.SetBatch(ctx, entries, t.l2TTL)
+		return
+	}
+	for userID, fields := range batch {
+		redisKey := t.redisKey(userID)
+		if err := t.store.Set(ctx, redisKey, fields); err == nil {
+			_ = t.store.Expire(ctx, redisKey, t.l2TTL)
+		}
+	}
 }
 
 func (t *Tracker) cleanupLoop(interval time.Duration) {
@@ -0,0 +1,462 @@
+package sessiontracker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/infigaming-com/go-common/pubsub"
+	"github.com/infigaming-com/go-common/pubsub/driver/memory"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectEvents(t *testing.T) (OnChangeFunc, <-chan *ChangeEvent) {
+	t.Helper()
+	events := make(chan *ChangeEvent, 16)
+	return func(event *ChangeEvent) { events <- event }, events
+}
+
+func nextEvent(t *testing.T, events <-chan *ChangeEvent) *ChangeEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no change event")
+		return nil
+	}
+}
+
+func TestTracker_DetectsChanges(t *testing.T) {
+	store := NewMemoryStore()
+	onChange, events := collectEvents(t)
+	tracker := NewWithStore(store, onChange, WithFlushInterval(0), WithL1TTL(0))
+	defer tracker.Stop()
+
+	ctx := context.Background()
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "1.1.1.1", UserAgent: "ua"})
+	assert.Equal(t, []string{TriggerDailyVisit}, nextEvent(t, events).Triggers)
+
+	// No change, no event
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "1.1.1.1", UserAgent: "ua"})
+
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "2.2.2.2", UserAgent: "other"})
+	event := nextEvent(t, events)
+	assert.Equal(t, []string{TriggerIPChange, TriggerDeviceChange}, event.Triggers)
+	assert.Equal(t, "1.1.1.1", event.PrevIP)
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %+v", event)
+	default:
+	}
+
+	fields, err := store.Get(ctx, "session_ctx:1")
+	require.NoError(t, err)
+	assert.Equal(t, "2.2.2.2", fields["ip"])
+}
+
+func TestTracker_WriteBehind(t *testing.T) {
+	store := NewMemoryStore()
+	onChange, events := collectEvents(t)
+	tracker := NewWithStore(store, onChange, WithFlushInterval(time.Hour), WithL1TTL(0))
+
+	ctx := context.Background()
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "1.1.1.1"})
+	nextEvent(t, events)
+
+	fields, err := store.Get(ctx, "session_ctx:1")
+	require.NoError(t, err)
+	assert.Empty(t, fields, "write should be buffered")
+
+	// Buffered state is still used for change detection
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "2.2.2.2"})
+	assert.Equal(t, []string{TriggerIPChange}, nextEvent(t, events).Triggers)
+
+	tracker.Stop()
+	fields, err = store.Get(ctx, "session_ctx:1")
+	require.NoError(t, err)
+	assert.Equal(t, "2.2.2.2", fields["ip"])
+}
+
+func TestTracker_WriteThroughByDefault(t *testing.T) {
+	store := NewMemoryStore()
+	tracker := NewWithStore(store, nil, WithL1TTL(0))
+	defer tracker.Stop()
+
+	ctx := context.Background()
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "1.1.1.1"})
+
+	fields, err := store.Get(ctx, "session_ctx:1")
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.1.1", fields["ip"], "write should not be buffered")
+}
+
+func TestMemoryStore_Expire(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.NoError(t, store.Set(ctx, "k", map[string]string{"a": "1"}))
+	require.NoError(t, store.Set(ctx, "k", map[string]string{"b": "2"}))
+	require.NoError(t, store.Expire(ctx, "k", time.Minute))
+
+	fields, err := store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, fields)
+
+	now = now.Add(time.Minute)
+	fields, err = store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Empty(t, fields)
+}
+
+func TestTracker_Rules(t *testing.T) {
+	store := NewMemoryStore()
+	onChange, events := collectEvents(t)
+	tracker := NewWithStore(store, onChange, WithFlushInterval(0), WithL1TTL(0),
+		WithRules(
+			ImpossibleTravelRule(30*time.Minute),
+			LoginMethodSwitchRule(),
+			Rule{
+				Trigger: "admin_console",
+				Match: func(prev, curr *TrackRequest, _ time.Duration) (map[string]string, bool) {
+					return nil, curr.ClientSource == "admin" && prev.ClientSource != "admin"
+				},
+			},
+		),
+	)
+	defer tracker.Stop()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "1.1.1.1", Country: "DE", LoginMethod: "password"})
+	assert.Equal(t, []string{TriggerDailyVisit}, nextEvent(t, events).Triggers)
+
+	// Unchanged sessions refresh the last seen time
+	now = now.Add(time.Hour)
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "1.1.1.1", Country: "DE", LoginMethod: "password"})
+
+	now = now.Add(10 * time.Minute)
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "2.2.2.2", Country: "BR", LoginMethod: "google"})
+	event := nextEvent(t, events)
+	assert.Equal(t, []string{TriggerIPChange, TriggerImpossibleTravel, TriggerLoginMethodSwitch}, event.Triggers)
+	assert.Equal(t, map[string]string{"prev_country": "DE", "country": "BR", "elapsed": "10m0s"}, event.Metadata[TriggerImpossibleTravel])
+	assert.Equal(t, map[string]string{"prev_login_method": "password", "login_method": "google"}, event.Metadata[TriggerLoginMethodSwitch])
+	assert.Equal(t, "password", event.PrevLoginMethod)
+
+	// A country change after the window is not impossible travel
+	now = now.Add(time.Hour)
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "3.3.3.3", Country: "DE", LoginMethod: "google", ClientSource: "admin"})
+	event = nextEvent(t, events)
+	assert.Equal(t, []string{TriggerIPChange, "admin_console"}, event.Triggers)
+	assert.Nil(t, event.Metadata)
+}
+
+func TestTracker_BoundedDispatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    OverflowPolicy
+		delivered []int64
+	}{
+		{name: "drop newest", policy: OverflowDropNewest, delivered: []int64{1, 2}},
+		{name: "drop oldest", policy: OverflowDropOldest, delivered: []int64{1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			started := make(chan struct{}, 3)
+			delivered := make(chan int64, 3)
+			var dropped []int64
+			tracker := NewWithStore(NewMemoryStore(), func(event *ChangeEvent) {
+				started <- struct{}{}
+				<-release
+				delivered <- event.UserID
+			},
+				WithFlushInterval(0),
+				WithDispatchWorkers(1),
+				WithDispatchQueueSize(1),
+				WithOverflowPolicy(tt.policy),
+				WithDropHandler(func(event *ChangeEvent) { dropped = append(dropped, event.UserID) }),
+			)
+
+			ctx := context.Background()
+			tracker.Track(ctx, &TrackRequest{UserID: 1})
+			<-started // the only worker is busy with user 1
+			tracker.Track(ctx, &TrackRequest{UserID: 2})
+			tracker.Track(ctx, &TrackRequest{UserID: 3})
+			assert.Equal(t, uint64(1), tracker.Dropped())
+
+			close(release)
+			tracker.Stop()
+			close(delivered)
+
+			var got []int64
+			for id := range delivered {
+				got = append(got, id)
+			}
+			assert.Equal(t, tt.delivered, got)
+			assert.Len(t, dropped, 1)
+		})
+	}
+}
+
+func TestTracker_DrainTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	tracker := NewWithStore(NewMemoryStore(), func(event *ChangeEvent) { <-release },
+		WithFlushInterval(0),
+		WithDispatchWorkers(1),
+		WithDrainTimeout(50*time.Millisecond),
+	)
+
+	ctx := context.Background()
+	tracker.Track(ctx, &TrackRequest{UserID: 1})
+	tracker.Track(ctx, &TrackRequest{UserID: 2})
+
+	start := time.Now()
+	tracker.Stop()
+	assert.Less(t, time.Since(start), time.Second, "Stop should give up after the drain timeout")
+
+	// Events tracked after Stop are dropped
+	tracker.Track(ctx, &TrackRequest{UserID: 3})
+	assert.GreaterOrEqual(t, tracker.Dropped(), uint64(1))
+}
+
+func TestTracker_StopWithBlockedDispatch(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{}, 1)
+	tracker := NewWithStore(NewMemoryStore(), func(event *ChangeEvent) {
+		started <- struct{}{}
+		<-release
+	},
+		WithDispatchWorkers(1),
+		WithDispatchQueueSize(1),
+		WithOverflowPolicy(OverflowBlock),
+		WithDrainTimeout(50*time.Millisecond),
+	)
+
+	ctx := context.Background()
+	tracker.Track(ctx, &TrackRequest{UserID: 1})
+	<-started // the only worker is stuck with user 1
+	tracker.Track(ctx, &TrackRequest{UserID: 2})
+
+	// The queue is full, user 3 blocks until Stop releases it
+	blocked := make(chan struct{})
+	go func() {
+		defer close(blocked)
+		tracker.Track(ctx, &TrackRequest{UserID: 3})
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		tracker.Stop()
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop should not hang on a blocked send")
+	}
+	<-blocked
+	assert.GreaterOrEqual(t, tracker.Dropped(), uint64(1))
+
+	// A blocked Track also returns when its context is done
+	tracker = NewWithStore(NewMemoryStore(), func(event *ChangeEvent) {
+		started <- struct{}{}
+		<-release
+	},
+		WithDispatchWorkers(1),
+		WithDispatchQueueSize(1),
+		WithOverflowPolicy(OverflowBlock),
+		WithDrainTimeout(50*time.Millisecond),
+	)
+	defer tracker.Stop()
+	tracker.Track(ctx, &TrackRequest{UserID: 1})
+	<-started
+	tracker.Track(ctx, &TrackRequest{UserID: 2})
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	tracker.Track(timeoutCtx, &TrackRequest{UserID: 3})
+	assert.Equal(t, uint64(1), tracker.Dropped())
+}
+
+type fakeResolver struct {
+	mu        sync.Mutex
+	locations map[string]Location
+	calls     int
+}
+
+func (r *fakeResolver) ResolveIP(ip string) (Location, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	loc, ok := r.locations[ip]
+	if !ok {
+		return Location{}, errors.New("not found")
+	}
+	return loc, nil
+}
+
+func TestTracker_Resolver(t *testing.T) {
+	resolver := &fakeResolver{locations: map[string]Location{
+		"1.1.1.1": {Country: "DE", City: "Berlin", ASN: 3320},
+		"2.2.2.2": {Country: "BR", City: "São Paulo", ASN: 28573},
+	}}
+	onChange, events := collectEvents(t)
+	tracker := NewWithStore(NewMemoryStore(), onChange,
+		WithFlushInterval(0),
+		WithResolver(resolver),
+		WithRules(ImpossibleTravelRule(time.Hour)),
+	)
+	defer tracker.Stop()
+
+	ctx := context.Background()
+	req := &TrackRequest{UserID: 1, IP: "1.1.1.1"}
+	tracker.Track(ctx, req)
+	event := nextEvent(t, events)
+	assert.Equal(t, "DE", event.Country)
+	assert.Equal(t, "Berlin", event.City)
+	assert.Equal(t, uint(3320), event.ASN)
+	assert.Empty(t, req.Country, "the request must not be modified")
+
+	// Same IP within the L1 TTL: the cached location is reused
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "1.1.1.1"})
+	assert.Equal(t, 1, resolver.calls)
+
+	tracker.Track(ctx, &TrackRequest{UserID: 1, IP: "2.2.2.2"})
+	event = nextEvent(t, events)
+	assert.Equal(t, []string{TriggerIPChange, TriggerImpossibleTravel}, event.Triggers)
+	assert.Equal(t, "BR", event.Country)
+	assert.Equal(t, "DE", event.PrevCountry)
+	assert.Equal(t, "Berlin", event.PrevCity)
+	assert.Equal(t, uint(3320), event.PrevASN)
+
+	// Caller supplied location wins, unresolvable IPs are tracked as is
+	tracker.Track(ctx, &TrackRequest{UserID: 2, IP: "2.2.2.2", Country: "PT"})
+	event = nextEvent(t, events)
+	assert.Equal(t, "PT", event.Country)
+	assert.Equal(t, "São Paulo", event.City)
+
+	tracker.Track(ctx, &TrackRequest{UserID: 3, IP: "10.0.0.1"})
+	event = nextEvent(t, events)
+	assert.Empty(t, event.Country)
+}
+
+func TestTracker_Sinks(t *testing.T) {
+	ctx := context.Background()
+	transport := memory.New()
+	client, err := pubsub.New(ctx, transport)
+	require.NoError(t, err)
+	defer client.Shutdown(ctx)
+
+	channel := NewChannelSink(4)
+	var attempts atomic.Int32
+	failed := make(chan error, 1)
+	flaky := ConfigureSink(SinkFunc(func(ctx context.Context, event *ChangeEvent) error {
+		attempts.Add(1)
+		return errors.New("unavailable")
+	}), SinkRetries(2, time.Millisecond), SinkErrorHandler(func(event *ChangeEvent, err error) {
+		failed <- err
+	}))
+
+	tracker := NewWithStore(NewMemoryStore(), nil, WithFlushInterval(0), WithL1TTL(0),
+		WithSinks(channel, flaky, NewPubSubSink(client, "session-changes")))
+	tracker.Track(ctx, &TrackRequest{UserID: 7, IP: "1.1.1.1", UserAgent: "ua"})
+
+	assert.Equal(t, []string{TriggerDailyVisit}, nextEvent(t, channel.Events()).Triggers)
+	select {
+	case err := <-failed:
+		assert.EqualError(t, err, "unavailable")
+	case <-time.After(time.Second):
+		t.Fatal("error handler not called")
+	}
+	assert.Equal(t, int32(2), attempts.Load())
+
+	tracker.Stop()
+	published := transport.Published("session-changes")
+	require.Len(t, published, 1)
+	assert.Equal(t, "7", published[0].Attributes[PubSubUserIDAttribute])
+	assert.Equal(t, TriggerDailyVisit, published[0].Attributes[PubSubTriggersAttribute])
+	assert.Zero(t, tracker.Dropped())
+}
+
+func TestChannelSink_Full(t *testing.T) {
+	sink := NewChannelSink(1)
+	require.NoError(t, sink.Send(context.Background(), &ChangeEvent{UserID: 1}))
+	assert.ErrorIs(t, sink.Send(context.Background(), &ChangeEvent{UserID: 2}), ErrSinkFull)
+	sink.Close()
+	assert.Equal(t, int64(1), (<-sink.Events()).UserID)
+}
+
+func TestTracker_Aggregator(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	aggregator := NewAggregator(client, WithRetention(48*time.Hour))
+	tracker := NewWithStore(NewMemoryStore(), nil, WithFlushInterval(0), WithL1TTL(0), WithAggregator(aggregator))
+	defer tracker.Stop()
+
+	day1 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := day1
+	tracker.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	tracker.Track(ctx, &TrackRequest{UserID: 1, RealOperatorID: 10, CompanyOperatorID: 100, IP: "1.1.1.1", UserAgent: "phone"})
+	tracker.Track(ctx, &TrackRequest{UserID: 1, RealOperatorID: 10, CompanyOperatorID: 100, IP: "2.2.2.2", UserAgent: "laptop"})
+	tracker.Track(ctx, &TrackRequest{UserID: 2, RealOperatorID: 10, CompanyOperatorID: 100, IP: "3.3.3.3", UserAgent: "phone"})
+	tracker.Track(ctx, &TrackRequest{UserID: 3, RealOperatorID: 11, CompanyOperatorID: 100, IP: "4.4.4.4"})
+
+	dau, err := aggregator.GetDailyActiveUsers(ctx, 10, day1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), dau)
+	dau, err = aggregator.GetDailyActiveUsers(ctx, 100, day1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), dau, "users count for their company operator too")
+
+	ips, err := aggregator.GetUniqueIPCount(ctx, 1, day1.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), ips)
+	devices, err := aggregator.GetDeviceCount(ctx, 1, day1.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), devices)
+	devices, err = aggregator.GetDeviceCount(ctx, 3, day1.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, devices, "requests without user agent are not counted as devices")
+
+	// Next day, user 1 is back from a known IP
+	now = day1.Add(24 * time.Hour)
+	tracker.Track(ctx, &TrackRequest{UserID: 1, RealOperatorID: 10, IP: "2.2.2.2", UserAgent: "laptop"})
+
+	dau, err = aggregator.GetDailyActiveUsers(ctx, 10, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), dau)
+	active, err := aggregator.GetActiveUsers(ctx, 10, day1, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), active)
+	_, err = aggregator.GetActiveUsers(ctx, 10, now, day1)
+	assert.Error(t, err)
+
+	userIPs, err := aggregator.GetUserIPs(ctx, 1, day1.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2.2.2.2", "1.1.1.1"}, userIPs)
+
+	// IPs not seen within the retention are dropped on the next activity
+	now = day1.Add(80 * time.Hour)
+	tracker.Track(ctx, &TrackRequest{UserID: 1, RealOperatorID: 10, IP: "5.5.5.5", UserAgent: "laptop"})
+	userIPs, err = aggregator.GetUserIPs(ctx, 1, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5.5.5.5"}, userIPs)
+}
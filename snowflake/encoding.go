@@ -0,0 +1,96 @@
+package snowflake
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Encoding is a string representation of IDs. Encoded IDs have a fixed
+// width and use alphabets in ASCII order, so they sort as strings in the
+// same order as the IDs, i.e. by time.
+type Encoding int
+
+const (
+	// EncodingBase62 uses 0-9A-Za-z, 11 characters per ID. It is case
+	// sensitive.
+	EncodingBase62 Encoding = iota
+	// EncodingBase32Crockford uses Crockford's base32, 13 characters per ID.
+	// It is case insensitive and decoding maps I and L to 1 and O to 0.
+	EncodingBase32Crockford
+)
+
+const (
+	base62Alphabet          = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	base32CrockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+func (e Encoding) alphabet() string {
+	if e == EncodingBase32Crockford {
+		return base32CrockfordAlphabet
+	}
+	return base62Alphabet
+}
+
+// width is the number of characters needed for any non-negative int64
+func (e Encoding) width() int {
+	if e == EncodingBase32Crockford {
+		return 13
+	}
+	return 11
+}
+
+// String returns the name of the encoding.
+func (e Encoding) String() string {
+	if e == EncodingBase32Crockford {
+		return "base32-crockford"
+	}
+	return "base62"
+}
+
+// Encode returns the fixed width string form of id. IDs are never negative.
+func (e Encoding) Encode(id int64) string {
+	alphabet := e.alphabet()
+	base := uint64(len(alphabet))
+	buf := make([]byte, e.width())
+	n := uint64(id)
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = alphabet[n%base]
+		n /= base
+	}
+	return string(buf)
+}
+
+// Decode parses an ID encoded with Encode.
+func (e Encoding) Decode(s string) (int64, error) {
+	if len(s) != e.width() {
+		return 0, fmt.Errorf("%w: %s id must be %d characters, got %d", ErrInvalidEncodedID, e, e.width(), len(s))
+	}
+	if e == EncodingBase32Crockford {
+		s = strings.NewReplacer("I", "1", "L", "1", "O", "0").Replace(strings.ToUpper(s))
+	}
+
+	alphabet := e.alphabet()
+	base := uint64(len(alphabet))
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(alphabet, s[i])
+		if digit < 0 {
+			return 0, fmt.Errorf("%w: invalid %s character %q", ErrInvalidEncodedID, e, s[i])
+		}
+		if n > (1<<63-1-uint64(digit))/base {
+			return 0, fmt.Errorf("%w: %s id overflows int64", ErrInvalidEncodedID, e)
+		}
+		n = n*base + uint64(digit)
+	}
+	return int64(n), nil
+}
+
+// NextString generates a unique ID in the encoding set with
+// WithStringEncoding, base62 by default.
+func (g *Generator) NextString() (string, error) {
+	id, err := g.NextID()
+	if err != nil {
+		return "", err
+	}
+	return g.encoding.Encode(id), nil
+}
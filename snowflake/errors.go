@@ -17,4 +17,13 @@ var (
 
 	// ErrLeaseNotHeld is returned when trying to renew/release a lease not held by this holder.
 	ErrLeaseNotHeld = errors.New("snowflake: lease not held by this holder")
+
+	// ErrNoLeaseBackend is returned when acquiring a lease without a Redis client or a lease backend.
+	ErrNoLeaseBackend = errors.New("snowflake: no redis client or lease backend")
+
+	// ErrInvalidEncodedID is returned when decoding a string that is not an encoded ID.
+	ErrInvalidEncodedID = errors.New("snowflake: invalid encoded ID")
+
+	// ErrUnknownDomain is returned by GeneratorRegistry for a domain it was not created with.
+	ErrUnknownDomain = errors.New("snowflake: unknown domain")
 )
@@ -0,0 +1,146 @@
+package snowflake
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LeaseRenewal reports how a LeaseBackend kept a lease alive.
+type LeaseRenewal int
+
+const (
+	// LeaseRenewed means the holder still held the lease and it was extended.
+	LeaseRenewed LeaseRenewal = iota + 1
+	// LeaseReclaimed means the lease had expired and the holder took it again.
+	LeaseReclaimed
+)
+
+// LeaseBackend stores the node leases of a NodeLease. The default backend
+// keeps them in Redis; the k8s package provides one keeping them in
+// Kubernetes Lease objects.
+type LeaseBackend interface {
+	// Claim claims any available node ID (0-1023) for holder.
+	// Returns ErrNoAvailableNode if every node is held.
+	Claim(ctx context.Context, holder string, ttl time.Duration) (int64, error)
+	// Renew extends holder's lease on nodeID, or reclaims it if it expired.
+	// Returns ErrLeaseNotHeld if another holder owns the node.
+	Renew(ctx context.Context, nodeID int64, holder string, ttl time.Duration) (LeaseRenewal, error)
+	// Release releases holder's lease on nodeID.
+	// Returns ErrLeaseNotHeld if the lease is not held by holder.
+	Release(ctx context.Context, nodeID int64, holder string) error
+}
+
+// redisLeaseBackend keeps each node lease in a Redis key "{prefix}{nodeID}"
+// holding the holder identity with the lease TTL.
+type redisLeaseBackend struct {
+	client    redis.Scripter
+	keyPrefix string
+}
+
+func (b *redisLeaseBackend) Claim(ctx context.Context, holder string, ttl time.Duration) (int64, error) {
+	result, err := redis.NewScript(claimNodeLua).Run(ctx, b.client,
+		nil, // no KEYS
+		b.keyPrefix, holder, int(ttl.Seconds()),
+	).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if result < 0 {
+		return 0, ErrNoAvailableNode
+	}
+	return result, nil
+}
+
+func (b *redisLeaseBackend) Renew(ctx context.Context, nodeID int64, holder string, ttl time.Duration) (LeaseRenewal, error) {
+	result, err := redis.NewScript(renewOrReclaimLua).Run(ctx, b.client,
+		[]string{b.leaseKey(nodeID)},
+		holder, int(ttl.Seconds()),
+	).Int64()
+	if err != nil {
+		return 0, err
+	}
+
+	switch result {
+	case 1:
+		return LeaseRenewed, nil
+	case 2:
+		return LeaseReclaimed, nil
+	default:
+		return 0, ErrLeaseNotHeld
+	}
+}
+
+func (b *redisLeaseBackend) Release(ctx context.Context, nodeID int64, holder string) error {
+	result, err := redis.NewScript(releaseLeaseLua).Run(ctx, b.client,
+		[]string{b.leaseKey(nodeID)},
+		holder,
+	).Int64()
+	if err != nil {
+		return err
+	}
+	if result == 0 {
+		return ErrLeaseNotHeld
+	}
+	return nil
+}
+
+func (b *redisLeaseBackend) leaseKey(nodeID int64) string {
+	return b.keyPrefix + strconv.FormatInt(nodeID, 10)
+}
+
+// statefulSetLeaseBackend derives the node ID from the ordinal of a
+// StatefulSet pod, which Kubernetes already guarantees to be unique.
+type statefulSetLeaseBackend struct {
+	podName string
+	offset  int64
+}
+
+// NewStatefulSetLeaseBackend returns a LeaseBackend deriving the node ID from
+// the ordinal suffix of a StatefulSet pod name ("wallet-3" is node 3), plus
+// offset so several StatefulSets can share the node ID space. An empty
+// podName falls back to the POD_NAME env var, then to the hostname.
+//
+// Nothing is stored: the lease is always renewed and never taken over, so it
+// stays healthy for the lifetime of the pod.
+func NewStatefulSetLeaseBackend(podName string, offset int64) LeaseBackend {
+	return &statefulSetLeaseBackend{podName: podName, offset: offset}
+}
+
+func (b *statefulSetLeaseBackend) Claim(ctx context.Context, holder string, ttl time.Duration) (int64, error) {
+	podName := b.podName
+	if podName == "" {
+		podName = os.Getenv("POD_NAME")
+	}
+	if podName == "" {
+		podName, _ = os.Hostname()
+	}
+
+	idx := strings.LastIndex(podName, "-")
+	if idx < 0 {
+		return 0, fmt.Errorf("pod name %q has no StatefulSet ordinal", podName)
+	}
+	ordinal, err := strconv.ParseInt(podName[idx+1:], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("pod name %q has no StatefulSet ordinal", podName)
+	}
+
+	nodeID := ordinal + b.offset
+	if nodeID < 0 || nodeID > maxNodeID {
+		return 0, fmt.Errorf("%w: got %d for pod %q", ErrInvalidNodeID, nodeID, podName)
+	}
+	return nodeID, nil
+}
+
+func (b *statefulSetLeaseBackend) Renew(ctx context.Context, nodeID int64, holder string, ttl time.Duration) (LeaseRenewal, error) {
+	return LeaseRenewed, nil
+}
+
+func (b *statefulSetLeaseBackend) Release(ctx context.Context, nodeID int64, holder string) error {
+	return nil
+}
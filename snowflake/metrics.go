@@ -1,5 +1,7 @@
 package snowflake
 
+import "time"
+
 // MetricsHook allows services to bridge snowflake metrics to their observability stack
 // (e.g., OTel, Prometheus) without adding a direct dependency.
 type MetricsHook interface {
@@ -14,6 +16,20 @@ type MetricsHook interface {
 	OnLeaseReleased()
 }
 
+// OverflowWaitHook is an optional extension of MetricsHook. When the hook set
+// with WithMetrics implements it, it is told how long each sequence overflow
+// made ID generation wait, a sign of the node running at capacity.
+type OverflowWaitHook interface {
+	OnSequenceOverflowWait(wait time.Duration)
+}
+
+// LeaseHealthHook is an optional extension of MetricsHook. When the hook set
+// with WithLeaseMetrics implements it, it is told every change of the lease
+// health, during which the generator refuses to issue IDs.
+type LeaseHealthHook interface {
+	OnLeaseHealthChanged(healthy bool)
+}
+
 // noopMetrics is the default no-op implementation.
 type noopMetrics struct{}
 
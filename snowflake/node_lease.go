@@ -2,9 +2,9 @@ package snowflake
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,26 +12,27 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-// NodeLease manages a leased node ID in Redis for snowflake ID generation.
+// NodeLease manages a leased node ID for snowflake ID generation. Leases are
+// kept in Redis unless another backend is selected with WithLeaseBackend.
 type NodeLease struct {
-	client    redis.Scripter
-	holder    string
-	keyPrefix string
-	ttl       time.Duration
-	healthy   atomic.Bool
-	metrics   MetricsHook
-	stopCh    chan struct{}
-	doneCh    chan struct{}
-
-	// mu protects nodeID, leaseKey, and nodeIDUpdater which may be
-	// modified by the heartbeat goroutine during self-healing.
-	mu            sync.RWMutex
-	nodeID        int64
-	leaseKey      string
-	nodeIDUpdater func(int64)
+	backend LeaseBackend
+	holder  string
+	ttl     time.Duration
+	healthy atomic.Bool
+	flaps   atomic.Uint64
+	metrics MetricsHook
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+
+	// mu protects nodeID and nodeIDUpdaters which may be modified by the
+	// heartbeat goroutine during self-healing.
+	mu             sync.RWMutex
+	nodeID         int64
+	nodeIDUpdaters []func(int64)
 }
 
-// AcquireNodeLease claims an available node ID (0-1023) from Redis.
+// AcquireNodeLease claims an available node ID (0-1023) from Redis, or from
+// the backend set with WithLeaseBackend, in which case client may be nil.
 // It starts a background heartbeat goroutine to keep the lease alive.
 func AcquireNodeLease(ctx context.Context, client redis.Scripter, opts ...LeaseOption) (*NodeLease, error) {
 	o := defaultLeaseOptions()
@@ -39,30 +40,36 @@ func AcquireNodeLease(ctx context.Context, client redis.Scripter, opts ...LeaseO
 		opt(o)
 	}
 
+	backend := o.backend
+	if backend == nil {
+		if client == nil {
+			return nil, ErrNoLeaseBackend
+		}
+		backend = &redisLeaseBackend{client: client, keyPrefix: o.keyPrefix}
+	}
+
 	holder := buildHolder(o.serviceName)
 
 	// Atomically claim first available node
-	result, err := redis.NewScript(claimNodeLua).Run(ctx, client,
-		nil, // no KEYS
-		o.keyPrefix, holder, int(o.ttl.Seconds()),
-	).Int64()
+	result, err := backend.Claim(ctx, holder, o.ttl)
 	if err != nil {
+		if errors.Is(err, ErrNoAvailableNode) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("snowflake: claim node lease: %w", err)
 	}
-	if result < 0 {
-		return nil, ErrNoAvailableNode
+	if result < 0 || result > maxNodeID {
+		return nil, fmt.Errorf("%w: got %d", ErrInvalidNodeID, result)
 	}
 
 	nl := &NodeLease{
-		client:    client,
-		nodeID:    result,
-		holder:    holder,
-		leaseKey:  o.keyPrefix + strconv.FormatInt(result, 10),
-		keyPrefix: o.keyPrefix,
-		ttl:       o.ttl,
-		metrics:   o.metrics,
-		stopCh:    make(chan struct{}),
-		doneCh:    make(chan struct{}),
+		backend: backend,
+		nodeID:  result,
+		holder:  holder,
+		ttl:     o.ttl,
+		metrics: o.metrics,
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
 	}
 	nl.healthy.Store(true)
 	nl.metrics.OnLeaseAcquired(result)
@@ -85,12 +92,31 @@ func (nl *NodeLease) IsHealthy() bool {
 	return nl.healthy.Load()
 }
 
-// setNodeIDUpdater registers a callback invoked when the node ID changes
+// HealthFlaps returns how many times the lease went from healthy to unhealthy.
+func (nl *NodeLease) HealthFlaps() uint64 {
+	return nl.flaps.Load()
+}
+
+// setHealthy updates the lease health, counting and reporting changes
+func (nl *NodeLease) setHealthy(healthy bool) {
+	if nl.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if !healthy {
+		nl.flaps.Add(1)
+	}
+	if hook, ok := nl.metrics.(LeaseHealthHook); ok {
+		hook.OnLeaseHealthChanged(healthy)
+	}
+}
+
+// addNodeIDUpdater registers a callback invoked when the node ID changes
 // during self-healing (e.g., when the original node was taken by another
-// holder and a new node had to be claimed).
-func (nl *NodeLease) setNodeIDUpdater(fn func(int64)) {
+// holder and a new node had to be claimed). Every Generator sharing the lease
+// registers its own callback.
+func (nl *NodeLease) addNodeIDUpdater(fn func(int64)) {
 	nl.mu.Lock()
-	nl.nodeIDUpdater = fn
+	nl.nodeIDUpdaters = append(nl.nodeIDUpdaters, fn)
 	nl.mu.Unlock()
 }
 
@@ -101,20 +127,12 @@ func (nl *NodeLease) Release(ctx context.Context) error {
 	// Wait for heartbeat goroutine to exit
 	<-nl.doneCh
 
-	nl.mu.RLock()
-	leaseKey := nl.leaseKey
-	nl.mu.RUnlock()
-
-	result, err := redis.NewScript(releaseLeaseLua).Run(ctx, nl.client,
-		[]string{leaseKey},
-		nl.holder,
-	).Int64()
-	if err != nil {
+	if err := nl.backend.Release(ctx, nl.NodeID(), nl.holder); err != nil {
+		if errors.Is(err, ErrLeaseNotHeld) {
+			return err
+		}
 		return fmt.Errorf("snowflake: release lease: %w", err)
 	}
-	if result == 0 {
-		return ErrLeaseNotHeld
-	}
 
 	nl.healthy.Store(false)
 	nl.metrics.OnLeaseReleased()
@@ -122,7 +140,7 @@ func (nl *NodeLease) Release(ctx context.Context) error {
 }
 
 // heartbeatLoop renews the lease at TTL/3 intervals.
-// If the lease key expires in Redis (e.g., after a transient outage),
+// If the lease expires in the backend (e.g., after a transient outage),
 // it automatically reclaims the same node ID or acquires a new one.
 func (nl *NodeLease) heartbeatLoop() {
 	defer close(nl.doneCh)
@@ -142,12 +160,12 @@ func (nl *NodeLease) heartbeatLoop() {
 			ok := nl.tryRenewOrReclaim(interval)
 			if ok {
 				consecutiveFailures = 0
-				nl.healthy.Store(true)
+				nl.setHealthy(true)
 			} else {
 				consecutiveFailures++
 				nl.metrics.OnLeaseRenewFail()
 				if consecutiveFailures >= maxConsecutiveFailures {
-					nl.healthy.Store(false)
+					nl.setHealthy(false)
 					nl.metrics.OnLeaseExpired()
 				}
 			}
@@ -156,7 +174,7 @@ func (nl *NodeLease) heartbeatLoop() {
 }
 
 // tryRenewOrReclaim attempts to keep the lease alive. It tries, in order:
-//  1. Renew the existing key (holder matches) or reclaim it (key expired, SET NX)
+//  1. Renew the existing lease (holder matches) or reclaim it (lease expired)
 //  2. If another holder owns our node, claim any available node and update state
 //
 // Returns true if the lease is healthy after this attempt.
@@ -164,55 +182,44 @@ func (nl *NodeLease) tryRenewOrReclaim(timeout time.Duration) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout/2)
 	defer cancel()
 
-	nl.mu.RLock()
-	leaseKey := nl.leaseKey
-	nl.mu.RUnlock()
-
-	result, err := redis.NewScript(renewOrReclaimLua).Run(ctx, nl.client,
-		[]string{leaseKey},
-		nl.holder, int(nl.ttl.Seconds()),
-	).Int64()
-	if err != nil {
+	result, err := nl.backend.Renew(ctx, nl.NodeID(), nl.holder, nl.ttl)
+	switch {
+	case errors.Is(err, ErrLeaseNotHeld):
+		// Different holder owns our node.
+		// Try to claim any available node.
+		return nl.tryClaimNewNode(ctx)
+	case err != nil:
 		return false
-	}
-
-	switch result {
-	case 1:
-		// Renewed successfully (holder matched)
-		nl.metrics.OnLeaseRenewed()
-		return true
-	case 2:
-		// Reclaimed same node ID (key had expired)
+	case result == LeaseReclaimed:
+		// Reclaimed same node ID (lease had expired)
 		nl.metrics.OnLeaseReclaimed(nl.NodeID())
 		return true
 	default:
-		// result == 0: different holder owns our node.
-		// Try to claim any available node.
-		return nl.tryClaimNewNode(ctx)
+		// Renewed successfully (holder matched)
+		nl.metrics.OnLeaseRenewed()
+		return true
 	}
 }
 
 // tryClaimNewNode claims a new node ID when the original one was taken.
 // It updates the lease state and notifies the Generator of the change.
 func (nl *NodeLease) tryClaimNewNode(ctx context.Context) bool {
-	newNodeID, err := redis.NewScript(claimNodeLua).Run(ctx, nl.client,
-		nil,
-		nl.keyPrefix, nl.holder, int(nl.ttl.Seconds()),
-	).Int64()
-	if err != nil || newNodeID < 0 {
+	newNodeID, err := nl.backend.Claim(ctx, nl.holder, nl.ttl)
+	if err != nil || newNodeID < 0 || newNodeID > maxNodeID {
 		return false
 	}
 
 	nl.mu.Lock()
 	oldNodeID := nl.nodeID
 	nl.nodeID = newNodeID
-	nl.leaseKey = nl.keyPrefix + strconv.FormatInt(newNodeID, 10)
-	updater := nl.nodeIDUpdater
+	updaters := nl.nodeIDUpdaters
 	nl.mu.Unlock()
 
-	// Notify Generator to update its node ID
-	if updater != nil && newNodeID != oldNodeID {
-		updater(newNodeID)
+	// Notify Generators to update their node ID
+	if newNodeID != oldNodeID {
+		for _, updater := range updaters {
+			updater(newNodeID)
+		}
 	}
 
 	nl.metrics.OnLeaseReclaimed(newNodeID)
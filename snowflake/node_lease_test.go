@@ -220,7 +220,7 @@ func TestNodeLease_SelfHeal_ClaimNewNode(t *testing.T) {
 
 	// Track node ID changes
 	var newNodeIDFromCallback int64
-	nl.setNodeIDUpdater(func(id int64) {
+	nl.addNodeIDUpdater(func(id int64) {
 		newNodeIDFromCallback = id
 	})
 
@@ -270,3 +270,86 @@ func TestNodeLease_SelfHeal_GeneratorNodeIDUpdate(t *testing.T) {
 	assert.Equal(t, nl.NodeID(), nodeFromID2, "generator should use the new node ID")
 	assert.NotEqual(t, originalNodeID, nodeFromID2, "new ID should use different node")
 }
+
+func TestNodeLease_StatefulSetBackend(t *testing.T) {
+	nl, err := AcquireNodeLease(context.Background(), nil,
+		WithLeaseBackend(NewStatefulSetLeaseBackend("wallet-3", 100)),
+		WithLeaseTTL(3*time.Second),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int64(103), nl.NodeID())
+
+	// Heartbeat keeps the lease healthy without any store
+	time.Sleep(1500 * time.Millisecond)
+	assert.True(t, nl.IsHealthy())
+	assert.NoError(t, nl.Release(context.Background()))
+}
+
+func TestNodeLease_StatefulSetBackend_InvalidOrdinal(t *testing.T) {
+	_, err := AcquireNodeLease(context.Background(), nil,
+		WithLeaseBackend(NewStatefulSetLeaseBackend("wallet", 0)),
+	)
+	assert.Error(t, err)
+
+	_, err = AcquireNodeLease(context.Background(), nil,
+		WithLeaseBackend(NewStatefulSetLeaseBackend("wallet-24", 1000)),
+	)
+	assert.ErrorIs(t, err, ErrInvalidNodeID)
+}
+
+func TestNodeLease_NoBackend(t *testing.T) {
+	_, err := AcquireNodeLease(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrNoLeaseBackend)
+}
+
+func TestGeneratorRegistry_SharedLeaseSelfHeal(t *testing.T) {
+	mr, client := setupMiniredis(t)
+
+	nl, err := AcquireNodeLease(context.Background(), client,
+		WithServiceName("test-svc"),
+		WithLeaseTTL(3*time.Second),
+	)
+	require.NoError(t, err)
+	defer func() { _ = nl.Release(context.Background()) }()
+
+	reg, err := NewGeneratorRegistry(nl.NodeID(), []string{"order", "wallet"}, WithLeaseHealthCheck(nl))
+	require.NoError(t, err)
+
+	originalNodeID := nl.NodeID()
+	leaseKey := "snowflake:node:" + strconv.FormatInt(originalNodeID, 10)
+	mr.Set(leaseKey, "other-holder")
+	mr.SetTTL(leaseKey, 30*time.Second)
+
+	time.Sleep(1500 * time.Millisecond)
+	require.NotEqual(t, originalNodeID, nl.NodeID())
+
+	// Every generator sharing the lease should follow the new node ID
+	for _, domain := range reg.Domains() {
+		id, err := reg.NextID(domain)
+		require.NoError(t, err)
+		_, nodeID, _ := DecomposeID(id)
+		assert.Equal(t, nl.NodeID(), nodeID, domain)
+	}
+}
+
+func TestLeasedGeneratorRegistry(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	ctx := context.Background()
+
+	reg, err := NewLeasedGeneratorRegistry(ctx, client, []string{"order", "wallet"},
+		[]LeaseOption{WithServiceName("test-svc"), WithLeaseKeyPrefix("ids:")})
+	require.NoError(t, err)
+
+	for _, domain := range reg.Domains() {
+		g, err := reg.Generator(domain)
+		require.NoError(t, err)
+		assert.True(t, mr.Exists("ids:"+domain+":"+strconv.FormatInt(g.NodeID(), 10)), domain)
+
+		_, err = reg.NextID(domain)
+		require.NoError(t, err)
+		assert.True(t, reg.Stats()[domain].LeaseHealthy)
+	}
+
+	require.NoError(t, reg.Close(ctx))
+	assert.Empty(t, mr.Keys())
+}
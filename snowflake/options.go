@@ -12,6 +12,7 @@ type generatorOptions struct {
 	metrics       MetricsHook
 	leaseCheck    *NodeLease
 	now           func() time.Time
+	encoding      Encoding
 }
 
 func defaultGeneratorOptions() *generatorOptions {
@@ -57,6 +58,14 @@ func WithNowFunc(fn func() time.Time) Option {
 	}
 }
 
+// WithStringEncoding sets the encoding of NextString.
+// Default: EncodingBase62.
+func WithStringEncoding(e Encoding) Option {
+	return func(o *generatorOptions) {
+		o.encoding = e
+	}
+}
+
 // ---------- Lease Options ----------
 
 // LeaseOption configures a NodeLease.
@@ -67,6 +76,7 @@ type leaseOptions struct {
 	serviceName string
 	keyPrefix   string
 	metrics     MetricsHook
+	backend     LeaseBackend
 }
 
 func defaultLeaseOptions() *leaseOptions {
@@ -115,3 +125,13 @@ func WithLeaseMetrics(m MetricsHook) LeaseOption {
 		}
 	}
 }
+
+// WithLeaseBackend sets where node leases are kept, instead of Redis.
+// The key prefix option only applies to the Redis backend.
+func WithLeaseBackend(b LeaseBackend) LeaseOption {
+	return func(o *leaseOptions) {
+		if b != nil {
+			o.backend = b
+		}
+	}
+}
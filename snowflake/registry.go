@@ -0,0 +1,128 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GeneratorRegistry keeps one Generator per business domain (e.g. "order",
+// "wallet", "user"), each with its own sequence and stats, so that a burst of
+// IDs in one domain does not consume the sequence of the others.
+type GeneratorRegistry struct {
+	generators map[string]*Generator
+	// leases owned by the registry, released by Close
+	leases []*NodeLease
+}
+
+// NewGeneratorRegistry creates a Generator per domain, all using nodeID.
+// IDs are only unique within a domain. To follow the self-healing of a
+// single NodeLease, pass nodeID = lease.NodeID() and WithLeaseHealthCheck(lease).
+func NewGeneratorRegistry(nodeID int64, domains []string, opts ...Option) (*GeneratorRegistry, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("snowflake: at least one domain is required")
+	}
+	r := &GeneratorRegistry{generators: make(map[string]*Generator, len(domains))}
+	for _, domain := range domains {
+		if _, ok := r.generators[domain]; ok {
+			return nil, fmt.Errorf("snowflake: duplicate domain %q", domain)
+		}
+		g, err := NewGenerator(nodeID, opts...)
+		if err != nil {
+			return nil, err
+		}
+		r.generators[domain] = g
+	}
+	return r, nil
+}
+
+// NewLeasedGeneratorRegistry acquires a NodeLease per domain and creates a
+// Generator per domain checking its own lease, so each domain has its own
+// 1024 node slots. With the Redis backend the lease keys of a domain are
+// prefixed with "{keyPrefix}{domain}:". Close releases the leases.
+func NewLeasedGeneratorRegistry(ctx context.Context, client redis.Scripter, domains []string, leaseOpts []LeaseOption, opts ...Option) (*GeneratorRegistry, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("snowflake: at least one domain is required")
+	}
+	lo := defaultLeaseOptions()
+	for _, opt := range leaseOpts {
+		opt(lo)
+	}
+
+	r := &GeneratorRegistry{generators: make(map[string]*Generator, len(domains))}
+	for _, domain := range domains {
+		if _, ok := r.generators[domain]; ok {
+			r.Close(ctx)
+			return nil, fmt.Errorf("snowflake: duplicate domain %q", domain)
+		}
+		domainLeaseOpts := append(slices.Clone(leaseOpts), WithLeaseKeyPrefix(lo.keyPrefix+domain+":"))
+		lease, err := AcquireNodeLease(ctx, client, domainLeaseOpts...)
+		if err != nil {
+			r.Close(ctx)
+			return nil, fmt.Errorf("snowflake: acquire lease for domain %q: %w", domain, err)
+		}
+		r.leases = append(r.leases, lease)
+
+		g, err := NewGenerator(lease.NodeID(), append(slices.Clone(opts), WithLeaseHealthCheck(lease))...)
+		if err != nil {
+			r.Close(ctx)
+			return nil, err
+		}
+		r.generators[domain] = g
+	}
+	return r, nil
+}
+
+// Generator returns the Generator of domain, e.g. to use BatchNextID or
+// NextString.
+func (r *GeneratorRegistry) Generator(domain string) (*Generator, error) {
+	g, ok := r.generators[domain]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDomain, domain)
+	}
+	return g, nil
+}
+
+// NextID generates a single unique int64 ID in domain.
+func (r *GeneratorRegistry) NextID(domain string) (int64, error) {
+	g, err := r.Generator(domain)
+	if err != nil {
+		return 0, err
+	}
+	return g.NextID()
+}
+
+// Domains returns the domains of the registry, sorted.
+func (r *GeneratorRegistry) Domains() []string {
+	domains := make([]string, 0, len(r.generators))
+	for domain := range r.generators {
+		domains = append(domains, domain)
+	}
+	slices.Sort(domains)
+	return domains
+}
+
+// Stats returns a snapshot of the activity of each domain.
+func (r *GeneratorRegistry) Stats() map[string]Stats {
+	stats := make(map[string]Stats, len(r.generators))
+	for domain, g := range r.generators {
+		stats[domain] = g.Stats()
+	}
+	return stats
+}
+
+// Close releases the leases acquired by NewLeasedGeneratorRegistry. Leases
+// passed to NewGeneratorRegistry stay with the caller.
+func (r *GeneratorRegistry) Close(ctx context.Context) error {
+	var errs []error
+	for _, lease := range r.leases {
+		if err := lease.Release(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	r.leases = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/infigaming-com/go-common/snowflake"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// The gRPC service only uses well-known protobuf types, so clients in other
+// languages need no generated code beyond the standard library:
+//
+//	service Snowflake {
+//	  rpc NextID(google.protobuf.Empty) returns (google.protobuf.Int64Value);
+//	  rpc BatchNextID(google.protobuf.UInt32Value) returns (stream google.protobuf.Int64Value);
+//	}
+const (
+	serviceName           = "snowflake.v1.Snowflake"
+	nextIDMethod          = "/" + serviceName + "/NextID"
+	batchNextIDMethod     = "/" + serviceName + "/" + batchNextIDStreamName
+	batchNextIDStreamName = "BatchNextID"
+)
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "NextID",
+			Handler:    nextIDHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    batchNextIDStreamName,
+			Handler:       batchNextIDHandler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// RegisterGRPC registers the Snowflake service on gs.
+func (s *Server) RegisterGRPC(gs grpc.ServiceRegistrar) {
+	gs.RegisterService(&serviceDesc, s)
+}
+
+func nextIDHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		id, err := srv.(*Server).NextID()
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		return wrapperspb.Int64(id), nil
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: nextIDMethod}
+	return interceptor(ctx, in, info, handler)
+}
+
+func batchNextIDHandler(srv any, stream grpc.ServerStream) error {
+	in := new(wrapperspb.UInt32Value)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	ids, err := srv.(*Server).BatchNextID(int(in.GetValue()))
+	if err != nil {
+		return grpcError(err)
+	}
+	for _, id := range ids {
+		if err := stream.SendMsg(wrapperspb.Int64(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidBatchSize):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, snowflake.ErrLeaseExpired), errors.Is(err, snowflake.ErrClockRollback):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// Client calls a remote Snowflake gRPC service.
+type Client struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewClient creates a client on an established connection. Pair with
+// grpcx.ResilientDialOptions to retry while issuer replicas restart.
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{cc: cc}
+}
+
+// NextID requests a single ID.
+func (c *Client) NextID(ctx context.Context, opts ...grpc.CallOption) (int64, error) {
+	out := new(wrapperspb.Int64Value)
+	if err := c.cc.Invoke(ctx, nextIDMethod, &emptypb.Empty{}, out, opts...); err != nil {
+		return 0, err
+	}
+	return out.GetValue(), nil
+}
+
+// BatchNextID requests count IDs.
+func (c *Client) BatchNextID(ctx context.Context, count int, opts ...grpc.CallOption) ([]int64, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+
+	stream, err := c.cc.NewStream(ctx, &serviceDesc.Streams[0], batchNextIDMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(wrapperspb.UInt32(uint32(count))); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, count)
+	for {
+		out := new(wrapperspb.Int64Value)
+		if err := stream.RecvMsg(out); err != nil {
+			if errors.Is(err, io.EOF) {
+				return ids, nil
+			}
+			return nil, err
+		}
+		ids = append(ids, out.GetValue())
+	}
+}
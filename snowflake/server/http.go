@@ -0,0 +1,70 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/infigaming-com/go-common/snowflake"
+)
+
+// NextIDResponse is the HTTP response of the next ID endpoint. IDs are encoded
+// as strings since they exceed the integer precision of JavaScript numbers.
+type NextIDResponse struct {
+	ID string `json:"id"`
+}
+
+// BatchNextIDResponse is the HTTP response of the batch endpoint.
+type BatchNextIDResponse struct {
+	IDs []string `json:"ids"`
+}
+
+// RegisterRoutes registers the HTTP endpoints on r:
+//
+//	GET /ids/next             -> {"id": "..."}
+//	GET /ids/batch?count=100  -> {"ids": ["...", ...]}
+func (s *Server) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/ids/next", s.handleNextID)
+	r.GET("/ids/batch", s.handleBatchNextID)
+}
+
+func (s *Server) handleNextID(c *gin.Context) {
+	id, err := s.NextID()
+	if err != nil {
+		c.JSON(httpStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, NextIDResponse{ID: strconv.FormatInt(id, 10)})
+}
+
+func (s *Server) handleBatchNextID(c *gin.Context) {
+	count, err := strconv.Atoi(c.Query("count"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "count must be an integer"})
+		return
+	}
+
+	ids, err := s.BatchNextID(count)
+	if err != nil {
+		c.JSON(httpStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := BatchNextIDResponse{IDs: make([]string, len(ids))}
+	for i, id := range ids {
+		resp.IDs[i] = strconv.FormatInt(id, 10)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidBatchSize):
+		return http.StatusBadRequest
+	case errors.Is(err, snowflake.ErrLeaseExpired), errors.Is(err, snowflake.ErrClockRollback):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
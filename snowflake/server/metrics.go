@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/infigaming-com/go-common/observability/metrics"
+	"github.com/infigaming-com/go-common/snowflake"
+)
+
+var _ snowflake.MetricsHook = (*exporterMetrics)(nil)
+
+// exporterMetrics bridges snowflake.MetricsHook to a MetricExporter.
+type exporterMetrics struct {
+	exporter *metrics.MetricExporter
+}
+
+func newExporterMetrics(exporter *metrics.MetricExporter) *exporterMetrics {
+	return &exporterMetrics{exporter: exporter}
+}
+
+func (m *exporterMetrics) OnIDGenerated(count int) {
+	m.add("snowflake_ids_issued_total", "Number of snowflake IDs issued", int64(count), nil)
+}
+
+func (m *exporterMetrics) OnClockRollback() {
+	m.add("snowflake_clock_rollbacks_total", "Number of clock rollbacks detected", 1, nil)
+}
+
+func (m *exporterMetrics) OnSequenceOverflow() {
+	m.add("snowflake_sequence_overflows_total", "Number of sequence overflows within a millisecond", 1, nil)
+}
+
+func (m *exporterMetrics) OnLeaseAcquired(nodeID int64) {
+	m.leaseEvent("acquired", map[string]string{"node_id": strconv.FormatInt(nodeID, 10)})
+}
+
+func (m *exporterMetrics) OnLeaseRenewed() {
+	m.leaseEvent("renewed", nil)
+}
+
+func (m *exporterMetrics) OnLeaseRenewFail() {
+	m.leaseEvent("renew_failed", nil)
+}
+
+func (m *exporterMetrics) OnLeaseExpired() {
+	m.leaseEvent("expired", nil)
+}
+
+func (m *exporterMetrics) OnLeaseReclaimed(nodeID int64) {
+	m.leaseEvent("reclaimed", map[string]string{"node_id": strconv.FormatInt(nodeID, 10)})
+}
+
+func (m *exporterMetrics) OnLeaseReleased() {
+	m.leaseEvent("released", nil)
+}
+
+func (m *exporterMetrics) leaseEvent(event string, attributes map[string]string) {
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+	attributes["event"] = event
+	m.add("snowflake_lease_events_total", "Number of node lease events", 1, attributes)
+}
+
+func (m *exporterMetrics) add(name, description string, value int64, attributes map[string]string) {
+	_ = m.exporter.RecordCounter(context.Background(), name, description, "1", value, attributes)
+}
@@ -0,0 +1,136 @@
+// Package server exposes a snowflake Generator as a centralized ID issuing
+// service over HTTP and gRPC, for deployments that prefer a single issuer over
+// embedding a Generator in every replica.
+//
+// The node ID of each issuer replica is allocated through a snowflake
+// NodeLease, so several replicas can run behind a load balancer without
+// handing out duplicate IDs.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/infigaming-com/go-common/observability/metrics"
+	"github.com/infigaming-com/go-common/snowflake"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidBatchSize is returned when a batch request asks for no IDs or
+// more than the configured maximum.
+var ErrInvalidBatchSize = errors.New("snowflake/server: invalid batch size")
+
+// Server issues snowflake IDs from a leased node.
+type Server struct {
+	generator    *snowflake.Generator
+	lease        *snowflake.NodeLease
+	maxBatchSize int
+}
+
+// Option configures a Server.
+type Option func(*serverOptions)
+
+type serverOptions struct {
+	maxBatchSize     int
+	leaseOptions     []snowflake.LeaseOption
+	generatorOptions []snowflake.Option
+	exporter         *metrics.MetricExporter
+}
+
+func defaultServerOptions() *serverOptions {
+	return &serverOptions{
+		maxBatchSize: 1000,
+	}
+}
+
+// WithMaxBatchSize limits the number of IDs returned by a single BatchNextID
+// call. Default: 1000.
+func WithMaxBatchSize(n int) Option {
+	return func(o *serverOptions) {
+		if n > 0 {
+			o.maxBatchSize = n
+		}
+	}
+}
+
+// WithLeaseOptions configures the node lease (TTL, service name, key prefix, backend).
+func WithLeaseOptions(opts ...snowflake.LeaseOption) Option {
+	return func(o *serverOptions) {
+		o.leaseOptions = append(o.leaseOptions, opts...)
+	}
+}
+
+// WithGeneratorOptions configures the underlying Generator.
+func WithGeneratorOptions(opts ...snowflake.Option) Option {
+	return func(o *serverOptions) {
+		o.generatorOptions = append(o.generatorOptions, opts...)
+	}
+}
+
+// WithMetricExporter reports issuance rates, clock rollbacks and lease events
+// through the given exporter.
+func WithMetricExporter(exporter *metrics.MetricExporter) Option {
+	return func(o *serverOptions) {
+		o.exporter = exporter
+	}
+}
+
+// New acquires a node lease from Redis, or from the backend set with
+// snowflake.WithLeaseBackend, and creates a Server issuing IDs for that node.
+// Close must be called to release the lease.
+func New(ctx context.Context, client redis.Scripter, opts ...Option) (*Server, error) {
+	o := defaultServerOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	leaseOptions := o.leaseOptions
+	generatorOptions := o.generatorOptions
+	if o.exporter != nil {
+		hook := newExporterMetrics(o.exporter)
+		leaseOptions = append(leaseOptions, snowflake.WithLeaseMetrics(hook))
+		generatorOptions = append(generatorOptions, snowflake.WithMetrics(hook))
+	}
+
+	lease, err := snowflake.AcquireNodeLease(ctx, client, leaseOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	generatorOptions = append(generatorOptions, snowflake.WithLeaseHealthCheck(lease))
+	generator, err := snowflake.NewGenerator(lease.NodeID(), generatorOptions...)
+	if err != nil {
+		_ = lease.Release(ctx)
+		return nil, err
+	}
+
+	return &Server{
+		generator:    generator,
+		lease:        lease,
+		maxBatchSize: o.maxBatchSize,
+	}, nil
+}
+
+// NextID issues a single ID.
+func (s *Server) NextID() (int64, error) {
+	return s.generator.NextID()
+}
+
+// BatchNextID issues count IDs.
+func (s *Server) BatchNextID(count int) ([]int64, error) {
+	if count <= 0 || count > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: must be between 1 and %d, got %d", ErrInvalidBatchSize, s.maxBatchSize, count)
+	}
+	return s.generator.BatchNextID(count)
+}
+
+// NodeID returns the node ID currently leased by this server.
+func (s *Server) NodeID() int64 {
+	return s.lease.NodeID()
+}
+
+// Close releases the node lease. The server must not be used afterwards.
+func (s *Server) Close(ctx context.Context) error {
+	return s.lease.Release(ctx)
+}
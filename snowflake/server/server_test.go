@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/infigaming-com/go-common/snowflake"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupServer(t *testing.T, opts ...Option) *Server {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	s, err := New(context.Background(), client, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close(context.Background()) })
+	return s
+}
+
+func TestServer_BatchNextID(t *testing.T) {
+	s := setupServer(t, WithMaxBatchSize(10))
+
+	ids, err := s.BatchNextID(10)
+	require.NoError(t, err)
+	assert.Len(t, ids, 10)
+	for _, id := range ids {
+		_, nodeID, _ := snowflake.DecomposeID(id)
+		assert.Equal(t, s.NodeID(), nodeID)
+	}
+
+	_, err = s.BatchNextID(11)
+	assert.ErrorIs(t, err, ErrInvalidBatchSize)
+	_, err = s.BatchNextID(0)
+	assert.ErrorIs(t, err, ErrInvalidBatchSize)
+}
+
+func TestServer_HTTP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := setupServer(t, WithMaxBatchSize(5))
+	engine := gin.New()
+	s.RegisterRoutes(engine)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ids/next", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var next NextIDResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &next))
+	assert.NotEmpty(t, next.ID)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ids/batch?count=5", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var batch BatchNextIDResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &batch))
+	assert.Len(t, batch.IDs, 5)
+
+	for _, query := range []string{"count=6", "count=abc"} {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ids/batch?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestServer_GRPC(t *testing.T) {
+	s := setupServer(t, WithMaxBatchSize(5))
+
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	s.RegisterGRPC(gs)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	client := NewClient(conn)
+	ctx := context.Background()
+
+	id, err := client.NextID(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, id)
+
+	ids, err := client.BatchNextID(ctx, 5)
+	require.NoError(t, err)
+	require.Len(t, ids, 5)
+	assert.Greater(t, ids[0], id)
+
+	_, err = client.BatchNextID(ctx, 6)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
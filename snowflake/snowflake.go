@@ -33,6 +33,8 @@ type Generator struct {
 	leaseCheck    *NodeLease
 	metrics       MetricsHook
 	now           func() time.Time
+	encoding      Encoding
+	stats         generatorStats
 }
 
 // NewGenerator creates a snowflake ID generator for the given node ID (0-1023).
@@ -53,11 +55,12 @@ func NewGenerator(nodeID int64, opts ...Option) (*Generator, error) {
 		leaseCheck:    o.leaseCheck,
 		metrics:       o.metrics,
 		now:           o.now,
+		encoding:      o.encoding,
 	}
 
 	// Register callback so the lease can update our node ID during self-healing
 	if g.leaseCheck != nil {
-		g.leaseCheck.setNodeIDUpdater(func(newID int64) {
+		g.leaseCheck.addNodeIDUpdater(func(newID int64) {
 			g.mu.Lock()
 			g.nodeID = newID
 			g.mu.Unlock()
@@ -72,17 +75,28 @@ func (g *Generator) NextID() (int64, error) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	now, nodeID, sequence, err := g.nextLocked()
+	if err != nil {
+		return 0, err
+	}
+	return (now << timestampShift) | (nodeID << nodeShift) | sequence, nil
+}
+
+// nextLocked advances the generator and returns the timestamp (ms since the
+// custom epoch), node ID and sequence of the next ID. g.mu must be held.
+func (g *Generator) nextLocked() (now, nodeID, sequence int64, err error) {
 	if g.leaseCheck != nil && !g.leaseCheck.IsHealthy() {
-		return 0, ErrLeaseExpired
+		return 0, 0, 0, ErrLeaseExpired
 	}
 
-	now := g.currentTimeMs()
+	now = g.currentTimeMs()
 
 	if now < g.lastTime {
 		drift := time.Duration(g.lastTime-now) * time.Millisecond
+		g.stats.clockRollbacks++
 		if drift > g.maxClockDrift {
 			g.metrics.OnClockRollback()
-			return 0, fmt.Errorf("%w: drift %v", ErrClockRollback, drift)
+			return 0, 0, 0, fmt.Errorf("%w: drift %v", ErrClockRollback, drift)
 		}
 		// Small drift: sleep and retry
 		g.metrics.OnClockRollback()
@@ -91,7 +105,7 @@ func (g *Generator) NextID() (int64, error) {
 		g.mu.Lock()
 		now = g.currentTimeMs()
 		if now < g.lastTime {
-			return 0, fmt.Errorf("%w: drift persists after sleep", ErrClockRollback)
+			return 0, 0, 0, fmt.Errorf("%w: drift persists after sleep", ErrClockRollback)
 		}
 	}
 
@@ -100,7 +114,14 @@ func (g *Generator) NextID() (int64, error) {
 		if g.sequence == 0 {
 			// Sequence overflow: spin-wait for next millisecond
 			g.metrics.OnSequenceOverflow()
+			start := time.Now()
 			now = g.waitNextMs(now)
+			wait := time.Since(start)
+			g.stats.sequenceOverflows++
+			g.stats.overflowWait += wait
+			if hook, ok := g.metrics.(OverflowWaitHook); ok {
+				hook.OnSequenceOverflowWait(wait)
+			}
 		}
 	} else {
 		g.sequence = 0
@@ -108,9 +129,9 @@ func (g *Generator) NextID() (int64, error) {
 
 	g.lastTime = now
 
-	id := (now << timestampShift) | (g.nodeID << nodeShift) | g.sequence
+	g.stats.countID(now)
 	g.metrics.OnIDGenerated(1)
-	return id, nil
+	return now, g.nodeID, g.sequence, nil
 }
 
 // BatchNextID generates multiple unique int64 IDs.
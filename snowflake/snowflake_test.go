@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -196,6 +197,97 @@ func TestNextID_ClockRollback_Large(t *testing.T) {
 	assert.ErrorIs(t, err, ErrClockRollback)
 }
 
+// statsHook records the optional hook calls
+type statsHook struct {
+	noopMetrics
+	waits         []time.Duration
+	healthChanges []bool
+}
+
+func (h *statsHook) OnSequenceOverflowWait(wait time.Duration) { h.waits = append(h.waits, wait) }
+func (h *statsHook) OnLeaseHealthChanged(healthy bool) {
+	h.healthChanges = append(h.healthChanges, healthy)
+}
+
+func TestGenerator_Stats(t *testing.T) {
+	currentTime := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	mu := sync.Mutex{}
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return currentTime
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		currentTime = currentTime.Add(d)
+		mu.Unlock()
+	}
+	hook := &statsHook{}
+
+	g, err := NewGenerator(1, WithMetrics(hook), WithMaxClockDrift(time.Millisecond), WithNowFunc(clock))
+	require.NoError(t, err)
+
+	// Exhaust the sequence of the millisecond, the next ID overflows and waits
+	// for the clock to advance
+	for i := 0; i <= maxSequence; i++ {
+		_, err := g.NextID()
+		require.NoError(t, err)
+	}
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		advance(time.Millisecond)
+	}()
+	_, err = g.NextID()
+	require.NoError(t, err)
+
+	// Roll back the clock beyond the tolerated drift
+	advance(-time.Second)
+	_, err = g.NextID()
+	require.ErrorIs(t, err, ErrClockRollback)
+	advance(time.Second)
+
+	stats := g.Stats()
+	assert.Equal(t, uint64(maxSequence+2), stats.IDsGenerated)
+	assert.Equal(t, uint64(1), stats.SequenceOverflows)
+	assert.Equal(t, uint64(1), stats.ClockRollbacks)
+	require.Len(t, hook.waits, 1)
+	assert.Equal(t, stats.OverflowWait, hook.waits[0])
+	assert.GreaterOrEqual(t, stats.OverflowWait, 5*time.Millisecond)
+	assert.True(t, stats.LeaseHealthy)
+	assert.Zero(t, stats.IDsPerSecond, "no full second elapsed yet")
+
+	// A second later, the IDs of the previous second are the rate
+	advance(time.Second)
+	stats = g.Stats()
+	assert.Equal(t, uint64(maxSequence+2), stats.IDsPerSecond)
+	assert.InDelta(t, float64(maxSequence+2)/maxIDsPerSecond, stats.Saturation, 1e-9)
+
+	// Without IDs for over a second, the rate drops to zero
+	advance(time.Second)
+	assert.Zero(t, g.Stats().IDsPerSecond)
+}
+
+func TestNodeLease_HealthFlaps(t *testing.T) {
+	hook := &statsHook{}
+	nl := &NodeLease{metrics: hook}
+	nl.healthy.Store(true)
+
+	g, err := NewGenerator(1, WithLeaseHealthCheck(nl))
+	require.NoError(t, err)
+
+	nl.setHealthy(true)
+	nl.setHealthy(false)
+	nl.setHealthy(false)
+	assert.False(t, g.Stats().LeaseHealthy)
+	nl.setHealthy(true)
+	nl.setHealthy(false)
+
+	stats := g.Stats()
+	assert.Equal(t, uint64(2), stats.LeaseFlaps)
+	assert.False(t, stats.LeaseHealthy)
+	assert.Equal(t, []bool{false, true, false}, hook.healthChanges)
+}
+
 func TestBatchNextID(t *testing.T) {
 	g, err := NewGenerator(5)
 	require.NoError(t, err)
@@ -262,6 +354,86 @@ func TestDifferentNodes_UniqueIDs(t *testing.T) {
 	assert.Len(t, seen, 20_000)
 }
 
+func TestEncoding_RoundTripAndOrder(t *testing.T) {
+	for _, enc := range []Encoding{EncodingBase62, EncodingBase32Crockford} {
+		t.Run(enc.String(), func(t *testing.T) {
+			ids := []int64{0, 1, 61, 62, 1 << 40, 1<<63 - 1}
+			var prev string
+			for _, id := range ids {
+				s := enc.Encode(id)
+				assert.Len(t, s, enc.width())
+				assert.Greater(t, s, prev, "encoding must preserve order")
+				prev = s
+
+				decoded, err := enc.Decode(s)
+				require.NoError(t, err)
+				assert.Equal(t, id, decoded)
+			}
+
+			_, err := enc.Decode("short")
+			assert.ErrorIs(t, err, ErrInvalidEncodedID)
+		})
+	}
+
+	// Crockford decoding is case insensitive and maps ambiguous letters
+	id, err := EncodingBase32Crockford.Decode("000000000000o")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), id)
+	id, err = EncodingBase32Crockford.Decode("000000000000l")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+
+	_, err = EncodingBase62.Decode("zzzzzzzzzzz")
+	assert.ErrorIs(t, err, ErrInvalidEncodedID)
+	_, err = EncodingBase62.Decode("0000000000-")
+	assert.ErrorIs(t, err, ErrInvalidEncodedID)
+}
+
+func TestNextString(t *testing.T) {
+	g, err := NewGenerator(7, WithStringEncoding(EncodingBase32Crockford))
+	require.NoError(t, err)
+
+	var prev string
+	for i := 0; i < 1000; i++ {
+		s, err := g.NextString()
+		require.NoError(t, err)
+		assert.Greater(t, s, prev)
+		prev = s
+	}
+
+	id, err := EncodingBase32Crockford.Decode(prev)
+	require.NoError(t, err)
+	_, nodeID, _ := DecomposeID(id)
+	assert.Equal(t, int64(7), nodeID)
+}
+
+func TestNextUUIDv7(t *testing.T) {
+	fixed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	g, err := NewGenerator(1000, WithNowFunc(func() time.Time { return fixed }))
+	require.NoError(t, err)
+
+	var prev string
+	for i := 0; i < 100; i++ {
+		id, err := g.NextUUIDv7()
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), id.Version())
+		assert.Equal(t, uuid.RFC4122, id.Variant())
+		assert.Greater(t, id.String(), prev)
+		prev = id.String()
+
+		ts, nodeID, sequence := DecomposeUUIDv7(id)
+		assert.Equal(t, fixed.UnixMilli(), ts.UnixMilli())
+		assert.Equal(t, int64(1000), nodeID)
+		assert.Equal(t, int64(i), sequence)
+	}
+
+	// UUIDs and int64 IDs share the sequence
+	id, err := g.NextID()
+	require.NoError(t, err)
+	_, _, sequence := DecomposeID(id)
+	assert.Equal(t, int64(100), sequence)
+}
+
 func BenchmarkNextID(b *testing.B) {
 	g, _ := NewGenerator(1)
 	b.ResetTimer()
@@ -279,3 +451,33 @@ func BenchmarkNextID_SingleThread(b *testing.B) {
 		_, _ = g.NextID()
 	}
 }
+
+func TestGeneratorRegistry(t *testing.T) {
+	reg, err := NewGeneratorRegistry(7, []string{"wallet", "order"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"order", "wallet"}, reg.Domains())
+
+	for range 3 {
+		_, err := reg.NextID("order")
+		require.NoError(t, err)
+	}
+	id, err := reg.NextID("wallet")
+	require.NoError(t, err)
+	_, nodeID, seq := DecomposeID(id)
+	assert.Equal(t, int64(7), nodeID)
+	assert.Equal(t, int64(0), seq, "domains should not share a sequence")
+
+	stats := reg.Stats()
+	assert.Equal(t, uint64(3), stats["order"].IDsGenerated)
+	assert.Equal(t, uint64(1), stats["wallet"].IDsGenerated)
+
+	_, err = reg.NextID("user")
+	assert.ErrorIs(t, err, ErrUnknownDomain)
+
+	_, err = NewGeneratorRegistry(7, []string{"order", "order"})
+	assert.Error(t, err)
+	_, err = NewGeneratorRegistry(7, nil)
+	assert.Error(t, err)
+	_, err = NewGeneratorRegistry(1024, []string{"order"})
+	assert.ErrorIs(t, err, ErrInvalidNodeID)
+}
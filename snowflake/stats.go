@@ -0,0 +1,79 @@
+package snowflake
+
+import "time"
+
+// maxIDsPerSecond is the capacity of a single node: 4096 IDs per millisecond
+const maxIDsPerSecond = (maxSequence + 1) * 1000
+
+// Stats is a snapshot of the activity of a Generator, for capacity planning.
+type Stats struct {
+	IDsGenerated      uint64
+	IDsPerSecond      uint64        // IDs issued during the last full second
+	Saturation        float64       // IDsPerSecond as a share of the node capacity of 4,096,000 IDs per second
+	SequenceOverflows uint64        // times the 4096 IDs of a millisecond were exhausted
+	OverflowWait      time.Duration // total time spent waiting for the next millisecond after an overflow
+	ClockRollbacks    uint64
+	LeaseHealthy      bool   // always true without WithLeaseHealthCheck
+	LeaseFlaps        uint64 // times the lease went from healthy to unhealthy
+}
+
+// generatorStats holds the counters behind Stats, protected by Generator.mu
+type generatorStats struct {
+	idsGenerated      uint64
+	sequenceOverflows uint64
+	overflowWait      time.Duration
+	clockRollbacks    uint64
+
+	// IDs issued during rateSecond and during the second before it
+	rateSecond   int64
+	rateCount    uint64
+	previousRate uint64
+}
+
+// countID counts an ID issued at nowMs
+func (s *generatorStats) countID(nowMs int64) {
+	s.idsGenerated++
+	second := nowMs / 1000
+	switch second {
+	case s.rateSecond:
+		s.rateCount++
+	case s.rateSecond + 1:
+		s.previousRate, s.rateSecond, s.rateCount = s.rateCount, second, 1
+	default:
+		s.previousRate, s.rateSecond, s.rateCount = 0, second, 1
+	}
+}
+
+// idsPerSecond returns the IDs issued during the second before the one of nowMs
+func (s *generatorStats) idsPerSecond(nowMs int64) uint64 {
+	switch nowMs / 1000 {
+	case s.rateSecond:
+		return s.previousRate
+	case s.rateSecond + 1:
+		return s.rateCount
+	default:
+		return 0
+	}
+}
+
+// Stats returns a snapshot of the activity of the generator.
+func (g *Generator) Stats() Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	idsPerSecond := g.stats.idsPerSecond(g.currentTimeMs())
+	stats := Stats{
+		IDsGenerated:      g.stats.idsGenerated,
+		IDsPerSecond:      idsPerSecond,
+		Saturation:        float64(idsPerSecond) / maxIDsPerSecond,
+		SequenceOverflows: g.stats.sequenceOverflows,
+		OverflowWait:      g.stats.overflowWait,
+		ClockRollbacks:    g.stats.clockRollbacks,
+		LeaseHealthy:      true,
+	}
+	if g.leaseCheck != nil {
+		stats.LeaseHealthy = g.leaseCheck.IsHealthy()
+		stats.LeaseFlaps = g.leaseCheck.HealthFlaps()
+	}
+	return stats
+}
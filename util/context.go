@@ -9,6 +9,8 @@ type CtxKey string
 
 const (
 	CorrelationIdKey CtxKey = "CorrelationId"
+	OperatorIdKey    CtxKey = "OperatorId"
+	UserIdKey        CtxKey = "UserId"
 )
 
 func ValueToCtx[T any](ctx context.Context, key CtxKey, value T) context.Context {
@@ -45,3 +47,19 @@ func CorrelationIdFromCtx(ctx context.Context) (string, error) {
 	}
 	return value, nil
 }
+
+func OperatorIdToCtx(ctx context.Context, operatorId int64) context.Context {
+	return ValueToCtx(ctx, OperatorIdKey, operatorId)
+}
+
+func OperatorIdFromCtx(ctx context.Context) (int64, error) {
+	return ValueFromCtx[int64](ctx, OperatorIdKey)
+}
+
+func UserIdToCtx(ctx context.Context, userId int64) context.Context {
+	return ValueToCtx(ctx, UserIdKey, userId)
+}
+
+func UserIdFromCtx(ctx context.Context) (int64, error) {
+	return ValueFromCtx[int64](ctx, UserIdKey)
+}
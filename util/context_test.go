@@ -2,6 +2,7 @@ package util
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -125,3 +126,44 @@ func TestValueFromCtx(t *testing.T) {
 		})
 	}
 }
+
+func TestOperatorAndUserIdCtx(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := OperatorIdFromCtx(ctx)
+	assert.Error(t, err)
+
+	ctx = UserIdToCtx(OperatorIdToCtx(ctx, 42), 1001)
+	operatorId, err := OperatorIdFromCtx(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), operatorId)
+	userId, err := UserIdFromCtx(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1001), userId)
+}
+
+func TestRequestMetaPropagation(t *testing.T) {
+	meta := RequestMeta{CorrelationId: "abc-123", OperatorId: 42, UserId: 1001}
+	ctx := RequestMetaToCtx(context.Background(), meta)
+	assert.Equal(t, meta, RequestMetaFromCtx(ctx))
+
+	header := http.Header{}
+	RequestMetaToHeader(ctx, header)
+	assert.Equal(t, "42", header.Get(HeaderOperatorId))
+	assert.Equal(t, meta, RequestMetaFromCtx(RequestMetaFromHeader(context.Background(), header)))
+
+	metadata := RequestMetaToMetadata(ctx)
+	assert.Equal(t, map[string]string{"correlation_id": "abc-123", "operator_id": "42", "user_id": "1001"}, metadata)
+	assert.Equal(t, meta, RequestMetaFromCtx(RequestMetaFromMetadata(context.Background(), metadata)))
+}
+
+func TestRequestMetaPropagation_Partial(t *testing.T) {
+	ctx := RequestMetaToCtx(context.Background(), RequestMeta{UserId: 7})
+	assert.Equal(t, map[string]string{"user_id": "7"}, RequestMetaToMetadata(ctx))
+
+	_, err := CorrelationIdFromCtx(ctx)
+	assert.Error(t, err)
+
+	ctx = RequestMetaFromMetadata(context.Background(), map[string]string{"operator_id": "not-a-number", "user_id": "7"})
+	assert.Equal(t, RequestMeta{UserId: 7}, RequestMetaFromCtx(ctx))
+}
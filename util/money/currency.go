@@ -0,0 +1,59 @@
+package money
+
+import (
+	"strings"
+	"sync"
+)
+
+// defaultExponents are the ISO 4217 minor unit exponents of the currencies
+// not using 2 decimals, plus common crypto currencies
+var defaultExponents = map[string]int32{
+	// 0 decimals
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	// 3 decimals
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	// 4 decimals
+	"CLF": 4, "UYW": 4,
+	// crypto
+	"BTC": 8, "ETH": 18, "USDT": 6, "USDC": 6,
+}
+
+// defaultExponent is the exponent of the currencies not listed, most ISO 4217
+// currencies having 2 decimals
+const defaultExponent = 2
+
+var (
+	exponentsMu sync.RWMutex
+	exponents   = map[string]int32{}
+)
+
+// RegisterCurrency sets the number of decimals of the minor unit of currency,
+// e.g. 0 for a "VND(K)" currency counted in thousands, overriding the ISO 4217
+// one.
+func RegisterCurrency(currency string, exponent int32) {
+	exponentsMu.Lock()
+	defer exponentsMu.Unlock()
+	exponents[normalizeCurrency(currency)] = exponent
+}
+
+// Exponent returns the number of decimals of the minor unit of currency: 2
+// for EUR whose minor unit is the cent, 0 for JPY. Currencies neither
+// registered nor known use 2.
+func Exponent(currency string) int32 {
+	currency = normalizeCurrency(currency)
+	exponentsMu.RLock()
+	exponent, ok := exponents[currency]
+	exponentsMu.RUnlock()
+	if ok {
+		return exponent
+	}
+	if exponent, ok := defaultExponents[currency]; ok {
+		return exponent
+	}
+	return defaultExponent
+}
+
+func normalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
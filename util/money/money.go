@@ -0,0 +1,239 @@
+// Package money provides amounts bound to a currency, with arithmetic that
+// refuses to mix currencies, minor unit conversion and allocation that never
+// loses a cent.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/infigaming-com/go-common/util"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidCurrency  = errors.New("invalid currency")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrInvalidRatios    = errors.New("invalid allocation ratios")
+)
+
+// Amount is an immutable decimal value in a currency. The zero Amount has no
+// currency and is invalid.
+type Amount struct {
+	value    decimal.Decimal
+	currency string
+}
+
+// New returns value in currency. value may be any type accepted by
+// util.NewDecimal, e.g. "12.50", 12 or a decimal.Decimal.
+func New(value any, currency string) (Amount, error) {
+	currency = normalizeCurrency(currency)
+	if currency == "" {
+		return Amount{}, ErrInvalidCurrency
+	}
+	d, err := util.NewDecimal(value)
+	if err != nil {
+		return Amount{}, fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+	return Amount{value: d, currency: currency}, nil
+}
+
+// Zero returns a zero amount in currency.
+func Zero(currency string) Amount {
+	return Amount{value: decimal.Zero, currency: normalizeCurrency(currency)}
+}
+
+// FromMinor returns the amount of minor units of currency, e.g. 1250 cents as
+// 12.50 EUR.
+func FromMinor(minor int64, currency string) (Amount, error) {
+	currency = normalizeCurrency(currency)
+	if currency == "" {
+		return Amount{}, ErrInvalidCurrency
+	}
+	return Amount{value: decimal.New(minor, -Exponent(currency)), currency: currency}, nil
+}
+
+func (a Amount) Currency() string {
+	return a.currency
+}
+
+func (a Amount) Decimal() decimal.Decimal {
+	return a.value
+}
+
+// Exponent returns the number of decimals of the minor unit of the currency.
+func (a Amount) Exponent() int32 {
+	return Exponent(a.currency)
+}
+
+// Minor returns the amount in minor units, e.g. 1250 for 12.50 EUR. Amounts
+// with more decimals than the currency, e.g. after Mul, must be rounded first.
+func (a Amount) Minor() (int64, error) {
+	minor := a.value.Shift(a.Exponent())
+	if !minor.IsInteger() {
+		return 0, fmt.Errorf("%w: %s has more than %d decimals", ErrInvalidAmount, a, a.Exponent())
+	}
+	if !minor.BigInt().IsInt64() {
+		return 0, fmt.Errorf("%w: %s overflows int64 minor units", ErrInvalidAmount, a)
+	}
+	return minor.IntPart(), nil
+}
+
+// Round rounds the amount to the decimals of its currency, half away from
+// zero.
+func (a Amount) Round() Amount {
+	return Amount{value: a.value.Round(a.Exponent()), currency: a.currency}
+}
+
+// Add returns a + b, failing with ErrCurrencyMismatch for other currencies.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if err := a.sameCurrency(b); err != nil {
+		return Amount{}, err
+	}
+	return Amount{value: a.value.Add(b.value), currency: a.currency}, nil
+}
+
+// Sub returns a - b, failing with ErrCurrencyMismatch for other currencies.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if err := a.sameCurrency(b); err != nil {
+		return Amount{}, err
+	}
+	return Amount{value: a.value.Sub(b.value), currency: a.currency}, nil
+}
+
+// Mul returns a multiplied by factor, e.g. a rate, unrounded.
+func (a Amount) Mul(factor decimal.Decimal) Amount {
+	return Amount{value: a.value.Mul(factor), currency: a.currency}
+}
+
+func (a Amount) Neg() Amount {
+	return Amount{value: a.value.Neg(), currency: a.currency}
+}
+
+// Cmp compares a and b like decimal.Decimal.Cmp, failing with
+// ErrCurrencyMismatch for other currencies.
+func (a Amount) Cmp(b Amount) (int, error) {
+	if err := a.sameCurrency(b); err != nil {
+		return 0, err
+	}
+	return a.value.Cmp(b.value), nil
+}
+
+// Equal reports whether a and b have the same currency and value.
+func (a Amount) Equal(b Amount) bool {
+	return a.currency == b.currency && a.value.Equal(b.value)
+}
+
+func (a Amount) IsZero() bool {
+	return a.value.IsZero()
+}
+
+func (a Amount) IsNegative() bool {
+	return a.value.IsNegative()
+}
+
+// Allocate splits the amount by ratios without losing minor units: the
+// remainder of the division is handed out one minor unit at a time to the
+// first parts, e.g. 10.00 EUR allocated 1:1:1 gives 3.34, 3.33 and 3.33. The
+// amount must be representable in minor units.
+func (a Amount) Allocate(ratios ...int) ([]Amount, error) {
+	total := 0
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("%w: negative ratio %d", ErrInvalidRatios, ratio)
+		}
+		total += ratio
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%w: ratios sum to zero", ErrInvalidRatios)
+	}
+	minor, err := a.Minor()
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]int64, len(ratios))
+	remainder := minor
+	for i, ratio := range ratios {
+		// computed as decimals, minor * ratio may overflow int64
+		parts[i] = decimal.NewFromInt(minor).Mul(decimal.NewFromInt(int64(ratio))).Div(decimal.NewFromInt(int64(total))).Truncate(0).IntPart()
+		remainder -= parts[i]
+	}
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i] += step
+		remainder -= step
+	}
+
+	amounts := make([]Amount, len(parts))
+	for i, part := range parts {
+		amounts[i], _ = FromMinor(part, a.currency)
+	}
+	return amounts, nil
+}
+
+// Split divides the amount into n parts differing by at most one minor unit,
+// the larger ones first.
+func (a Amount) Split(n int) ([]Amount, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: cannot split in %d parts", ErrInvalidRatios, n)
+	}
+	ratios := make([]int, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return a.Allocate(ratios...)
+}
+
+// Format formats the amount rounded to the decimals of its currency, with
+// thousandsSep and decimalSep defaulting to "," and ".", the way
+// reports.CurrencyAmountFormatter formats amounts, e.g. "-1,234.50".
+func (a Amount) Format(thousandsSep, decimalSep string) string {
+	if thousandsSep == "" {
+		thousandsSep = ","
+	}
+	if decimalSep == "" {
+		decimalSep = "."
+	}
+	exponent := a.Exponent()
+	intPart, fracPart, _ := strings.Cut(a.value.Round(exponent).StringFixed(exponent), ".")
+	sign := ""
+	if strings.HasPrefix(intPart, "-") {
+		sign, intPart = "-", intPart[1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(thousandsSep)
+		}
+		b.WriteRune(digit)
+	}
+	if fracPart != "" {
+		b.WriteString(decimalSep)
+		b.WriteString(fracPart)
+	}
+	return b.String()
+}
+
+// String returns the amount with its currency code, e.g. "1234.50 EUR".
+func (a Amount) String() string {
+	return a.value.StringFixed(max(a.Exponent(), -a.value.Exponent())) + " " + a.currency
+}
+
+func (a Amount) sameCurrency(b Amount) error {
+	if a.currency != b.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.currency, b.currency)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/infigaming-com/go-common/reports"
+)
+
+func mustNew(t *testing.T, value any, currency string) Amount {
+	t.Helper()
+	amount, err := New(value, currency)
+	require.NoError(t, err)
+	return amount
+}
+
+func TestArithmetic(t *testing.T) {
+	a := mustNew(t, "10.25", "eur")
+	b := mustNew(t, 2, "EUR")
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	assert.Equal(t, "12.25 EUR", sum.String())
+
+	diff, err := b.Sub(a)
+	require.NoError(t, err)
+	assert.True(t, diff.IsNegative())
+	assert.Equal(t, "-8.25 EUR", diff.String())
+
+	cmp, err := a.Cmp(b)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cmp)
+
+	_, err = a.Add(mustNew(t, 1, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = a.Cmp(mustNew(t, 1, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	// multiplication keeps the precision until rounded
+	interest := a.Mul(decimal.RequireFromString("0.015"))
+	assert.Equal(t, "0.15375 EUR", interest.String())
+	_, err = interest.Minor()
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	assert.True(t, interest.Round().Equal(mustNew(t, "0.15", "EUR")))
+
+	_, err = New("abc", "EUR")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = New(1, " ")
+	assert.ErrorIs(t, err, ErrInvalidCurrency)
+}
+
+func TestMinorUnits(t *testing.T) {
+	tcs := []struct {
+		currency string
+		minor    int64
+		expected string
+	}{
+		{"EUR", 1250, "12.50 EUR"},
+		{"JPY", 1250, "1250 JPY"},
+		{"KWD", 1250, "1.250 KWD"},
+		{"BTC", 1, "0.00000001 BTC"},
+		{"XYZ", -5, "-0.05 XYZ"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.currency, func(t *testing.T) {
+			amount, err := FromMinor(tc.minor, tc.currency)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, amount.String())
+			minor, err := amount.Minor()
+			require.NoError(t, err)
+			assert.Equal(t, tc.minor, minor)
+		})
+	}
+
+	RegisterCurrency("VND(K)", 3)
+	amount, err := FromMinor(1500, "vnd(k)")
+	require.NoError(t, err)
+	assert.Equal(t, "1.500 VND(K)", amount.String())
+}
+
+func TestAllocate(t *testing.T) {
+	tcs := []struct {
+		name     string
+		amount   string
+		ratios   []int
+		expected []string
+	}{
+		{"equal thirds", "10.00", []int{1, 1, 1}, []string{"3.34", "3.33", "3.33"}},
+		{"weighted", "100.00", []int{70, 20, 10}, []string{"70", "20", "10"}},
+		{"remainder", "0.05", []int{1, 3}, []string{"0.02", "0.03"}},
+		{"negative", "-10.00", []int{1, 1, 1}, []string{"-3.34", "-3.33", "-3.33"}},
+		{"zero ratio", "1.00", []int{0, 1, 2}, []string{"0", "0.34", "0.66"}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			amount := mustNew(t, tc.amount, "EUR")
+			parts, err := amount.Allocate(tc.ratios...)
+			require.NoError(t, err)
+			total := Zero("EUR")
+			for i, part := range parts {
+				assert.True(t, part.Decimal().Equal(decimal.RequireFromString(tc.expected[i])), "part %d: %s", i, part)
+				total, err = total.Add(part)
+				require.NoError(t, err)
+			}
+			assert.True(t, total.Equal(amount))
+		})
+	}
+
+	parts, err := mustNew(t, 100, "JPY").Split(3)
+	require.NoError(t, err)
+	assert.Equal(t, "34 JPY", parts[0].String())
+	assert.Equal(t, "33 JPY", parts[2].String())
+
+	_, err = mustNew(t, 1, "EUR").Allocate(0, 0)
+	assert.ErrorIs(t, err, ErrInvalidRatios)
+	_, err = mustNew(t, 1, "EUR").Split(0)
+	assert.ErrorIs(t, err, ErrInvalidRatios)
+	_, err = mustNew(t, "0.001", "EUR").Split(2)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func TestFormatMatchesReports(t *testing.T) {
+	tcs := []struct {
+		value, currency, thousandsSep, decimalSep string
+	}{
+		{"1234567.891", "EUR", "", ""},
+		{"-1234.5", "EUR", ".", ","},
+		{"999", "JPY", " ", ""},
+		{"0.0004", "KWD", "", ""},
+	}
+	for _, tc := range tcs {
+		amount := mustNew(t, tc.value, tc.currency)
+		formatter := &reports.CurrencyAmountFormatter{
+			DefaultDecimalPlaces:      amount.Exponent(),
+			DefaultThousandsSeparator: tc.thousandsSep,
+			DefaultDecimalSeparator:   tc.decimalSep,
+		}
+		expected, err := formatter.Format(tc.value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, amount.Format(tc.thousandsSep, tc.decimalSep))
+	}
+	assert.Equal(t, "1.234.567,89", mustNew(t, "1234567.891", "EUR").Format(".", ","))
+}
@@ -0,0 +1,106 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// Header names used to propagate RequestMeta over HTTP
+const (
+	HeaderCorrelationId = "X-CORRELATION-ID"
+	HeaderOperatorId    = "X-OPERATOR-ID"
+	HeaderUserId        = "X-USER-ID"
+)
+
+// Metadata keys used to propagate RequestMeta in message attributes, e.g.
+// Kafka headers or Pub/Sub attributes
+const (
+	MetadataCorrelationId = "correlation_id"
+	MetadataOperatorId    = "operator_id"
+	MetadataUserId        = "user_id"
+)
+
+// RequestMeta is the caller identity and tracing information propagated
+// across service boundaries. Zero values mean unknown.
+type RequestMeta struct {
+	CorrelationId string
+	OperatorId    int64
+	UserId        int64
+}
+
+// RequestMetaToCtx stores the non-zero fields of meta in ctx, where they are
+// available to CorrelationIdFromCtx, OperatorIdFromCtx and UserIdFromCtx.
+func RequestMetaToCtx(ctx context.Context, meta RequestMeta) context.Context {
+	if meta.CorrelationId != "" {
+		ctx = CorrelationIdToCtx(ctx, meta.CorrelationId)
+	}
+	if meta.OperatorId != 0 {
+		ctx = OperatorIdToCtx(ctx, meta.OperatorId)
+	}
+	if meta.UserId != 0 {
+		ctx = UserIdToCtx(ctx, meta.UserId)
+	}
+	return ctx
+}
+
+// RequestMetaFromCtx collects the request meta stored in ctx, leaving missing
+// fields zero.
+func RequestMetaFromCtx(ctx context.Context) RequestMeta {
+	var meta RequestMeta
+	meta.CorrelationId, _ = CorrelationIdFromCtx(ctx)
+	meta.OperatorId, _ = OperatorIdFromCtx(ctx)
+	meta.UserId, _ = UserIdFromCtx(ctx)
+	return meta
+}
+
+// RequestMetaToHeader sets the request meta of ctx on outgoing HTTP headers
+func RequestMetaToHeader(ctx context.Context, header http.Header) {
+	for k, v := range requestMetaValues(RequestMetaFromCtx(ctx), HeaderCorrelationId, HeaderOperatorId, HeaderUserId) {
+		header.Set(k, v)
+	}
+}
+
+// RequestMetaFromHeader returns a copy of ctx carrying the request meta found
+// in incoming HTTP headers. Malformed IDs are ignored.
+func RequestMetaFromHeader(ctx context.Context, header http.Header) context.Context {
+	return RequestMetaToCtx(ctx, parseRequestMeta(header.Get, HeaderCorrelationId, HeaderOperatorId, HeaderUserId))
+}
+
+// RequestMetaToMetadata returns the request meta of ctx as message metadata,
+// e.g. Kafka headers or Pub/Sub attributes
+func RequestMetaToMetadata(ctx context.Context) map[string]string {
+	return requestMetaValues(RequestMetaFromCtx(ctx), MetadataCorrelationId, MetadataOperatorId, MetadataUserId)
+}
+
+// RequestMetaFromMetadata returns a copy of ctx carrying the request meta
+// found in message metadata. Malformed IDs are ignored.
+func RequestMetaFromMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	get := func(key string) string { return metadata[key] }
+	return RequestMetaToCtx(ctx, parseRequestMeta(get, MetadataCorrelationId, MetadataOperatorId, MetadataUserId))
+}
+
+func requestMetaValues(meta RequestMeta, correlationIdKey, operatorIdKey, userIdKey string) map[string]string {
+	values := make(map[string]string, 3)
+	if meta.CorrelationId != "" {
+		values[correlationIdKey] = meta.CorrelationId
+	}
+	if meta.OperatorId != 0 {
+		values[operatorIdKey] = strconv.FormatInt(meta.OperatorId, 10)
+	}
+	if meta.UserId != 0 {
+		values[userIdKey] = strconv.FormatInt(meta.UserId, 10)
+	}
+	return values
+}
+
+func parseRequestMeta(get func(string) string, correlationIdKey, operatorIdKey, userIdKey string) RequestMeta {
+	meta := RequestMeta{CorrelationId: get(correlationIdKey)}
+	if operatorId, err := strconv.ParseInt(get(operatorIdKey), 10, 64); err == nil {
+		meta.OperatorId = operatorId
+	}
+	if userId, err := strconv.ParseInt(get(userIdKey), 10, 64); err == nil {
+		meta.UserId = userId
+	}
+	return meta
+}